@@ -0,0 +1,304 @@
+// Package admin serves remote admin operations on a running world over HTTP: triggering an autosave, changing the
+// speed, toggling overlays, starting disasters, querying, naming and tagging beings and food and shutting down
+// gracefully, and the statistics of the run. Every request has to carry the token (Authorization: Bearer <token>)
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/terrain"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// How long a request waits for the world to run its operation
+	commandTimeout = 30 * time.Second
+)
+
+// Controls are the operations that depend on how the world is run (the ones left nil are not available)
+type Controls struct {
+	Save     func() error                    // Save the world right away
+	Speed    func(tps int)                   // Run the given number of epochs every second
+	Overlay  func(name string) (bool, error) // Toggle the overlay, returns if it is shown now
+	Shutdown func()                          // Stop the run after the current epoch (from any goroutine)
+}
+
+// Server serves the admin operations of a world
+type Server struct {
+	Token    string
+	Controls Controls
+	world    *terrain.RandomWorld
+	commands chan *command
+}
+
+// The states of a command (see command.state)
+const (
+	waiting int32 = iota
+	running
+	cancelled
+)
+
+// command is an operation waiting for the world to run it between two epochs
+type command struct {
+	run   func() (interface{}, error)
+	done  chan reply
+	state int32 // Waiting, running or cancelled (the request gave up waiting), changed atomically
+}
+
+// reply is the outcome of a command
+type reply struct {
+	value interface{}
+	err   error
+}
+
+// operation reads the request of an admin operation and returns what has to run between two epochs
+// Returns an error if the request is not valid (nothing runs then)
+type operation func(r *http.Request) (func() (interface{}, error), error)
+
+// New starts taking admin operations for the world, they run at the end of an epoch (so they never happen in the
+// middle of an update)
+// Returns an error if the token is empty (anybody could run the operations)
+func New(world *terrain.RandomWorld, token string, controls Controls) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("error creating admin server: the token is empty")
+	}
+	s := &Server{Token: token, Controls: controls, world: world, commands: make(chan *command, 16)}
+	world.Subscribe(func(action string, _ []uuid.UUID) {
+		if action == "ticked" {
+			s.runCommands()
+		}
+	})
+	return s, nil
+}
+
+// runCommands runs the operations waiting for the world, except the ones the requests stopped waiting for
+func (s *Server) runCommands() {
+	for {
+		select {
+		case c := <-s.commands:
+			if !atomic.CompareAndSwapInt32(&c.state, waiting, running) {
+				continue
+			}
+			value, err := c.run()
+			c.done <- reply{value: value, err: err}
+		default:
+			return
+		}
+	}
+}
+
+// Handler returns the handler of the admin operations (all of them are POST requests):
+//
+// /admin/autosave saves the world, /admin/speed sets the epochs per second ({"TPS": 30}), /admin/overlay toggles an
+// overlay ({"Name": "Timings"}), /admin/disaster makes a scenario event happen ({"Kind": "Drought", "Amount": 0.3}
+// or {"Kind": "Spawn", "Type": "Water", "Count": 20, "Region": {"WaterBody": 2}}, see terrain.ScenarioEvent),
+// /admin/query returns the IDs of the beings and food matching an expression
+// ({"Expression": "type == 'Carnivore' && hunger > 200"}, see terrain.Query), /admin/name names a being or food
+// ({"ID": "<uuid>", "Name": "Rex"}, an empty name removes it), /admin/tag adds a tag to it or removes one ({"ID":
+// "<uuid>", "Tag": "study", "Remove": false}), /admin/stats returns the species summary, the predator and prey samples
+// and the energy flows of the food web over the run (see terrain.Stats) and /admin/shutdown ends the run (right away,
+// also while the world is paused)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/autosave", s.handle(func(*http.Request) (func() (interface{}, error), error) {
+		if s.Controls.Save == nil {
+			return nil, fmt.Errorf("error saving world: autosave is not enabled")
+		}
+		return func() (interface{}, error) {
+			return map[string]uint64{"Epoch": s.world.Epoch}, s.Controls.Save()
+		}, nil
+	}))
+	mux.HandleFunc("/admin/speed", s.handle(func(r *http.Request) (func() (interface{}, error), error) {
+		var speed struct{ TPS int }
+		if err := json.NewDecoder(r.Body).Decode(&speed); err != nil {
+			return nil, fmt.Errorf("error changing speed: %v", err)
+		}
+		if speed.TPS <= 0 {
+			return nil, fmt.Errorf("error changing speed: %d epochs per second", speed.TPS)
+		}
+		if s.Controls.Speed == nil {
+			return nil, fmt.Errorf("error changing speed: the speed can not be changed")
+		}
+		return func() (interface{}, error) {
+			s.Controls.Speed(speed.TPS)
+			return speed, nil
+		}, nil
+	}))
+	mux.HandleFunc("/admin/overlay", s.handle(func(r *http.Request) (func() (interface{}, error), error) {
+		var overlay struct {
+			Name  string
+			Shown bool
+		}
+		if err := json.NewDecoder(r.Body).Decode(&overlay); err != nil {
+			return nil, fmt.Errorf("error toggling overlay: %v", err)
+		}
+		if s.Controls.Overlay == nil {
+			return nil, fmt.Errorf("error toggling overlay: there is no display")
+		}
+		return func() (interface{}, error) {
+			var err error
+			overlay.Shown, err = s.Controls.Overlay(overlay.Name)
+			return overlay, err
+		}, nil
+	}))
+	mux.HandleFunc("/admin/disaster", s.handle(func(r *http.Request) (func() (interface{}, error), error) {
+		var event terrain.ScenarioEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			return nil, fmt.Errorf("error starting disaster: %v", err)
+		}
+		if !terrain.KnownEvent(event.Kind) {
+			return nil, fmt.Errorf("error starting disaster: unknown event %v", event.Kind)
+		}
+		return func() (interface{}, error) {
+			event.Tick = s.world.Epoch
+			if err := s.world.Happen(event); err != nil {
+				return nil, err
+			}
+			return event, nil
+		}, nil
+	}))
+	mux.HandleFunc("/admin/query", s.handle(func(r *http.Request) (func() (interface{}, error), error) {
+		var query struct {
+			Expression string
+			IDs        []uuid.UUID
+		}
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			return nil, fmt.Errorf("error querying world: %v", err)
+		}
+		return func() (interface{}, error) {
+			var err error
+			query.IDs, err = s.world.Query(query.Expression)
+			return query, err
+		}, nil
+	}))
+	mux.HandleFunc("/admin/name", s.handle(func(r *http.Request) (func() (interface{}, error), error) {
+		var name struct {
+			ID   uuid.UUID
+			Name string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&name); err != nil {
+			return nil, fmt.Errorf("error naming entity: %v", err)
+		}
+		return func() (interface{}, error) {
+			return name, s.world.NameEntity(name.ID, name.Name)
+		}, nil
+	}))
+	mux.HandleFunc("/admin/tag", s.handle(func(r *http.Request) (func() (interface{}, error), error) {
+		var tag struct {
+			ID     uuid.UUID
+			Tag    string
+			Remove bool
+		}
+		if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+			return nil, fmt.Errorf("error tagging entity: %v", err)
+		}
+		return func() (interface{}, error) {
+			if tag.Remove {
+				return tag, s.world.UntagEntity(tag.ID, tag.Tag)
+			}
+			return tag, s.world.TagEntity(tag.ID, tag.Tag)
+		}, nil
+	}))
+	mux.HandleFunc("/admin/stats", s.handle(func(*http.Request) (func() (interface{}, error), error) {
+		return func() (interface{}, error) {
+			return struct {
+				Epoch        uint64
+				Species      []GoWorld.SpeciesSummary
+				PredatorPrey []GoWorld.PredatorPreySample
+				Energy       []GoWorld.EnergyWindow
+				EnergyTotals []GoWorld.EnergyFlow
+			}{s.world.Epoch, s.world.SpeciesSummary(), s.world.PredatorPrey(), s.world.EnergyFlows(),
+				s.world.EnergyTotals()}, nil
+		}, nil
+	}))
+	// Stopping does not wait for an epoch, a paused world would never get to it
+	mux.HandleFunc("/admin/shutdown", s.authorized(func(w http.ResponseWriter, r *http.Request) {
+		if s.Controls.Shutdown == nil {
+			respond(w, http.StatusBadRequest, fmt.Errorf("error shutting down: the run can not be stopped"))
+			return
+		}
+		s.Controls.Shutdown()
+		respond(w, http.StatusOK, map[string]bool{"Stopping": true})
+	}))
+	return mux
+}
+
+// authorized only lets POST requests carrying the token through to the handler
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			respond(w, http.StatusMethodNotAllowed, fmt.Errorf("error running operation: only POST is allowed"))
+			return
+		}
+		token := []byte("Bearer " + s.Token)
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), token) != 1 {
+			respond(w, http.StatusUnauthorized, fmt.Errorf("error running operation: wrong token"))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// handle checks the token and reads the request, runs the operation between two epochs and answers with its outcome
+// as JSON. An operation the world does not get to in time is cancelled, it does not run later
+func (s *Server) handle(read operation) http.HandlerFunc {
+	return s.authorized(func(w http.ResponseWriter, r *http.Request) {
+		run, err := read(r)
+		if err != nil {
+			respond(w, http.StatusBadRequest, err)
+			return
+		}
+		c := &command{run: run, done: make(chan reply, 1)}
+		select {
+		case s.commands <- c:
+		case <-time.After(commandTimeout):
+			respond(w, http.StatusServiceUnavailable, fmt.Errorf("error running operation: too many waiting"))
+			return
+		}
+		select {
+		case result := <-c.done:
+			respondWith(w, result)
+		case <-time.After(commandTimeout):
+			if atomic.CompareAndSwapInt32(&c.state, waiting, cancelled) {
+				// The world may be paused or stopped
+				respond(w, http.StatusGatewayTimeout, fmt.Errorf("error running operation: the world did not run it"))
+				return
+			}
+			// The world started running it just now
+			respondWith(w, <-c.done)
+		}
+	})
+}
+
+// respondWith writes the outcome of the command as JSON
+func respondWith(w http.ResponseWriter, result reply) {
+	if result.err != nil {
+		respond(w, http.StatusBadRequest, result.err)
+		return
+	}
+	respond(w, http.StatusOK, result.value)
+}
+
+// respond writes the value (or the error) as JSON
+func respond(w http.ResponseWriter, status int, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = map[string]string{"Error": err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// ListenAndServe serves the admin operations on the address (e.g. localhost:8081), it returns only when serving fails
+func (s *Server) ListenAndServe(address string) error {
+	if err := http.ListenAndServe(address, s.Handler()); err != nil {
+		return fmt.Errorf("error serving admin operations: %v", err)
+	}
+	return nil
+}
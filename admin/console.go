@@ -0,0 +1,397 @@
+package admin
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld/autosave"
+	"github.com/rubinda/GoWorld/terrain"
+	"strconv"
+	"strings"
+)
+
+// consoleHelp lists the commands of the console
+const consoleHelp = `spawn <type> <count>     add random beings of the type (e.g. spawn carnivore 10), optionally only
+                         in a box, on a surface or in a water body (e.g. spawn water 20 lake 2, spawn
+                         flying 5 in 0 0 100 50 on forest)
+lakes                    list the water bodies (their ids, sizes and bounding boxes)
+predators [n]            list the latest n (10) predator and prey samples (populations and kill rates)
+energy                   list the energy that went between the levels of the food web over the run
+kill <id>                kill the being (the start of its id is enough)
+describe <id>            show what the being is doing and its main attributes
+speed <tps>              run the given number of epochs every second
+save [file]              save the world into the file (or autosave it)
+load <file>              restore the world from the file
+overlay <name>           toggle the overlay (Timings, Perception, Graph, Drift or Species)
+disaster <kind> [amount] make a scenario event happen (e.g. disaster drought 0.3)
+query <expression>       list the beings and food matching (e.g. query type == 'Insect' && age > 100)
+name <id> [name]         name the being or food to follow it (no name removes it)
+tag <id> <tag>           tag the being or food (query them with tags == 'tag')
+untag <id> <tag>         remove the tag from the being or food
+quit                     end the run`
+
+const (
+	// How many of the matching ids the query command lists
+	consoleQueryShown = 10
+	// How many of the latest samples the predators command lists
+	consolePredatorPreyShown = 10
+)
+
+// Console runs the admin operations typed as commands (e.g. in a console of the display), it takes the same controls
+// as the HTTP server
+type Console struct {
+	Controls Controls
+	world    *terrain.RandomWorld
+}
+
+// NewConsole creates a console for the world
+func NewConsole(world *terrain.RandomWorld, controls Controls) *Console {
+	return &Console{Controls: controls, world: world}
+}
+
+// Exec runs the command line (call it between two epochs, e.g. from the update of the display)
+// Returns what the command did or an error if it is unknown, misses arguments or fails
+func (c *Console) Exec(line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	args := fields[1:]
+	argument := func(i int, name string) (string, error) {
+		if i >= len(args) {
+			return "", fmt.Errorf("error running %v: missing %v", fields[0], name)
+		}
+		return args[i], nil
+	}
+	switch strings.ToLower(fields[0]) {
+	case "help":
+		return consoleHelp, nil
+	case "spawn":
+		beingType, err := argument(0, "type")
+		if err != nil {
+			return "", err
+		}
+		beingType = strings.Title(strings.ToLower(beingType))
+		if !terrain.KnownType(beingType) {
+			return "", fmt.Errorf("error spawning beings: unknown type %v", beingType)
+		}
+		count := 1
+		if len(args) > 1 {
+			if count, err = strconv.Atoi(args[1]); err != nil || count <= 0 {
+				return "", fmt.Errorf("error spawning beings: %v is not a count", args[1])
+			}
+		}
+		event := terrain.ScenarioEvent{Tick: c.world.Epoch, Kind: "Spawn", Type: beingType, Count: count}
+		if len(args) > 2 {
+			if event.Region, err = parseRegion(args[2:]); err != nil {
+				return "", err
+			}
+		}
+		if err := c.world.Happen(event); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("spawned %d %v", count, beingType), nil
+	case "lakes":
+		bodies := c.world.WaterBodies()
+		lines := make([]string, 0, len(bodies))
+		for _, body := range bodies {
+			lines = append(lines, fmt.Sprintf("lake %d: %d spots from %v to %v", body.ID, body.Size, body.Min,
+				body.Max))
+		}
+		if len(lines) == 0 {
+			return "no water bodies", nil
+		}
+		return strings.Join(lines, "\n"), nil
+	case "predators":
+		shown := consolePredatorPreyShown
+		if len(args) > 0 {
+			var err error
+			if shown, err = strconv.Atoi(args[0]); err != nil || shown <= 0 {
+				return "", fmt.Errorf("error listing predators: %v is not a count", args[0])
+			}
+		}
+		samples := c.world.PredatorPrey()
+		if len(samples) > shown {
+			samples = samples[len(samples)-shown:]
+		}
+		lines := make([]string, 0, len(samples))
+		for _, s := range samples {
+			lines = append(lines, fmt.Sprintf("epoch %d: %d predators, %d prey, %d kills (%.3f per predator and epoch)",
+				s.Epoch, s.Predators, s.Prey, s.Kills, s.KillRate))
+		}
+		if len(lines) == 0 {
+			return "no samples yet", nil
+		}
+		return strings.Join(lines, "\n"), nil
+	case "energy":
+		totals := c.world.EnergyTotals()
+		lines := make([]string, 0, len(totals))
+		for _, flow := range totals {
+			lines = append(lines, fmt.Sprintf("%v to %v: %.1f taken, %.1f absorbed", flow.From, flow.To, flow.Taken,
+				flow.Absorbed))
+		}
+		if len(lines) == 0 {
+			return "no energy went anywhere yet", nil
+		}
+		return strings.Join(lines, "\n"), nil
+	case "kill":
+		prefix, err := argument(0, "id")
+		if err != nil {
+			return "", err
+		}
+		id, err := c.findBeing(prefix)
+		if err != nil {
+			return "", err
+		}
+		if err := c.world.KillBeing(id, "console"); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("killed %v", id), nil
+	case "describe":
+		prefix, err := argument(0, "id")
+		if err != nil {
+			return "", err
+		}
+		id, err := c.findBeing(prefix)
+		if err != nil {
+			return "", err
+		}
+		d, err := c.world.DescribeBeing(id)
+		if err != nil {
+			return "", err
+		}
+		label := id.String()
+		if d.Name != "" {
+			label += " " + strconv.Quote(d.Name)
+		}
+		grown := "adult"
+		if d.Juvenile {
+			grown = "juvenile"
+		}
+		return fmt.Sprintf("%v %v, %v %v, age %.0f (%.0f%% of its life)\n"+
+			"chose %q, did %q, %d moves left, sight %.1f, pace %.1f on %v\n"+
+			"hunger %.0f, thirst %.0f, stress %.0f, energy %.0f, offspring %d (%d alive)",
+			d.Type, label, grown, d.Gender, d.Age, 100*d.Lived, d.Action, d.Done, d.PathLeft, d.Sight, d.Pace,
+			d.Surface, d.Hunger, d.Thirst, d.Stress, d.Energy, d.Offspring, d.Living), nil
+	case "speed":
+		tps, err := argument(0, "epochs per second")
+		if err != nil {
+			return "", err
+		}
+		speed, err := strconv.Atoi(tps)
+		if err != nil || speed <= 0 {
+			return "", fmt.Errorf("error changing speed: %v epochs per second", tps)
+		}
+		if c.Controls.Speed == nil {
+			return "", fmt.Errorf("error changing speed: the speed can not be changed")
+		}
+		c.Controls.Speed(speed)
+		return fmt.Sprintf("running %d epochs per second", speed), nil
+	case "save":
+		if len(args) == 0 {
+			if c.Controls.Save == nil {
+				return "", fmt.Errorf("error saving world: autosave is not enabled (name a file)")
+			}
+			return fmt.Sprintf("saved epoch %d", c.world.Epoch), c.Controls.Save()
+		}
+		if err := autosave.SaveFile(args[0], c.world.Snapshot()); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("saved epoch %d into %v", c.world.Epoch, args[0]), nil
+	case "load":
+		path, err := argument(0, "file")
+		if err != nil {
+			return "", err
+		}
+		snapshot, err := autosave.LoadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error loading world: %v", err)
+		}
+		if err := c.world.Restore(snapshot); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("restored epoch %d from %v", snapshot.Epoch, path), nil
+	case "overlay":
+		name, err := argument(0, "name")
+		if err != nil {
+			return "", err
+		}
+		if c.Controls.Overlay == nil {
+			return "", fmt.Errorf("error toggling overlay: there is no display")
+		}
+		shown, err := c.Controls.Overlay(name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%v shown: %v", name, shown), nil
+	case "disaster":
+		kind, err := argument(0, "kind")
+		if err != nil {
+			return "", err
+		}
+		event := terrain.ScenarioEvent{Tick: c.world.Epoch, Kind: strings.Title(strings.ToLower(kind))}
+		if !terrain.KnownEvent(event.Kind) {
+			return "", fmt.Errorf("error starting disaster: unknown event %v", event.Kind)
+		}
+		if len(args) > 1 {
+			if event.Amount, err = strconv.ParseFloat(args[1], 64); err != nil {
+				return "", fmt.Errorf("error starting disaster: %v is not an amount", args[1])
+			}
+		}
+		if err := c.world.Happen(event); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%v happened", event.Kind), nil
+	case "query":
+		if len(args) == 0 {
+			return "", fmt.Errorf("error running query: missing expression")
+		}
+		// The expression is the rest of the line as typed (strings can hold spaces)
+		ids, err := c.world.Query(strings.TrimSpace(strings.TrimSpace(line)[len(fields[0]):]))
+		if err != nil {
+			return "", err
+		}
+		// The start of an id is enough for the other commands
+		shown := make([]string, 0, consoleQueryShown)
+		for _, id := range ids {
+			if len(shown) == consoleQueryShown {
+				shown = append(shown, "...")
+				break
+			}
+			shown = append(shown, id.String()[:8])
+		}
+		return fmt.Sprintf("%d matching: %v", len(ids), strings.Join(shown, " ")), nil
+	case "name":
+		prefix, err := argument(0, "id")
+		if err != nil {
+			return "", err
+		}
+		id, err := c.findEntity(prefix)
+		if err != nil {
+			return "", err
+		}
+		// The name is the rest of the line (it can hold spaces)
+		rest := strings.TrimSpace(strings.TrimSpace(line)[len(fields[0]):])
+		name := strings.TrimSpace(rest[len(prefix):])
+		if err := c.world.NameEntity(id, name); err != nil {
+			return "", err
+		}
+		if name == "" {
+			return fmt.Sprintf("removed the name of %v", id), nil
+		}
+		return fmt.Sprintf("named %v %v", id, name), nil
+	case "tag", "untag":
+		prefix, err := argument(0, "id")
+		if err != nil {
+			return "", err
+		}
+		tag, err := argument(1, "tag")
+		if err != nil {
+			return "", err
+		}
+		id, err := c.findEntity(prefix)
+		if err != nil {
+			return "", err
+		}
+		if strings.ToLower(fields[0]) == "untag" {
+			if err := c.world.UntagEntity(id, tag); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("untagged %v %v", id, tag), nil
+		}
+		if err := c.world.TagEntity(id, tag); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("tagged %v %v", id, tag), nil
+	case "quit":
+		if c.Controls.Shutdown == nil {
+			return "", fmt.Errorf("error shutting down: the run can not be stopped")
+		}
+		c.Controls.Shutdown()
+		return "stopping", nil
+	}
+	return "", fmt.Errorf("error running %v: unknown command (see help)", fields[0])
+}
+
+// findBeing returns the being whose id starts with the prefix
+// Returns an error if no being or more than one have such an id
+func (c *Console) findBeing(prefix string) (uuid.UUID, error) {
+	var found []uuid.UUID
+	for id, b := range c.world.BeingList {
+		if strings.HasPrefix(id, strings.ToLower(prefix)) {
+			found = append(found, b.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return uuid.Nil, fmt.Errorf("error finding being: no being with id %v", prefix)
+	case 1:
+		return found[0], nil
+	}
+	return uuid.Nil, fmt.Errorf("error finding being: %d beings have an id starting with %v", len(found), prefix)
+}
+
+// findEntity returns the being or food whose id starts with the prefix
+// Returns an error if nothing or more than one being or food have such an id
+func (c *Console) findEntity(prefix string) (uuid.UUID, error) {
+	var found []uuid.UUID
+	for id, b := range c.world.BeingList {
+		if strings.HasPrefix(id, strings.ToLower(prefix)) {
+			found = append(found, b.ID)
+		}
+	}
+	for id, f := range c.world.FoodList {
+		if strings.HasPrefix(id, strings.ToLower(prefix)) {
+			found = append(found, f.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return uuid.Nil, fmt.Errorf("error finding entity: no being or food with id %v", prefix)
+	case 1:
+		return found[0], nil
+	}
+	return uuid.Nil, fmt.Errorf("error finding entity: %d beings and food have an id starting with %v", len(found),
+		prefix)
+}
+
+// parseRegion reads the region beings spawn in from the words after the count: "in <x0> <y0> <x1> <y1>" (a bounding
+// box), "on <surface>" and "lake <id>" (see terrain.Region)
+// Returns an error if a part is unknown or misses its values
+func parseRegion(words []string) (*terrain.Region, error) {
+	region := &terrain.Region{}
+	for i := 0; i < len(words); i++ {
+		switch strings.ToLower(words[i]) {
+		case "in":
+			if i+4 >= len(words) {
+				return nil, fmt.Errorf("error spawning beings: a box needs two corners (in x0 y0 x1 y1)")
+			}
+			var corners [4]int
+			for j := range corners {
+				var err error
+				if corners[j], err = strconv.Atoi(words[i+1+j]); err != nil {
+					return nil, fmt.Errorf("error spawning beings: %v is not a coordinate", words[i+1+j])
+				}
+			}
+			region.Min.X, region.Min.Y, region.Max.X, region.Max.Y = corners[0], corners[1], corners[2], corners[3]
+			i += 4
+		case "on":
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("error spawning beings: missing surface")
+			}
+			region.Surface = strings.Title(strings.ToLower(words[i+1]))
+			i++
+		case "lake":
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("error spawning beings: missing water body")
+			}
+			var err error
+			if region.WaterBody, err = strconv.Atoi(words[i+1]); err != nil || region.WaterBody <= 0 {
+				return nil, fmt.Errorf("error spawning beings: %v is not a water body", words[i+1])
+			}
+			i++
+		default:
+			return nil, fmt.Errorf("error spawning beings: unknown region %v (in, on or lake)", words[i])
+		}
+	}
+	return region, nil
+}
@@ -0,0 +1,8 @@
+package GoWorld
+
+import "embed"
+
+// Assets holds the default sprites (assets/*.png) inside the binary, so it runs from any directory
+//
+//go:embed assets/*.png
+var Assets embed.FS
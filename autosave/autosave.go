@@ -0,0 +1,156 @@
+// Package autosave periodically stores gzip-compressed snapshots of a world and finds the latest one to resume from
+// (SaveFile and LoadFile store and read single snapshots)
+package autosave
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/rubinda/GoWorld"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	filePrefix = "autosave-"
+	fileSuffix = ".gob.gz"
+)
+
+// Saver writes a snapshot of the world every few epochs and keeps only the latest saves
+type Saver struct {
+	Dir   string // Where the saves are stored
+	Every uint64 // How many epochs pass between two saves
+	Keep  int    // How many of the latest saves are kept (older ones are removed)
+	// Saved is told about every save written (e.g. to notify someone), nothing is told if nil
+	Saved func(epoch uint64, path string)
+	ticks uint64 // How many epochs passed since the saver started
+}
+
+// New creates a saver that stores a save into the directory every few epochs and keeps the latest ones
+func New(dir string, every uint64, keep int) (*Saver, error) {
+	if every == 0 || keep <= 0 {
+		return nil, fmt.Errorf("error creating autosave: saves have to happen (every %d epochs, keeping %d)", every,
+			keep)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating autosave: %v", err)
+	}
+	return &Saver{Dir: dir, Every: every, Keep: keep}, nil
+}
+
+// Tick counts an epoch of the world (call once per update) and saves the world when it is time
+func (s *Saver) Tick(world GoWorld.World) error {
+	s.ticks++
+	if s.ticks%s.Every != 0 {
+		return nil
+	}
+	return s.Save(world.Snapshot())
+}
+
+// Save writes the snapshot and removes the saves that are no longer kept
+func (s *Saver) Save(snapshot *GoWorld.Snapshot) error {
+	path := filepath.Join(s.Dir, fmt.Sprintf("%s%020d%s", filePrefix, snapshot.Epoch, fileSuffix))
+	if err := SaveFile(path, snapshot); err != nil {
+		return err
+	}
+	if s.Saved != nil {
+		s.Saved(snapshot.Epoch, path)
+	}
+	return s.rotate()
+}
+
+// SaveFile writes the gzip-compressed snapshot to the path (e.g. a quicksave). The save is written to a temporary file
+// first, so a crash never leaves a broken save behind
+func SaveFile(path string, snapshot *GoWorld.Snapshot) error {
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return fmt.Errorf("error saving world: %v", err)
+	}
+	zipped := gzip.NewWriter(file)
+	err = GoWorld.EncodeSnapshot(zipped, snapshot, GoWorld.BinaryFormat)
+	if closeErr := zipped.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return fmt.Errorf("error saving world: %v", err)
+	}
+	return nil
+}
+
+// rotate removes all but the latest saves
+func (s *Saver) rotate() error {
+	saves, err := s.saves()
+	if err != nil {
+		return err
+	}
+	for len(saves) > s.Keep {
+		if err := os.Remove(saves[0]); err != nil {
+			return fmt.Errorf("error removing old save: %v", err)
+		}
+		saves = saves[1:]
+	}
+	return nil
+}
+
+// saves returns the paths of the saves in the directory, from the oldest to the latest
+func (s *Saver) saves() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing saves: %v", err)
+	}
+	var saves []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		epoch := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix)
+		if _, err := strconv.ParseUint(epoch, 10, 64); err != nil {
+			continue
+		}
+		saves = append(saves, filepath.Join(s.Dir, name))
+	}
+	// The epochs are zero padded, so the names sort in the order of epochs
+	sort.Strings(saves)
+	return saves, nil
+}
+
+// Latest returns the snapshot from the latest save that can be read (broken saves are skipped)
+// Returns nil if there is no such save
+func (s *Saver) Latest() (*GoWorld.Snapshot, error) {
+	saves, err := s.saves()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(saves) - 1; i >= 0; i-- {
+		if snapshot, err := LoadFile(saves[i]); err == nil {
+			return snapshot, nil
+		}
+	}
+	return nil, nil
+}
+
+// LoadFile reads the snapshot from a save (or a file written by SaveFile)
+func LoadFile(path string) (*GoWorld.Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	zipped, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer zipped.Close()
+	return GoWorld.DecodeSnapshot(zipped, GoWorld.BinaryFormat)
+}
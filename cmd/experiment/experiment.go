@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"github.com/rubinda/GoWorld/experiment"
+	"github.com/rubinda/GoWorld/terrain"
+	"io"
+	"os"
+	"strings"
+)
+
+// parameters collect the parameters of a sweep from repeated flags
+type parameters []experiment.Parameter
+
+func (p *parameters) String() string {
+	names := make([]string, len(*p))
+	for i, parameter := range *p {
+		names[i] = parameter.Name
+	}
+	return strings.Join(names, " x ")
+}
+
+func (p *parameters) Set(text string) error {
+	parameter, err := experiment.ParseParameter(text)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, parameter)
+	return nil
+}
+
+func main() {
+	configFile := flag.String("config", "", "JSON file describing the world (see terrain.Config)")
+	scenarioFile := flag.String("scenario", "", "JSON file describing a repeatable setup (see terrain.Scenario)")
+	runs := flag.Int("runs", 8, "How many simulations are run")
+	seed := flag.Int64("seed", 1, "The seed of the first run (the next runs use the following seeds)")
+	epochs := flag.Uint64("epochs", 10000, "How many epochs every run lasts at most (no limit if 0)")
+	stopExtinct := flag.Bool("stop-extinct", false, "Stop a run once all beings died out")
+	stopPopulation := flag.Int("stop-population", 0, "Stop a run once more beings than this live (no limit if 0)")
+	stopQuery := flag.String("stop-query", "", "Stop a run once any being or food matches the expression (e.g. "+
+		"\"type == 'Insect' && age > 500\", see terrain.Query)")
+	workers := flag.Int("workers", 0, "How many runs are made at once (the number of CPUs if 0)")
+	reportFile := flag.String("report", "report.json", "File to write the report into")
+	runReports := flag.String("run-reports", "", "Directory to write a summary page of every run into")
+	var vary parameters
+	flag.Var(&vary, "vary", "A config value and the values it takes in a sweep over all their combinations (e.g. "+
+		"NeedIncrements.Hunger=0.5,1,2, repeat for more values)")
+	flag.Parse()
+	terrain.LogDeaths = false
+
+	// Describe the world (a scenario takes precedence over a config)
+	scenario := &terrain.Scenario{Config: *terrain.DefaultConfig()}
+	if *scenarioFile != "" {
+		var err error
+		if scenario, err = terrain.LoadScenario(*scenarioFile); err != nil {
+			panic(err)
+		}
+	} else if *configFile != "" {
+		config, err := terrain.LoadConfig(*configFile)
+		if err != nil {
+			panic(err)
+		}
+		scenario.Config = *config
+	}
+	e := &experiment.Experiment{
+		Scenario: scenario,
+		Seeds:    experiment.Seeds(*seed, *runs),
+		Epochs:   *epochs,
+		Workers:  *workers,
+		Reports:  *runReports,
+	}
+	if *stopExtinct {
+		e.Until = append(e.Until, experiment.Condition{Kind: "Extinction"})
+	}
+	if *stopPopulation > 0 {
+		e.Until = append(e.Until, experiment.Condition{Kind: "Population", Limit: float64(*stopPopulation)})
+	}
+	if *stopQuery != "" {
+		e.Until = append(e.Until, experiment.Condition{Kind: "Query", Query: *stopQuery})
+	}
+	var report interface{ Write(w io.Writer) error }
+	var err error
+	if len(vary) > 0 {
+		report, err = (&experiment.Sweep{Experiment: *e, Parameters: vary}).Run()
+	} else {
+		report, err = e.Run()
+	}
+	if err != nil {
+		panic(err)
+	}
+	out, err := os.Create(*reportFile)
+	if err != nil {
+		panic(err)
+	}
+	defer out.Close()
+	if err := report.Write(out); err != nil {
+		panic(err)
+	}
+}
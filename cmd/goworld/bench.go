@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/rubinda/GoWorld/terrain"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"time"
+)
+
+// bench runs a world without the display for a number of epochs and reports how fast it went and how much it
+// allocated (goworld bench -help lists the flags)
+func bench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	configFile := flags.String("config", "", "JSON file describing the world (see terrain.Config)")
+	scenarioFile := flags.String("scenario", "", "JSON file describing a repeatable setup (see terrain.Scenario)")
+	seed := flags.Int64("seed", 1, "Seed of the world (the same seed makes runs comparable)")
+	ticks := flags.Uint64("ticks", 1000, "How many epochs the world runs")
+	cpuProfile := flags.String("cpuprofile", "", "File to write the CPU profile of the run into")
+	memProfile := flags.String("memprofile", "", "File to write the heap profile at the end of the run into")
+	_ = flags.Parse(args)
+	terrain.LogDeaths = false
+
+	scenario, err := describeWorld(*configFile, *scenarioFile)
+	if err != nil {
+		return err
+	}
+	scenario.Seed = *seed
+	started := time.Now()
+	world, err := terrain.NewWorldFromScenario(scenario, terrain.WithTerrainImage(""))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Generated a %dx%d world (seed %d) in %v\n", world.Width, world.Height, *seed, time.Since(started))
+
+	if *cpuProfile != "" {
+		file, err := os.Create(*cpuProfile)
+		if err != nil {
+			return fmt.Errorf("error creating CPU profile: %v", err)
+		}
+		defer file.Close()
+		if err := pprof.StartCPUProfile(file); err != nil {
+			return fmt.Errorf("error starting CPU profile: %v", err)
+		}
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	started = time.Now()
+	for i := uint64(0); i < *ticks; i++ {
+		world.Step()
+	}
+	elapsed := time.Since(started)
+	runtime.ReadMemStats(&after)
+	if *cpuProfile != "" {
+		pprof.StopCPUProfile()
+	}
+
+	n := float64(*ticks)
+	fmt.Printf("Ran %d ticks in %v (%.1f ticks/s, %v per tick)\n", *ticks, elapsed, n/elapsed.Seconds(),
+		elapsed/time.Duration(*ticks))
+	fmt.Printf("Allocated %.0f objects (%.0f B) per tick, %d garbage collections\n",
+		float64(after.Mallocs-before.Mallocs)/n, float64(after.TotalAlloc-before.TotalAlloc)/n,
+		after.NumGC-before.NumGC)
+	fmt.Printf("At the end: %d beings, %d food, %.1f MB heap\n", len(world.BeingList), len(world.FoodList),
+		float64(after.HeapAlloc)/(1<<20))
+	timings := world.Timings()
+	phases := make([]string, 0, len(timings))
+	for phase := range timings {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	for _, phase := range phases {
+		t := timings[phase]
+		fmt.Printf("%-9s p50 %v, p90 %v, p99 %v, max %v per tick\n", phase, t.P50, t.P90, t.P99, t.Max)
+	}
+
+	if *memProfile != "" {
+		file, err := os.Create(*memProfile)
+		if err != nil {
+			return fmt.Errorf("error creating heap profile: %v", err)
+		}
+		defer file.Close()
+		// Collect the garbage first, so the profile shows what is still in use
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(file); err != nil {
+			return fmt.Errorf("error writing heap profile: %v", err)
+		}
+	}
+	return nil
+}
+
+// describeWorld returns the world the scenario or config file describes (a scenario takes precedence over a config,
+// the default world if there is neither)
+func describeWorld(configFile, scenarioFile string) (*terrain.Scenario, error) {
+	if scenarioFile != "" {
+		return terrain.LoadScenario(scenarioFile)
+	}
+	scenario := &terrain.Scenario{Config: *terrain.DefaultConfig()}
+	if configFile != "" {
+		config, err := terrain.LoadConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Config = *config
+	}
+	return scenario, nil
+}
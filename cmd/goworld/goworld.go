@@ -1,29 +1,425 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/rubinda/GoWorld/admin"
+	"github.com/rubinda/GoWorld/autosave"
+	"github.com/rubinda/GoWorld/compare"
 	"github.com/rubinda/GoWorld/display"
+	"github.com/rubinda/GoWorld/publish"
+	"github.com/rubinda/GoWorld/render3d"
+	"github.com/rubinda/GoWorld/replay"
+	"github.com/rubinda/GoWorld/report"
+	"github.com/rubinda/GoWorld/script"
 	"github.com/rubinda/GoWorld/terrain"
+	"github.com/rubinda/GoWorld/viewer"
+	"github.com/rubinda/GoWorld/webhook"
+	"net/http"
+	_ "net/http/pprof" // Registers the profiling handlers on the default server
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
-const (
-	width  = 1000
-	height = 1000
+// The headless run (changed only between two epochs, e.g. by the admin operations)
+var (
+	// How many epochs run every second (as many as possible if 0)
+	headlessSpeed int
+	// Set (to 1) once the run should end, also by the admin operations while an epoch runs
+	headlessStopped int32
 )
 
+// urls collects the values of a repeated flag
+type urls []string
+
+func (u *urls) String() string {
+	return strings.Join(*u, ",")
+}
+
+func (u *urls) Set(url string) error {
+	*u = append(*u, url)
+	return nil
+}
+
 func main() {
-	// Initialize a world
-	world := &terrain.RandomWorld{
-		Width: width, Height: height,
-	}
-	// Create the terrain
-	_ = world.New()
-	// Add beings
-	world.CreateCarnivores(15)
-	world.CreateFishies(10)
-	world.CreateFlyers(15)
-	// Add food
-	world.ProvideFood(30, 20)
-
-	// Run the animation
-	display.Run(world)
+	// Measure the performance without the display (goworld bench)
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		if err := bench(os.Args[2:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+	// Check that a recorded run replays the same way (goworld replay)
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := verifyReplay(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	// Run many short worlds looking for panics (goworld soak)
+	if len(os.Args) > 1 && os.Args[1] == "soak" {
+		if err := soak(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	configFile := flag.String("config", "", "JSON file describing the world (see terrain.Config)")
+	scenarioFile := flag.String("scenario", "", "JSON file describing a repeatable setup (see terrain.Scenario)")
+	scriptFile := flag.String("script", "", "Lua script that decides what beings do (see package script, only in the "+
+		"first world with -compare)")
+	scriptType := flag.String("script-type", "", "The being type that uses the script (all types if empty)")
+	pollination := flag.Bool("pollination", false, "Plants only produce seeds with another plant of their type nearby")
+	seed := flag.Int64("seed", 0, "Seed that makes the world the same on every run (overrides the one in the config)")
+	saveDir := flag.String("autosave", "", "Directory to save the world into (and resume from)")
+	saveEvery := flag.Uint64("autosave-every", 10000, "How many epochs pass between two saves")
+	saveKeep := flag.Int("autosave-keep", 3, "How many of the latest saves are kept")
+	terrainImage := flag.String("terrain-image", "terrain.png",
+		"File to store the colored terrain into (nothing is stored if empty)")
+	meshFile := flag.String("mesh", "", "File to store the terrain into as a 3D mesh (.obj or .gltf)")
+	meshStep := flag.Int("mesh-step", 1, "Use every n-th spot of the terrain as a vertex of the mesh")
+	exportDir := flag.String("export-dir", "", "Directory to export the beings and plants into (the E key exports too)")
+	exportEvery := flag.Uint64("export-every", 0, "How many epochs pass between two exports (none if 0)")
+	exportAt := flag.String("export-at", "", "Comma separated epochs to export at (e.g. 10000,50000)")
+	gzipExports := flag.Bool("export-gzip", false, "Compress the exports")
+	csvExports := flag.Bool("export-csv", false, "Export CSV tables (a row for every being and plant) instead of JSON")
+	noPlantExports := flag.Bool("export-no-plants", false, "Only export the beings")
+	reportFile := flag.String("report", "", "HTML file to write a summary of the run into once it ends")
+	replayFile := flag.String("record", "", "File to write the event log of the run into once it ends, a seeded "+
+		"world replays the same way (see goworld replay)")
+	replayEvery := flag.Uint64("record-every", 1, "How many epochs pass between two ticks hashed into the event log")
+	var hooks urls
+	flag.Var(&hooks, "webhook", "URL to post JSON about major events to (repeat for more URLs)")
+	hookEvents := flag.String("webhook-events", "", "Comma separated events posted to the webhooks (all if empty): "+
+		"Extinction, Milestone, Autosave")
+	milestones := flag.String("webhook-milestones", "", "Populations posted once reached (e.g. 500,1000)")
+	broker := flag.String("publish", "", "Message broker to publish the events of the world to "+
+		"(e.g. nats://localhost:4222 or mqtt://localhost:1883)")
+	topic := flag.String("publish-topic", "goworld", "The prefix of the topics the events are published to")
+	viewerAddr := flag.String("viewer", "", "Address to serve a page watching the world in the browser on "+
+		"(e.g. localhost:8080)")
+	viewerEvery := flag.Uint64("viewer-every", 1, "How many epochs pass between two frames sent to the browsers")
+	assets := flag.String("assets", "", "Directory to load the sprites from (the ones embedded in the binary if empty)")
+	theme := flag.String("theme", "", "The sprite theme to draw the world in, a directory in themes/ or a path "+
+		"(replaces the Theme of the config)")
+	isometric := flag.Bool("isometric", false, "Draw the world in a 2.5D projection lifted by the terrain heights")
+	renderer := flag.String("renderer", "2d", "How the world is drawn: 2d (the map with sprites) or 3d (a heightfield "+
+		"with an orbiting camera)")
+	compareFile := flag.String("compare", "", "JSON config of a second world drawn next to the first and stepped "+
+		"together with it, for A/B comparisons (it keeps the seed of the first unless it sets its own)")
+	unattended := flag.Bool("unattended", false, "Keep the world running while the window is not focused")
+	headless := flag.Bool("headless", false, "Run without a window until interrupted (Ctrl+C), e.g. with -viewer")
+	adminAddr := flag.String("admin", "", "Address to serve the admin operations on (e.g. localhost:8081, see package "+
+		"admin)")
+	adminToken := flag.String("admin-token", os.Getenv("GOWORLD_ADMIN_TOKEN"),
+		"The token the admin requests have to carry (defaults to $GOWORLD_ADMIN_TOKEN)")
+	pprofAddr := flag.String("pprof", "", "Address to serve the profiles of the running world on (e.g. localhost:6060)")
+	flag.Parse()
+	if *renderer != "2d" && *renderer != "3d" {
+		panic(fmt.Errorf("error drawing world: unknown renderer %v (2d or 3d)", *renderer))
+	}
+	display.Isometric = *isometric
+	if *compareFile != "" && *headless {
+		panic(fmt.Errorf("error comparing worlds: -compare needs a window (not -headless)"))
+	}
+	display.Pace.PauseUnfocused = !*unattended
+	render3d.Pace.PauseUnfocused = !*unattended
+	compare.Pace.PauseUnfocused = !*unattended
+	if *assets != "" {
+		display.Assets = display.DirAssets(*assets)
+	}
+
+	// Profile the running world with 'go tool pprof http://<address>/debug/pprof/profile'
+	if *pprofAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(*pprofAddr, nil); err != nil {
+				fmt.Println("error serving profiles:", err)
+			}
+		}()
+	}
+
+	// Describe the world
+	scenario, err := describeWorld(*configFile, *scenarioFile)
+	if err != nil {
+		panic(err)
+	}
+	if *seed != 0 {
+		scenario.Seed = *seed
+	}
+	if *theme != "" {
+		scenario.Theme = *theme
+	}
+	if scenario.Theme != "" {
+		if err := display.LoadTheme(scenario.Theme); err != nil {
+			panic(err)
+		}
+	}
+	// Create the terrain and add the beings and food
+	opts := []terrain.Option{terrain.WithProgress(generationProgress()), terrain.WithPollination(*pollination)}
+	flag.Visit(func(f *flag.Flag) {
+		// The flag only replaces the path from the config when it is given
+		if f.Name == "terrain-image" {
+			opts = append(opts, terrain.WithTerrainImage(*terrainImage))
+		}
+	})
+	world, err := terrain.NewWorldFromScenario(scenario, opts...)
+	if err != nil {
+		panic(err)
+	}
+	// The second world of the comparison differs only in its config
+	var other *terrain.RandomWorld
+	if *compareFile != "" {
+		otherScenario, err := describeWorld(*compareFile, "")
+		if err != nil {
+			panic(err)
+		}
+		if otherScenario.Seed == 0 {
+			otherScenario.Seed = scenario.Seed
+		}
+		other, err = terrain.NewWorldFromScenario(otherScenario, terrain.WithProgress(generationProgress()),
+			terrain.WithPollination(*pollination), terrain.WithTerrainImage(""))
+		if err != nil {
+			panic(err)
+		}
+		compare.Labels = [2]string{"A", "B (" + filepath.Base(*compareFile) + ")"}
+		if *configFile != "" {
+			compare.Labels[0] = "A (" + filepath.Base(*configFile) + ")"
+		}
+	}
+	// Store the terrain as a mesh for other tools (e.g. Blender)
+	if *meshFile != "" {
+		if err := world.ExportMesh(*meshFile, terrain.MeshOptions{Step: *meshStep}); err != nil {
+			panic(err)
+		}
+	}
+	// Export the beings and plants when the schedule says
+	exports := terrain.ExportSchedule{Dir: *exportDir, Every: *exportEvery, Gzip: *gzipExports, CSV: *csvExports,
+		NoPlants: *noPlantExports}
+	if *exportAt != "" {
+		for _, at := range strings.Split(*exportAt, ",") {
+			epoch, err := strconv.ParseUint(strings.TrimSpace(at), 10, 64)
+			if err != nil {
+				panic(fmt.Errorf("error parsing export epochs: %v", err))
+			}
+			exports.At = append(exports.At, epoch)
+		}
+	}
+	world.ScheduleExports(exports)
+	// Save the world every few epochs and resume from the latest save
+	if *saveDir != "" {
+		saver, err := autosave.New(*saveDir, *saveEvery, *saveKeep)
+		if err != nil {
+			panic(err)
+		}
+		snapshot, err := saver.Latest()
+		if err != nil {
+			panic(err)
+		}
+		if snapshot != nil {
+			if err := world.Restore(snapshot); err != nil {
+				panic(err)
+			}
+			fmt.Printf("Resumed the world at epoch %d\n", snapshot.Epoch)
+		}
+		display.Autosave = saver
+	}
+	// Post the major events of the run to the webhooks
+	if len(hooks) > 0 {
+		var events []string
+		if *hookEvents != "" {
+			for _, event := range strings.Split(*hookEvents, ",") {
+				events = append(events, strings.TrimSpace(event))
+			}
+		}
+		var populations []int
+		if *milestones != "" {
+			for _, m := range strings.Split(*milestones, ",") {
+				population, err := strconv.Atoi(strings.TrimSpace(m))
+				if err != nil {
+					panic(fmt.Errorf("error parsing webhook milestones: %v", err))
+				}
+				populations = append(populations, population)
+			}
+		}
+		var registered []webhook.Hook
+		for _, url := range hooks {
+			registered = append(registered, webhook.Hook{URL: url, Events: events})
+		}
+		notifier, err := webhook.New(world, registered, populations)
+		if err != nil {
+			panic(err)
+		}
+		defer notifier.Wait()
+		if display.Autosave != nil {
+			display.Autosave.Saved = notifier.Saved
+		}
+	}
+	// Forward the events to the message broker, a topic for every kind of event
+	if *broker != "" {
+		b, err := publish.Dial(*broker, *topic)
+		if err != nil {
+			panic(err)
+		}
+		defer b.Close()
+		publish.Forward(world, b)
+	}
+	// Replace the built-in behavior with the script (the world falls back to it when the script has no answer)
+	if *scriptFile != "" {
+		brain, err := script.NewLuaBrain(*scriptFile, nil)
+		if err != nil {
+			panic(err)
+		}
+		defer brain.Close()
+		for _, beingType := range []string{"Carnivore", "Water", "Flying", "Scavenger", "Amphibian", "Insect"} {
+			if *scriptType == "" || *scriptType == beingType {
+				world.Brains[beingType] = brain
+			}
+		}
+	}
+	// Log the run for its replay, from the state it starts (or resumes) at
+	var events *replay.Recorder
+	if *replayFile != "" {
+		if events, err = replay.Record(world, *scenario, *replayEvery); err != nil {
+			panic(err)
+		}
+	}
+	// Follow the run for its summary
+	var recorder *report.Recorder
+	if *reportFile != "" {
+		recorder = report.New(world, 100)
+	}
+	// Follow the populations for the graph of the display
+	if !*headless && *renderer == "2d" && *compareFile == "" {
+		display.Graph = report.New(world, 10)
+		display.Graph.Keep = display.GraphSamples
+	}
+	// Stream the world to the browsers
+	if *viewerAddr != "" {
+		server, err := viewer.New(world, *viewerEvery)
+		if err != nil {
+			panic(err)
+		}
+		go func() {
+			if err := server.ListenAndServe(*viewerAddr); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
+	// The admin operations depend on how the world is run
+	controls := admin.Controls{
+		Speed:    display.SetSpeed,
+		Overlay:  display.ToggleOverlay,
+		Shutdown: display.Stop,
+	}
+	if *renderer == "3d" {
+		controls.Overlay = nil
+		controls.Shutdown = render3d.Stop
+	}
+	if other != nil {
+		controls.Overlay = nil
+		controls.Shutdown = compare.Stop
+	}
+	if *headless {
+		controls.Speed = func(tps int) { headlessSpeed = tps }
+		controls.Overlay = nil
+		controls.Shutdown = func() { atomic.StoreInt32(&headlessStopped, 1) }
+	}
+	if saver := display.Autosave; saver != nil {
+		controls.Save = func() error { return saver.Save(world.Snapshot()) }
+	}
+	// Type them into the console of the display
+	display.Console = admin.NewConsole(world, controls).Exec
+	// Take admin operations over HTTP (they run between two epochs)
+	if *adminAddr != "" {
+		server, err := admin.New(world, *adminToken, controls)
+		if err != nil {
+			panic(err)
+		}
+		go func() {
+			if err := server.ListenAndServe(*adminAddr); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
+	// Run the animation (or only the world)
+	switch {
+	case *headless:
+		runHeadless(world, display.Autosave)
+	case other != nil:
+		compare.Run(world, other)
+	case *renderer == "3d":
+		render3d.Autosave = display.Autosave
+		render3d.Run(world)
+	default:
+		display.Run(world)
+	}
+	if recorder != nil {
+		if err := recorder.WriteFile(*reportFile); err != nil {
+			panic(err)
+		}
+	}
+	if events != nil {
+		if err := events.WriteFile(*replayFile); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// runHeadless moves the world forward without a display until the program is interrupted (Ctrl+C) or the run is
+// stopped (see headlessStopped)
+func runHeadless(world *terrain.RandomWorld, saver *autosave.Saver) {
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	for atomic.LoadInt32(&headlessStopped) == 0 {
+		select {
+		case <-interrupted:
+			atomic.StoreInt32(&headlessStopped, 1)
+			continue
+		default:
+		}
+		started := time.Now()
+		world.Step()
+		if saver != nil {
+			if err := saver.Tick(world); err != nil {
+				fmt.Println(err)
+			}
+		}
+		if headlessSpeed > 0 {
+			time.Sleep(time.Second/time.Duration(headlessSpeed) - time.Since(started))
+		}
+	}
+	fmt.Printf("Stopped the world at epoch %d\n", world.Epoch)
+}
+
+// generationProgress shows the progress of the terrain generation on the terminal, interrupting the program (Ctrl+C)
+// cancels the generation
+func generationProgress() (context.Context, terrain.Progress) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		<-interrupted
+		cancel()
+	}()
+	shown := -1
+	return ctx, func(percent float64, phase string) {
+		if int(percent) == shown {
+			return
+		}
+		shown = int(percent)
+		fmt.Printf("\rGenerating terrain: %3d%% (%v)    ", shown, phase)
+		if phase == "Done" {
+			fmt.Println()
+			// Interrupting the program stops it again
+			signal.Stop(interrupted)
+		}
+	}
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/rubinda/GoWorld/replay"
+	"github.com/rubinda/GoWorld/terrain"
+	"time"
+)
+
+// verifyReplay re-simulates the run of an event log (written with -record) and reports the first tick that differs
+// from it (goworld replay -help lists the flags)
+// Returns an error if the log can not be read or the replay diverged
+func verifyReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	logFile := flags.String("log", "run.replay", "The event log of the run (see -record)")
+	quiet := flags.Bool("quiet", false, "Only report the outcome (not the progress)")
+	_ = flags.Parse(args)
+	terrain.LogDeaths = false
+
+	log, err := replay.LoadFile(*logFile)
+	if err != nil {
+		return err
+	}
+	if len(log.Ticks) == 0 {
+		return fmt.Errorf("error verifying replay: %v has no ticks", *logFile)
+	}
+	fmt.Printf("Replaying %d ticks from epoch %d (seed %d)\n", len(log.Ticks), log.Start.Epoch, log.Scenario.Seed)
+	started := time.Now()
+	shown := time.Now()
+	divergence, err := replay.Verify(log, func(epoch uint64) {
+		if !*quiet && time.Since(shown) > time.Second {
+			fmt.Printf("Epoch %d matches\n", epoch)
+			shown = time.Now()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if divergence != nil {
+		return fmt.Errorf("error verifying replay: %v", divergence)
+	}
+	fmt.Printf("The replay matches the log up to epoch %d (%v)\n", log.Ticks[len(log.Ticks)-1].Epoch,
+		time.Since(started))
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/rubinda/GoWorld/terrain"
+	"math"
+	"math/rand"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// soakRun is how a world of the soak ran
+type soakRun struct {
+	Seed          int64
+	Width, Height int
+	Epoch         uint64        // The epoch the world got to
+	Err           error         // Why the world could not be created (nil if it was)
+	Panic         interface{}   // What the world panicked with (nil if it did not)
+	Stack         string        // Where it panicked
+	Hung          bool          // The world ran longer than the timeout
+	Took          time.Duration // How long the run took
+}
+
+// soak runs many short simulations without the display across seeds and sizes, catching the panics, and reports the
+// seeds of the worlds that panicked or hung, along with how to run them again (goworld soak -help lists the flags).
+// The size of every world follows from its seed, so a run repeats with its seed and the same size range
+// Returns an error if the flags are invalid or a world panicked or hung
+func soak(args []string) error {
+	flags := flag.NewFlagSet("soak", flag.ExitOnError)
+	configFile := flags.String("config", "", "JSON file describing the worlds (see terrain.Config)")
+	scenarioFile := flags.String("scenario", "", "JSON file describing a repeatable setup (see terrain.Scenario)")
+	seed := flags.Int64("seed", 0, "Seed of the first world, the next ones count up from it (a random one if 0)")
+	runs := flags.Int("runs", 20, "How many worlds run")
+	ticks := flags.Uint64("ticks", 200, "How many epochs every world runs")
+	minSize := flags.Int("min-size", 64, "The smallest width and height of the worlds")
+	maxSize := flags.Int("max-size", 512, "The largest width and height of the worlds")
+	timeout := flags.Duration("timeout", 2*time.Minute, "How long a world may run before it counts as hung (0 "+
+		"for no limit)")
+	crowding := flags.Float64("crowding", 1, "How crowded the worlds are compared to the configured one (the "+
+		"populations and plants follow the area of every world)")
+	stacks := flags.Bool("stacks", false, "Print the whole stack of every panic")
+	_ = flags.Parse(args)
+	terrain.LogDeaths = false
+
+	if *runs <= 0 || *minSize <= 0 || *maxSize < *minSize || *crowding <= 0 {
+		return fmt.Errorf("error soaking: %d runs of sizes %d - %d (crowding %v) can not run", *runs, *minSize,
+			*maxSize, *crowding)
+	}
+	scenario, err := describeWorld(*configFile, *scenarioFile)
+	if err != nil {
+		return err
+	}
+	if *seed == 0 {
+		*seed = rand.New(rand.NewSource(time.Now().UnixNano())).Int63n(1e9) + 1
+	}
+	fmt.Printf("Soaking %d worlds of %d - %d spots a side for %d ticks (seeds from %d)\n", *runs, *minSize, *maxSize,
+		*ticks, *seed)
+
+	var failed, refused []soakRun
+	for i := 0; i < *runs; i++ {
+		worldSeed := *seed + int64(i)
+		if worldSeed == 0 {
+			// Worlds with the seed 0 are not repeatable
+			worldSeed = *seed + int64(*runs)
+		}
+		run := soakWorld(*scenario, worldSeed, *minSize, *maxSize, *crowding, *ticks, *timeout)
+		fmt.Printf("Run %d/%d: seed %d, %dx%d ", i+1, *runs, run.Seed, run.Width, run.Height)
+		switch {
+		case run.Hung:
+			fmt.Printf("HUNG after %v\n", run.Took)
+			failed = append(failed, run)
+		case run.Panic != nil:
+			fmt.Printf("PANICKED at epoch %d: %v\n", run.Epoch, run.Panic)
+			failed = append(failed, run)
+		case run.Err != nil:
+			fmt.Printf("refused: %v\n", run.Err)
+			refused = append(refused, run)
+		default:
+			fmt.Printf("ok, %d epochs in %v\n", run.Epoch, run.Took)
+		}
+		if run.Hung {
+			// The hung world keeps running on a CPU, the next runs would be slowed down and time out
+			fmt.Println("Stopping, the hung world still runs")
+			break
+		}
+	}
+
+	if len(refused) > 0 {
+		fmt.Printf("\n%d worlds could not be created (not a failure, e.g. no room for the beings):\n", len(refused))
+		for _, run := range refused {
+			fmt.Printf("  seed %d (%dx%d): %v\n", run.Seed, run.Width, run.Height, run.Err)
+		}
+	}
+	if len(failed) == 0 {
+		fmt.Printf("\nNo world panicked or hung\n")
+		return nil
+	}
+	fmt.Printf("\n%d worlds failed:\n", len(failed))
+	for _, run := range failed {
+		if run.Hung {
+			fmt.Printf("  seed %d (%dx%d) hung\n", run.Seed, run.Width, run.Height)
+		} else {
+			fmt.Printf("  seed %d (%dx%d) panicked at epoch %d: %v\n", run.Seed, run.Width, run.Height, run.Epoch,
+				run.Panic)
+			fmt.Printf("    at %v\n", panicSite(run.Stack))
+		}
+		// Up to the epoch it failed at is enough (hung worlds run the whole way)
+		repeat := *ticks
+		if !run.Hung {
+			repeat = run.Epoch + 1
+		}
+		command := fmt.Sprintf("goworld soak -seed %d -runs 1 -ticks %d -min-size %d -max-size %d -crowding %v",
+			run.Seed, repeat, *minSize, *maxSize, *crowding)
+		if run.Hung {
+			command += fmt.Sprintf(" -timeout %v", *timeout)
+		}
+		if *scenarioFile != "" {
+			command += " -scenario " + *scenarioFile
+		} else if *configFile != "" {
+			command += " -config " + *configFile
+		}
+		fmt.Printf("    run again with: %v\n", command)
+		if *stacks && !run.Hung {
+			fmt.Println(run.Stack)
+		}
+	}
+	return fmt.Errorf("error soaking: %d of the worlds failed", len(failed))
+}
+
+// soakWorld creates a world of the scenario with the seed (its size picked from the range by the seed, see
+// crowdScenario) and runs it for the ticks, catching a panic. A world that runs longer than the timeout (0 for no
+// limit) is left running
+func soakWorld(scenario terrain.Scenario, seed int64, minSize, maxSize int, crowding float64, ticks uint64,
+	timeout time.Duration) soakRun {
+	sizes := rand.New(rand.NewSource(seed))
+	run := soakRun{Seed: seed, Width: minSize + sizes.Intn(maxSize-minSize+1),
+		Height: minSize + sizes.Intn(maxSize-minSize+1)}
+	scenario.Seed = seed
+	// A heightmap decides the size of its world
+	if scenario.Heightmap == "" {
+		crowdScenario(&scenario, run.Width, run.Height, crowding)
+	}
+	started := time.Now()
+	done := make(chan soakRun, 1)
+	go func(run soakRun) {
+		var world *terrain.RandomWorld
+		defer func() {
+			if p := recover(); p != nil {
+				run.Panic, run.Stack = p, string(debug.Stack())
+			}
+			if world != nil {
+				run.Epoch = world.Epoch
+			}
+			done <- run
+		}()
+		world, run.Err = terrain.NewWorldFromScenario(&scenario, terrain.WithTerrainImage(""))
+		if run.Err != nil {
+			return
+		}
+		run.Width, run.Height = world.Width, world.Height
+		for world.Epoch < ticks {
+			world.Step()
+		}
+	}(run)
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = time.After(timeout)
+	}
+	select {
+	case run = <-done:
+	case <-expired:
+		run.Hung = true
+	}
+	run.Took = time.Since(started)
+	return run
+}
+
+// crowdScenario resizes the world of the scenario and scales its populations, plants and templates by the area of the
+// new size compared to the configured one and by the crowding (at least one of everything the scenario has)
+func crowdScenario(scenario *terrain.Scenario, width, height int, crowding float64) {
+	share := crowding
+	if scenario.Width > 0 && scenario.Height > 0 {
+		share *= float64(width*height) / float64(scenario.Width*scenario.Height)
+	}
+	scaled := func(count int) int {
+		if count <= 0 {
+			return count
+		}
+		return int(math.Max(math.Round(float64(count)*share), 1))
+	}
+	scenario.Width, scenario.Height = width, height
+	// Copied, the scenario shares them with the other worlds
+	populations := make(map[string]int, len(scenario.Populations))
+	for beingType, count := range scenario.Populations {
+		populations[beingType] = scaled(count)
+	}
+	scenario.Populations = populations
+	scenario.Templates = append([]terrain.TemplateConfig(nil), scenario.Templates...)
+	for i := range scenario.Templates {
+		scenario.Templates[i].Count = scaled(scenario.Templates[i].Count)
+	}
+	scenario.Plants.Land, scenario.Plants.Water = scaled(scenario.Plants.Land), scaled(scenario.Plants.Water)
+}
+
+// panicSite returns the function and the line the stack panicked at (the first frame after the panic outside the
+// runtime)
+func panicSite(stack string) string {
+	lines := strings.Split(stack, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "panic(") {
+			continue
+		}
+		// The frames are a function line followed by its file and line
+		for j := i + 2; j+1 < len(lines); j += 2 {
+			if strings.HasPrefix(lines[j], "runtime.") {
+				continue
+			}
+			function, file := lines[j], strings.TrimSpace(lines[j+1])
+			// Without the arguments and the offset
+			if at := strings.LastIndex(function, "("); at > 0 {
+				function = function[:at]
+			}
+			if at := strings.LastIndex(file, " +0x"); at >= 0 {
+				file = file[:at]
+			}
+			return fmt.Sprintf("%v (%v)", function, file)
+		}
+	}
+	return "an unknown place"
+}
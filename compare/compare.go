@@ -0,0 +1,142 @@
+// Package compare draws two worlds side by side and steps them together, an epoch of one for every epoch of the
+// other, for A/B comparisons of config changes (e.g. the same seed with a higher hunger increment). It runs the worlds
+// instead of the views of package display and render3d, the beings are dots in the colors of their types
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/display"
+	"github.com/rubinda/GoWorld/terrain"
+	"image/color"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	worlds [2]*terrain.RandomWorld
+	// Labels name the worlds above their halves of the window (e.g. the config files they were made from)
+	Labels = [2]string{"A", "B"}
+	// The pixels between the two worlds
+	gap = 8
+	// The height of the text lines above the worlds
+	header = 32
+	// How many updates pass before the terrain images are read again (the surfaces change slowly)
+	terrainRefresh uint64 = 100
+	terrains       [2]*ebiten.Image
+	// The colors of the dots of the being types
+	typeColors = map[string]color.RGBA{
+		"Carnivore": {R: 214, G: 39, B: 40, A: 255},
+		"Water":     {R: 31, G: 119, B: 180, A: 255},
+		"Flying":    {R: 255, G: 255, B: 255, A: 255},
+		"Scavenger": {R: 140, G: 86, B: 75, A: 255},
+		"Amphibian": {R: 44, G: 160, B: 44, A: 255},
+		"Insect":    {R: 255, G: 221, B: 51, A: 255},
+	}
+	plantColor = color.RGBA{R: 58, G: 125, B: 44, A: 255}
+	otherFood  = color.RGBA{R: 85, G: 85, B: 85, A: 255}
+
+	// Pace is how many epochs both worlds run in every frame (see display.Pacing)
+	Pace = display.NewPacing()
+
+	// Number of epochs run (the same in both worlds)
+	updates uint64
+	// Set once the window should close (see Stop)
+	stopping   int32
+	errStopped = errors.New("comparison stopped")
+)
+
+// Run draws the worlds next to each other until the window closes, both have to be built (the left one is drawn on
+// the left side). Every world has its own random numbers, so a seeded world runs the same as it does alone
+func Run(left, right *terrain.RandomWorld) {
+	worlds = [2]*terrain.RandomWorld{left, right}
+	width := left.Width + gap + right.Width
+	height := left.Height
+	if right.Height > height {
+		height = right.Height
+	}
+	// The updates go on without focus, the worlds themselves pause (see display.Pacing.PauseUnfocused)
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.Run(update, width, header+height, 1, "GoWorld A/B"); err != nil && err != errStopped {
+		panic(err)
+	}
+}
+
+// Stop closes the window after the current update (Run returns then)
+func Stop() {
+	atomic.StoreInt32(&stopping, 1)
+}
+
+// update steps both worlds as many epochs as the speed asks for and draws them
+func update(screen *ebiten.Image) error {
+	if atomic.LoadInt32(&stopping) == 1 {
+		return errStopped
+	}
+	Pace.HandleKeys()
+	for i := Pace.Steps(); i > 0; i-- {
+		// Neither world gets ahead of the other
+		for _, w := range worlds {
+			w.Step()
+		}
+		updates++
+	}
+	if ebiten.IsDrawingSkipped() {
+		return nil
+	}
+	if terrains[0] == nil || updates%terrainRefresh == 0 {
+		for i, w := range worlds {
+			terrains[i], _ = ebiten.NewImageFromImage(w.GetTerrainImage(), ebiten.FilterDefault)
+		}
+	}
+	left := 0
+	for i, w := range worlds {
+		drawing := time.Now()
+		drawWorld(screen, i, left)
+		w.RecordTiming("Rendering", time.Since(drawing))
+		left += w.Width + gap
+	}
+	Pace.Draw(screen)
+	return nil
+}
+
+// drawWorld draws the i-th world with its label and populations, its left side at x
+func drawWorld(screen *ebiten.Image, i, x int) {
+	w := worlds[i]
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(header))
+	if w.IsNight() {
+		// Darken the terrain during the night
+		op.ColorM.Scale(0.6, 0.6, 0.75, 1)
+	}
+	_ = screen.DrawImage(terrains[i], op)
+	plants := 0
+	w.ForEachFood(nil, func(f *GoWorld.Food) {
+		c := otherFood
+		if f.Type != "Carrion" && f.Type != "Egg" && f.Type != "Cache" {
+			c = plantColor
+			plants++
+		}
+		_ = screen.Set(x+f.Position.X, header+f.Position.Y, c)
+	})
+	populations := map[string]int{}
+	w.ForEachBeing(nil, func(b *GoWorld.Being) {
+		populations[b.Type]++
+		c, known := typeColors[b.Type]
+		if !known {
+			c = typeColors["Carnivore"]
+		}
+		ebitenutil.DrawRect(screen, float64(x+b.Position.X-1), float64(header+b.Position.Y-1), 3, 3, c)
+	})
+	types := make([]string, 0, len(populations))
+	for t, n := range populations {
+		types = append(types, fmt.Sprintf("%v %d", t, n))
+	}
+	sort.Strings(types)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%v: epoch %d, %d plants", Labels[i], w.Epoch, plants), x, 0)
+	ebitenutil.DebugPrintAt(screen, strings.Join(types, ", "), x, 16)
+}
@@ -0,0 +1,110 @@
+package GoWorld
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+)
+
+// Delta holds what changed between two snapshots, a small update instead of a full copy of the world (see Diff and
+// Snapshot.Apply)
+type Delta struct {
+	FromEpoch, ToEpoch uint64           // The epochs of the snapshots the delta leads from and to
+	Season             string           // The season at the later snapshot
+	Night              bool             // Whether it was night at the later snapshot
+	Beings             map[string]Being // Beings that were added or changed (ID: Being)
+	RemovedBeings      []string         // The IDs of beings that are gone
+	Food               map[string]Food  // Food that was added or changed (ID: Food)
+	RemovedFood        []string         // The IDs of food that is gone
+	Spots              []SpotChange     // Terrain spots that changed color
+}
+
+// SpotChange is a terrain spot that changed its color (e.g. a built home, a puddle or a new surface)
+type SpotChange struct {
+	Location Location
+	Color    color.RGBA
+}
+
+// Diff returns the changes that lead from the previous snapshot to the next one
+func Diff(prev, next *Snapshot) *Delta {
+	d := &Delta{
+		FromEpoch: prev.Epoch,
+		ToEpoch:   next.Epoch,
+		Season:    next.Season,
+		Night:     next.Night,
+		Beings:    make(map[string]Being),
+		Food:      make(map[string]Food),
+	}
+	for id, b := range next.Beings {
+		if old, ok := prev.Beings[id]; !ok || !reflect.DeepEqual(old, b) {
+			d.Beings[id] = b
+		}
+	}
+	for id := range prev.Beings {
+		if _, ok := next.Beings[id]; !ok {
+			d.RemovedBeings = append(d.RemovedBeings, id)
+		}
+	}
+	for id, f := range next.Food {
+		if old, ok := prev.Food[id]; !ok || !reflect.DeepEqual(old, f) {
+			d.Food[id] = f
+		}
+	}
+	for id := range prev.Food {
+		if _, ok := next.Food[id]; !ok {
+			d.RemovedFood = append(d.RemovedFood, id)
+		}
+	}
+	if prev.Terrain != nil && next.Terrain != nil && prev.Terrain.Bounds() == next.Terrain.Bounds() {
+		bounds := next.Terrain.Bounds()
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				if c := next.Terrain.RGBAAt(x, y); c != prev.Terrain.RGBAAt(x, y) {
+					d.Spots = append(d.Spots, SpotChange{Location{X: x, Y: y}, c})
+				}
+			}
+		}
+	}
+	return d
+}
+
+// Apply returns a new snapshot with the delta applied to the snapshot (the snapshot itself stays unchanged), the values
+// the delta does not hold (the seed and the size) are the ones of the snapshot
+func (s *Snapshot) Apply(d *Delta) *Snapshot {
+	next := &Snapshot{
+		Epoch:  d.ToEpoch,
+		Seed:   s.Seed,
+		Season: d.Season,
+		Night:  d.Night,
+		Width:  s.Width,
+		Height: s.Height,
+		Beings: make(map[string]Being, len(s.Beings)),
+		Food:   make(map[string]Food, len(s.Food)),
+	}
+	for id, b := range s.Beings {
+		next.Beings[id] = b
+	}
+	for _, id := range d.RemovedBeings {
+		delete(next.Beings, id)
+	}
+	for id, b := range d.Beings {
+		next.Beings[id] = b
+	}
+	for id, f := range s.Food {
+		next.Food[id] = f
+	}
+	for _, id := range d.RemovedFood {
+		delete(next.Food, id)
+	}
+	for id, f := range d.Food {
+		next.Food[id] = f
+	}
+	if s.Terrain != nil {
+		next.Terrain = image.NewRGBA(s.Terrain.Bounds())
+		copy(next.Terrain.Pix, s.Terrain.Pix)
+		for _, spot := range d.Spots {
+			next.Terrain.SetRGBA(spot.Location.X, spot.Location.Y, spot.Color)
+		}
+	}
+	return next
+}
@@ -0,0 +1,126 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"strings"
+)
+
+var (
+	// FrameUpdates is how many updates every frame of an animation is shown
+	FrameUpdates uint64 = 6
+	// How many updates a dead being fades out when its image has no dying frames
+	fadeUpdates uint64 = 12
+	// The states a being image can have animation frames for (<image>-<state>-<n>.png counting from 0, e.g.
+	// being-male-walking-0.png), without frames the still image is shown
+	animationStates = []string{"walking", "eating", "dying"}
+	// The frames of the states of every being image (image: state: frames)
+	animations = make(map[*ebiten.Image]map[string][]*ebiten.Image)
+	// The dead beings, shown until their dying animation ends
+	dyingSprites []*BeingSprite
+)
+
+// loadSprite reads the being image with the name from Assets along with the frames of its animations
+func loadSprite(name string) (*ebiten.Image, error) {
+	img, err := loadImage(name)
+	if err != nil {
+		return nil, err
+	}
+	return img, loadAnimations(img, strings.TrimSuffix(name, ".png"))
+}
+
+// loadAnimations reads the frames of the states of the image Assets have (base is its name without the extension)
+func loadAnimations(img *ebiten.Image, base string) error {
+	for _, state := range animationStates {
+		var frames []*ebiten.Image
+		for n := 0; ; n++ {
+			decoded, found, err := decodeOptional(fmt.Sprintf("%v-%v-%d.png", base, state, n))
+			if err != nil {
+				return err
+			}
+			if !found {
+				break
+			}
+			frame, err := ebiten.NewImageFromImage(decoded, ebiten.FilterDefault)
+			if err != nil {
+				return err
+			}
+			frames = append(frames, frame)
+		}
+		if len(frames) == 0 {
+			continue
+		}
+		if animations[img] == nil {
+			animations[img] = make(map[string][]*ebiten.Image)
+		}
+		animations[img][state] = frames
+	}
+	return nil
+}
+
+// setState starts the animation of the state (empty for standing still), a being that stops eating finishes the
+// animation first
+func (bs *BeingSprite) setState(state string) {
+	if state == bs.state || state == "" && bs.state == "eating" && !bs.animationDone() {
+		return
+	}
+	bs.state = state
+	bs.since = updates
+}
+
+// animationDone returns if the animation of the current state played through once
+func (bs *BeingSprite) animationDone() bool {
+	frames := uint64(len(animations[bs.image][bs.state]))
+	length := frames * FrameUpdates
+	if bs.state == "dying" && frames == 0 {
+		length = fadeUpdates
+	}
+	return updates-bs.since >= length
+}
+
+// frame returns the image the sprite shows in this update and its tint
+func (bs *BeingSprite) frame() (*ebiten.Image, [4]float64) {
+	frames := animations[bs.image][bs.state]
+	elapsed := updates - bs.since
+	if bs.state == "dying" && len(frames) == 0 {
+		// Without dying frames the being fades out
+		fade := 1 - float64(elapsed)/float64(fadeUpdates)
+		if fade < 0 {
+			fade = 0
+		}
+		return bs.image, [4]float64{bs.tint[0] * fade, bs.tint[1] * fade, bs.tint[2] * fade, bs.tint[3] * fade}
+	}
+	if len(frames) == 0 {
+		return bs.image, bs.tint
+	}
+	n := int(elapsed / FrameUpdates)
+	if bs.state == "dying" && n >= len(frames) {
+		// The dead stay down
+		n = len(frames) - 1
+	}
+	return frames[n%len(frames)], bs.tint
+}
+
+// startDying keeps showing the sprite of the dead being until its dying animation ends
+func startDying(bs *BeingSprite) {
+	bs.setState("dying")
+	dyingSprites = append(dyingSprites, bs)
+}
+
+// drawDying queues the dead beings whose animation still plays, the others are dropped
+func drawDying(screen *ebiten.Image) {
+	playing := dyingSprites[:0]
+	for _, s := range dyingSprites {
+		if s.animationDone() {
+			continue
+		}
+		playing = append(playing, s)
+		x, y := s.x, s.y
+		if Isometric {
+			x, y = project(x, y)
+		}
+		img, tint := s.frame()
+		batch.add(screen, img, float64(x-8), float64(y-8), tint)
+	}
+	dyingSprites = playing
+}
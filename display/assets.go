@@ -0,0 +1,52 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/rubinda/GoWorld"
+	"image"
+	// The sprites are PNG images
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// AssetLoader opens the sprite images by their name (e.g. being-male.png)
+type AssetLoader interface {
+	Open(name string) (io.ReadCloser, error)
+}
+
+// Assets loads the sprites when the display starts (set it before Run to supply your own art), by default the images
+// embedded in the binary
+var Assets AssetLoader = EmbeddedAssets{}
+
+// EmbeddedAssets loads the sprites embedded in the binary (see GoWorld.Assets)
+type EmbeddedAssets struct{}
+
+// Open opens the embedded image
+func (EmbeddedAssets) Open(name string) (io.ReadCloser, error) {
+	return GoWorld.Assets.Open("assets/" + name)
+}
+
+// DirAssets loads the sprites from a directory (the images in it are named as the ones in assets/)
+type DirAssets string
+
+// Open opens the image in the directory
+func (d DirAssets) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), name))
+}
+
+// loadImage reads the sprite image with the name from Assets
+func loadImage(name string) (*ebiten.Image, error) {
+	r, err := Assets.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error loading sprite %v: %v", name, err)
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("error loading sprite %v: %v", name, err)
+	}
+	return ebiten.NewImageFromImage(img, ebiten.FilterDefault)
+}
@@ -0,0 +1,106 @@
+package display
+
+import (
+	"github.com/hajimehoshi/ebiten"
+	"image"
+	"math"
+)
+
+var (
+	// All the sprite images packed next to each other, so the sprites are drawn in a few DrawTriangles calls instead of
+	// one DrawImage call for each of them
+	atlas *ebiten.Image
+	// Where every sprite image lies in the atlas
+	atlasRegions map[*ebiten.Image]image.Rectangle
+	// Pixels left empty between the images (so the filtering never bleeds into the neighbours)
+	atlasPadding = 1
+	// How wide the atlas gets at most (in pixels, far below the texture limits of the GPUs)
+	atlasWidth = 1024
+)
+
+// buildAtlas packs the sprite images (with the frames of their animations) into the atlas
+func buildAtlas() {
+	images := []*ebiten.Image{pumpkin, corn, eggplant, carrot, potato, seaweed, carrion, egg, cache, manImage,
+		womanImage, waterManImage, waterWomanImage, airManImage, airWomanImage}
+	for _, img := range typeImages {
+		images = append(images, img)
+	}
+	for _, states := range animations {
+		for _, frames := range states {
+			images = append(images, frames...)
+		}
+	}
+	// The images are placed in rows no wider than atlasWidth (a row gets as high as its highest image)
+	atlasRegions = make(map[*ebiten.Image]image.Rectangle, len(images))
+	x, y, rowHeight, width := atlasPadding, atlasPadding, 0, 0
+	for _, img := range images {
+		w, h := img.Size()
+		if x > atlasPadding && x+w+atlasPadding > atlasWidth {
+			x, y, rowHeight = atlasPadding, y+rowHeight+atlasPadding, 0
+		}
+		atlasRegions[img] = image.Rect(x, y, x+w, y+h)
+		x += w + atlasPadding
+		if x > width {
+			width = x
+		}
+		if h > rowHeight {
+			rowHeight = h
+		}
+	}
+	var err error
+	atlas, err = ebiten.NewImage(width, y+rowHeight+atlasPadding, ebiten.FilterDefault)
+	checkError(err)
+	for img, region := range atlasRegions {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(region.Min.X), float64(region.Min.Y))
+		_ = atlas.DrawImage(img, op)
+	}
+}
+
+// spriteBatch collects the sprites drawn from the atlas in a frame
+type spriteBatch struct {
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// add queues the image at x, y on the screen scaled by the tint (R, G, B, A multipliers), images not in the atlas are
+// drawn right away (after the queued ones)
+func (sb *spriteBatch) add(screen *ebiten.Image, img *ebiten.Image, x, y float64, tint [4]float64) {
+	region, packed := atlasRegions[img]
+	if !packed {
+		sb.flush(screen)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(x, y)
+		op.ColorM.Scale(tint[0], tint[1], tint[2], tint[3])
+		_ = screen.DrawImage(img, op)
+		return
+	}
+	// The indices are 16 bit, a full batch is drawn before it overflows
+	if len(sb.vertices)+4 > math.MaxUint16 {
+		sb.flush(screen)
+	}
+	first := uint16(len(sb.vertices))
+	w, h := float64(region.Dx()), float64(region.Dy())
+	for _, corner := range [4][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		sb.vertices = append(sb.vertices, ebiten.Vertex{
+			DstX:   float32(x + corner[0]*w),
+			DstY:   float32(y + corner[1]*h),
+			SrcX:   float32(float64(region.Min.X) + corner[0]*w),
+			SrcY:   float32(float64(region.Min.Y) + corner[1]*h),
+			ColorR: float32(tint[0]),
+			ColorG: float32(tint[1]),
+			ColorB: float32(tint[2]),
+			ColorA: float32(tint[3]),
+		})
+	}
+	sb.indices = append(sb.indices, first, first+1, first+2, first+1, first+3, first+2)
+}
+
+// flush draws the queued sprites
+func (sb *spriteBatch) flush(screen *ebiten.Image) {
+	if len(sb.indices) == 0 {
+		return
+	}
+	screen.DrawTriangles(sb.vertices, sb.indices, atlas, nil)
+	sb.vertices, sb.indices = sb.vertices[:0], sb.indices[:0]
+}
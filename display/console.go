@@ -0,0 +1,110 @@
+package display
+
+import (
+	"github.com/google/uuid"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+	"image/color"
+	"strings"
+)
+
+var (
+	// Console runs the commands typed into the console of the display (opened with the ` key), e.g. the Exec of an
+	// admin.Console. Nil keeps the console closed
+	Console func(line string) (string, error)
+	// Set while the console is open (the keys type into it then)
+	consoleOpen bool
+	// The command being typed
+	consoleInput string
+	// The latest commands with their outcomes (the oldest first)
+	consoleLines []string
+	// How many lines the console shows
+	consoleHeight = 12
+	// The commands typed before (the latest last), Up and Down go through them
+	consoleHistory []string
+	// The command of the history shown (len(consoleHistory) while typing a new one)
+	consoleRecall int
+	// The color behind the console
+	consoleBackground = color.RGBA{R: 0, G: 0, B: 0, A: 200}
+)
+
+// updateConsole opens and closes the console and takes the keys typed into it
+// Returns if the console is open (the other keys of the display are ignored then)
+func updateConsole() bool {
+	if Console == nil {
+		return false
+	}
+	closing := consoleOpen && inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+	if inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) || closing {
+		consoleOpen = !consoleOpen
+		return true
+	}
+	if !consoleOpen {
+		return false
+	}
+	for _, r := range ebiten.InputChars() {
+		if r != '`' {
+			consoleInput += string(r)
+		}
+	}
+	if repeated(ebiten.KeyBackspace) && len(consoleInput) > 0 {
+		runes := []rune(consoleInput)
+		consoleInput = string(runes[:len(runes)-1])
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) && consoleRecall > 0 {
+		consoleRecall--
+		consoleInput = consoleHistory[consoleRecall]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) && consoleRecall < len(consoleHistory) {
+		consoleRecall++
+		consoleInput = ""
+		if consoleRecall < len(consoleHistory) {
+			consoleInput = consoleHistory[consoleRecall]
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && strings.TrimSpace(consoleInput) != "" {
+		consoleLines = append(consoleLines, "> "+consoleInput)
+		out, err := Console(consoleInput)
+		if err != nil {
+			out = err.Error()
+		}
+		if out != "" {
+			consoleLines = append(consoleLines, strings.Split(out, "\n")...)
+		}
+		if len(consoleLines) > consoleHeight {
+			consoleLines = consoleLines[len(consoleLines)-consoleHeight:]
+		}
+		consoleHistory = append(consoleHistory, consoleInput)
+		consoleRecall = len(consoleHistory)
+		consoleInput = ""
+	}
+	return true
+}
+
+// repeated checks if the key was just pressed or is held long enough to repeat
+func repeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || d >= 30 && d%3 == 0
+}
+
+// drawConsole draws the open console over the top of the screen (with the id of the selected being, to use it in
+// commands)
+func drawConsole(screen *ebiten.Image) {
+	if !consoleOpen {
+		return
+	}
+	width, _ := screen.Size()
+	// DebugPrint lines are 16 pixels high
+	lines := append([]string(nil), consoleLines...)
+	if selected != uuid.Nil {
+		label := "(selected " + selected.String()
+		if s, alive := beingSprites[selected.String()]; alive && s.Being.Name != "" {
+			label += " " + s.Being.Name
+		}
+		lines = append(lines, label+")")
+	}
+	lines = append(lines, "> "+consoleInput+"_")
+	ebitenutil.DrawRect(screen, 0, 0, float64(width), float64(16*len(lines)+4), consoleBackground)
+	_ = ebitenutil.DebugPrint(screen, strings.Join(lines, "\n"))
+}
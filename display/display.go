@@ -1,16 +1,25 @@
 package display
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
 	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/autosave"
 	"image/color"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	world GoWorld.World
+	// Autosave stores the world every few epochs while it is displayed (nil for no saves)
+	Autosave *autosave.Saver
 	// Gender specific colors (for marking dots on the terrain as beings)
 	manBlue color.RGBA = color.RGBA{
 		R: 103, G: 175, B: 255, A: 255,
@@ -37,6 +46,22 @@ var (
 	potato *ebiten.Image
 	// Water plants are always the same
 	seaweed *ebiten.Image
+	// Bodies of dead beings
+	carrion *ebiten.Image
+	// Eggs laid by fish and flyers
+	egg *ebiten.Image
+	// Food cached by hoarding beings
+	cache *ebiten.Image
+	// Some being types use the land being images in their own tint (R, G, B, A multipliers)
+	typeTints = map[string][4]float64{
+		"Scavenger": {0.6, 0.45, 0.3, 1},
+		"Amphibian": {0.5, 1, 0.6, 1},
+		"Insect":    {1, 0.9, 0.2, 1},
+	}
+	// The sprites in their own colors
+	noTint = [4]float64{1, 1, 1, 1}
+	// The sprites drawn in the current update
+	batch spriteBatch
 
 	// Gender images
 	manImage        *ebiten.Image
@@ -47,7 +72,13 @@ var (
 	airWomanImage   *ebiten.Image
 
 	// Number of updates called
-	time uint64
+	updates uint64
+	// ShowTimings shows how long the phases of a tick take over the terrain (toggled with the T key)
+	ShowTimings bool
+	// Set once the display should close (see Stop)
+	stopping int32
+	// Returned from the update to close the display
+	errStopped = errors.New("display stopped")
 )
 
 // BeingSprite is the image representing a being on the display
@@ -56,6 +87,9 @@ type BeingSprite struct {
 	x     int            // Sprite X position on display
 	y     int            // Sprite Y position on display
 	image *ebiten.Image  // The sprite image
+	tint  [4]float64     // The R, G, B, A multipliers of the image
+	state string         // The animation played (walking, eating or dying, empty when standing still)
+	since uint64         // The update the animation started in
 }
 
 type FoodSprite struct {
@@ -70,28 +104,64 @@ type FoodSprite struct {
 // Update on a being Sprite moves it in the world und updates its coordinates
 func (bs *BeingSprite) Update() {
 	// Make the being do an action in the terrain package
-	actionDone, ids := world.UpdateBeing(bs.Being)
+	result := world.UpdateBeing(bs.Being)
+	ids := result.Targets
+	eating := false
 
 	// Check if being died => remove it from sprite list
-	switch actionDone {
-	case "died":
+	switch result.Outcome {
+	case GoWorld.Died:
 		delete(beingSprites, ids[0].String())
+		startDying(bs)
+		// Show the body left behind
+		if len(ids) > 1 {
+			(&FoodSprite{}).New(ids[1])
+		}
 		return
-	case "ate plant":
+	case GoWorld.AtePlant, GoWorld.AteCache, GoWorld.StoleCache:
 		// Remove the food item from screen (being ate it)
 		delete(foodSprites, ids[0].String())
-	case "ate being":
+		eating = true
+	case GoWorld.TookBite, GoWorld.AteCarried, GoWorld.Drank, GoWorld.Busy:
+		// Drinking looks like eating (the beings are only busy with eating and drinking)
+		eating = true
+	case GoWorld.Cached:
+		// Show the hidden food
+		(&FoodSprite{}).New(ids[0])
+	case GoWorld.AteBeing:
 		// Remove the being that was eaten
-		delete(beingSprites, ids[0].String())
-	case "mated":
+		if eaten, shown := beingSprites[ids[0].String()]; shown {
+			delete(beingSprites, ids[0].String())
+			startDying(eaten)
+		}
+		eating = true
+		// Show the remains left behind
+		if len(ids) > 1 {
+			(&FoodSprite{}).New(ids[1])
+		}
+	case GoWorld.Mated:
 		// Add the new beings to sprites
 		for _, id := range ids {
 			bs.New(id)
 		}
+	case GoWorld.LaidEggs:
+		// Add the eggs to food sprites
+		for _, id := range ids {
+			(&FoodSprite{}).New(id)
+		}
 	}
 	// Synchronize the positional coordinates with the terrain package
+	moved := bs.x != bs.Being.Position.X || bs.y != bs.Being.Position.Y
 	bs.x = bs.Being.Position.X
 	bs.y = bs.Being.Position.Y
+	switch {
+	case eating:
+		bs.setState("eating")
+	case moved:
+		bs.setState("walking")
+	default:
+		bs.setState("")
+	}
 }
 
 // New creates a new food sprite based on ID from GoWorld.Food
@@ -102,6 +172,12 @@ func (fs *FoodSprite) New(id uuid.UUID) {
 	img := growthStageImage(f.GrowthStage)
 	if f.Type == "Water" {
 		img = seaweed
+	} else if f.Type == "Carrion" {
+		img = carrion
+	} else if f.Type == "Egg" {
+		img = egg
+	} else if f.Type == "Cache" {
+		img = cache
 	}
 	foodSprites[id.String()] = &FoodSprite{
 		Food:  f,
@@ -115,46 +191,31 @@ func (fs *FoodSprite) New(id uuid.UUID) {
 
 // New creates a new being sprite based on being with ID
 func (bs *BeingSprite) New(id uuid.UUID) {
-	img := manImage
 	// Get from terrain package
 	b := world.GetBeingWithID(id)
-	// Check the sex of the baby
-	switch t := b.Type; {
-	case t == "Flying":
-		if b.Gender == "male" {
-			img = airManImage
-		} else {
-			img = airWomanImage
-		}
-	case t == "Water":
-		if b.Gender == "male" {
-			img = waterManImage
-		} else {
-			img = waterWomanImage
-		}
-	default:
-		if b.Gender == "male" {
-			img = manImage
-		} else {
-			img = womanImage
-		}
-	}
+	img, tint := beingImage(b)
 	beingSprites[id.String()] = &BeingSprite{
 		Being: b,
 		x:     b.Position.X,
 		y:     b.Position.Y,
 		image: img,
+		tint:  tint,
 	}
 }
 
 func (fs *FoodSprite) Update() {
-	actionDone, uuids := world.UpdatePlant(fs.Food)
+	result := world.UpdatePlant(fs.Food)
+	uuids := result.Targets
 	// Check what happened with the plant and update sprites accordingly
-	switch actionDone {
-	case "withered":
+	switch result.Outcome {
+	case GoWorld.Withered:
 		// The plant died :(
 		delete(foodSprites, uuids[0].String())
-	case "planted seeds":
+	case GoWorld.Hatched:
+		// The egg is gone, the hatchling takes its place
+		delete(foodSprites, uuids[0].String())
+		(&BeingSprite{}).New(uuids[1])
+	case GoWorld.PlantedSeeds:
 		// The plant had babies :)
 		if fs.Food.Type != "Water" {
 			fs.image = growthStageImage(fs.Food.GrowthStage)
@@ -162,7 +223,7 @@ func (fs *FoodSprite) Update() {
 		for _, id := range uuids {
 			fs.New(id)
 		}
-	case "planted fail":
+	case GoWorld.PlantedFail:
 		// Planting failed, but still plant is in new stage
 		if fs.Food.Type != "Water" {
 			fs.image = growthStageImage(fs.Food.GrowthStage)
@@ -195,6 +256,35 @@ func (bss *BeingSprites) Update() {
 	}
 }
 
+// syncSprites matches the sprites with the beings and food in the world (they can be added or removed from outside,
+// e.g. with AddBeing, KillBeing or Restore)
+func syncSprites() {
+	food := world.GetFood()
+	for id := range foodSprites {
+		if _, exists := food[id]; !exists {
+			delete(foodSprites, id)
+		}
+	}
+	for id, f := range food {
+		// Restoring a snapshot replaces the food with copies
+		if s, shown := foodSprites[id]; !shown || s.Food != f {
+			(&FoodSprite{}).New(f.ID)
+		}
+	}
+	beings := world.GetBeings()
+	for id, s := range beingSprites {
+		if _, alive := beings[id]; !alive {
+			delete(beingSprites, id)
+			startDying(s)
+		}
+	}
+	for id, b := range beings {
+		if s, shown := beingSprites[id]; !shown || s.Being != b {
+			(&BeingSprite{}).New(b.ID)
+		}
+	}
+}
+
 // GrowthStageImage returns the image associated with a growth stage
 func growthStageImage(stage float64) *ebiten.Image {
 	switch s := stage; {
@@ -225,38 +315,17 @@ func BeingSpriteInit() error {
 	}
 	beingSprites = make(map[string]*BeingSprite)
 
-	// This should always change to a specific gender, but just in case ...
-	img := manImage
-
 	// Initialize the image we will later color as a simple rectangular sprite
 	for _, b := range beings {
-		// Check what type this being is and update the image with respect of gender
-		switch t := b.Type; {
-		case t == "Flying":
-			if b.Gender == "male" {
-				img = airManImage
-			} else {
-				img = airWomanImage
-			}
-		case t == "Water":
-			if b.Gender == "male" {
-				img = waterManImage
-			} else {
-				img = waterWomanImage
-			}
-		default:
-			if b.Gender == "male" {
-				img = manImage
-			} else {
-				img = womanImage
-			}
-		}
+		// The image depends on the type and gender of the being
+		img, tint := beingImage(b)
 		// Initialize a sprite of the being and store it
 		beingSprites[b.ID.String()] = &BeingSprite{
 			Being: b,
 			x:     b.Position.X,
 			y:     b.Position.Y,
 			image: img,
+			tint:  tint,
 		}
 	}
 	return nil
@@ -277,6 +346,12 @@ func FoodSpriteInit() error {
 		// Find out if we are dealing with land or water plants
 		if f.Type == "Water" {
 			img = seaweed
+		} else if f.Type == "Carrion" {
+			img = carrion
+		} else if f.Type == "Egg" {
+			img = egg
+		} else if f.Type == "Cache" {
+			img = cache
 		} else {
 			img = growthStageImage(f.GrowthStage)
 		}
@@ -295,39 +370,153 @@ func FoodSpriteInit() error {
 
 // update is the ebiten function that handles screen drawing updates
 func update(screen *ebiten.Image) error {
-	// Draw the background colored terrain (zones)
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(0, 0)
-	terrainImage, _ := ebiten.NewImageFromImage(world.GetTerrainImage(), ebiten.FilterDefault)
-	_ = screen.DrawImage(terrainImage, op)
-
+	if atomic.LoadInt32(&stopping) == 1 {
+		return errStopped
+	}
+	// The keys type into the console while it is open
+	if !updateConsole() {
+		handleKeys()
+	}
+	// Move the world forward as many epochs as the speed asks for in this frame (one every other frame at 0.5x)
+	for i := Pace.Steps(); i > 0; i-- {
+		step()
+	}
 	if ebiten.IsDrawingSkipped() {
 		return nil
 	}
-	// Draw food onto screen
+	drawing := time.Now()
+	draw(screen)
+	world.RecordTiming("Rendering", time.Since(drawing))
+	drawGraph(screen)
+	if ShowTimings {
+		_ = ebitenutil.DebugPrint(screen, timingsText())
+	}
+	Pace.Draw(screen)
+	drawSpecies(screen)
+	drawNotice(screen)
+	drawConsole(screen)
+	return nil
+}
 
+// step moves the world an epoch forward, every being and plant acts
+func step() {
+	// Catch up with the beings and food changed from outside
+	syncSprites()
 	for _, f := range foodSprites {
 		f.Update()
-		op.GeoM.Reset()
-		op.GeoM.Translate(float64(f.x-f.w/2), float64(f.y-f.h/2))
-		_ = screen.DrawImage(f.image, op)
 	}
-
-	// Redraw the sprites on screen to match the new positions
 	for _, s := range beingSprites {
 		s.Update()
-		op.GeoM.Reset()
-		op.GeoM.Translate(float64(s.x-8), float64(s.y-8))
-		// As of ebiten 1.5.0 alpha DrawImage() always returns nil, so safe to ignore return value
-		_ = screen.DrawImage(s.image, op)
+	}
+	updates++
+	world.AdvanceTime()
+	if Autosave != nil {
+		if err := Autosave.Tick(world); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
 
+// draw draws the terrain with the food and beings on it (and the overlays of the selected being)
+func draw(screen *ebiten.Image) {
+	// Draw the background colored terrain (zones)
+	op := &ebiten.DrawImageOptions{}
+	var terrainImage *ebiten.Image
+	if Isometric {
+		if isoTerrain == nil || updates%isoRefresh == 0 {
+			projectTerrain()
+		}
+		terrainImage = isoTerrain
+	} else {
+		terrainImage = themedTerrain()
 	}
-	time++
-	if time == 10000 {
-		world.PlantsToJSON("plants@10k.json")
-		world.BeingsToJSON("beings@10k.json")
+	if world.IsNight() {
+		// Darken the terrain during the night
+		op.ColorM.Scale(0.6, 0.6, 0.75, 1)
+	}
+	_ = screen.DrawImage(terrainImage, op)
+	if atlas == nil {
+		buildAtlas()
+	}
+	// Draw food onto screen
+	for _, f := range foodSprites {
+		x, y := f.x, f.y
+		if Isometric {
+			x, y = project(x, y)
+		}
+		batch.add(screen, f.image, float64(x-f.w/2), float64(y-f.h/2), noTint)
+	}
+	// Draw the beings at their new positions
+	for _, s := range beingSprites {
+		if s.state == "dying" {
+			// Drawn with the other dead beings
+			continue
+		}
+		x, y := s.x, s.y
+		if Isometric {
+			x, y = project(x, y)
+		}
+		img, tint := s.frame()
+		batch.add(screen, img, float64(x-8), float64(y-8), tint)
+	}
+	drawDying(screen)
+	batch.flush(screen)
+	drawPerception(screen)
+	drawDrift(screen)
+	drawPlannedPath(screen)
+	drawLabels(screen)
+}
+
+// handleKeys toggles the overlays, exports, selects beings, saves and sets the speed
+func handleKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		ShowTimings = !ShowTimings
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		ShowPerception = !ShowPerception
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		ShowGraph = !ShowGraph
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		toggleDrift()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		toggleSpecies()
+	}
+	// Click on a being to see the path it plans
+	selectBeing()
+	// The keys 1 to 5 set the speed
+	Pace.HandleKeys()
+	// F5 saves the world, F9 goes back to that save
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		quickSave()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		quickLoad()
+	}
+	// Press E to export the beings and plants
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		if err := world.Export(); err != nil {
+			fmt.Println(err)
+		}
 	}
-	return nil
+}
+
+// timingsText describes how long the phases of a tick took (the median, 90th and 99th percentile and the slowest)
+func timingsText() string {
+	timings := world.Timings()
+	phases := make([]string, 0, len(timings))
+	for phase := range timings {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	lines := []string{fmt.Sprintf("Update %d (p50 / p90 / p99 / max)", updates)}
+	for _, phase := range phases {
+		t := timings[phase]
+		lines = append(lines, fmt.Sprintf("%-9s %v / %v / %v / %v", phase, t.P50, t.P90, t.P99, t.Max))
+	}
+	return strings.Join(lines, "\n")
 }
 
 // checkError panics if error is not nil
@@ -337,47 +526,55 @@ func checkError(err error) {
 	}
 }
 
-// init initializes the image sprites
-func init() {
+// loadSprites reads the sprite images from Assets
+func loadSprites() {
 	// Load food sprites for each growth stage
 	var err error
-	pumpkin, _, err = ebitenutil.NewImageFromFile("assets/pumpkin.png", ebiten.FilterDefault)
+	pumpkin, err = loadImage("pumpkin.png")
+	checkError(err)
+	potato, err = loadImage("potato.png")
 	checkError(err)
-	potato, _, err = ebitenutil.NewImageFromFile("assets/potato.png", ebiten.FilterDefault)
+	corn, err = loadImage("corn.png")
 	checkError(err)
-	corn, _, err = ebitenutil.NewImageFromFile("assets/corn.png", ebiten.FilterDefault)
+	eggplant, err = loadImage("eggplant.png")
 	checkError(err)
-	eggplant, _, err = ebitenutil.NewImageFromFile("assets/eggplant.png", ebiten.FilterDefault)
+	carrot, err = loadImage("carrot.png")
 	checkError(err)
-	carrot, _, err = ebitenutil.NewImageFromFile("assets/carrot.png", ebiten.FilterDefault)
+	seaweed, err = loadImage("seaweed.png")
 	checkError(err)
-	seaweed, _, err = ebitenutil.NewImageFromFile("assets/seaweed.png", ebiten.FilterDefault)
+	carrion, err = ebiten.NewImage(6, 6, ebiten.FilterDefault)
 	checkError(err)
+	_ = carrion.Fill(color.RGBA{R: 110, G: 30, B: 30, A: 255})
+	egg, err = ebiten.NewImage(4, 4, ebiten.FilterDefault)
+	checkError(err)
+	_ = egg.Fill(color.RGBA{R: 245, G: 238, B: 210, A: 255})
+	cache, err = ebiten.NewImage(5, 5, ebiten.FilterDefault)
+	checkError(err)
+	_ = cache.Fill(color.RGBA{R: 150, G: 105, B: 45, A: 255})
 
 	// Load being sprites
-	manImage, _, err = ebitenutil.NewImageFromFile("assets/being-male.png", ebiten.FilterDefault)
+	manImage, err = loadSprite("being-male.png")
 	checkError(err)
-	womanImage, _, err = ebitenutil.NewImageFromFile("assets/being-female.png", ebiten.FilterDefault)
+	womanImage, err = loadSprite("being-female.png")
 	checkError(err)
 
-	waterManImage, _, err = ebitenutil.NewImageFromFile("assets/being-male-water.png", ebiten.FilterDefault)
+	waterManImage, err = loadSprite("being-male-water.png")
 	checkError(err)
-	waterWomanImage, _, err = ebitenutil.NewImageFromFile("assets/being-female-water.png", ebiten.FilterDefault)
+	waterWomanImage, err = loadSprite("being-female-water.png")
 	checkError(err)
 
-	airManImage, _, err = ebitenutil.NewImageFromFile("assets/being-male-flying.png", ebiten.FilterDefault)
+	airManImage, err = loadSprite("being-male-flying.png")
 	checkError(err)
-	airWomanImage, _, err = ebitenutil.NewImageFromFile("assets/being-female-flying.png", ebiten.FilterDefault)
+	airWomanImage, err = loadSprite("being-female-flying.png")
 	checkError(err)
-
-	// Start time
-	time = 0
 }
 
 // Run draws the initial terrain
 // Provide screen width and height and a initialized world
 func Run(goworld GoWorld.World) {
 	world = goworld // Set the global world variable
+	loadSprites()
+	checkError(loadThemeImages())
 	if err := BeingSpriteInit(); err != nil {
 		// TODO handle no beings in the world better than panicing
 		panic(err)
@@ -386,9 +583,49 @@ func Run(goworld GoWorld.World) {
 		panic(err)
 	}
 	screenWidth, screenHeight := world.GetSize()
+	if Isometric {
+		initIsometric()
+		screenWidth, screenHeight = isoSize(screenWidth, screenHeight)
+	}
+	// The updates go on without focus, the world itself pauses (see Pacing.PauseUnfocused)
+	ebiten.SetRunnableOnUnfocused(true)
 	// Start the display output
 	//ebiten.SetMaxTPS(30)
-	if err := ebiten.Run(update, screenWidth, screenHeight, 1, "GoWorld"); err != nil {
+	if err := ebiten.Run(update, screenWidth, screenHeight, 1, "GoWorld"); err != nil && err != errStopped {
 		panic(err)
 	}
 }
+
+// Stop closes the display after the current update (Run returns then)
+func Stop() {
+	atomic.StoreInt32(&stopping, 1)
+}
+
+// SetSpeed changes how many updates (epochs) run every second
+func SetSpeed(tps int) {
+	ebiten.SetMaxTPS(tps)
+}
+
+// ToggleOverlay shows the overlay (Timings, Perception, Graph or Species) if it is hidden and hides it otherwise, Drift goes
+// on to the next attribute (see toggleDrift)
+// Returns if the overlay is shown now or an error if there is no such overlay
+func ToggleOverlay(name string) (bool, error) {
+	switch name {
+	case "Timings":
+		ShowTimings = !ShowTimings
+		return ShowTimings, nil
+	case "Perception":
+		ShowPerception = !ShowPerception
+		return ShowPerception, nil
+	case "Graph":
+		ShowGraph = !ShowGraph
+		return ShowGraph, nil
+	case "Drift":
+		toggleDrift()
+		return Drift != "", nil
+	case "Species":
+		toggleSpecies()
+		return ShowSpecies, nil
+	}
+	return false, fmt.Errorf("error toggling overlay: unknown overlay %v", name)
+}
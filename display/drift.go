@@ -0,0 +1,164 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/rubinda/GoWorld"
+	"image/color"
+	"sort"
+)
+
+var (
+	// Drift colors the beings by what they inherited (toggled through with the D key): the percentile of an attribute
+	// among the beings of their type (one of DriftAttributes) or their species, empty colors nothing
+	Drift string
+	// DriftAttributes are the attributes the D key goes through (before the species)
+	DriftAttributes = []string{"Speed", "Size", "VisionRange", "Durability", "Camouflage", "Resistance"}
+	// The attribute values the beings can be colored by (named as the Being fields)
+	driftValues = map[string]func(b *GoWorld.Being) float64{
+		"Speed":        func(b *GoWorld.Being) float64 { return b.Speed },
+		"Size":         func(b *GoWorld.Being) float64 { return b.Size },
+		"VisionRange":  func(b *GoWorld.Being) float64 { return b.VisionRange },
+		"Durability":   func(b *GoWorld.Being) float64 { return b.Durability },
+		"Camouflage":   func(b *GoWorld.Being) float64 { return b.Camouflage },
+		"Resistance":   func(b *GoWorld.Being) float64 { return b.Resistance },
+		"Fertility":    func(b *GoWorld.Being) float64 { return b.Fertility },
+		"MutationRate": func(b *GoWorld.Being) float64 { return b.MutationRate },
+	}
+	// How many updates pass before the colors are worked out again (grouping into species is slow)
+	driftRefresh uint64 = 30
+	// The colors of the beings (ID: color) and the legend of the latest refresh
+	driftColors  map[string]color.RGBA
+	driftLegend  []driftEntry
+	driftUpdated uint64
+	// The colors of the species (the later ones are repeated) and of the lowest and highest percentile
+	speciesPalette = []color.RGBA{
+		{R: 230, G: 25, B: 75, A: 255}, {R: 60, G: 180, B: 75, A: 255}, {R: 255, G: 225, B: 25, A: 255},
+		{R: 0, G: 130, B: 200, A: 255}, {R: 245, G: 130, B: 48, A: 255}, {R: 145, G: 30, B: 180, A: 255},
+		{R: 70, G: 240, B: 240, A: 255}, {R: 240, G: 50, B: 230, A: 255}, {R: 210, G: 245, B: 60, A: 255},
+		{R: 250, G: 190, B: 212, A: 255}, {R: 0, G: 128, B: 128, A: 255}, {R: 170, G: 110, B: 40, A: 255},
+	}
+	lowDrift  = color.RGBA{R: 40, G: 90, B: 255, A: 255}
+	highDrift = color.RGBA{R: 255, G: 50, B: 40, A: 255}
+	// How many species the legend lists
+	driftLegendShown = 12
+)
+
+// driftEntry is a line of the legend
+type driftEntry struct {
+	label string
+	c     color.RGBA
+}
+
+// toggleDrift colors the beings by the next of the DriftAttributes, by their species after the last one and by
+// nothing after that
+func toggleDrift() {
+	modes := append(append([]string{""}, DriftAttributes...), "Species")
+	next := 0
+	for i, mode := range modes {
+		if mode == Drift {
+			next = (i + 1) % len(modes)
+		}
+	}
+	Drift = modes[next]
+	// Work out the new colors right away
+	driftColors = nil
+}
+
+// refreshDrift works out the color of every being and the legend for the current Drift
+func refreshDrift() {
+	driftColors = make(map[string]color.RGBA)
+	driftLegend = nil
+	driftUpdated = updates
+	if Drift == "Species" {
+		species := world.Species()
+		names := make([]string, 0, len(species))
+		seen := make(map[string]bool)
+		for _, name := range species {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		colors := make(map[string]color.RGBA, len(names))
+		for i, name := range names {
+			colors[name] = speciesPalette[i%len(speciesPalette)]
+			if i < driftLegendShown {
+				driftLegend = append(driftLegend, driftEntry{name, colors[name]})
+			}
+		}
+		if len(names) > driftLegendShown {
+			driftLegend = append(driftLegend, driftEntry{fmt.Sprintf("... %d more", len(names)-driftLegendShown),
+				speciesPalette[driftLegendShown%len(speciesPalette)]})
+		}
+		for id, name := range species {
+			driftColors[id] = colors[name]
+		}
+		return
+	}
+	value, known := driftValues[Drift]
+	if !known {
+		return
+	}
+	// Rank the beings of every type by the attribute (their ranges differ, e.g. insects are tiny)
+	byType := make(map[string][]*GoWorld.Being)
+	for _, b := range world.GetBeings() {
+		byType[b.Type] = append(byType[b.Type], b)
+	}
+	for _, beings := range byType {
+		sort.Slice(beings, func(i, j int) bool { return value(beings[i]) < value(beings[j]) })
+		for i, b := range beings {
+			percentile := 0.5
+			if len(beings) > 1 {
+				percentile = float64(i) / float64(len(beings)-1)
+			}
+			driftColors[b.ID.String()] = blend(lowDrift, highDrift, percentile)
+		}
+	}
+	driftLegend = []driftEntry{
+		{fmt.Sprintf("%v: lowest of its type", Drift), lowDrift},
+		{"median", blend(lowDrift, highDrift, 0.5)},
+		{"highest", highDrift},
+	}
+}
+
+// blend mixes the colors, share 0 is all from and 1 is all to
+func blend(from, to color.RGBA, share float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*share)
+	}
+	return color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 255}
+}
+
+// drawDrift marks every being with the color of its attribute percentile or species and draws the legend in the
+// bottom left corner
+func drawDrift(screen *ebiten.Image) {
+	if Drift == "" {
+		return
+	}
+	if driftColors == nil || updates-driftUpdated >= driftRefresh {
+		refreshDrift()
+	}
+	for id, s := range beingSprites {
+		c, colored := driftColors[id]
+		if !colored {
+			// Born since the latest refresh
+			continue
+		}
+		x, y := s.x, s.y
+		if Isometric {
+			x, y = project(x, y)
+		}
+		ebitenutil.DrawRect(screen, float64(x-3), float64(y-3), 6, 6, c)
+	}
+	_, height := screen.Size()
+	top := height - 8 - 16*len(driftLegend)
+	ebitenutil.DrawRect(screen, 4, float64(top-4), 190, float64(16*len(driftLegend)+8), graphBackground)
+	for i, entry := range driftLegend {
+		y := top + 16*i
+		ebitenutil.DrawRect(screen, 8, float64(y+3), 10, 10, entry.c)
+		ebitenutil.DebugPrintAt(screen, entry.label, 22, y)
+	}
+}
@@ -0,0 +1,95 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/rubinda/GoWorld/report"
+	"image/color"
+	"sort"
+)
+
+var (
+	// Graph feeds the population graph drawn in the bottom right corner (nil draws no graph), keep GraphSamples of its
+	// samples (see report.Recorder.Keep)
+	Graph *report.Recorder
+	// GraphSamples is how many samples the graph shows (one pixel apart), the older ones scroll out on the left
+	GraphSamples = 200
+	// ShowGraph shows the population graph (toggled with the G key)
+	ShowGraph = true
+	// The height of the graph in pixels
+	graphHeight = 80
+	// The colors of the lines of the being types and the plants
+	graphColors = map[string]color.RGBA{
+		"Carnivore": {R: 214, G: 39, B: 40, A: 255},
+		"Water":     {R: 31, G: 119, B: 180, A: 255},
+		"Flying":    {R: 255, G: 255, B: 255, A: 255},
+		"Scavenger": {R: 140, G: 86, B: 75, A: 255},
+		"Amphibian": {R: 44, G: 160, B: 44, A: 255},
+		"Insect":    {R: 255, G: 221, B: 51, A: 255},
+		"Plants":    {R: 127, G: 191, B: 63, A: 255},
+	}
+	graphBackground = color.RGBA{R: 0, G: 0, B: 0, A: 160}
+)
+
+// drawGraph draws a line for the population of every being type and one for the plants over the latest samples of
+// the graph recorder, scaled to the largest count shown
+func drawGraph(screen *ebiten.Image) {
+	if !ShowGraph || Graph == nil || len(Graph.Samples) < 2 {
+		return
+	}
+	width, height := screen.Size()
+	// Small worlds show fewer samples
+	columns := GraphSamples
+	if columns > width-16 {
+		columns = width - 16
+	}
+	if columns < 2 {
+		return
+	}
+	samples := Graph.Samples
+	if len(samples) > columns {
+		samples = samples[len(samples)-columns:]
+	}
+	series := make(map[string][]int)
+	largest := 1
+	for i, s := range samples {
+		add := func(name string, count int) {
+			if series[name] == nil {
+				series[name] = make([]int, len(samples))
+			}
+			series[name][i] = count
+			if count > largest {
+				largest = count
+			}
+		}
+		for beingType, count := range s.Population {
+			add(beingType, count)
+		}
+		add("Plants", s.Plants)
+	}
+	left, top := float64(width-columns-8), float64(height-graphHeight-8)
+	ebitenutil.DrawRect(screen, left-4, top-14, float64(columns)+8, float64(graphHeight)+18, graphBackground)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("max %d", largest), int(left), int(top)-16)
+	// Sorted, so the lines always overlap in the same order
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c, known := graphColors[name]
+		if !known {
+			c = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+		}
+		// The newest sample is at the right edge
+		offset := float64(columns - len(samples))
+		y := func(count int) float64 {
+			return top + float64(graphHeight) - float64(count)/float64(largest)*float64(graphHeight)
+		}
+		for i := 1; i < len(samples); i++ {
+			ebitenutil.DrawLine(screen, left+offset+float64(i-1), y(series[name][i-1]), left+offset+float64(i),
+				y(series[name][i]), c)
+		}
+	}
+}
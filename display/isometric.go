@@ -0,0 +1,88 @@
+package display
+
+import (
+	"github.com/hajimehoshi/ebiten"
+	"github.com/rubinda/GoWorld"
+	"image"
+	"image/color"
+	"math"
+)
+
+var (
+	// Isometric draws the world in an isometric projection (2.5D) where the heights of the terrain lift the spots, the
+	// simulation grid stays the same (set it before Run)
+	Isometric bool
+	// How many pixels the highest spot is lifted above the lowest
+	isoElevation = 48
+	// How many updates pass before the terrain is projected again (the surfaces change slowly, e.g. in a drought)
+	isoRefresh uint64 = 100
+	// The projected terrain
+	isoTerrain *ebiten.Image
+	// How many pixels every spot is lifted (stretched between the lowest and the highest spot)
+	isoLift [][]int
+)
+
+// isoSize returns the size of the screen that fits the projected world
+func isoSize(width, height int) (int, int) {
+	return width + height, (width+height)/2 + isoElevation + 1
+}
+
+// project returns where the spot is drawn in the isometric projection (a spot is two pixels wide and one high)
+func project(x, y int) (int, int) {
+	_, height := world.GetSize()
+	return x - y + height, (x+y)/2 + isoElevation - isoLift[x][y]
+}
+
+// initIsometric reads the heights of the terrain
+func initIsometric() {
+	width, height := world.GetSize()
+	heights := make([][]float64, width)
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for x := range heights {
+		heights[x] = make([]float64, height)
+		for y := range heights[x] {
+			h, _ := world.GetHeightAt(GoWorld.Location{X: x, Y: y})
+			heights[x][y] = h
+			lowest = math.Min(lowest, h)
+			highest = math.Max(highest, h)
+		}
+	}
+	span := math.Max(highest-lowest, 1e-9)
+	isoLift = make([][]int, width)
+	for x := range heights {
+		isoLift[x] = make([]int, height)
+		for y, h := range heights[x] {
+			isoLift[x][y] = int((h - lowest) / span * float64(isoElevation))
+		}
+	}
+}
+
+// projectTerrain draws the terrain in the isometric projection: every spot is a column as high as the terrain there,
+// its top in the color of the surface and its side darker, drawn from the back to the front
+func projectTerrain() {
+	width, height := world.GetSize()
+	screenWidth, screenHeight := isoSize(width, height)
+	img := image.NewRGBA(image.Rect(0, 0, screenWidth, screenHeight))
+	for depth := 0; depth < width+height-1; depth++ {
+		for x := 0; x < width; x++ {
+			y := depth - x
+			if y < 0 || y >= height {
+				continue
+			}
+			top := surfaceColor(x, y)
+			side := color.RGBA{R: uint8(float64(top.R) * 0.7), G: uint8(float64(top.G) * 0.7),
+				B: uint8(float64(top.B) * 0.7), A: 255}
+			sx, sy := project(x, y)
+			ground := (x+y)/2 + isoElevation
+			for py := sy; py <= ground; py++ {
+				c := side
+				if py == sy {
+					c = top
+				}
+				img.SetRGBA(sx, py, c)
+				img.SetRGBA(sx+1, py, c)
+			}
+		}
+	}
+	isoTerrain, _ = ebiten.NewImageFromImage(img, ebiten.FilterDefault)
+}
@@ -0,0 +1,103 @@
+package display
+
+import (
+	"github.com/google/uuid"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+	"image/color"
+	"strings"
+)
+
+var (
+	// The being clicked on, its planned path is drawn (uuid.Nil for none)
+	selected uuid.UUID
+	// How far from a being (in pixels) a click still selects it
+	selectRadius = 8
+	// The colors of a planned path, of the straight line to a goal without a path and of the selection mark
+	pathColor     = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	noPathColor   = color.RGBA{R: 255, G: 40, B: 40, A: 255}
+	selectedColor = color.RGBA{R: 255, G: 220, B: 0, A: 255}
+)
+
+// selectBeing selects the being closest to a left click (a click far from every being clears the selection) and
+// makes the world keep its plans
+func selectBeing() {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	cursorX, cursorY := ebiten.CursorPosition()
+	closest, distance := uuid.Nil, selectRadius*selectRadius+1
+	for _, s := range beingSprites {
+		x, y := s.x, s.y
+		if Isometric {
+			x, y = project(x, y)
+		}
+		if d := (x-cursorX)*(x-cursorX) + (y-cursorY)*(y-cursorY); d < distance {
+			closest, distance = s.Being.ID, d
+		}
+	}
+	selected = closest
+	world.TraceBeing(selected)
+}
+
+// drawPlannedPath draws the path the selected being planned in this update as a line from it to its goal, a red
+// straight line if the pathfinder found no path
+func drawPlannedPath(screen *ebiten.Image) {
+	if selected == uuid.Nil {
+		return
+	}
+	s, alive := beingSprites[selected.String()]
+	if !alive {
+		selected = uuid.Nil
+		world.TraceBeing(selected)
+		return
+	}
+	point := func(x, y int) (float64, float64) {
+		if Isometric {
+			x, y = project(x, y)
+		}
+		return float64(x), float64(y)
+	}
+	x, y := point(s.x, s.y)
+	outline(screen, x, y, 18, selectedColor)
+	plan, planned := world.PlannedPath()
+	if !planned {
+		return
+	}
+	fromX, fromY := point(plan.From.X, plan.From.Y)
+	if len(plan.Path) == 0 {
+		goalX, goalY := point(plan.Goal.X, plan.Goal.Y)
+		ebitenutil.DrawLine(screen, fromX, fromY, goalX, goalY, noPathColor)
+		return
+	}
+	for _, spot := range plan.Path {
+		toX, toY := point(spot.X, spot.Y)
+		ebitenutil.DrawLine(screen, fromX, fromY, toX, toY, pathColor)
+		fromX, fromY = toX, toY
+	}
+}
+
+// drawLabels writes the names above the named beings (to follow them over the run) and the tags below the selected
+// one, DebugPrint characters are 6 pixels wide
+func drawLabels(screen *ebiten.Image) {
+	for id, s := range beingSprites {
+		tags := ""
+		if id == selected.String() && len(s.Being.Tags) > 0 {
+			tags = "#" + strings.Join(s.Being.Tags, " #")
+		}
+		if s.Being.Name == "" && tags == "" {
+			continue
+		}
+		x, y := s.x, s.y
+		if Isometric {
+			x, y = project(x, y)
+		}
+		if s.Being.Name != "" {
+			ebitenutil.DebugPrintAt(screen, s.Being.Name, x-3*len(s.Being.Name), y-26)
+		}
+		if tags != "" {
+			ebitenutil.DebugPrintAt(screen, tags, x-3*len(tags), y+10)
+		}
+	}
+}
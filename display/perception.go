@@ -0,0 +1,89 @@
+package display
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/rubinda/GoWorld"
+	"image"
+	"image/color"
+)
+
+var (
+	// ShowPerception shows what the selected being perceives (toggled with the P key): the spots it sees, the ones
+	// hidden from it, the food and beings it noticed and its target
+	ShowPerception bool
+	// The colors of the perception overlay (the spots are premultiplied, they are drawn over the terrain)
+	visibleSpotColor = color.RGBA{R: 60, G: 60, B: 60, A: 60}
+	hiddenSpotColor  = color.RGBA{R: 90, G: 0, B: 0, A: 90}
+	seenFoodColor    = color.RGBA{R: 120, G: 255, B: 120, A: 255}
+	seenBeingColor   = color.RGBA{R: 255, G: 150, B: 40, A: 255}
+	targetColor      = color.RGBA{R: 255, G: 40, B: 40, A: 255}
+)
+
+// drawPerception draws the perception of the selected being over the world
+func drawPerception(screen *ebiten.Image) {
+	if !ShowPerception || selected == uuid.Nil {
+		return
+	}
+	view, err := world.PerceptionFor(selected)
+	if err != nil {
+		return
+	}
+	point := func(l GoWorld.Location) (int, int) {
+		if Isometric {
+			return project(l.X, l.Y)
+		}
+		return l.X, l.Y
+	}
+	width, height := screen.Size()
+	spots := image.NewRGBA(image.Rect(0, 0, width, height))
+	for _, l := range []struct {
+		spots []GoWorld.Location
+		c     color.RGBA
+	}{{view.Visible, visibleSpotColor}, {view.Hidden, hiddenSpotColor}} {
+		for _, spot := range l.spots {
+			x, y := point(spot)
+			spots.SetRGBA(x, y, l.c)
+			if Isometric {
+				// A spot is two pixels wide in the projection
+				spots.SetRGBA(x+1, y, l.c)
+			}
+		}
+	}
+	overlay, _ := ebiten.NewImageFromImage(spots, ebiten.FilterDefault)
+	_ = screen.DrawImage(overlay, &ebiten.DrawImageOptions{})
+	for _, id := range view.Food {
+		if f := world.GetFoodWithID(id); f != nil {
+			x, y := point(f.Position)
+			outline(screen, float64(x), float64(y), 6, seenFoodColor)
+		}
+	}
+	for _, id := range view.Beings {
+		if b := world.GetBeingWithID(id); b != nil {
+			c := seenBeingColor
+			if id == view.Target {
+				c = targetColor
+			}
+			x, y := point(b.Position)
+			outline(screen, float64(x), float64(y), 12, c)
+		}
+	}
+	if view.Action != "" {
+		// Mark the spot of the chosen action with a cross and name the action
+		x, y := point(view.Goal)
+		ebitenutil.DrawLine(screen, float64(x-4), float64(y-4), float64(x+4), float64(y+4), targetColor)
+		ebitenutil.DrawLine(screen, float64(x-4), float64(y+4), float64(x+4), float64(y-4), targetColor)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%v (sees %d spots)", view.Action, len(view.Visible)), x+6, y-8)
+	}
+}
+
+// outline draws a square frame of the size around x, y
+func outline(screen *ebiten.Image, x, y, size float64, c color.Color) {
+	half := size / 2
+	ebitenutil.DrawRect(screen, x-half, y-half, size, 1, c)
+	ebitenutil.DrawRect(screen, x-half, y+half-1, size, 1, c)
+	ebitenutil.DrawRect(screen, x-half, y-half, 1, size, c)
+	ebitenutil.DrawRect(screen, x+half-1, y-half, 1, size, c)
+}
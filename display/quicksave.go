@@ -0,0 +1,62 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/rubinda/GoWorld/autosave"
+)
+
+var (
+	// QuickSave is where F5 saves the whole world and F9 restores it from, so a moment of the run can be revisited and
+	// the run branched off from it
+	QuickSave = "quicksave.gob.gz"
+	// The latest message of the display (e.g. that the world was saved) and the update it disappears at
+	notice      string
+	noticeUntil uint64
+	// How many updates a message stays
+	noticeUpdates uint64 = 180
+)
+
+// quickSave saves a snapshot of the world into QuickSave
+func quickSave() {
+	snapshot := world.Snapshot()
+	if err := autosave.SaveFile(QuickSave, snapshot); err != nil {
+		notify(err.Error())
+		return
+	}
+	notify(fmt.Sprintf("Saved epoch %d into %v", snapshot.Epoch, QuickSave))
+}
+
+// quickLoad restores the world from QuickSave
+func quickLoad() {
+	snapshot, err := autosave.LoadFile(QuickSave)
+	if err != nil {
+		notify(fmt.Sprintf("error loading world: %v", err))
+		return
+	}
+	if err := world.Restore(snapshot); err != nil {
+		notify(err.Error())
+		return
+	}
+	// The dead beings of the abandoned branch are not shown
+	dyingSprites = nil
+	syncSprites()
+	notify(fmt.Sprintf("Restored epoch %d", snapshot.Epoch))
+}
+
+// notify shows the message for a while (and prints it)
+func notify(message string) {
+	fmt.Println(message)
+	notice = message
+	noticeUntil = updates + noticeUpdates
+}
+
+// drawNotice draws the latest message in the bottom left corner until it disappears
+func drawNotice(screen *ebiten.Image) {
+	if notice == "" || updates >= noticeUntil {
+		return
+	}
+	_, height := screen.Size()
+	ebitenutil.DebugPrintAt(screen, notice, 4, height-20)
+}
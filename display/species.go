@@ -0,0 +1,65 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/rubinda/GoWorld"
+	"sort"
+	"strings"
+)
+
+var (
+	// ShowSpecies lists the largest species in the top right corner (toggled with the S key)
+	ShowSpecies bool
+	// How many species the list shows
+	speciesShown = 8
+	// How many updates pass before the list is worked out again (grouping into species is slow)
+	speciesRefresh uint64 = 30
+	// The list of the latest refresh
+	speciesText    string
+	speciesUpdated uint64
+)
+
+// toggleSpecies shows the list of species if it is hidden and hides it otherwise
+func toggleSpecies() {
+	ShowSpecies = !ShowSpecies
+	// Work out the list right away
+	speciesText = ""
+}
+
+// refreshSpecies lists the species with the most living members (the whole types are left out)
+func refreshSpecies() {
+	speciesUpdated = updates
+	var species []GoWorld.SpeciesSummary
+	for _, s := range world.SpeciesSummary() {
+		if s.Species != s.Type && s.Population > 0 {
+			species = append(species, s)
+		}
+	}
+	sort.SliceStable(species, func(i, j int) bool { return species[i].Population > species[j].Population })
+	lines := []string{fmt.Sprintf("%-12s %5s %5s %6s %5s %5s", "Species", "Live", "Died", "Span", "Kills", "Speed")}
+	for i, s := range species {
+		if i == speciesShown {
+			lines = append(lines, fmt.Sprintf("... %d more", len(species)-speciesShown))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%-12s %5d %5d %6.0f %5d %5.1f", s.Species, s.Population, s.Deaths,
+			s.Lifespan, s.Kills, s.Means["Speed"]))
+	}
+	speciesText = strings.Join(lines, "\n")
+}
+
+// drawSpecies draws the list of the largest species below the speed in the top right corner
+func drawSpecies(screen *ebiten.Image) {
+	if !ShowSpecies {
+		return
+	}
+	if speciesText == "" || updates-speciesUpdated >= speciesRefresh {
+		refreshSpecies()
+	}
+	width, _ := screen.Size()
+	lines := strings.Count(speciesText, "\n") + 1
+	ebitenutil.DrawRect(screen, float64(width-260), 16, 256, float64(16*lines+8), graphBackground)
+	ebitenutil.DebugPrintAt(screen, speciesText, width-256, 20)
+}
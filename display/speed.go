@@ -0,0 +1,68 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+var (
+	// Pace is how fast the world runs in the window (see Pacing)
+	Pace = NewPacing()
+	// The speeds of the keys 1 to 5
+	speeds = []float64{0.5, 1, 2, 4, 8}
+	// The keys that pick the speeds
+	speedKeys = []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5}
+)
+
+// Pacing decides how many epochs a window runs in every frame, the views of this package, render3d and compare all
+// use it so the keys and the pause work the same in each of them
+type Pacing struct {
+	// Speed is how many epochs pass in every frame (drawing stays at the same rate), the keys 1 to 5 pick 0.5, 1, 2, 4
+	// or 8
+	Speed float64
+	// PauseUnfocused stops the world while the window is not focused (behind other windows or minimized), so a long run
+	// does not go on unnoticed while nobody watches. Turn it off for unattended runs
+	PauseUnfocused bool
+	pending        float64 // The part of an epoch still to run (the slow speeds run an epoch every few frames)
+}
+
+// NewPacing returns the pacing windows start with: an epoch every frame, paused while the window is not focused
+func NewPacing() *Pacing {
+	return &Pacing{Speed: 1, PauseUnfocused: true}
+}
+
+// Paused checks if the world waits for the window to be focused again
+func (p *Pacing) Paused() bool {
+	return p.PauseUnfocused && !ebiten.IsFocused()
+}
+
+// HandleKeys sets the speed of the key 1 to 5 pressed in this frame
+func (p *Pacing) HandleKeys() {
+	for i, key := range speedKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			p.Speed = speeds[i]
+		}
+	}
+}
+
+// Steps returns how many epochs the world runs in this frame (none while it is paused, one every other frame at 0.5x)
+func (p *Pacing) Steps() int {
+	if !p.Paused() {
+		p.pending += p.Speed
+	}
+	steps := int(p.pending)
+	p.pending -= float64(steps)
+	return steps
+}
+
+// Draw shows the speed in the top right corner of the screen (or that the world is paused)
+func (p *Pacing) Draw(screen *ebiten.Image) {
+	width, _ := screen.Size()
+	if p.Paused() {
+		ebitenutil.DebugPrintAt(screen, "Paused (focus the window to resume)", width-220, 0)
+		return
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%gx (1-5)", p.Speed), width-64, 0)
+}
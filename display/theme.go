@@ -0,0 +1,186 @@
+package display
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/rubinda/GoWorld"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ThemesDir holds the named themes, every theme is a directory in it (e.g. themes/pixel)
+	ThemesDir = "themes"
+	// The being types a theme can draw in their own images (being-<type>-<gender>.png, e.g. being-insect-male.png)
+	beingTypes = []string{"Carnivore", "Water", "Flying", "Scavenger", "Amphibian", "Insect"}
+	// The images of the beings of a type and gender (Type/gender) the theme has
+	typeImages map[string]*ebiten.Image
+	// The tiles covering the surfaces (by common name) the theme has, the others keep their color
+	surfaceTiles map[string]image.Image
+	// How many updates pass before the tiled terrain is drawn again (the surfaces change slowly)
+	tileRefresh uint64 = 100
+	// The terrain covered with the tiles
+	tiledTerrain *ebiten.Image
+)
+
+// Theme loads the sprites from a directory and falls back to the ones embedded in the binary for the images it does not
+// have, so a theme can replace as few of them as it likes
+type Theme string
+
+// Open opens the image in the theme directory (or the embedded one)
+func (t Theme) Open(name string) (io.ReadCloser, error) {
+	r, err := DirAssets(t).Open(name)
+	if os.IsNotExist(err) {
+		return EmbeddedAssets{}.Open(name)
+	}
+	return r, err
+}
+
+// LoadTheme makes the display use the theme, given by its name (a directory in ThemesDir) or its path
+// Returns an error if there is no such directory
+func LoadTheme(name string) error {
+	dir := name
+	if !strings.ContainsRune(name, os.PathSeparator) && !strings.ContainsRune(name, '/') {
+		dir = filepath.Join(ThemesDir, name)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("error loading theme %v: %v", name, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("error loading theme %v: %v is not a directory", name, dir)
+	}
+	Assets = Theme(dir)
+	return nil
+}
+
+// fileName turns the name (of a type or a surface) into a part of a file name, e.g. Moutain Peak to moutain-peak
+func fileName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
+// decodeOptional reads the image with the name from Assets, it is not found (and nil) if Assets do not have it
+func decodeOptional(name string) (image.Image, bool, error) {
+	r, err := Assets.Open(name)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error loading sprite %v: %v", name, err)
+	}
+	defer r.Close()
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("error loading sprite %v: %v", name, err)
+	}
+	return img, true, nil
+}
+
+// loadThemeImages reads the images of the being types and the surface tiles Assets have
+func loadThemeImages() error {
+	typeImages = make(map[string]*ebiten.Image)
+	for _, t := range beingTypes {
+		for _, gender := range []string{"male", "female"} {
+			img, found, err := decodeOptional("being-" + fileName(t) + "-" + gender + ".png")
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			sprite, err := ebiten.NewImageFromImage(img, ebiten.FilterDefault)
+			if err != nil {
+				return err
+			}
+			typeImages[t+"/"+gender] = sprite
+			if err := loadAnimations(sprite, "being-"+fileName(t)+"-"+gender); err != nil {
+				return err
+			}
+		}
+	}
+	surfaceTiles = make(map[string]image.Image)
+	// Every surface is looked up once, at the first spot it covers
+	looked := make(map[string]bool)
+	width, height := world.GetSize()
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			name, _ := world.GetSurfaceNameAt(GoWorld.Location{X: x, Y: y})
+			if looked[name] {
+				continue
+			}
+			looked[name] = true
+			tile, found, err := decodeOptional("tile-" + fileName(name) + ".png")
+			if err != nil {
+				return err
+			}
+			if found && !tile.Bounds().Empty() {
+				surfaceTiles[name] = tile
+			}
+		}
+	}
+	return nil
+}
+
+// beingImage returns the image of the being and how it is tinted: the one the theme has for its type and gender, or
+// the image of its medium (land, water or air) and gender in the tint of its type
+func beingImage(b *GoWorld.Being) (*ebiten.Image, [4]float64) {
+	if img, ok := typeImages[b.Type+"/"+b.Gender]; ok {
+		return img, noTint
+	}
+	tint, ok := typeTints[b.Type]
+	if !ok {
+		tint = noTint
+	}
+	switch t := b.Type; {
+	case t == "Flying":
+		if b.Gender == "male" {
+			return airManImage, tint
+		}
+		return airWomanImage, tint
+	case t == "Water":
+		if b.Gender == "male" {
+			return waterManImage, tint
+		}
+		return waterWomanImage, tint
+	default:
+		if b.Gender == "male" {
+			return manImage, tint
+		}
+		return womanImage, tint
+	}
+}
+
+// surfaceColor returns the color the spot is drawn in, from the tile of its surface if the theme has one
+func surfaceColor(x, y int) color.RGBA {
+	name, _ := world.GetSurfaceNameAt(GoWorld.Location{X: x, Y: y})
+	if tile, ok := surfaceTiles[name]; ok {
+		bounds := tile.Bounds()
+		c := color.RGBAModel.Convert(tile.At(bounds.Min.X+x%bounds.Dx(), bounds.Min.Y+y%bounds.Dy())).(color.RGBA)
+		c.A = 255
+		return c
+	}
+	return world.GetSurfaceColorAtSpot(GoWorld.Location{X: x, Y: y})
+}
+
+// themedTerrain returns the terrain to draw under the sprites, covered with the surface tiles if the theme has any
+func themedTerrain() *ebiten.Image {
+	if len(surfaceTiles) == 0 {
+		img, _ := ebiten.NewImageFromImage(world.GetTerrainImage(), ebiten.FilterDefault)
+		return img
+	}
+	if tiledTerrain == nil || updates%tileRefresh == 0 {
+		width, height := world.GetSize()
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				img.SetRGBA(x, y, surfaceColor(x, y))
+			}
+		}
+		tiledTerrain, _ = ebiten.NewImageFromImage(img, ebiten.FilterDefault)
+	}
+	return tiledTerrain
+}
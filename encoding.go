@@ -0,0 +1,54 @@
+package GoWorld
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	BinaryFormat = "gob"  // Compact and fast, for saves and streams of large worlds
+	JSONFormat   = "json" // Human-readable
+)
+
+// EncodeSnapshot writes the snapshot in the format (BinaryFormat or JSONFormat), the brains of beings are not stored
+func EncodeSnapshot(w io.Writer, s *Snapshot, format string) error {
+	stored := *s
+	stored.Beings = make(map[string]Being, len(s.Beings))
+	for id, b := range s.Beings {
+		b.Brain = nil
+		stored.Beings[id] = b
+	}
+	var err error
+	switch format {
+	case BinaryFormat:
+		err = gob.NewEncoder(w).Encode(&stored)
+	case JSONFormat:
+		err = json.NewEncoder(w).Encode(&stored)
+	default:
+		return fmt.Errorf("error encoding snapshot: unknown format %v", format)
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %v", err)
+	}
+	return nil
+}
+
+// DecodeSnapshot reads a snapshot written in the format by EncodeSnapshot
+func DecodeSnapshot(r io.Reader, format string) (*Snapshot, error) {
+	s := &Snapshot{}
+	var err error
+	switch format {
+	case BinaryFormat:
+		err = gob.NewDecoder(r).Decode(s)
+	case JSONFormat:
+		err = json.NewDecoder(r).Decode(s)
+	default:
+		return nil, fmt.Errorf("error decoding snapshot: unknown format %v", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding snapshot: %v", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,75 @@
+package GoWorld
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// benchmarkSnapshot returns a snapshot of a 512x512 world with a thousand beings and four thousand plants
+func benchmarkSnapshot() *Snapshot {
+	s := &Snapshot{
+		Epoch:   10000,
+		Seed:    1,
+		Season:  "Summer",
+		Width:   512,
+		Height:  512,
+		Beings:  make(map[string]Being),
+		Food:    make(map[string]Food),
+		Terrain: image.NewRGBA(image.Rect(0, 0, 512, 512)),
+	}
+	for i := 0; i < 1000; i++ {
+		b := Being{ID: uuid.New(), Type: "Carnivore", Position: Location{X: i % 512, Y: i / 2}, Hunger: 120,
+			Thirst: 80, Memory: Memory{Water: []Location{{X: 3, Y: 4}}, Food: []Location{{X: 5, Y: 6}}}}
+		s.Beings[b.ID.String()] = b
+	}
+	for i := 0; i < 4000; i++ {
+		f := Food{ID: uuid.New(), Type: "Carrot", Position: Location{X: i % 512, Y: i / 8}, NutritionalValue: 40}
+		s.Food[f.ID.String()] = f
+	}
+	for x := 0; x < 512; x++ {
+		for y := 0; y < 512; y++ {
+			s.Terrain.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	return s
+}
+
+// BenchmarkEncodeSnapshot compares how fast the formats write a snapshot (the bytes are the size of the encoding)
+func BenchmarkEncodeSnapshot(b *testing.B) {
+	s := benchmarkSnapshot()
+	for _, format := range []string{BinaryFormat, JSONFormat} {
+		b.Run(format, func(b *testing.B) {
+			var encoded bytes.Buffer
+			for i := 0; i < b.N; i++ {
+				encoded.Reset()
+				if err := EncodeSnapshot(&encoded, s, format); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.SetBytes(int64(encoded.Len()))
+		})
+	}
+}
+
+// BenchmarkDecodeSnapshot compares how fast the formats read a snapshot (the bytes are the size of the encoding)
+func BenchmarkDecodeSnapshot(b *testing.B) {
+	s := benchmarkSnapshot()
+	for _, format := range []string{BinaryFormat, JSONFormat} {
+		b.Run(format, func(b *testing.B) {
+			var encoded bytes.Buffer
+			if err := EncodeSnapshot(&encoded, s, format); err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(int64(encoded.Len()))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := DecodeSnapshot(bytes.NewReader(encoded.Bytes()), format); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
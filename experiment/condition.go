@@ -0,0 +1,127 @@
+package experiment
+
+import (
+	"fmt"
+	"github.com/rubinda/GoWorld/terrain"
+)
+
+// Condition ends a run once it is met, its Kind is one of
+// Extinction (all beings of the Type, of any type if empty, died out), Population (more than Limit beings of the Type,
+// of any type if empty, live), Epoch (Limit epochs passed) or Query (more than Limit beings and food match the Query,
+// e.g. "type == 'Insect' && age > 500", see terrain.Query)
+type Condition struct {
+	Kind  string
+	Type  string  // The being type the condition watches (all types if empty)
+	Limit float64 // The population or epoch the condition is met at
+	Query string  // The expression the beings and food are matched with
+}
+
+// Result describes which condition ended a run and when
+type Result struct {
+	Condition  Condition
+	Epoch      uint64 // The epoch the condition was met at
+	Population int    // The number of beings of the watched type then (or the matches of the query)
+}
+
+// String describes the condition (e.g. 'Population of Insect > 500')
+func (c Condition) String() string {
+	of := "all beings"
+	if c.Type != "" {
+		of = c.Type
+	}
+	switch c.Kind {
+	case "Extinction":
+		return "Extinction of " + of
+	case "Population":
+		return fmt.Sprintf("Population of %v > %v", of, c.Limit)
+	case "Epoch":
+		return fmt.Sprintf("Epoch %v", c.Limit)
+	case "Query":
+		return fmt.Sprintf("Matches of %v > %v", c.Query, c.Limit)
+	}
+	return c.Kind
+}
+
+// known checks if the condition is of a kind that can be met
+func (c Condition) known() bool {
+	switch c.Kind {
+	case "Extinction", "Population", "Epoch", "Query":
+		return true
+	}
+	return false
+}
+
+// population returns the number of beings of the type the condition watches (or of the matches of its query)
+func (c Condition) population(w *terrain.RandomWorld) int {
+	if c.Kind == "Query" {
+		// Checked before the run (see validate)
+		ids, _ := w.Query(c.Query)
+		return len(ids)
+	}
+	if c.Type == "" {
+		return len(w.BeingList)
+	}
+	n := 0
+	for _, b := range w.BeingList {
+		if b.Type == c.Type {
+			n++
+		}
+	}
+	return n
+}
+
+// met checks if the condition is met in the world
+func (c Condition) met(w *terrain.RandomWorld) bool {
+	switch c.Kind {
+	case "Extinction":
+		return c.population(w) == 0
+	case "Population", "Query":
+		return float64(c.population(w)) > c.Limit
+	case "Epoch":
+		return float64(w.Epoch) >= c.Limit
+	}
+	return false
+}
+
+// check returns the result of the first condition met in the world (nil if none is)
+func check(w *terrain.RandomWorld, conditions []Condition) *Result {
+	for _, c := range conditions {
+		if c.met(w) {
+			return &Result{Condition: c, Epoch: w.Epoch, Population: c.population(w)}
+		}
+	}
+	return nil
+}
+
+// RunUntil moves the world forward (see terrain.RandomWorld.Step) until one of the conditions is met, they are checked
+// in their order before every epoch
+// Returns an error if a condition is of an unknown kind or none are given (the run would never end)
+func RunUntil(w *terrain.RandomWorld, conditions ...Condition) (*Result, error) {
+	if err := validate(conditions); err != nil {
+		return nil, err
+	}
+	for {
+		if r := check(w, conditions); r != nil {
+			return r, nil
+		}
+		w.Step()
+	}
+}
+
+// validate checks that the conditions can end a run
+func validate(conditions []Condition) error {
+	if len(conditions) == 0 {
+		return fmt.Errorf("error checking conditions: no conditions end the run")
+	}
+	for _, c := range conditions {
+		if !c.known() {
+			return fmt.Errorf("error checking conditions: unknown condition %v", c.Kind)
+		}
+		if c.Kind == "Query" {
+			if _, err := terrain.ParseQuery(c.Query); err != nil {
+				return fmt.Errorf("error checking conditions: %v", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Package experiment runs many independent simulations without a display and summarizes how they turned out
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rubinda/GoWorld/report"
+	"github.com/rubinda/GoWorld/terrain"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Experiment describes a batch of runs of the same world with different seeds
+type Experiment struct {
+	Scenario *terrain.Scenario // The world every run starts from (its seed is replaced by the seed of the run)
+	Seeds    []int64           // One run is made for every seed
+	Epochs   uint64            // How many epochs every run lasts at most (no limit if 0)
+	Until    []Condition       // The conditions that end a run earlier
+	Reports  string            // The directory to write a summary page of every run into (none if empty)
+	Workers  int               // How many runs are made at once (the number of CPUs if 0)
+}
+
+// Summary describes how a single run turned out
+type Summary struct {
+	Seed       int64
+	Epochs     uint64            // How many epochs the run lasted
+	Population map[string]int    // The number of beings of each type at the end
+	Peak       map[string]int    // The largest number of beings of each type during the run
+	Extinct    map[string]uint64 // The epoch at which each type died out (types that survived are missing)
+	Food       int               // The number of food sources at the end
+	Seconds    float64           // How long the run took
+	Result     Result            // The condition that ended the run
+}
+
+// Stats describes a value across all runs
+type Stats struct {
+	Mean, StdDev, Min, Max float64
+}
+
+// Report holds the summaries of all runs and the statistics across them
+type Report struct {
+	Runs        []Summary
+	Population  map[string]Stats   // The number of beings of each type at the end
+	Peak        map[string]Stats   // The largest number of beings of each type during a run
+	Extinctions map[string]int     // In how many runs each type died out
+	Extinction  map[string]Stats   // The epoch at which each type died out (in the runs it did)
+	Mix         map[string]float64 // The mean share of each type in the population at the end
+	Food        Stats              // The number of food sources at the end
+	Ends        map[string]int     // How many runs each condition ended (see Condition.String)
+}
+
+// Seeds returns n consecutive seeds starting with the first one
+func Seeds(first int64, n int) []int64 {
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = first + int64(i)
+	}
+	return seeds
+}
+
+// Run makes all runs of the experiment in parallel, every world has its own random numbers (see terrain.WithSeed) so
+// a run turns out the same however many are made at once
+// Returns an error if a world can not be created
+func (e *Experiment) Run() (*Report, error) {
+	for _, seed := range e.Seeds {
+		if seed == 0 {
+			return nil, fmt.Errorf("error running experiment: seed 0 makes the run unrepeatable")
+		}
+	}
+	if err := validate(e.conditions()); err != nil {
+		return nil, fmt.Errorf("error running experiment: %v", err)
+	}
+	if e.Reports != "" {
+		if err := os.MkdirAll(e.Reports, 0755); err != nil {
+			return nil, fmt.Errorf("error running experiment: %v", err)
+		}
+	}
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	summaries := make([]Summary, len(e.Seeds))
+	errs := make([]error, len(e.Seeds))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range next {
+				summaries[run], errs[run] = e.run(e.Seeds[run])
+			}
+		}()
+	}
+	for run := range e.Seeds {
+		next <- run
+	}
+	close(next)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return aggregate(summaries), nil
+}
+
+// run simulates the world with the seed for the epochs of the experiment
+func (e *Experiment) run(seed int64) (Summary, error) {
+	started := time.Now()
+	scenario := *e.Scenario
+	scenario.Seed = seed
+	// The runs would all store their terrain into the same file
+	w, err := terrain.NewWorldFromScenario(&scenario, terrain.WithTerrainImage(""))
+	if err != nil {
+		return Summary{}, fmt.Errorf("error running experiment with seed %d: %v", seed, err)
+	}
+	s := Summary{
+		Seed:       seed,
+		Population: w.Census(),
+		Peak:       make(map[string]int),
+		Extinct:    make(map[string]uint64),
+	}
+	s.record(w)
+	var recorder *report.Recorder
+	if e.Reports != "" {
+		recorder = report.New(w, 100)
+	}
+	conditions := e.conditions()
+	for {
+		if r := check(w, conditions); r != nil {
+			s.Result = *r
+			break
+		}
+		w.Step()
+		s.record(w)
+	}
+	if recorder != nil {
+		if err := recorder.WriteFile(filepath.Join(e.Reports, fmt.Sprintf("run-%d.html", seed))); err != nil {
+			return Summary{}, err
+		}
+	}
+	s.Epochs = w.Epoch
+	s.Food = len(w.FoodList)
+	s.Seconds = time.Since(started).Seconds()
+	return s, nil
+}
+
+// conditions returns the conditions that end a run, the epoch limit comes last
+func (e *Experiment) conditions() []Condition {
+	conditions := append([]Condition(nil), e.Until...)
+	if e.Epochs > 0 {
+		conditions = append(conditions, Condition{Kind: "Epoch", Limit: float64(e.Epochs)})
+	}
+	return conditions
+}
+
+// record updates the summary with the current population of the world (types that were never seen are left out)
+func (s *Summary) record(w *terrain.RandomWorld) {
+	population := w.Census()
+	for beingType := range s.Population {
+		if _, counted := population[beingType]; !counted {
+			population[beingType] = 0
+		}
+	}
+	for beingType, n := range population {
+		if n > s.Peak[beingType] {
+			s.Peak[beingType] = n
+		}
+		if _, extinct := s.Extinct[beingType]; n == 0 && !extinct {
+			s.Extinct[beingType] = w.Epoch
+		} else if n > 0 {
+			// The type came back (e.g. hatched from eggs)
+			delete(s.Extinct, beingType)
+		}
+	}
+	s.Population = population
+}
+
+// aggregate computes the statistics across the runs
+func aggregate(runs []Summary) *Report {
+	r := &Report{
+		Runs:        runs,
+		Population:  make(map[string]Stats),
+		Peak:        make(map[string]Stats),
+		Extinctions: make(map[string]int),
+		Extinction:  make(map[string]Stats),
+		Mix:         make(map[string]float64),
+		Ends:        make(map[string]int),
+	}
+	for _, run := range runs {
+		r.Ends[run.Result.Condition.String()]++
+	}
+	types := make(map[string]bool)
+	for _, run := range runs {
+		for beingType := range run.Population {
+			types[beingType] = true
+		}
+	}
+	for beingType := range types {
+		final := make([]float64, len(runs))
+		peak := make([]float64, len(runs))
+		var extinction []float64
+		for i, run := range runs {
+			final[i] = float64(run.Population[beingType])
+			peak[i] = float64(run.Peak[beingType])
+			if epoch, extinct := run.Extinct[beingType]; extinct {
+				r.Extinctions[beingType]++
+				extinction = append(extinction, float64(epoch))
+			}
+			if total := populationOf(run); total > 0 {
+				r.Mix[beingType] += final[i] / float64(total) / float64(len(runs))
+			}
+		}
+		r.Population[beingType] = statsOf(final)
+		r.Peak[beingType] = statsOf(peak)
+		if len(extinction) > 0 {
+			r.Extinction[beingType] = statsOf(extinction)
+		}
+	}
+	food := make([]float64, len(runs))
+	for i, run := range runs {
+		food[i] = float64(run.Food)
+	}
+	r.Food = statsOf(food)
+	return r
+}
+
+// populationOf returns the number of all beings at the end of the run
+func populationOf(run Summary) int {
+	total := 0
+	for _, n := range run.Population {
+		total += n
+	}
+	return total
+}
+
+// statsOf returns the statistics of the values
+func statsOf(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	s := Stats{Min: sorted[0], Max: sorted[len(sorted)-1]}
+	for _, v := range values {
+		s.Mean += v
+	}
+	s.Mean /= float64(len(values))
+	for _, v := range values {
+		s.StdDev += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(len(values)))
+	return s
+}
+
+// Write writes the report as indented JSON
+func (r *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("error writing report: %v", err)
+	}
+	return nil
+}
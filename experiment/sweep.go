@@ -0,0 +1,116 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rubinda/GoWorld/terrain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parameter is a config value and the values it takes in a sweep
+type Parameter struct {
+	Name   string    // The name of the config value (see terrain.Config.Set, e.g. NeedIncrements.Hunger)
+	Values []float64 // The values it takes
+}
+
+// Sweep runs the experiment for every combination of the parameter values (a grid of all of them)
+type Sweep struct {
+	Experiment
+	Parameters []Parameter
+}
+
+// Combination holds the parameter values of a grid point and the report of the experiment run with them
+type Combination struct {
+	Values map[string]float64 // The parameter values (Name: value)
+	*Report
+}
+
+// SweepReport holds the outcome of every combination of the parameter values
+type SweepReport struct {
+	Parameters   []Parameter
+	Combinations []Combination
+}
+
+// ParseParameter reads a parameter from the text with its name and comma separated values (e.g.
+// 'Ranges.Mutation.Max=8,16,31')
+func ParseParameter(text string) (Parameter, error) {
+	eq := strings.Index(text, "=")
+	if eq < 0 {
+		return Parameter{}, fmt.Errorf("error parsing parameter %v: missing values (e.g. Plants.Land=10,20)", text)
+	}
+	p := Parameter{Name: text[:eq]}
+	for _, v := range strings.Split(text[eq+1:], ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return Parameter{}, fmt.Errorf("error parsing parameter %v: %v", text, err)
+		}
+		p.Values = append(p.Values, value)
+	}
+	return p, nil
+}
+
+// Run runs the experiment for every combination of the parameter values, one combination after another
+// Returns an error if a parameter can not be set or a world can not be created
+func (s *Sweep) Run() (*SweepReport, error) {
+	grid := []map[string]float64{{}}
+	for _, p := range s.Parameters {
+		if len(p.Values) == 0 {
+			return nil, fmt.Errorf("error running sweep: parameter %v has no values", p.Name)
+		}
+		// Every combination so far is extended with every value of the parameter
+		next := make([]map[string]float64, 0, len(grid)*len(p.Values))
+		for _, values := range grid {
+			for _, v := range p.Values {
+				combination := map[string]float64{p.Name: v}
+				for name, value := range values {
+					combination[name] = value
+				}
+				next = append(next, combination)
+			}
+		}
+		grid = next
+	}
+	report := &SweepReport{Parameters: s.Parameters}
+	for _, values := range grid {
+		e := s.Experiment
+		e.Scenario = copyScenario(s.Scenario)
+		// Set the values in the order of the parameters, so the later ones win on the same name
+		for _, p := range s.Parameters {
+			if err := e.Scenario.Set(p.Name, values[p.Name]); err != nil {
+				return nil, fmt.Errorf("error running sweep: %v", err)
+			}
+		}
+		r, err := e.Run()
+		if err != nil {
+			return nil, err
+		}
+		report.Combinations = append(report.Combinations, Combination{Values: values, Report: r})
+	}
+	return report, nil
+}
+
+// copyScenario returns a copy of the scenario that can be changed without changing the original
+func copyScenario(s *terrain.Scenario) *terrain.Scenario {
+	c := *s
+	c.Populations = make(map[string]int, len(s.Populations))
+	for beingType, n := range s.Populations {
+		c.Populations[beingType] = n
+	}
+	c.Ranges = make(map[string]terrain.RangeConfig, len(s.Ranges))
+	for attribute, r := range s.Ranges {
+		c.Ranges[attribute] = r
+	}
+	return &c
+}
+
+// Write writes the sweep report as indented JSON
+func (r *SweepReport) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("error writing sweep report: %v", err)
+	}
+	return nil
+}
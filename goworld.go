@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"image"
 	"image/color"
+	"time"
 )
 
 // Location represents coordinates of an object
@@ -18,13 +19,35 @@ type Location struct {
 // Being is a living creature that is 'living' on the terrain
 type Being struct {
 	ID             uuid.UUID // The identifier
+	Name           string    // The name given to follow the creature over a run (empty if it has none, not inherited)
+	Tags           []string  // The labels given to group creatures (e.g. study), replaced as a whole when they change
 	Hunger         float64   // The desire for food
 	Thirst         float64   // The desire for liquid
 	WantsChild     float64   // The desire to produce offspring
+	Sleepiness     float64   // The desire for sleep (beings sleep during their resting hours, preferably in habitat)
+	Nocturnal      bool      // Nocturnal beings are active at night and sleep during the day
+	Hibernating    bool      // Hibernating beings stay in their habitat during winter and barely need food or water
+	Migrating      bool      // Migrating beings travel towards their destination when they have nothing else to do
+	Destination    Location  // The center of the region the being is migrating to
+	Target         uuid.UUID // The prey the being is hunting (carnivores share it with their pack)
+	Home           uuid.UUID // The nest or burrow the being built (nil if it has none yet)
+	Carrying       float64   // The nutritional value of the food the being carries to cache it for later
+	Busy           string    // The action that keeps the creature busy for several epochs (e.g. eat, empty if none)
+	BusyFor        float64   // How many more epochs the action takes (the creature does nothing else meanwhile)
+	MateCooldown   float64   // How many epochs pass before the creature can mate again
 	LifeExpectancy float64   // How many epochs the being will survive
+	Age            float64   // How many epochs the being has already lived
+	MaturityAge    float64   // How many epochs it takes to grow up (juveniles can not mate or hunt large prey)
 	VisionRange    float64   // How far the creature can spot objects
+	MemorySize     float64   // How many water and food locations the creature can remember (bigger brains need more food)
+	Memory         Memory    // The locations the creature remembers
 	Speed          float64   // How fast the creature can move (faster -> get hungry and thirsty quicker)
 	Durability     float64   // More durable creatures need less food and liquids
+	Energy         float64   // How much stamina the creature has left (moving uses it up, resting restores it)
+	Injury         float64   // How badly the creature is injured (slows it down, heals over time)
+	Camouflage     float64   // How well the creature blends into its habitat (predators can overlook it there)
+	Resistance     float64   // How well the creature tolerates toxic plants (toxicity above it is harmful)
+	Parasites      float64   // The parasites the creature carries (wear down durability, raise hunger, 0 for none)
 	Stress         float64   // How stressed the creature is
 	// Stress increases when:
 	// 	- the being becomes hungrier / thirstier
@@ -35,22 +58,157 @@ type Being struct {
 	Size    float64   // Physical size of the creature (bigger need more food and liquid, but are
 	// not affected by stress as much)
 	Fertility float64 // The number of offspring produced after successful mating with another being
+	// The personality decides how strongly the creature weighs its needs and risks when picking what to do
+	Personality Personality
+	// The brain decides what the creature does next (nil uses the brain of its type or the built-in behavior)
+	Brain Brain `json:"-"`
 	// The offspring inherit their features from the parents with a random value using the parents values as borders
 	MutationRate float64  // How much the attributes can deviate
 	Position     Location // Where the creature is currently located in the world
+	Heading      Location // The last move of the creature (used to align with the group it moves with)
+	// The family tree of the creature (the founders of the families have no lineage and are generation 0)
+	Lineage    uuid.UUID    // The founder of the family, passed down from the parent that started the mating
+	Generation int          // How many generations separate the creature from the founder
+	Parents    [2]uuid.UUID // The beings that mated to produce the creature (nil for the founders)
+	Offspring  int          // How many offspring the creature produced (born or laid in eggs)
+	Kills      int          // How many beings the creature killed and ate
 	// The creature can not move on water (Jesus not implemented yet) or on mountain peaks.
 	Type string // Being type refers to what it can eat and where it can move:
 	//	Flying ... can move anywhere and eats plants plus smaller beings (at most half its size)
 	//  Water ... eats (water plants only) and moves in water, comes to land only to reproduce
 	//  Carnivore ... eats all beings (flying / water / other carnivores) and can use a speed boost when stalking prey
+	//  Scavenger ... moves like carnivores, but does not hunt and only eats carrion (bodies of beings that died)
+	//  Amphibian ... moves and eats (plants) both in water and on land, but is less efficient outside its habitat
+	//  Insect ... tiny and fast breeding, flies and feeds on young plants without eating them (pollinating them)
+}
+
+// Memory holds locations a being has seen before and can come back to when it can not see what it needs
+type Memory struct {
+	Water []Location // Where the being found water
+	Food  []Location // Where the being found plants to eat
+}
+
+// Personality holds the weights a being gives to its needs and to the danger around the spots it could go to
+type Personality struct {
+	Thirst  float64 // How much the being cares about drinking
+	Hunger  float64 // How much the being cares about eating
+	Mating  float64 // How much the being cares about finding a partner
+	Caution float64 // How much predators around a spot put the being off (0 ignores them, 1 avoids them)
+}
+
+// Perception is what a being senses around itself, its brain decides on the next action based on it
+type Perception struct {
+	World        World      // The world the being lives in (to look up what is on the visible spots)
+	Surroundings []Location // The spots the being can see
+	Sight        float64    // How far the being can currently see
+}
+
+// PerceptionView shows what a being perceives, for debugging its decisions (see World.PerceptionFor)
+type PerceptionView struct {
+	Sight   float64     // How far the being can currently see
+	Visible []Location  // The spots the being can see
+	Hidden  []Location  // The spots in its sight the being can not see (behind boulders)
+	Food    []uuid.UUID // The food on the visible spots
+	Beings  []uuid.UUID // The other beings on the visible spots
+	Target  uuid.UUID   // The prey the being is hunting (uuid.Nil if none)
+	Action  string      // The action the being chose in its latest update (empty if it is not traced, see TraceBeing)
+	Goal    Location    // Where the being wants to take the action
+}
+
+// BeingDescription is a being with the values derived from its attributes and its latest update, for inspectors and
+// other UIs (see World.DescribeBeing)
+type BeingDescription struct {
+	Being              // A copy of the attributes (changing it does not change the being)
+	Action    string   // The action the being chose in its latest update (e.g. eat, empty if it did not choose one)
+	Done      Outcome  // What the being did in its latest update (e.g. ate plant, wandered, slept)
+	Goal      Location // Where the being wants to take the action
+	PathLeft  int      // How many moves of the path towards the goal are left (0 if it planned none)
+	Sight     float64  // How far the being sees right now (its vision changed by age and stress)
+	Pace      float64  // How fast the being moves right now (its speed changed by age and injuries)
+	Lived     float64  // The share of its lifetime the being has lived (0 at birth, 1 at death)
+	Juvenile  bool     // The being has not grown up yet (it can not mate or hunt large prey)
+	Living    int      // How many of its offspring are still alive (see Being.Offspring)
+	Surface   string   // The surface the being stands on
+	TargetPos Location // Where the prey the being is hunting is (see Being.Target)
+}
+
+// Action is what the brain of a being decided to do next
+type Action struct {
+	Name     string   // What to do, e.g. drink, eat, mate, wander, sleep, rest, chase
+	Location Location // Where to do it
+}
+
+// Outcome is what an update of a being or plant did (see ActionResult), the text is the one listeners are told
+type Outcome string
+
+// The outcomes of the updates of beings (the targets of the ActionResult in brackets)
+const (
+	Died         Outcome = "died"          // The being died (the being and the body it left behind, if there was room)
+	Wandered     Outcome = "wandered"      // The being moved around without a goal
+	Drank        Outcome = "drank"         // The being drank (or moved towards water)
+	Stalked      Outcome = "stalked"       // The hunter waits next to the prey for the rest of its pack
+	AttackFailed Outcome = "attack failed" // The prey fought back or escaped
+	AteBeing     Outcome = "ate being"     // The being ate another one (the prey and the remains left behind)
+	AtePlant     Outcome = "ate plant"     // The being ate a plant up (the plant)
+	Pollinated   Outcome = "pollinated"    // The insect fed on a plant without eating it (the plant)
+	AteCache     Outcome = "ate cache"     // The being ate food it cached (the cache)
+	StoleCache   Outcome = "stole cache"   // The being ate food another being cached (the cache)
+	TookBite     Outcome = "took bite"     // The being took a bite of a plant that lives on (the plant)
+	AteFail      Outcome = "ate fail"      // The being moved towards food it could not reach in this update
+	Mated        Outcome = "mated"         // The being mated (the offspring born)
+	LaidEggs     Outcome = "laid eggs"     // The being mated and laid eggs (the eggs)
+	AteCarried   Outcome = "ate carried"   // The being ate the food it carried
+	Cached       Outcome = "cached"        // The being hid the food it carried (the cache)
+	CacheFailed  Outcome = "cache failed"  // There was no room to hide the food
+	Slept        Outcome = "slept"         // The being slept
+	BuiltHome    Outcome = "built home"    // The being built a nest or burrow (the home)
+	BuildFailed  Outcome = "build failed"  // There was no room for the home
+	Froze        Outcome = "froze"         // The being could not move anywhere
+	ChasedAway   Outcome = "chased away"   // The being chased an intruder out of its territory (the intruder)
+	Chased       Outcome = "chased"        // The being ran after an intruder
+	Recalled     Outcome = "recalled"      // The being went towards a spot it remembers
+	Tracked      Outcome = "tracked"       // The being followed a scent trail
+	Rested       Outcome = "rested"        // The being stayed in place and regained energy
+	Hibernated   Outcome = "hibernated"    // The being spent the update hibernating
+	Retreated    Outcome = "retreated"     // The being moved towards its habitat to hibernate
+	Migrated     Outcome = "migrated"      // The being moved towards the region it is migrating to
+	Busy         Outcome = "busy"          // The being went on with an action that takes several updates
+)
+
+// The outcomes of the updates of plants and of the other food (the targets of the ActionResult in brackets)
+const (
+	Grew         Outcome = "grew"          // The plant grew
+	PlantedSeeds Outcome = "planted seeds" // The plant moved to the next growth stage and seeded (the seedlings)
+	PlantedFail  Outcome = "planted fail"  // The plant moved to the next growth stage but found no room for seedlings
+	Withered     Outcome = "withered"      // The plant (or carrion, egg, cache) is gone (the food)
+	Rotted       Outcome = "rotted"        // The carrion rotted a bit
+	Incubated    Outcome = "incubated"     // The egg is still incubating
+	Hatched      Outcome = "hatched"       // The offspring hatched (the egg and the hatchling)
+	Spoiling     Outcome = "spoiling"      // The cache spoiled a bit
+)
+
+// ActionResult is what an update of a being or plant did (see World.UpdateBeing and World.UpdatePlant)
+type ActionResult struct {
+	Outcome Outcome     // What the update did
+	Actor   uuid.UUID   // The being or plant updated
+	Targets []uuid.UUID // The beings and food affected (see the outcomes)
+}
+
+// Brain is an interface for the decision making of beings, so behaviors can be swapped without changing the world
+type Brain interface {
+	Decide(being *Being, perception Perception) Action // Return the next action for the being
 }
 
 // Food is for now just plants
 type Food struct {
 	ID               uuid.UUID // Identifier
+	Name             string    // The name given to follow the food over a run (empty if it has none, not inherited)
+	Tags             []string  // The labels given to group food, replaced as a whole when they change
 	GrowthSpeed      float64   // How fast the food will grow (how many epochs to move to the next growth stage)
 	NutritionalValue float64   // How much it decreases the hunger (also possible for minimal thirst decrease)
+	Eaten            float64   // How much of the nutritional value was bitten off (regrows, eaten up plants die)
 	Taste            float64   // Tastier food is preferred among creatures (when not too hungry)
+	Toxicity         float64   // Eating toxic food harms beings (lowers durability, raises stress) without resistance
 	GrowthStage      float64   // The current growth phase of the food
 	StageProgress    float64   // Percentage toward next growth stage. Resets when reaching next growth stage
 	Area             float64   // How much area it needs to grow (taken as diameter of circle)
@@ -78,33 +236,168 @@ type World interface {
 	GetFood() map[string]*Food                          // Get all edible food on the map (ID: Food)
 	GetSurfaceColorAtSpot(spot Location) color.RGBA     // Returns the color of the surface at a location
 	GetSurfaceNameAt(location Location) (string, error) // Returns the common name belonging to the surface at the location
+	GetHeightAt(location Location) (float64, error)     // Returns the height of the terrain at the location (0 to 1)
 	GetBeingAt(location Location) (uuid.UUID, error)    // Returns the being id at the location (or uuid.Nil if no being)
 	GetSize() (int, int)                                // Return width, height of the world
 	IsHabitable(location Location) (bool, error)        // Return if the world is inhabitable at the desired location
 	IsOutOfBounds(location Location) bool               // Return true if location is outside the defined area
+	IsFord(location Location) bool                      // Return true if land beings can cross the water at the location
 	GetFoodWithID(id uuid.UUID) *Food                   // Returns food with id or nil
 	GetBeingWithID(id uuid.UUID) *Being                 // Returns being that belongs to id or nil
 	Distance(from, to Location) float64                 // Return distance between locations
 
-	CreateCarnivores(quantity int)              // Create random beings and place them (previous beings should remain)
-	CreateFishies(quantity int)                 // Create random beings that live in water
-	CreateFlyers(quantity int)                  // Create random beings that can fly
-	CreateRandomCarnivore() *Being              // Make a random being (predefined attribute ranges)
-	ThrowBeing(b *Being)                        // Place the (NEW) being onto a random map (adjusts its habitat to that spot)
+	// Create random beings and place them (previous beings should remain), return how many were placed and an
+	// error if there was no room for the rest
+	CreateCarnivores(quantity int) (int, error)
+	CreateFishies(quantity int) (int, error)    // Create random beings that live in water
+	CreateFlyers(quantity int) (int, error)     // Create random beings that can fly
+	CreateScavengers(quantity int) (int, error) // Create random beings that feed on carrion
+	CreateAmphibians(quantity int) (int, error) // Create random beings that live both in water and on land
+	CreateInsects(quantity int) (int, error)    // Create random tiny flying beings that pollinate plants
+	CreateRandomCarnivore() (*Being, error)     // Make a random being (predefined attribute ranges)
+	AddBeing(b *Being) error                    // Place the being onto the map at its position (validated)
+	RemoveBeing(id uuid.UUID) error             // Take the being off the map (along with its home and territory)
+	ThrowBeing(b *Being) error                  // Place the (NEW) being onto a random map spot (adjusts its habitat)
 	Wander(b *Being) error                      // Make the provided being move randomly across the terrain
-	UpdateBeing(b *Being) (string, []uuid.UUID) // Make the being execute an action based on its needs
-	UpdatePlant(p *Food) (string, []uuid.UUID)  // Update plant values, e.g. growth, wither, throw seeds ...
+	UpdateBeing(b *Being) ActionResult          // Make the being execute an action based on its needs
+	UpdatePlant(p *Food) ActionResult           // Update plant values, e.g. growth, wither, throw seeds ...
+	AdvanceTime()                               // Move the world clock one epoch forward (call once per update)
+	IsNight() bool                              // Returns true if it is currently night in the world
+	Season() string                             // Returns the current season (Spring, Summer, Autumn or Winter)
+
+	ProvideFood(landPlants, waterPlants int) (int, error) // Create edible food with random attributes (how many placed)
+	// Change the surface at the location (what can not stay there is moved or removed)
+	SetSurfaceAt(location Location, surfaceID uuid.UUID) error
+
+	// Intervene with a being mid-run (return an error if there is no being with the id)
+	KillBeing(id uuid.UUID, cause string) error                  // Make the being die (it leaves its body behind)
+	FeedBeing(id uuid.UUID, amount float64) error                // Lower the hunger of the being
+	HealBeing(id uuid.UUID, amount float64) error                // Lower the injury of the being
+	SetAttribute(id uuid.UUID, name string, value float64) error // Set a numeric attribute (e.g. Speed) within its range
+	TeleportBeing(id uuid.UUID, to Location) error               // Move the being straight to a spot it could walk onto
+
+	Snapshot() *Snapshot       // Returns a deep copy of the current state, safe to read while the world keeps updating
+	Restore(s *Snapshot) error // Replace the beings, food and time with the ones from a snapshot of this world
+	Hash() string              // Returns a digest of the whole state (the same for runs that stayed identical)
+
+	// Timings returns how long every phase of a tick took (rolling percentiles over the latest ticks)
+	Timings() map[string]Timing
+	// RecordTiming adds the duration to the phase of the current tick (e.g. the rendering of a display)
+	RecordTiming(phase string, d time.Duration)
+
+	// Stores being and food information into json files (gzipped if the name ends with .gz)
+	PlantsToJSON(fileName string) error
+	BeingsToJSON(fileName string) error
+	// Stores being and food attributes as CSV tables with a row for each (gzipped if the name ends with .gz)
+	PlantsToCSV(fileName string) error
+	BeingsToCSV(fileName string) error
+	SpeciesToCSV(fileName string) error // A row for each species and type (see SpeciesSummary)
+	// Export stores the beings and plants right away (e.g. when the user asks for it)
+	Export() error
+
+	TraceBeing(id uuid.UUID)   // Keep the plans of the being (uuid.Nil for none), e.g. to show its path
+	PlannedPath() (Plan, bool) // Returns the latest plan of the traced being (false if it planned nothing)
+	// PerceptionFor returns what the being perceives right now (an error if there is no being with the id)
+	PerceptionFor(id uuid.UUID) (PerceptionView, error)
+	// DescribeBeing returns the being with the values derived from it (an error if there is no being with the id)
+	DescribeBeing(id uuid.UUID) (BeingDescription, error)
+	// Species groups the living beings into species by how alike they are (ID: species, e.g. Insect-2)
+	Species() map[string]string
+	// SpeciesSummary returns the summary of every species and every type (see SpeciesSummary)
+	SpeciesSummary() []SpeciesSummary
+	// PredatorPrey returns the predator and prey populations and the kill rates sampled over the run (the oldest
+	// first), the points of a phase plot
+	PredatorPrey() []PredatorPreySample
+	// EnergyFlows returns the nutritional energy that went between the levels of the food web (e.g. Plants to
+	// Herbivores) in windows of epochs, EnergyTotals over the whole run
+	EnergyFlows() []EnergyWindow
+	EnergyTotals() []EnergyFlow
+	// PathCost returns the cost of the path a being of the type would take between the locations (the surfaces it
+	// crosses weigh in) without building the path, an error if the type is unknown or there is no path
+	PathCost(from, to Location, beingType string) (float64, error)
+	// Find the closest water spot (false if there is none), the closest food a being of the diet (its type) can eat
+	// (any food if empty) and the closest being the filter accepts (any being if nil) in the whole world, nil if none
+	FindNearestWater(from Location) (Location, bool)
+	FindNearestFood(from Location, diet string) *Food
+	FindNearestBeing(from Location, filter func(*Being) bool) *Being
+	// Call fn for every being (or food) the filter accepts, all of them if nil (in the order of their IDs)
+	ForEachBeing(filter func(*Being) bool, fn func(*Being))
+	ForEachFood(filter func(*Food) bool, fn func(*Food))
+	// Query returns the IDs of the beings and food matching the expression, e.g. "type == 'Carnivore' && hunger > 200"
+	Query(expression string) ([]uuid.UUID, error)
+	// Name the being or food (empty removes the name), add or remove one of its tags, an error if there is no such
+	// being or food. EntityName returns the name, also of beings and food that are gone (empty if it had none)
+	NameEntity(id uuid.UUID, name string) error
+	TagEntity(id uuid.UUID, tag string) error
+	UntagEntity(id uuid.UUID, tag string) error
+	EntityName(id uuid.UUID) string
+}
+
+// Snapshot is a deep copy of the world state at one epoch. Take it between updates, then other goroutines (stats,
+// streaming, UI) can read it while the world keeps changing
+type Snapshot struct {
+	Epoch         uint64           // The epoch the snapshot was taken at
+	Seed          int64            // The seed the world was created with (0 if it was not seeded)
+	Season        string           // The season at that epoch
+	Night         bool             // Whether it was night at that epoch
+	Width, Height int              // The size of the world
+	Beings        map[string]Being // Copies of the living beings (ID: Being)
+	Food          map[string]Food  // Copies of the food on the map (ID: Food)
+	Terrain       *image.RGBA      // A copy of the colored terrain
+}
 
-	ProvideFood(landPlants, waterPlants int) // Create edible food with random attributes
+// Plan is the path a being found towards the spot of its action
+type Plan struct {
+	Action     string     // What the being is about to do there (e.g. eat)
+	From, Goal Location   // Where the being planned from and where it wants to get
+	Path       []Location // The moves from the being to the goal (empty if the pathfinder found no path)
+}
+
+// Timing describes how long a phase of a tick took over the latest ticks
+type Timing struct {
+	P50, P90, P99, Max time.Duration
+}
+
+// SpeciesSummary describes a species (or all the beings of a type): its living members and their attributes, how
+// long the members that died lived and how many beings its members killed over the run
+type SpeciesSummary struct {
+	Species    string             // The species (e.g. Insect-2), the type in the summary of the whole type
+	Type       string             // The being type
+	Population int                // How many members live now
+	Means      map[string]float64 // The mean attributes of the living members (named as the Being fields)
+	Variances  map[string]float64 // The variances of the attributes of the living members
+	Deaths     int                // How many members died during the run
+	Lifespan   float64            // The mean age the members that died reached (0 if none died yet)
+	Kills      int                // How many beings the members (living and dead) killed during the run
+}
 
-	// Stores being and food information into json files
-	PlantsToJSON(fileName string)
-	BeingsToJSON(fileName string)
+// PredatorPreySample is the state of the predators (the beings that hunt) and their prey at an epoch
+type PredatorPreySample struct {
+	Epoch     uint64
+	Predators int     // How many predators live
+	Prey      int     // How many beings the predators hunt live
+	Kills     int     // How many beings the predators killed since the previous sample
+	KillRate  float64 // The kills per predator and epoch since the previous sample
+}
+
+// EnergyFlow is the nutritional energy that went from a level of the food web to another (e.g. from Plants to
+// Herbivores or from Corpses to Scavengers)
+type EnergyFlow struct {
+	From, To string
+	Taken    float64 // What the source lost
+	Absorbed float64 // What the consumer gained (less than taken when it digests poorly or is full)
+}
+
+// EnergyWindow is the energy that went through the food web over a window of epochs
+type EnergyWindow struct {
+	From, To uint64 // The first and the last epoch of the window
+	Flows    []EnergyFlow
 }
 
 // Pathfinder is an interface for path finding implementations
 type Pathfinder interface {
 	GetPath(from, to Location, allowInhabitable bool) []Location // Return a list of neighbouring locations to move to the desired
 	// location
-
+	// Return the cost of the path GetPath finds without building it (false if there is none)
+	GetCost(from, to Location, allowInhabitable bool) (float64, bool)
 }
@@ -16,10 +16,10 @@ type Perlin struct {
 	Octaves     float64
 	Persistence float64
 	p           []int // Used in a hash function to determine which gradient vector to use (quicker than completely random)
+	repeat      int   // After how many units the noise repeats (never if 0)
 }
 
 var (
-	Repeat int
 	// The predefined permutation table by Ken Perlin in his reference implementation
 	// (https://mrl.nyu.edu/~perlin/noise/)
 	permutation = [512]int{151, 160, 137, 91, 90, 15, 131, 13, 201, 95, 96, 53, 194, 233, 7, 225, 140, 36, 103,
@@ -39,12 +39,11 @@ var (
 // NewPerlin sets the Perlin generator attributes to the specified and initializes the permutation table
 func NewPerlin(octaves, persistence float64, repeat int) *Perlin {
 	p := &Perlin{
-		octaves, persistence, make([]int, 512),
+		octaves, persistence, make([]int, 512), repeat,
 	}
 	for i := range p.p {
 		p.p[i] = permutation[i%256]
 	}
-	Repeat = repeat
 	return p
 }
 
@@ -125,10 +124,10 @@ func grad(hash int, x, y, z float64) float64 {
 }
 
 // inc is used to increment the numbers and make sure that the noise repeats if repeat is set
-func inc(n int) int {
+func (p *Perlin) inc(n int) int {
 	n++
-	if Repeat > 0 {
-		n %= Repeat
+	if p.repeat > 0 {
+		n %= p.repeat
 	}
 	return n
 }
@@ -179,13 +178,13 @@ func (p *Perlin) Noise3D(x, y, z float64) float64 {
 	w := fade(zf)
 
 	aaa := p.p[p.p[p.p[xi]+yi]+zi]
-	aba := p.p[p.p[p.p[xi]+inc(yi)]+zi]
-	aab := p.p[p.p[p.p[xi]+yi]+inc(zi)]
-	abb := p.p[p.p[p.p[xi]+inc(yi)]+inc(zi)]
-	baa := p.p[p.p[p.p[inc(xi)]+yi]+zi]
-	bba := p.p[p.p[p.p[inc(xi)]+inc(yi)]+zi]
-	bab := p.p[p.p[p.p[inc(xi)]+yi]+inc(zi)]
-	bbb := p.p[p.p[p.p[inc(xi)]+inc(yi)]+inc(zi)]
+	aba := p.p[p.p[p.p[xi]+p.inc(yi)]+zi]
+	aab := p.p[p.p[p.p[xi]+yi]+p.inc(zi)]
+	abb := p.p[p.p[p.p[xi]+p.inc(yi)]+p.inc(zi)]
+	baa := p.p[p.p[p.p[p.inc(xi)]+yi]+zi]
+	bba := p.p[p.p[p.p[p.inc(xi)]+p.inc(yi)]+zi]
+	bab := p.p[p.p[p.p[p.inc(xi)]+yi]+p.inc(zi)]
+	bbb := p.p[p.p[p.p[p.inc(xi)]+p.inc(yi)]+p.inc(zi)]
 
 	var x1, x2, y1, y2 float64
 	x1 = lerp(
@@ -26,7 +26,6 @@ var (
 		{0, 1},
 		{-1, 0},
 	}
-	worldWidth = 0
 )
 
 type AStar struct {
@@ -54,6 +53,12 @@ func (n *aStarNode) PathNeighborCost(to *aStarNode, w GoWorld.World) float64 {
 	case "Mountain":
 		// Slopes are hardest to cross
 		return 2.5
+	case "Water":
+		// Swimming is slower than walking on grass
+		return 2.0
+	case "Moutain Peak":
+		// Peaks can only be flown over, avoid them if possible
+		return 10.0
 	default:
 		// Unknown surface, assume it is harder to cross than others
 		return 3.0
@@ -82,7 +87,9 @@ func (n *aStarNode) PathNeighbors(w GoWorld.World, allowInhabitable bool) []*aSt
 		if allowInhabitable {
 			habitable = !w.IsOutOfBounds(newLocation)
 		} else {
+			// Land beings can also wade across fords
 			habitable, _ = w.IsHabitable(newLocation)
+			habitable = habitable || w.IsFord(newLocation)
 		}
 		// Check if the neighbouring spot is blocked (surface not passable or being on it)
 		if habitable {
@@ -98,8 +105,6 @@ func NewPathfinder(world GoWorld.World) GoWorld.Pathfinder {
 	a := &AStar{
 		World: world,
 	}
-	// Store the world size for node ID generation
-	worldWidth, _ = a.World.GetSize()
 	return a
 }
 
@@ -107,6 +112,7 @@ func NewPathfinder(world GoWorld.World) GoWorld.Pathfinder {
 func (a *AStar) GetPath(from GoWorld.Location, to GoWorld.Location, allowInhabitable bool) []GoWorld.Location {
 	// Check if both spots are valid to walk on
 	toHab, _ := a.World.IsHabitable(to)
+	toHab = toHab || a.World.IsFord(to)
 	// If being can move across inhabitable locations
 	if !allowInhabitable && !toHab {
 		// TODO return error and handle it there?
@@ -125,7 +131,7 @@ func (a *AStar) GetPath(from GoWorld.Location, to GoWorld.Location, allowInhabit
 	}
 
 	// Find a path using the A* algorithm
-	path, _, found := astar(fromSpot, toSpot, a.World, allowInhabitable)
+	goal, found := astar(fromSpot, toSpot, a.World, allowInhabitable)
 	//fmt.Println("path -> locations array")
 	if !found {
 		// TODO return error and handle it there?
@@ -135,24 +141,42 @@ func (a *AStar) GetPath(from GoWorld.Location, to GoWorld.Location, allowInhabit
 		return []GoWorld.Location{}
 	}
 
-	// Convert the nodes back to locations for use in other GoWorld packages
-	locations := make([]GoWorld.Location, len(path))
-	j := 0
-	for i := len(path) - 1; i >= 0; i-- {
-		locations[j] = GoWorld.Location{
-			X: path[i].X,
-			Y: path[i].Y,
+	// Convert the nodes back to locations for use in other GoWorld packages (following the parents from the goal)
+	length := 0
+	for ancestor := goal; ancestor != nil; ancestor = ancestor.parent {
+		length++
+	}
+	locations := make([]GoWorld.Location, length)
+	for ancestor := goal; ancestor != nil; ancestor = ancestor.parent {
+		length--
+		locations[length] = GoWorld.Location{
+			X: ancestor.X,
+			Y: ancestor.Y,
 		}
-		j++
 	}
 	//fmt.Println("Path search return")
 	return locations
 }
 
-// astar calculates a short path and the distance between the two nodes
+// GetCost returns the cost of the path GetPath finds between the locations (the sum of the surface costs of its
+// spots) without building the path, false if there is no path
+func (a *AStar) GetCost(from GoWorld.Location, to GoWorld.Location, allowInhabitable bool) (float64, bool) {
+	toHab, _ := a.World.IsHabitable(to)
+	toHab = toHab || a.World.IsFord(to)
+	if !allowInhabitable && !toHab {
+		return 0, false
+	}
+	goal, found := astar(aStarNode{X: from.X, Y: from.Y}, aStarNode{X: to.X, Y: to.Y}, a.World, allowInhabitable)
+	if !found {
+		return 0, false
+	}
+	return goal.gScore, true
+}
+
+// astar finds a short path between the two nodes and returns its last node (the parents lead back to from, the G
+// score is the distance)
 // If no path is found, found will be false
-// PATH IS RETURNED IN REVERSE ORDER, FIRST NODE IS TO, LAST IS FROM
-func astar(from, to aStarNode, w GoWorld.World, allowInhabitable bool) (path []*aStarNode, distance float64, found bool) {
+func astar(from, to aStarNode, w GoWorld.World, allowInhabitable bool) (goal *aStarNode, found bool) {
 	// The open and closed lists from A*
 	// The open list is a priority queue for performance reasons
 	openList := &aStarQueue{indexOf: make(map[int64]int)}
@@ -160,9 +184,10 @@ func astar(from, to aStarNode, w GoWorld.World, allowInhabitable bool) (path []*
 	// The closed list should be a set, but for simplicity it is a map where keys work as the set
 	closedList := make(map[int64]bool)
 
-	// Calculate the node IDs
-	from.calculateID()
-	to.calculateID()
+	// Calculate the node IDs (from the width of this world, several worlds can search for paths at the same time)
+	width, _ := w.GetSize()
+	from.calculateID(width)
+	to.calculateID(width)
 
 	// Add the source node and start exploring paths
 	heap.Push(openList, from)
@@ -177,21 +202,14 @@ func astar(from, to aStarNode, w GoWorld.World, allowInhabitable bool) (path []*
 
 		// Check if we reached the goal
 		if currentNode.id == to.id {
-			// Reconstruct the path from current node
-			foundPath := []*aStarNode{}
-			ancestor := &currentNode
-			for ancestor != nil {
-				foundPath = append(foundPath, ancestor)
-				ancestor = ancestor.parent
-			}
-			// Return path, distance, and that we found a path
-			return foundPath, currentNode.gScore, true
+			// Return the goal (the path leads back from it) and that we found a path
+			return &currentNode, true
 		}
 		// Explore every suitable neighbour of the current node
 
 		for _, neighbour := range currentNode.PathNeighbors(w, allowInhabitable) {
 			// Calculate the ID if it doesn't exist
-			neighbour.calculateID()
+			neighbour.calculateID(width)
 
 			// If the neighbour is in the closed list (has been visited already), do not revisit him
 			if _, ok := closedList[neighbour.id]; ok {
@@ -239,8 +257,8 @@ type aStarNode struct {
 // CalculateID sets and returns the node identifier
 // Imagine raveling 2D array into 1D and the 1D index becomes the ID of the node
 // The operation is idempotent
-func (n *aStarNode) calculateID() int64 {
-	id := int64(n.Y*worldWidth + n.X)
+func (n *aStarNode) calculateID(width int) int64 {
+	id := int64(n.Y*width + n.X)
 	n.id = id
 	return id
 }
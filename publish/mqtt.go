@@ -0,0 +1,49 @@
+package publish
+
+import (
+	"fmt"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"time"
+)
+
+var (
+	// How long the connection to an MQTT broker may take
+	mqttTimeout = 10 * time.Second
+	// How long the events still being sent may take when the connection is closed (in milliseconds)
+	mqttQuiesce uint = 1000
+)
+
+// MQTT publishes the events to the topics <prefix>/<event> of an MQTT broker
+type MQTT struct {
+	client mqtt.Client
+	prefix string
+}
+
+// DialMQTT connects to the MQTT broker at the address (e.g. tcp://localhost:1883)
+func DialMQTT(address, prefix string) (*MQTT, error) {
+	options := mqtt.NewClientOptions().AddBroker(address).SetClientID(fmt.Sprintf("goworld-%d", time.Now().UnixNano()))
+	client := mqtt.NewClient(options)
+	token := client.Connect()
+	if !token.WaitTimeout(mqttTimeout) {
+		return nil, fmt.Errorf("error connecting to MQTT: timed out after %v", mqttTimeout)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("error connecting to MQTT: %v", err)
+	}
+	return &MQTT{client: client, prefix: prefix}, nil
+}
+
+// Publish sends the payload to the topic of the event (at most once, the world does not wait for the broker)
+func (m *MQTT) Publish(event string, payload []byte) error {
+	if !m.client.IsConnected() {
+		return fmt.Errorf("error publishing to MQTT: not connected")
+	}
+	m.client.Publish(m.prefix+"/"+event, 0, false, payload)
+	return nil
+}
+
+// Close disconnects from the broker once the events still being sent are out
+func (m *MQTT) Close() error {
+	m.client.Disconnect(mqttQuiesce)
+	return nil
+}
@@ -0,0 +1,35 @@
+package publish
+
+import (
+	"fmt"
+	"github.com/nats-io/nats.go"
+)
+
+// NATS publishes the events to the subjects <prefix>.<event> of a NATS server
+type NATS struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// DialNATS connects to the NATS server at the address (e.g. nats://localhost:4222)
+func DialNATS(address, prefix string) (*NATS, error) {
+	conn, err := nats.Connect(address, nats.Name("GoWorld"))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to NATS: %v", err)
+	}
+	return &NATS{conn: conn, prefix: prefix}, nil
+}
+
+// Publish sends the payload to the subject of the event (the client buffers it, the world does not wait)
+func (n *NATS) Publish(event string, payload []byte) error {
+	return n.conn.Publish(n.prefix+"."+event, payload)
+}
+
+// Close sends the buffered events and disconnects
+func (n *NATS) Close() error {
+	if err := n.conn.Drain(); err != nil {
+		n.conn.Close()
+		return fmt.Errorf("error closing NATS connection: %v", err)
+	}
+	return nil
+}
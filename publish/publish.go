@@ -0,0 +1,68 @@
+// Package publish forwards the events of a world (see terrain.Listener) to a message broker, a topic for every kind of
+// event, so external pipelines (stream processing, dashboards) can follow the world as it runs
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld/terrain"
+	"net/url"
+)
+
+// Broker is a message broker the events are published to
+type Broker interface {
+	// Publish sends the payload to the topic of the event (e.g. goworld.died on NATS, goworld/died on MQTT)
+	Publish(event string, payload []byte) error
+	Close() error
+}
+
+// Event is the JSON published for every event of the world
+type Event struct {
+	Action string      // What happened (e.g. died, added, ticked)
+	Epoch  uint64      // The epoch it happened at
+	IDs    []uuid.UUID // The beings or plants affected
+	// The names of the affected beings and plants that have one (ID: name, see terrain.RandomWorld.NameEntity)
+	Names map[string]string `json:",omitempty"`
+}
+
+// Dial connects to the broker at the address, its scheme picks the broker: nats://host:4222 for NATS,
+// mqtt://host:1883 (tcp://, ssl://, ws://) for MQTT. The topics of the events start with the prefix
+func Dial(address, prefix string) (Broker, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to broker: %v", err)
+	}
+	switch u.Scheme {
+	case "nats", "tls":
+		return DialNATS(address, prefix)
+	case "mqtt":
+		u.Scheme = "tcp"
+		return DialMQTT(u.String(), prefix)
+	case "tcp", "ssl", "ws", "wss":
+		return DialMQTT(address, prefix)
+	}
+	return nil, fmt.Errorf("error connecting to broker: unknown scheme %v (nats or mqtt)", u.Scheme)
+}
+
+// Forward publishes every event of the world to the broker (failures are only printed, the world goes on)
+func Forward(world *terrain.RandomWorld, broker Broker) {
+	world.Subscribe(func(action string, ids []uuid.UUID) {
+		event := Event{Action: action, Epoch: world.Epoch, IDs: ids}
+		for _, id := range ids {
+			if name := world.EntityName(id); name != "" {
+				if event.Names == nil {
+					event.Names = make(map[string]string)
+				}
+				event.Names[id.String()] = name
+			}
+		}
+		payload, err := json.Marshal(event)
+		if err == nil {
+			err = broker.Publish(action, payload)
+		}
+		if err != nil {
+			fmt.Printf("error publishing %v: %v\n", action, err)
+		}
+	})
+}
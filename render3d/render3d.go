@@ -0,0 +1,491 @@
+// Package render3d draws a world as a 3D heightfield seen by a camera orbiting its center, with the beings (and the
+// food as smaller ones) as billboards facing the camera. It runs the world instead of the 2D view of package display
+// (e.g. for presentations and debugging the terrain): the arrow keys turn and tilt the camera, - and = (or the mouse
+// wheel) zoom, far out the beings and the food become density dots (see LODDistance)
+package render3d
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/autosave"
+	"github.com/rubinda/GoWorld/display"
+	"github.com/rubinda/GoWorld/terrain"
+	"image/color"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	world *terrain.RandomWorld
+	// Autosave stores the world every few epochs while it is drawn (nil for no saves)
+	Autosave *autosave.Saver
+	// Step makes every Step-th spot a vertex of the heightfield (larger steps keep huge worlds fast)
+	Step = 4
+	// The size of the window
+	screenWidth, screenHeight = 960, 720
+	// How high the highest spot is compared to the larger side of the world
+	relief = 0.12
+	// How many updates pass before the colors of the terrain are read again (the surfaces change slowly)
+	colorRefresh uint64 = 100
+	// Where the light comes from (normalized, shades the slopes)
+	light = normalize(vector{-0.4, 1, -0.3})
+	// The colors of the billboards of the being types
+	typeColors = map[string]color.RGBA{
+		"Carnivore": {R: 214, G: 39, B: 40, A: 255},
+		"Water":     {R: 31, G: 119, B: 180, A: 255},
+		"Flying":    {R: 255, G: 255, B: 255, A: 255},
+		"Scavenger": {R: 140, G: 86, B: 75, A: 255},
+		"Amphibian": {R: 44, G: 160, B: 44, A: 255},
+		"Insect":    {R: 255, G: 221, B: 51, A: 255},
+	}
+
+	// The colors of the food that is not a plant (plants are plantColor)
+	foodColors = map[string]color.RGBA{
+		"Carrion": {R: 85, G: 85, B: 85, A: 255},
+		"Egg":     {R: 244, G: 231, B: 195, A: 255},
+		"Cache":   {R: 196, G: 156, B: 107, A: 255},
+	}
+	plantColor = color.RGBA{R: 58, G: 125, B: 44, A: 255}
+	// LODDistance is how far the camera has to be before the beings and the food are drawn as density dots (one for
+	// every cell of lodCell by lodCell spots) instead of one by one, keeps huge populations fast when zoomed out
+	LODDistance = 2.0
+	lodCell     = 8
+
+	// Pace is how many epochs pass in every frame (see display.Pacing)
+	Pace = display.NewPacing()
+
+	camera = orbit{yaw: 0.8, pitch: 0.6, distance: 1.5}
+	field  heightfield
+	// The source of every triangle (a white image, the vertex colors tint it)
+	white *ebiten.Image
+	// Number of epochs run
+	updates uint64
+	// Set once the window should close (see Stop)
+	stopping   int32
+	errStopped = errors.New("renderer stopped")
+)
+
+// vector is a point or a direction in the space of the heightfield (y points up)
+type vector [3]float64
+
+func (a vector) sub(b vector) vector {
+	return vector{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func (a vector) dot(b vector) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func (a vector) cross(b vector) vector {
+	return vector{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func normalize(a vector) vector {
+	length := math.Sqrt(a.dot(a))
+	if length == 0 {
+		return a
+	}
+	return vector{a[0] / length, a[1] / length, a[2] / length}
+}
+
+// orbit is a camera circling the center of the world and looking at it
+type orbit struct {
+	yaw      float64 // The angle around the vertical axis
+	pitch    float64 // The angle above the ground
+	distance float64 // How far the camera is from the center (1 is the larger side of the world)
+}
+
+// view is the camera placed for drawing a frame
+type view struct {
+	position, right, up, forward vector
+	focal                        float64
+}
+
+// place returns where the camera is and where it looks
+func (o orbit) place() view {
+	position := vector{
+		o.distance * math.Cos(o.pitch) * math.Sin(o.yaw),
+		o.distance * math.Sin(o.pitch),
+		o.distance * math.Cos(o.pitch) * math.Cos(o.yaw),
+	}
+	forward := normalize(vector{-position[0], -position[1], -position[2]})
+	right := normalize(forward.cross(vector{0, 1, 0}))
+	return view{position: position, right: right, up: right.cross(forward), forward: forward,
+		focal: float64(screenHeight)}
+}
+
+// project returns where the point is drawn on the screen and how far from the camera it is (not drawn if false)
+func (v view) project(p vector) (float32, float32, float64, bool) {
+	d := p.sub(v.position)
+	depth := d.dot(v.forward)
+	if depth < 0.01 {
+		// Behind the camera
+		return 0, 0, 0, false
+	}
+	x := float64(screenWidth)/2 + d.dot(v.right)/depth*v.focal
+	y := float64(screenHeight)/2 - d.dot(v.up)/depth*v.focal
+	return float32(x), float32(y), depth, true
+}
+
+// heightfield is a grid over the terrain with two triangles in every cell
+type heightfield struct {
+	xs, ys        []int // The spots of the columns and rows of the grid
+	columns, rows int
+	points        []vector     // The vertices of the grid (row by row)
+	colors        []color.RGBA // The colors of the surfaces at the vertices
+	triangles     [][3]int     // The vertices of the triangles
+	shades        []float64    // How much light every triangle gets
+	// The heights are stretched between the lowest and the highest spot (the noise rarely uses the whole range)
+	lowest, highest float64
+}
+
+// Run draws the world until the window is closed, the world moves Pace.Speed epochs forward with every update
+func Run(w *terrain.RandomWorld) {
+	world = w
+	white, _ = ebiten.NewImage(4, 4, ebiten.FilterDefault)
+	_ = white.Fill(color.White)
+	buildHeightfield()
+	// The updates go on without focus, the world itself pauses (see display.Pacing.PauseUnfocused)
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.Run(update, screenWidth, screenHeight, 1, "GoWorld 3D"); err != nil && err != errStopped {
+		panic(err)
+	}
+}
+
+// Stop closes the window after the current update (Run returns then)
+func Stop() {
+	atomic.StoreInt32(&stopping, 1)
+}
+
+// position returns where the spot lies in the space of the heightfield (the world is centered and its larger side is
+// one long)
+func position(x, y int, height float64) vector {
+	size := float64(world.Width)
+	if world.Height > world.Width {
+		size = float64(world.Height)
+	}
+	return vector{
+		(float64(x) - float64(world.Width)/2) / size,
+		(height - field.lowest) / math.Max(field.highest-field.lowest, 1e-9) * relief,
+		(float64(y) - float64(world.Height)/2) / size,
+	}
+}
+
+// buildHeightfield places the vertices of the grid at the heights of the terrain and shades the triangles
+func buildHeightfield() {
+	step := Step
+	if step <= 0 {
+		step = 1
+	}
+	var xs, ys []int
+	for x := 0; x < world.Width; x += step {
+		xs = append(xs, x)
+	}
+	if xs[len(xs)-1] != world.Width-1 {
+		xs = append(xs, world.Width-1)
+	}
+	for y := 0; y < world.Height; y += step {
+		ys = append(ys, y)
+	}
+	if ys[len(ys)-1] != world.Height-1 {
+		ys = append(ys, world.Height-1)
+	}
+	field = heightfield{xs: xs, ys: ys, columns: len(xs), rows: len(ys), lowest: 1, highest: 0}
+	for x := 0; x < world.Width; x++ {
+		for y := 0; y < world.Height; y++ {
+			height, _ := world.GetHeightAt(GoWorld.Location{X: x, Y: y})
+			field.lowest = math.Min(field.lowest, height)
+			field.highest = math.Max(field.highest, height)
+		}
+	}
+	for _, y := range ys {
+		for _, x := range xs {
+			height, _ := world.GetHeightAt(GoWorld.Location{X: x, Y: y})
+			field.points = append(field.points, position(x, y, height))
+		}
+	}
+	for row := 0; row+1 < field.rows; row++ {
+		for column := 0; column+1 < field.columns; column++ {
+			topLeft := row*field.columns + column
+			bottomLeft := topLeft + field.columns
+			field.triangles = append(field.triangles, [3]int{topLeft, bottomLeft, topLeft + 1},
+				[3]int{topLeft + 1, bottomLeft, bottomLeft + 1})
+		}
+	}
+	for _, t := range field.triangles {
+		a, b, c := field.points[t[0]], field.points[t[1]], field.points[t[2]]
+		normal := normalize(b.sub(a).cross(c.sub(a)))
+		// Slopes facing away from the light still get some of it
+		field.shades = append(field.shades, 0.45+0.55*math.Max(normal.dot(light), 0))
+	}
+	colorHeightfield()
+}
+
+// colorHeightfield reads the colors of the surfaces at the vertices
+func colorHeightfield() {
+	field.colors = field.colors[:0]
+	for _, y := range field.ys {
+		for _, x := range field.xs {
+			field.colors = append(field.colors, world.GetSurfaceColorAtSpot(GoWorld.Location{X: x, Y: y}))
+		}
+	}
+}
+
+// steer moves the camera with the arrow keys, - and = or the mouse wheel
+func steer() {
+	const turn, tilt = 0.03, 0.02
+	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		camera.yaw -= turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyRight) {
+		camera.yaw += turn
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		camera.pitch = math.Min(camera.pitch+tilt, 1.5)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) {
+		camera.pitch = math.Max(camera.pitch-tilt, 0.1)
+	}
+	_, wheel := ebiten.Wheel()
+	if ebiten.IsKeyPressed(ebiten.KeyMinus) {
+		wheel--
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyEqual) {
+		wheel++
+	}
+	camera.distance = math.Min(math.Max(camera.distance*math.Pow(0.97, wheel), 0.2), 5)
+}
+
+// update is the ebiten function that moves the world forward (see Pace) and draws it
+func update(screen *ebiten.Image) error {
+	if atomic.LoadInt32(&stopping) == 1 {
+		return errStopped
+	}
+	steer()
+	Pace.HandleKeys()
+	for i := Pace.Steps(); i > 0; i-- {
+		world.Step()
+		updates++
+		if updates%colorRefresh == 0 {
+			colorHeightfield()
+		}
+		if Autosave != nil {
+			if err := Autosave.Tick(world); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+	if ebiten.IsDrawingSkipped() {
+		return nil
+	}
+	drawing := time.Now()
+	_ = screen.Fill(color.RGBA{R: 20, G: 24, B: 32, A: 255})
+	v := camera.place()
+	night := 1.
+	if world.IsNight() {
+		night = 0.6
+	}
+	drawTerrain(screen, v, night)
+	if camera.distance >= LODDistance {
+		drawDensity(screen, v, night)
+	} else {
+		drawFood(screen, v, night)
+		drawBeings(screen, v, night)
+	}
+	world.RecordTiming("Rendering", time.Since(drawing))
+	Pace.Draw(screen)
+	return nil
+}
+
+// face is a triangle on the screen
+type face struct {
+	vertices [3]ebiten.Vertex
+	depth    float64
+}
+
+// drawTerrain draws the triangles of the heightfield from the farthest to the closest (the closer ones cover the
+// ones behind them)
+func drawTerrain(screen *ebiten.Image, v view, night float64) {
+	type projected struct {
+		x, y  float32
+		depth float64
+		ok    bool
+	}
+	points := make([]projected, len(field.points))
+	for i, p := range field.points {
+		x, y, depth, ok := v.project(p)
+		points[i] = projected{x: x, y: y, depth: depth, ok: ok}
+	}
+	faces := make([]face, 0, len(field.triangles))
+	for i, t := range field.triangles {
+		a, b, c := points[t[0]], points[t[1]], points[t[2]]
+		if !a.ok || !b.ok || !c.ok {
+			continue
+		}
+		// Triangles facing away from the camera are hidden by the ones facing it
+		if (b.x-a.x)*(c.y-a.y)-(b.y-a.y)*(c.x-a.x) > 0 {
+			continue
+		}
+		f := face{depth: (a.depth + b.depth + c.depth) / 3}
+		for j, p := range []projected{a, b, c} {
+			f.vertices[j] = vertex(p.x, p.y, field.colors[t[j]], field.shades[i]*night)
+		}
+		faces = append(faces, f)
+	}
+	drawSorted(screen, faces)
+}
+
+// drawBeings draws every being as a square billboard in the color of its type, standing on the terrain (the closer
+// ones are larger)
+func drawBeings(screen *ebiten.Image, v view, night float64) {
+	faces := make([]face, 0, 2*len(world.BeingList))
+	for _, b := range world.BeingList {
+		c, known := typeColors[b.Type]
+		if !known {
+			c = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+		}
+		faces = billboard(faces, v, b.Position, 1, c, night)
+	}
+	drawSorted(screen, faces)
+}
+
+// drawFood draws every plant (and the other food) as a billboard half as large as the ones of the beings
+func drawFood(screen *ebiten.Image, v view, night float64) {
+	faces := make([]face, 0, 2*len(world.FoodList))
+	for _, f := range world.FoodList {
+		c, known := foodColors[f.Type]
+		if !known {
+			c = plantColor
+		}
+		faces = billboard(faces, v, f.Position, 0.5, c, night)
+	}
+	drawSorted(screen, faces)
+}
+
+// density is how many beings (of every type) or food stand in a cell
+type density struct {
+	count int
+	types map[string]int
+}
+
+// drawDensity draws a dot for every cell with food and one for every cell with beings, larger the more of them there
+// are, the beings in the color of the most common type
+func drawDensity(screen *ebiten.Image, v view, night float64) {
+	cells := func(locations func(add func(at GoWorld.Location, kind string))) map[GoWorld.Location]*density {
+		counted := make(map[GoWorld.Location]*density)
+		locations(func(at GoWorld.Location, kind string) {
+			cell := GoWorld.Location{X: at.X / lodCell, Y: at.Y / lodCell}
+			d, ok := counted[cell]
+			if !ok {
+				d = &density{types: make(map[string]int)}
+				counted[cell] = d
+			}
+			d.count++
+			d.types[kind]++
+		})
+		return counted
+	}
+	food := cells(func(add func(GoWorld.Location, string)) {
+		for _, f := range world.FoodList {
+			add(f.Position, f.Type)
+		}
+	})
+	beings := cells(func(add func(GoWorld.Location, string)) {
+		for _, b := range world.BeingList {
+			add(b.Position, b.Type)
+		}
+	})
+	faces := make([]face, 0, 2*len(food))
+	for cell, d := range food {
+		faces = billboard(faces, v, center(cell), 0.5*math.Sqrt(float64(d.count)), plantColor, night)
+	}
+	drawSorted(screen, faces)
+	faces = make([]face, 0, 2*len(beings))
+	for cell, d := range beings {
+		common, most := "", 0
+		for kind, count := range d.types {
+			// Ties go to the first type in alphabetical order, so the dot does not flicker between them
+			if count > most || count == most && kind < common {
+				common, most = kind, count
+			}
+		}
+		c, known := typeColors[common]
+		if !known {
+			c = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+		}
+		faces = billboard(faces, v, center(cell), math.Sqrt(float64(d.count)), c, night)
+	}
+	drawSorted(screen, faces)
+}
+
+// center returns the spot in the middle of the cell (or of the part inside the world, for the cells on the edges)
+func center(cell GoWorld.Location) GoWorld.Location {
+	x, y := cell.X*lodCell+lodCell/2, cell.Y*lodCell+lodCell/2
+	if x >= world.Width {
+		x = (cell.X*lodCell + world.Width - 1) / 2
+	}
+	if y >= world.Height {
+		y = (cell.Y*lodCell + world.Height - 1) / 2
+	}
+	return GoWorld.Location{X: x, Y: y}
+}
+
+// billboard appends the two triangles of a square standing on the terrain at the spot (scale 1 is the size of a
+// being), they are left out if the spot is behind the camera
+func billboard(faces []face, v view, at GoWorld.Location, scale float64, c color.RGBA, night float64) []face {
+	height, _ := world.GetHeightAt(at)
+	x, y, depth, ok := v.project(position(at.X, at.Y, height))
+	if !ok {
+		return faces
+	}
+	size := float32(math.Max(v.focal*0.004*scale/depth, 1.5))
+	topLeft := vertex(x-size, y-2*size, c, night)
+	topRight := vertex(x+size, y-2*size, c, night)
+	bottomLeft := vertex(x-size, y, c, night)
+	bottomRight := vertex(x+size, y, c, night)
+	return append(faces, face{vertices: [3]ebiten.Vertex{topLeft, bottomLeft, topRight}, depth: depth},
+		face{vertices: [3]ebiten.Vertex{topRight, bottomLeft, bottomRight}, depth: depth})
+}
+
+// drawSorted draws the faces from the farthest to the closest
+func drawSorted(screen *ebiten.Image, faces []face) {
+	sort.Slice(faces, func(i, j int) bool {
+		return faces[i].depth > faces[j].depth
+	})
+	drawFaces(screen, faces)
+}
+
+// vertex returns a point on the screen in the color (dimmed by the shade)
+func vertex(x, y float32, c color.RGBA, shade float64) ebiten.Vertex {
+	return ebiten.Vertex{
+		DstX:   x,
+		DstY:   y,
+		SrcX:   1,
+		SrcY:   1,
+		ColorR: float32(float64(c.R) / 255 * shade),
+		ColorG: float32(float64(c.G) / 255 * shade),
+		ColorB: float32(float64(c.B) / 255 * shade),
+		ColorA: 1,
+	}
+}
+
+// drawFaces draws the triangles in their order, in batches as large as the 16 bit indices of ebiten allow
+func drawFaces(screen *ebiten.Image, faces []face) {
+	const batch = math.MaxUint16 / 3
+	vertices := make([]ebiten.Vertex, 0, 3*batch)
+	indices := make([]uint16, 0, 3*batch)
+	for start := 0; start < len(faces); start += batch {
+		vertices, indices = vertices[:0], indices[:0]
+		for _, f := range faces[start:int(math.Min(float64(start+batch), float64(len(faces))))] {
+			for _, v := range f.vertices {
+				indices = append(indices, uint16(len(vertices)))
+				vertices = append(vertices, v)
+			}
+		}
+		screen.DrawTriangles(vertices, indices, white, nil)
+	}
+}
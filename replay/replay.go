@@ -0,0 +1,136 @@
+// Package replay records the event log of a run of a seeded world: the snapshot it started from, the hashes of the
+// world after every tick and the events of the tick. Verify re-simulates the run from the snapshot and reports the
+// first tick whose hashes differ from the log, e.g. to trust replays or to hunt down nondeterminism
+package replay
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/terrain"
+	"os"
+)
+
+// Log is the event log of a run
+type Log struct {
+	Scenario terrain.Scenario // What the world was created from (its seed included)
+	// Plants only produce seeds with another plant of their type nearby (see terrain.WithPollination)
+	RequirePollination bool
+	Every              uint64           // How many epochs pass between two hashed ticks
+	Start              GoWorld.Snapshot // The state the run started from (the brains of the beings are left out)
+	Ticks              []Tick
+}
+
+// Tick is the state of the world after a tick
+type Tick struct {
+	Epoch  uint64
+	Parts  map[string]string // The hashes of the parts of the world (see terrain.RandomWorld.HashParts)
+	Events []Event           // What happened to the world from outside the regular updates since the previous tick
+}
+
+// Event is a change made to the world from outside the regular updates (see terrain.Listener)
+type Event struct {
+	Action string
+	IDs    []uuid.UUID
+}
+
+// Recorder writes the event log of a world while it runs
+type Recorder struct {
+	Log    *Log
+	events []Event // The events since the latest hashed tick
+}
+
+// Record starts the event log of the world created from the scenario. It has to start before the world steps (right
+// after it was created or restored from a save), the world is restored from its own snapshot and its random numbers
+// are seeded again, so the replay starts from exactly the same state
+// Returns an error if the world is not seeded
+func Record(world *terrain.RandomWorld, scenario terrain.Scenario, every uint64) (*Recorder, error) {
+	if every == 0 {
+		every = 1
+	}
+	start := world.Snapshot()
+	if err := world.Restore(start); err != nil {
+		return nil, fmt.Errorf("error recording replay: %v", err)
+	}
+	if err := world.PrepareReplay(); err != nil {
+		return nil, fmt.Errorf("error recording replay: %v", err)
+	}
+	r := &Recorder{Log: &Log{Scenario: scenario, RequirePollination: world.Settings.RequirePollination, Every: every,
+		Start: withoutBrains(start)}}
+	world.Subscribe(r.listen(world, func(tick Tick) {
+		r.Log.Ticks = append(r.Log.Ticks, tick)
+	}))
+	return r, nil
+}
+
+// listen returns a listener that collects the events and hands over every hashed tick
+func (r *Recorder) listen(world *terrain.RandomWorld, ticked func(tick Tick)) terrain.Listener {
+	return func(action string, ids []uuid.UUID) {
+		if action != "ticked" {
+			r.events = append(r.events, Event{Action: action, IDs: append([]uuid.UUID(nil), ids...)})
+			return
+		}
+		if world.Epoch%r.Log.Every != 0 {
+			return
+		}
+		ticked(Tick{Epoch: world.Epoch, Parts: world.HashParts(), Events: r.events})
+		r.events = nil
+	}
+}
+
+// withoutBrains returns the snapshot with the brains of its beings left out (they can not be stored)
+func withoutBrains(s *GoWorld.Snapshot) GoWorld.Snapshot {
+	stored := *s
+	stored.Beings = make(map[string]GoWorld.Being, len(s.Beings))
+	for id, b := range s.Beings {
+		b.Brain = nil
+		stored.Beings[id] = b
+	}
+	return stored
+}
+
+// WriteFile writes the gzip-compressed event log to the path. The log is written to a temporary file first, so a
+// crash never leaves a broken log behind
+func (r *Recorder) WriteFile(path string) error {
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return fmt.Errorf("error writing replay: %v", err)
+	}
+	zipped := gzip.NewWriter(file)
+	err = gob.NewEncoder(zipped).Encode(r.Log)
+	if closeErr := zipped.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return fmt.Errorf("error writing replay: %v", err)
+	}
+	return nil
+}
+
+// LoadFile reads the event log written by WriteFile
+func LoadFile(path string) (*Log, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading replay: %v", err)
+	}
+	defer file.Close()
+	zipped, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("error loading replay %v: %v", path, err)
+	}
+	defer zipped.Close()
+	log := &Log{}
+	if err := gob.NewDecoder(zipped).Decode(log); err != nil {
+		return nil, fmt.Errorf("error loading replay %v: %v", path, err)
+	}
+	return log, nil
+}
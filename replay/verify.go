@@ -0,0 +1,94 @@
+package replay
+
+import (
+	"fmt"
+	"github.com/rubinda/GoWorld/terrain"
+	"sort"
+)
+
+// Divergence is the first tick of a replay that did not end up as the log says
+type Divergence struct {
+	Epoch    uint64
+	Parts    []string // The parts of the world that differ (e.g. Beings, see terrain.RandomWorld.HashParts)
+	Recorded []Event  // The events of the tick in the log
+	Replayed []Event  // The events of the tick in the replay
+}
+
+// Verify re-simulates the run of the log from its start (stepping the world with terrain.RandomWorld.Step) and
+// compares the hashes after every logged tick, progress is told about every tick compared (nothing is told if nil)
+// Returns the first tick that differs (nil if the replay matches the whole log) or an error if the world of the log
+// can not be created
+func Verify(log *Log, progress func(epoch uint64)) (*Divergence, error) {
+	scenario := log.Scenario
+	// The replay stores no terrain image
+	noImage := ""
+	scenario.TerrainImage = &noImage
+	world, err := terrain.NewWorldFromScenario(&scenario, terrain.WithPollination(log.RequirePollination))
+	if err != nil {
+		return nil, fmt.Errorf("error verifying replay: %v", err)
+	}
+	start := log.Start
+	if err := world.Restore(&start); err != nil {
+		return nil, fmt.Errorf("error verifying replay: %v", err)
+	}
+	if err := world.PrepareReplay(); err != nil {
+		return nil, fmt.Errorf("error verifying replay: %v", err)
+	}
+	r := &Recorder{Log: &Log{Every: log.Every}}
+	var replayed *Tick
+	world.Subscribe(r.listen(world, func(tick Tick) {
+		replayed = &tick
+	}))
+	for _, recorded := range log.Ticks {
+		replayed = nil
+		for replayed == nil {
+			if world.Epoch >= recorded.Epoch {
+				return nil, fmt.Errorf("error verifying replay: the log has no tick at epoch %d", recorded.Epoch)
+			}
+			world.Step()
+		}
+		if parts := differentParts(recorded.Parts, replayed.Parts); len(parts) > 0 {
+			return &Divergence{Epoch: recorded.Epoch, Parts: parts, Recorded: recorded.Events,
+				Replayed: replayed.Events}, nil
+		}
+		if progress != nil {
+			progress(recorded.Epoch)
+		}
+	}
+	return nil, nil
+}
+
+// differentParts returns the names of the parts whose hashes differ (in order)
+func differentParts(recorded, replayed map[string]string) []string {
+	var parts []string
+	for name, hash := range recorded {
+		if replayed[name] != hash {
+			parts = append(parts, name)
+		}
+	}
+	for name := range replayed {
+		if _, known := recorded[name]; !known {
+			parts = append(parts, name)
+		}
+	}
+	sort.Strings(parts)
+	return parts
+}
+
+// String describes the divergence, e.g. for the terminal
+func (d *Divergence) String() string {
+	text := fmt.Sprintf("the replay diverged at epoch %d in %v", d.Epoch, d.Parts)
+	for _, events := range []struct {
+		name   string
+		events []Event
+	}{{"recorded", d.Recorded}, {"replayed", d.Replayed}} {
+		text += fmt.Sprintf("\n%v events:", events.name)
+		if len(events.events) == 0 {
+			text += " none"
+		}
+		for _, e := range events.events {
+			text += fmt.Sprintf("\n  %v %v", e.Action, e.IDs)
+		}
+	}
+	return text
+}
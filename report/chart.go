@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+var (
+	// The size of a chart in pixels
+	chartWidth, chartHeight = 640, 240
+	// The colors of the lines (one for every being type, in the order of their names)
+	palette = []color.RGBA{
+		{R: 214, G: 39, B: 40, A: 255},
+		{R: 31, G: 119, B: 180, A: 255},
+		{R: 44, G: 160, B: 44, A: 255},
+		{R: 255, G: 127, B: 14, A: 255},
+		{R: 148, G: 103, B: 189, A: 255},
+		{R: 140, G: 86, B: 75, A: 255},
+		{R: 227, G: 119, B: 194, A: 255},
+		{R: 127, G: 127, B: 127, A: 255},
+	}
+	gridGray = color.RGBA{R: 225, G: 225, B: 225, A: 255}
+)
+
+// lineChart draws every series (values at the sample epochs, NaN where there is none) as a line in its color
+// Returns the PNG of the chart and the range of values its height spans
+func lineChart(series map[string][]float64, colors map[string]color.RGBA) ([]byte, float64, float64, error) {
+	min, max := math.Inf(1), math.Inf(-1)
+	samples := 0
+	for _, values := range series {
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+			}
+		}
+		if len(values) > samples {
+			samples = len(values)
+		}
+	}
+	if math.IsInf(min, 1) {
+		// There are no values to draw
+		min, max = 0, 0
+	}
+	if min > 0 && min < max/2 {
+		// Counts and most attributes read better from zero
+		min = 0
+	}
+	if max == min {
+		max = min + 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for i := 0; i <= 4; i++ {
+		y := (chartHeight - 1) * i / 4
+		for x := 0; x < chartWidth; x++ {
+			img.SetRGBA(x, y, gridGray)
+		}
+	}
+	// The point of the i-th sample with the value
+	point := func(i int, v float64) (float64, float64) {
+		x := 0.
+		if samples > 1 {
+			x = float64(i) / float64(samples-1) * float64(chartWidth-1)
+		}
+		return x, (1 - (v-min)/(max-min)) * float64(chartHeight-1)
+	}
+	for name, values := range series {
+		for i := 1; i < len(values); i++ {
+			if math.IsNaN(values[i-1]) || math.IsNaN(values[i]) {
+				continue
+			}
+			x0, y0 := point(i-1, values[i-1])
+			x1, y1 := point(i, values[i])
+			drawLine(img, x0, y0, x1, y1, colors[name])
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), min, max, nil
+}
+
+// drawLine draws a line two pixels thick between the points
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		x := int(math.Round(x0 + t*(x1-x0)))
+		y := int(math.Round(y0 + t*(y1-y0)))
+		img.SetRGBA(x, y, c)
+		img.SetRGBA(x, y+1, c)
+	}
+}
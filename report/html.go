@@ -0,0 +1,199 @@
+package report
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/rubinda/GoWorld"
+	"html/template"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+)
+
+// page is the summary of a run (the charts are embedded, so the page is a single file)
+var page = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GoWorld run summary</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #333; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ddd; padding: 0.3em 0.8em; text-align: right; }
+th { background: #f4f4f4; }
+.legend span { margin-right: 1em; font-weight: bold; }
+.range { color: #888; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>GoWorld run summary</h1>
+<p>The run lasted {{.Epochs}} epochs, {{.Living}} beings live at the end.</p>
+<p class="legend">{{range .Types}}<span style="color: {{.Color}}">{{.Name}}</span>{{end}}</p>
+
+<h2>Population</h2>
+<table>
+<tr><th>Type</th><th>At the start</th><th>Peak</th><th>At the end</th></tr>
+{{range .Types}}<tr><td>{{.Name}}</td><td>{{.Start}}</td><td>{{.Peak}}</td><td>{{.End}}</td></tr>
+{{end}}</table>
+{{template "chart" .Population}}
+
+<h2>Extinctions</h2>
+{{if .Extinctions}}<table>
+<tr><th>Type</th><th>Epoch</th></tr>
+{{range .Extinctions}}<tr><td>{{.Type}}</td><td>{{.Epoch}}</td></tr>
+{{end}}</table>{{else}}<p>No type died out.</p>{{end}}
+
+<h2>Species</h2>
+<p>The living members of every species and type at the end, the members that died and the beings they killed during
+the run.</p>
+<table>
+<tr><th>Species</th><th>Type</th><th>Living</th><th>Died</th><th>Mean lifespan</th><th>Kills</th>{{range .SpeciesColumns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Species}}<tr><td>{{.Species}}</td><td>{{.Type}}</td><td>{{.Population}}</td><td>{{.Deaths}}</td><td>{{printf "%.1f" .Lifespan}}</td><td>{{.Kills}}</td>{{range .Means}}<td>{{printf "%.2f" .}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Attribute drift</h2>
+<p>The mean attributes of every type over the run.</p>
+{{range .Drift}}<h3>{{.Title}}</h3>
+{{template "chart" .}}{{end}}
+
+<h2>Largest families</h2>
+<table>
+<tr><th>Founder</th><th>Type</th><th>Living members</th><th>Latest generation</th></tr>
+{{range .Lineages}}<tr><td>{{.Founder}}</td><td>{{.Type}}</td><td>{{.Living}}</td><td>{{.Generation}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+{{define "chart"}}<img src="{{.Image}}" alt="{{.Title}}">
+<p class="range">{{printf "%.1f" .Min}} to {{printf "%.1f" .Max}} over epochs {{.From}} to {{.To}}</p>
+{{end}}`))
+
+// chart is a chart on the page
+type chart struct {
+	Title    string
+	Image    template.URL // The PNG of the chart as a data URL
+	Min, Max float64      // The range of values the chart spans
+	From, To uint64       // The epochs the chart spans
+}
+
+// typeSummary is a being type on the page
+type typeSummary struct {
+	Name             string
+	Color            template.CSS
+	Start, Peak, End int
+}
+
+// speciesRow is a species on the page (the means in the order of the species columns)
+type speciesRow struct {
+	GoWorld.SpeciesSummary
+	Means []float64
+}
+
+// Write writes the summary of the run so far as an HTML page
+func (r *Recorder) Write(w io.Writer) error {
+	if r.Samples[len(r.Samples)-1].Epoch != r.world.Epoch {
+		// The run ended between two samples
+		r.sample()
+	}
+	first, last := r.Samples[0], r.Samples[len(r.Samples)-1]
+	data := struct {
+		Epochs      uint64
+		Living      int
+		Types       []typeSummary
+		Population  chart
+		Extinctions []Extinction
+		Drift       []chart
+		Lineages    []Lineage
+		// The mean attributes listed for every species (the attributes followed over the run)
+		SpeciesColumns []string
+		Species        []speciesRow
+	}{Epochs: last.Epoch, Living: len(r.world.BeingList), Extinctions: r.Extinctions, Lineages: r.Lineages()}
+
+	// Every type seen during the run gets its color
+	seen := make(map[string]bool)
+	for _, s := range r.Samples {
+		for beingType := range s.Population {
+			seen[beingType] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for beingType := range seen {
+		names = append(names, beingType)
+	}
+	sort.Strings(names)
+	colors := make(map[string]color.RGBA, len(names))
+	for i, name := range names {
+		c := palette[i%len(palette)]
+		colors[name] = c
+		t := typeSummary{Name: name, Color: template.CSS(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)),
+			Start: first.Population[name], End: last.Population[name]}
+		for _, s := range r.Samples {
+			if s.Population[name] > t.Peak {
+				t.Peak = s.Population[name]
+			}
+		}
+		data.Types = append(data.Types, t)
+	}
+
+	population := make(map[string][]float64, len(names))
+	for _, name := range names {
+		for _, s := range r.Samples {
+			population[name] = append(population[name], float64(s.Population[name]))
+		}
+	}
+	var err error
+	if data.Population, err = r.chart("Population", population, colors); err != nil {
+		return err
+	}
+	attributeNames := make([]string, 0, len(attributes))
+	for name := range attributes {
+		attributeNames = append(attributeNames, name)
+	}
+	sort.Strings(attributeNames)
+	data.SpeciesColumns = attributeNames
+	for _, s := range r.world.SpeciesSummary() {
+		row := speciesRow{SpeciesSummary: s}
+		for _, attribute := range attributeNames {
+			row.Means = append(row.Means, s.Means[attribute])
+		}
+		data.Species = append(data.Species, row)
+	}
+	for _, attribute := range attributeNames {
+		means := make(map[string][]float64, len(names))
+		for _, name := range names {
+			for _, s := range r.Samples {
+				mean, ok := s.Attributes[name][attribute]
+				if !ok {
+					// The type had no living beings then (the line has a gap)
+					mean = math.NaN()
+				}
+				means[name] = append(means[name], mean)
+			}
+		}
+		c, err := r.chart(attribute, means, colors)
+		if err != nil {
+			return err
+		}
+		data.Drift = append(data.Drift, c)
+	}
+	if err := page.Execute(w, data); err != nil {
+		return fmt.Errorf("error writing report: %v", err)
+	}
+	return nil
+}
+
+// chart draws the series into a chart of the page
+func (r *Recorder) chart(title string, series map[string][]float64, colors map[string]color.RGBA) (chart, error) {
+	img, min, max, err := lineChart(series, colors)
+	if err != nil {
+		return chart{}, fmt.Errorf("error drawing %v chart: %v", title, err)
+	}
+	return chart{
+		Title: title,
+		Image: template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(img)),
+		Min:   min,
+		Max:   max,
+		From:  r.Samples[0].Epoch,
+		To:    r.Samples[len(r.Samples)-1].Epoch,
+	}, nil
+}
@@ -0,0 +1,163 @@
+// Package report records how a run of a world goes and writes a summary page of it at the end: population curves,
+// extinctions, the species, the drift of being attributes and the largest families
+package report
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/terrain"
+	"os"
+	"sort"
+)
+
+var (
+	// The attributes whose means are followed over the run (the drift of the population)
+	attributes = map[string]func(b *GoWorld.Being) float64{
+		"Speed":        func(b *GoWorld.Being) float64 { return b.Speed },
+		"VisionRange":  func(b *GoWorld.Being) float64 { return b.VisionRange },
+		"Size":         func(b *GoWorld.Being) float64 { return b.Size },
+		"Durability":   func(b *GoWorld.Being) float64 { return b.Durability },
+		"Fertility":    func(b *GoWorld.Being) float64 { return b.Fertility },
+		"Camouflage":   func(b *GoWorld.Being) float64 { return b.Camouflage },
+		"Resistance":   func(b *GoWorld.Being) float64 { return b.Resistance },
+		"MutationRate": func(b *GoWorld.Being) float64 { return b.MutationRate },
+	}
+	// How many families are listed in the report
+	topLineages = 10
+)
+
+// Sample is the state of the population at an epoch
+type Sample struct {
+	Epoch      uint64
+	Population map[string]int                // The number of beings of each type
+	Plants     int                           // The number of plants (land and water, without the other food)
+	Attributes map[string]map[string]float64 // The mean attributes of each type (Type: attribute: mean)
+}
+
+// Extinction is the epoch a being type died out at
+type Extinction struct {
+	Type  string
+	Epoch uint64
+}
+
+// Lineage is a family of beings descending from the same founder
+type Lineage struct {
+	Founder    uuid.UUID
+	Type       string
+	Living     int // How many members of the family live at the end
+	Generation int // The latest generation of the family
+}
+
+// Recorder follows a world while it runs (samples are taken every few epochs, extinctions are noticed right away)
+type Recorder struct {
+	Every       uint64 // How many epochs pass between two samples
+	Keep        int    // How many of the latest samples are kept (0 keeps all of them), e.g. for a live graph
+	Samples     []Sample
+	Extinctions []Extinction
+	world       *terrain.RandomWorld
+	living      map[string]int // The number of beings of each type at the last epoch
+}
+
+// New starts recording the world, a sample is taken right away and then every few epochs
+func New(world *terrain.RandomWorld, every uint64) *Recorder {
+	if every == 0 {
+		every = 1
+	}
+	r := &Recorder{Every: every, world: world, living: world.Census()}
+	r.sample()
+	world.Subscribe(func(action string, _ []uuid.UUID) {
+		if action == "ticked" {
+			r.tick()
+		}
+	})
+	return r
+}
+
+// tick notices the types that died out in the epoch and samples the world when it is time
+func (r *Recorder) tick() {
+	living := r.world.Census()
+	for beingType := range r.living {
+		if living[beingType] == 0 {
+			r.Extinctions = append(r.Extinctions, Extinction{Type: beingType, Epoch: r.world.Epoch})
+		}
+	}
+	r.living = living
+	if r.world.Epoch%r.Every == 0 {
+		r.sample()
+	}
+}
+
+// sample stores the population and the mean attributes of every type
+func (r *Recorder) sample() {
+	s := Sample{
+		Epoch:      r.world.Epoch,
+		Population: r.world.Census(),
+		Attributes: make(map[string]map[string]float64),
+	}
+	r.world.ForEachFood(func(f *GoWorld.Food) bool {
+		return f.Type == "Land" || f.Type == "Water"
+	}, func(*GoWorld.Food) {
+		s.Plants++
+	})
+	r.world.ForEachBeing(nil, func(b *GoWorld.Being) {
+		if s.Attributes[b.Type] == nil {
+			s.Attributes[b.Type] = make(map[string]float64)
+		}
+		for name, value := range attributes {
+			s.Attributes[b.Type][name] += value(b) / float64(s.Population[b.Type])
+		}
+	})
+	r.Samples = append(r.Samples, s)
+	if r.Keep > 0 && len(r.Samples) > r.Keep {
+		// Copied, so the dropped samples do not stay in memory
+		r.Samples = append([]Sample(nil), r.Samples[len(r.Samples)-r.Keep:]...)
+	}
+}
+
+// Lineages returns the largest families living in the world (the ones with the most living members first)
+func (r *Recorder) Lineages() []Lineage {
+	families := make(map[uuid.UUID]*Lineage)
+	for _, b := range r.world.BeingList {
+		founder := b.Lineage
+		if founder == uuid.Nil {
+			founder = b.ID
+		}
+		family := families[founder]
+		if family == nil {
+			family = &Lineage{Founder: founder, Type: b.Type}
+			families[founder] = family
+		}
+		family.Living++
+		if b.Generation > family.Generation {
+			family.Generation = b.Generation
+		}
+	}
+	lineages := make([]Lineage, 0, len(families))
+	for _, family := range families {
+		lineages = append(lineages, *family)
+	}
+	sort.Slice(lineages, func(i, j int) bool {
+		if lineages[i].Living != lineages[j].Living {
+			return lineages[i].Living > lineages[j].Living
+		}
+		return lineages[i].Generation > lineages[j].Generation
+	})
+	if len(lineages) > topLineages {
+		lineages = lineages[:topLineages]
+	}
+	return lineages
+}
+
+// WriteFile writes the summary page of the run so far into the file (see Write)
+func (r *Recorder) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error writing report: %v", err)
+	}
+	err = r.Write(file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing report: %v", closeErr)
+	}
+	return err
+}
@@ -0,0 +1,247 @@
+// Package script runs being behaviors written in Lua, so the decision making can be changed without recompiling
+//
+// A script has to define a global function decide(being, perception) that returns the action name and the x, y
+// coordinates where to do it. Returning nil leaves the decision to the fallback brain (to the world without one).
+// Example:
+//
+//	function decide(being, perception)
+//		if being.thirst > 100 then
+//			local x, y = perception.nearest("Water")
+//			if x then return "drink", x, y end
+//		end
+//		return nil
+//	end
+//
+// Scripts run in a sandbox: only the base, table, string and math libraries are available (without loading other
+// files), loading a script and every decision have to finish within the time limits and stay within the size limits
+package script
+
+import (
+	"context"
+	"fmt"
+	"github.com/rubinda/GoWorld"
+	"github.com/yuin/gopher-lua"
+	"strings"
+	"time"
+)
+
+var (
+	// LoadTimeout is how long running the top level of a script can take when it is loaded
+	LoadTimeout = time.Second
+	// DecisionTimeout is how long a script can take to decide for a single being
+	DecisionTimeout = 10 * time.Millisecond
+	// MaxCallDepth is how deep the Lua functions of a script can call each other
+	MaxCallDepth = 200
+	// MaxRegistrySize is how many values the stack of a script can hold (e.g. locals, arguments and unpacked tables)
+	MaxRegistrySize = 64 * 1024
+	// MaxStringLength is the longest string string.rep can make
+	MaxStringLength = 1 << 20
+)
+
+// LuaBrain is a being brain that runs a Lua script
+type LuaBrain struct {
+	FileName string        // The script file
+	Fallback GoWorld.Brain // Decides when the script returns nil or fails (nil leaves it to the world)
+	Err      error         // The last error the script produced (nil if it ran fine)
+	state    *lua.LState
+}
+
+// NewLuaBrain loads the script from the file and returns a brain that uses it
+func NewLuaBrain(fileName string, fallback GoWorld.Brain) (*LuaBrain, error) {
+	brain := &LuaBrain{FileName: fileName, Fallback: fallback}
+	if err := brain.Reload(); err != nil {
+		return nil, err
+	}
+	return brain, nil
+}
+
+// Reload loads the script file again (e.g. after it was edited), the previous script stays in use if it fails
+func (l *LuaBrain) Reload() error {
+	state := newSandbox()
+	ctx, cancel := context.WithTimeout(context.Background(), LoadTimeout)
+	defer cancel()
+	state.SetContext(ctx)
+	err := state.DoFile(l.FileName)
+	state.RemoveContext()
+	if err != nil {
+		state.Close()
+		return fmt.Errorf("error loading behavior script %v: %v", l.FileName, err)
+	}
+	if state.GetGlobal("decide").Type() != lua.LTFunction {
+		state.Close()
+		return fmt.Errorf("error loading behavior script %v: no decide(being, perception) function", l.FileName)
+	}
+	if l.state != nil {
+		l.state.Close()
+	}
+	l.state = state
+	return nil
+}
+
+// Close releases the interpreter
+func (l *LuaBrain) Close() {
+	if l.state != nil {
+		l.state.Close()
+		l.state = nil
+	}
+}
+
+// Decide runs the decide function of the script for the being
+func (l *LuaBrain) Decide(b *GoWorld.Being, p GoWorld.Perception) GoWorld.Action {
+	if l.state == nil {
+		return l.fallback(b, p)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DecisionTimeout)
+	defer cancel()
+	l.state.SetContext(ctx)
+	defer l.state.RemoveContext()
+
+	top := l.state.GetTop()
+	defer l.state.SetTop(top)
+	l.Err = l.state.CallByParam(lua.P{Fn: l.state.GetGlobal("decide"), NRet: 3, Protect: true},
+		beingTable(l.state, b), perceptionTable(l.state, b, p))
+	if l.Err != nil {
+		return l.fallback(b, p)
+	}
+	name, ok := l.state.Get(-3).(lua.LString)
+	if !ok {
+		return l.fallback(b, p)
+	}
+	x, xOk := l.state.Get(-2).(lua.LNumber)
+	y, yOk := l.state.Get(-1).(lua.LNumber)
+	if !xOk || !yOk {
+		// Actions without a location are done in place
+		return GoWorld.Action{Name: string(name), Location: b.Position}
+	}
+	return GoWorld.Action{Name: string(name), Location: GoWorld.Location{X: int(x), Y: int(y)}}
+}
+
+// fallback lets the fallback brain decide. Without one it returns no action, which the world does not do and decides
+// with its built-in brain instead (see terrain.RandomWorld.SenseActionFor)
+func (l *LuaBrain) fallback(b *GoWorld.Being, p GoWorld.Perception) GoWorld.Action {
+	if l.Fallback == nil {
+		return GoWorld.Action{}
+	}
+	return l.Fallback.Decide(b, p)
+}
+
+// newSandbox creates an interpreter with only the harmless libraries opened and the size of its stacks and strings
+// limited, a script going over them fails like any other error
+func newSandbox() *lua.LState {
+	// The stack grows in large steps, growing it value by value up to the limit would take longer than a decision
+	state := lua.NewState(lua.Options{SkipOpenLibs: true, CallStackSize: MaxCallDepth, RegistrySize: 1024,
+		RegistryGrowStep: 1024, RegistryMaxSize: MaxRegistrySize})
+	for name, open := range map[string]lua.LGFunction{
+		lua.BaseLibName:   lua.OpenBase,
+		lua.TabLibName:    lua.OpenTable,
+		lua.StringLibName: lua.OpenString,
+		lua.MathLibName:   lua.OpenMath,
+	} {
+		state.Push(state.NewFunction(open))
+		state.Push(lua.LString(name))
+		state.Call(1, 0)
+	}
+	// Scripts can not load other code
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		state.SetGlobal(name, lua.LNil)
+	}
+	if library, ok := state.GetGlobal(lua.StringLibName).(*lua.LTable); ok {
+		library.RawSetString("rep", state.NewFunction(stringRep))
+	}
+	return state
+}
+
+// stringRep is string.rep(s, n) refusing to make strings longer than MaxStringLength
+func stringRep(state *lua.LState) int {
+	s := state.CheckString(1)
+	n := state.CheckInt(2)
+	if n <= 0 {
+		state.Push(lua.LString(""))
+		return 1
+	}
+	// Checked before repeating, the whole string would be allocated at once
+	if len(s) > 0 && n > MaxStringLength/len(s) {
+		state.RaiseError("string.rep would make a string longer than %d bytes", MaxStringLength)
+	}
+	state.Push(lua.LString(strings.Repeat(s, n)))
+	return 1
+}
+
+// beingTable converts the being attributes into a Lua table
+func beingTable(state *lua.LState, b *GoWorld.Being) *lua.LTable {
+	t := state.NewTable()
+	t.RawSetString("id", lua.LString(b.ID.String()))
+	t.RawSetString("type", lua.LString(b.Type))
+	t.RawSetString("gender", lua.LString(b.Gender))
+	t.RawSetString("x", lua.LNumber(b.Position.X))
+	t.RawSetString("y", lua.LNumber(b.Position.Y))
+	t.RawSetString("hunger", lua.LNumber(b.Hunger))
+	t.RawSetString("thirst", lua.LNumber(b.Thirst))
+	t.RawSetString("wants_child", lua.LNumber(b.WantsChild))
+	t.RawSetString("sleepiness", lua.LNumber(b.Sleepiness))
+	t.RawSetString("energy", lua.LNumber(b.Energy))
+	t.RawSetString("carrying", lua.LNumber(b.Carrying))
+	t.RawSetString("injury", lua.LNumber(b.Injury))
+	t.RawSetString("stress", lua.LNumber(b.Stress))
+	t.RawSetString("age", lua.LNumber(b.Age))
+	t.RawSetString("maturity_age", lua.LNumber(b.MaturityAge))
+	t.RawSetString("size", lua.LNumber(b.Size))
+	t.RawSetString("speed", lua.LNumber(b.Speed))
+	t.RawSetString("vision", lua.LNumber(b.VisionRange))
+	return t
+}
+
+// perceptionTable converts what the being perceives into a Lua table with helper functions to query its surroundings
+func perceptionTable(state *lua.LState, b *GoWorld.Being, p GoWorld.Perception) *lua.LTable {
+	t := state.NewTable()
+	t.RawSetString("sight", lua.LNumber(p.Sight))
+	t.RawSetString("night", lua.LBool(p.World.IsNight()))
+	t.RawSetString("season", lua.LString(p.World.Season()))
+	// surface(x, y) returns the surface name at the spot
+	t.RawSetString("surface", state.NewFunction(func(state *lua.LState) int {
+		name, err := p.World.GetSurfaceNameAt(GoWorld.Location{X: state.CheckInt(1), Y: state.CheckInt(2)})
+		if err != nil {
+			state.Push(lua.LNil)
+			return 1
+		}
+		state.Push(lua.LString(name))
+		return 1
+	}))
+	// nearest(surface) returns the x, y of the closest visible spot with the surface (nil if none in sight)
+	t.RawSetString("nearest", state.NewFunction(func(state *lua.LState) int {
+		surface := state.CheckString(1)
+		found := false
+		closest, closestDist := GoWorld.Location{}, 0.
+		for _, spot := range p.Surroundings {
+			if name, _ := p.World.GetSurfaceNameAt(spot); name != surface {
+				continue
+			}
+			if dist := p.World.Distance(b.Position, spot); !found || dist < closestDist {
+				closest, closestDist, found = spot, dist, true
+			}
+		}
+		if !found {
+			state.Push(lua.LNil)
+			return 1
+		}
+		state.Push(lua.LNumber(closest.X))
+		state.Push(lua.LNumber(closest.Y))
+		return 2
+	}))
+	// beings() returns a list of the other beings in sight
+	t.RawSetString("beings", state.NewFunction(func(state *lua.LState) int {
+		list := state.NewTable()
+		for _, spot := range p.Surroundings {
+			id, err := p.World.GetBeingAt(spot)
+			if err != nil || id == b.ID {
+				continue
+			}
+			if other := p.World.GetBeingWithID(id); other != nil {
+				list.Append(beingTable(state, other))
+			}
+		}
+		state.Push(list)
+		return 1
+	}))
+	return t
+}
@@ -0,0 +1,85 @@
+package script
+
+import (
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/worldtest"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// fallbackBrain always rests, so its decisions can be told apart from the ones of the scripts
+type fallbackBrain struct{}
+
+func (fallbackBrain) Decide(b *GoWorld.Being, _ GoWorld.Perception) GoWorld.Action {
+	return GoWorld.Action{Name: "rest", Location: b.Position}
+}
+
+// scriptFile stores the Lua source in a temporary file and returns its name
+func scriptFile(t *testing.T, source string) string {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), "brain.lua")
+	if err := ioutil.WriteFile(fileName, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+// decide loads the decide function body as a script and lets it decide for a being in a small fake world
+func decide(t *testing.T, body string) (GoWorld.Action, error) {
+	t.Helper()
+	brain, err := NewLuaBrain(scriptFile(t, "function decide(being, perception)\n"+body+"\nend"), fallbackBrain{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer brain.Close()
+	b := &GoWorld.Being{Type: "Carnivore", Position: GoWorld.Location{X: 2, Y: 2}}
+	action := brain.Decide(b, GoWorld.Perception{World: worldtest.New(5, 5)})
+	return action, brain.Err
+}
+
+// TestDecide checks that the action the script returns is the decision
+func TestDecide(t *testing.T) {
+	action, err := decide(t, `return "drink", 1, 3`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (GoWorld.Action{Name: "drink", Location: GoWorld.Location{X: 1, Y: 3}}); action != want {
+		t.Errorf("decided %v, want %v", action, want)
+	}
+}
+
+// TestDecideLimits checks that scripts running too long or taking too much memory fail and the fallback decides
+func TestDecideLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"endless loop", `while true do end`},
+		{"growing table", `local t = {} while true do t[#t + 1] = {} end`},
+		{"deep recursion", `local function f(n) return f(n + 1) + 1 end return f(0)`},
+		{"long string", `local s = string.rep("x", 1e9) return "drink", 1, 3`},
+		{"long string method", `local s = ("xy"):rep(1e9) return "drink", 1, 3`},
+		{"full stack", `return string.byte(string.rep("x", 100000), 1, -1)`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			action, err := decide(t, test.body)
+			if err == nil {
+				t.Error("the script did not fail")
+			}
+			if action.Name != "rest" {
+				t.Errorf("decided %v, want the fallback to rest", action)
+			}
+		})
+	}
+}
+
+// TestLoadTimeout checks that a script running too long when it is loaded is refused
+func TestLoadTimeout(t *testing.T) {
+	fileName := scriptFile(t, "while true do end\nfunction decide(being, perception) return nil end")
+	if brain, err := NewLuaBrain(fileName, fallbackBrain{}); err == nil {
+		brain.Close()
+		t.Fatal("the script was loaded")
+	}
+}
@@ -0,0 +1,40 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+)
+
+var (
+	// How efficient amphibians are outside their primary medium (speed and nutrition of the food found there)
+	amphibianEfficiency = 0.5
+)
+
+// crossesWater checks if beings of the type can move across water (and other surfaces that can not be walked on)
+func crossesWater(beingType string) bool {
+	return beingType == "Water" || beingType == "Amphibian" || flies(beingType)
+}
+
+// inPrimaryMedium checks if the location is in the medium the amphibian is at home in (water or land, depending on
+// its habitat). Other beings are always in their primary medium
+func (w *RandomWorld) inPrimaryMedium(b *GoWorld.Being, location GoWorld.Location) bool {
+	if b.Type != "Amphibian" || w.IsOutOfBounds(location) {
+		return true
+	}
+	inWater := w.TerrainSpots[location.X][location.Y].Surface.CommonName == "Water"
+	homeInWater := false
+	for _, surface := range w.surfaces {
+		if surface.ID == b.Habitat {
+			homeInWater = surface.CommonName == "Water"
+			break
+		}
+	}
+	return inWater == homeInWater
+}
+
+// mediumEfficiency returns how efficient the being is at the location (1 in its primary medium)
+func (w *RandomWorld) mediumEfficiency(b *GoWorld.Being, location GoWorld.Location) float64 {
+	if w.inPrimaryMedium(b, location) {
+		return 1
+	}
+	return amphibianEfficiency
+}
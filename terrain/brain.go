@@ -0,0 +1,122 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+)
+
+var (
+	// The actions a being can take (see UpdateBeing)
+	knownActions = map[string]bool{"drink": true, "eat": true, "mate": true, "eat carried": true, "cache": true,
+		"wander": true, "sleep": true, "build": true, "chase": true, "recall": true, "track": true, "rest": true,
+		"hold": true}
+)
+
+// BuiltinBrain is the default decision making of beings (see instinctFor for its rules). Custom brains can use it
+// to fall back to the default behavior
+type BuiltinBrain struct {
+	World *RandomWorld
+}
+
+// Decide returns the next action for the being based on the built-in rules
+func (brain BuiltinBrain) Decide(b *GoWorld.Being, p GoWorld.Perception) GoWorld.Action {
+	name, location := brain.World.instinctFor(b, p)
+	return GoWorld.Action{Name: name, Location: location}
+}
+
+// brainFor returns the brain that decides for the being: its own, the one set for its type in the world (see
+// RandomWorld.Brains) or the built-in one
+func (w *RandomWorld) brainFor(b *GoWorld.Being) GoWorld.Brain {
+	if b.Brain != nil {
+		return b.Brain
+	}
+	if brain, ok := w.Brains[b.Type]; ok && brain != nil {
+		return brain
+	}
+	return BuiltinBrain{World: w}
+}
+
+// Perceive returns what the being can currently sense around itself
+// Vision range is influenced by stress, a stress value of 0 represents the beings natural senses, stress of
+// maxStress represents sense range * 2. Boulders hide what is behind them from beings that do not fly
+func (w *RandomWorld) Perceive(b *GoWorld.Being) GoWorld.Perception {
+	sight := w.currentSight(b)
+	surroundings := w.MidpointCircleAt(b.Position, sight)
+	if !flies(b.Type) {
+		surroundings = w.visibleSpots(b.Position, surroundings)
+	}
+	return GoWorld.Perception{
+		World:        w,
+		Surroundings: surroundings,
+		Sight:        sight,
+	}
+}
+
+// currentSight returns how far the being can see, its vision range adjusted for its age and stress
+func (w *RandomWorld) currentSight(b *GoWorld.Being) float64 {
+	stressShare := 1 + b.Stress/stressRange.Max
+	return w.currentVision(b) * stressShare
+}
+
+// PerceptionFor returns what the being perceives right now, the spots it can see with what is on them and the spots
+// hidden from it, along with its latest decision if it is traced (see TraceBeing). Nothing in the world changes
+// Returns an error if there is no being with the id
+func (w *RandomWorld) PerceptionFor(id uuid.UUID) (GoWorld.PerceptionView, error) {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return GoWorld.PerceptionView{}, fmt.Errorf("error providing perception: no being with id %v", id)
+	}
+	p := w.Perceive(b)
+	view := GoWorld.PerceptionView{Sight: p.Sight, Visible: p.Surroundings, Target: b.Target}
+	visible := make(map[GoWorld.Location]bool, len(p.Surroundings))
+	for _, spot := range p.Surroundings {
+		visible[spot] = true
+		s := w.TerrainSpots[spot.X][spot.Y]
+		// The objects can be obstacles as well
+		if _, food := w.FoodList[s.Object.String()]; food {
+			view.Food = append(view.Food, s.Object)
+		}
+		if s.Being != uuid.Nil && s.Being != b.ID {
+			view.Beings = append(view.Beings, s.Being)
+		}
+	}
+	for _, spot := range w.MidpointCircleAt(b.Position, p.Sight) {
+		if !visible[spot] {
+			view.Hidden = append(view.Hidden, spot)
+		}
+	}
+	if b.ID == w.traced && w.tracedPlan != nil {
+		view.Action, view.Goal = w.tracedPlan.Action, w.tracedPlan.Goal
+	}
+	return view, nil
+}
+
+// SenseActionFor uses the sense range and the brain of the being to decide on its next action. The built-in brain
+// decides instead of a custom one when the action of the custom one can not be done (see validAction)
+// Returns action to do as string and the location it picked for the action
+func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location) {
+	brain, p := w.brainFor(b), w.Perceive(b)
+	action := brain.Decide(b, p)
+	if _, builtin := brain.(BuiltinBrain); !builtin && !w.validAction(b, action) {
+		action = BuiltinBrain{World: w}.Decide(b, p)
+	}
+	return action.Name, action.Location
+}
+
+// validAction checks if the being can do the action: it has to be a known one at a spot inside the world. Wandering
+// moves the being straight to the spot, so it can only be a free spot next to the being (or its own). The other
+// actions are done at the spot or on the way to it
+func (w *RandomWorld) validAction(b *GoWorld.Being, action GoWorld.Action) bool {
+	if !knownActions[action.Name] || w.IsOutOfBounds(action.Location) {
+		return false
+	}
+	if action.Name != "wander" || action.Location == b.Position {
+		return true
+	}
+	dX, dY := action.Location.X-b.Position.X, action.Location.Y-b.Position.Y
+	if dX < -1 || dX > 1 || dY < -1 || dY > 1 {
+		return false
+	}
+	return w.canPlaceBeing(action.Location, b.Type)
+}
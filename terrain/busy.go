@@ -0,0 +1,41 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+)
+
+var (
+	// How many epochs eating a being takes for every unit of its body size (large prey keep the hunter busy)
+	eatingTime = 0.25
+	// How many epochs drinking takes
+	drinkingTime = 1.
+	// How many epochs pass after mating before the parents can mate again
+	matingCooldown = 300.
+)
+
+// keepBusy makes the being go on with the action for the epochs (see goOn)
+func keepBusy(b *GoWorld.Being, action string, epochs float64) {
+	if epochs <= 0 {
+		return
+	}
+	b.Busy = action
+	b.BusyFor = epochs
+}
+
+// goOn lets the being go on with the action it is busy with for another epoch, its needs keep growing meanwhile
+// Returns false if the being is not busy (it decides what to do next)
+func (w *RandomWorld) goOn(b *GoWorld.Being) (GoWorld.Outcome, bool) {
+	if b.BusyFor <= 0 {
+		b.Busy = ""
+		return "", false
+	}
+	b.BusyFor--
+	w.AdjustStressFor(b)
+	w.AdjustNeeds(b)
+	return GoWorld.Busy, true
+}
+
+// canMate checks if the being is old enough to mate and recovered from mating the last time
+func canMate(b *GoWorld.Being) bool {
+	return !isJuvenile(b) && b.MateCooldown <= 0
+}
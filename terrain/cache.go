@@ -0,0 +1,95 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// HoardingTypes are the being types that carry food they can not eat right away and cache it for later
+	HoardingTypes = map[string]bool{
+		"Flying":    true,
+		"Amphibian": true,
+	}
+	// The most nutritional value a being can carry
+	carryCapacity = 16.
+	// How long a cache lasts before the food in it spoils (in plant wither units, 4 epochs each)
+	cacheWither = 1200.
+)
+
+// PickUpFood lets a hoarding being with free room take food from the plant after it has eaten its fill
+// Returns true if the being picked up any food
+func (w *RandomWorld) PickUpFood(b *GoWorld.Being, food *GoWorld.Food) bool {
+	if !HoardingTypes[b.Type] || b.Hunger > 0 || food.Type != "Land" {
+		return false
+	}
+	taken := math.Min(carryCapacity-b.Carrying, food.NutritionalValue-food.Eaten)
+	if taken <= 0 {
+		return false
+	}
+	b.Carrying += taken
+	food.Eaten += taken
+	w.recordEnergy("Plants", "Caches", taken, taken)
+	return true
+}
+
+// EatCarried lets the being eat the food it carries (as much as its hunger)
+func (w *RandomWorld) EatCarried(b *GoWorld.Being) {
+	bite := math.Min(b.Carrying, b.Hunger)
+	b.Carrying -= bite
+	b.Hunger -= bite
+	w.recordEnergy("Caches", trophicLevel(b.Type), bite, bite)
+}
+
+// cacheSpotFor returns the free spot in the being habitat (among the surroundings) closest to its home, or to the
+// being itself if it has no home
+// Returns false if there is no spot where the food could be cached
+func (w *RandomWorld) cacheSpotFor(b *GoWorld.Being, surroundings []GoWorld.Location) (GoWorld.Location, bool) {
+	center := b.Position
+	if home := w.homeOf(b); home != nil {
+		center = home.Position
+	}
+	// Caches are hidden at the same kind of spots homes are built on
+	return w.nearest(center, append([]GoWorld.Location{b.Position}, surroundings...), func(spot GoWorld.Location) bool {
+		return w.canBuildHome(b, spot)
+	})
+}
+
+// CacheFood places the food the being carries onto its current spot as a cache, the being remembers where it is
+// Returns the cache or nil if the spot is not free
+func (w *RandomWorld) CacheFood(b *GoWorld.Being) *GoWorld.Food {
+	if b.Carrying <= 0 || !w.canBuildHome(b, b.Position) {
+		return nil
+	}
+	cache := &GoWorld.Food{ID: w.newID()}
+	cache.Type = "Cache"
+	cache.NutritionalValue = b.Carrying
+	cache.Taste = tasteRange.Max / 2
+	cache.Wither = cacheWither
+	cache.Area = 1
+	// Caches do not grow or drop seeds
+	cache.GrowthStage = stageRange.Max + 1
+	cache.Position = b.Position
+	cache.Habitat = w.TerrainSpots[b.Position.X][b.Position.Y].Surface.ID
+	w.updatePlantSpot(cache.Position.X, cache.Position.Y, cache.Area, cache.ID)
+	w.FoodList[cache.ID.String()] = cache
+	w.Caches[cache.ID.String()] = b.ID
+	w.remember(b, "eat", cache.Position)
+	b.Carrying = 0
+	return cache
+}
+
+// Spoil makes the food in the cache go bad over time, spoiled caches are removed
+// Returns the outcome and the UUIDs of objects affected by the action
+func (w *RandomWorld) Spoil(cache *GoWorld.Food) (GoWorld.Outcome, []uuid.UUID) {
+	cache.Wither -= 1. / 4
+	if cache.Wither > 0 {
+		return GoWorld.Spoiling, []uuid.UUID{}
+	}
+	w.recordEnergy("Caches", "Decay", cache.NutritionalValue-cache.Eaten, cache.NutritionalValue-cache.Eaten)
+	delete(w.FoodList, cache.ID.String())
+	delete(w.Caches, cache.ID.String())
+	w.updatePlantSpot(cache.Position.X, cache.Position.Y, cache.Area, uuid.Nil)
+	return GoWorld.Withered, []uuid.UUID{cache.ID}
+}
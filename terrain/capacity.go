@@ -0,0 +1,89 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// How many beings every spot where beings can live supports (used to derive the world carrying capacity)
+	carryingDensity = 0.004
+	// How many beings a spot of each surface supports compared to the carrying density (surfaces not listed support
+	// none), used for the carrying capacity of the regions
+	biomeCapacity = map[string]float64{
+		"Grassland": 1.5,
+		"Forest":    1.2,
+		"Water":     1,
+		"Gravel":    0.5,
+		"Mountain":  0.3,
+	}
+	// How much faster stress and hunger grow for every full carrying capacity a region is over
+	crowdingStress = 0.5
+	crowdingHunger = 0.5
+)
+
+// carryingCapacity derives the most beings the world can support from the area they can live on (land that can be
+// walked on and water)
+func (w *RandomWorld) carryingCapacity() int {
+	area := 0
+	for x := range w.TerrainSpots {
+		for _, spot := range w.TerrainSpots[x] {
+			if spot.Surface.Habitable || spot.Surface.CommonName == "Water" {
+				area++
+			}
+		}
+	}
+	return int(float64(area) * carryingDensity)
+}
+
+// population returns the number of beings in the world, including the ones still in their eggs
+func (w *RandomWorld) population() int {
+	return len(w.BeingList) + len(w.Eggs)
+}
+
+// roomForBirth checks if the world can support another being: births get less likely the closer the population is to
+// the carrying capacity and stop once it is reached (no limit if MaxBeings is not set)
+func (w *RandomWorld) roomForBirth() bool {
+	if w.MaxBeings <= 0 {
+		return true
+	}
+	crowding := float64(w.population()) / float64(w.MaxBeings)
+	return crowding < 1 && w.random.Float64() >= crowding
+}
+
+// countRegionBeings counts the beings in every region and computes the region carrying capacities (once, SetSurfaceAt
+// keeps them up to date)
+func (w *RandomWorld) countRegionBeings(regionsX, regionsY int) {
+	if w.regionCap == nil {
+		w.regionCap = make([][]float64, regionsX)
+		for rx := range w.regionCap {
+			w.regionCap[rx] = make([]float64, regionsY)
+		}
+		for x := range w.TerrainSpots {
+			for y, spot := range w.TerrainSpots[x] {
+				w.regionCap[x/regionSize][y/regionSize] += biomeCapacity[spot.Surface.CommonName] * carryingDensity
+			}
+		}
+	}
+	w.regionPop = make([][]int, regionsX)
+	for rx := range w.regionPop {
+		w.regionPop[rx] = make([]int, regionsY)
+	}
+	for _, b := range w.BeingList {
+		w.regionPop[b.Position.X/regionSize][b.Position.Y/regionSize]++
+	}
+}
+
+// overcrowding returns by how many carrying capacities the region of the location is over (0 if it is not crowded)
+func (w *RandomWorld) overcrowding(location GoWorld.Location) float64 {
+	if w.regionPop == nil || w.IsOutOfBounds(location) {
+		return 0
+	}
+	capacity := w.regionCap[location.X/regionSize][location.Y/regionSize]
+	beings := float64(w.regionPop[location.X/regionSize][location.Y/regionSize])
+	if capacity <= 0 {
+		// Beings can not live here at all
+		return beings
+	}
+	return math.Max(beings/capacity-1, 0)
+}
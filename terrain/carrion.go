@@ -0,0 +1,119 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// How long carrion lies around before it rots away (in plant wither units, 4 epochs each)
+	carrionWither = 600.
+	// The nutritional value of carrion per body size of the dead being (same as eating the being alive)
+	carrionNutrition = 4.
+	// How much nutritional value carrion loses to the soil around it every epoch while decomposing
+	carrionDecay = 0.05
+	// How far around the carrion the soil is enriched
+	soilRadius = 2.
+	// Soil nutrients that double the growth speed of plants seeded on them
+	soilGrowthNutrients = 32.
+)
+
+// canEatFood checks if the being can eat the food: water beings only eat seaweed, scavengers and carnivores only eat
+// carrion and eggs, amphibians eat seaweed, land plants, eggs and cached food, insects only young land plants and the
+// other beings eat land plants and cached food
+func canEatFood(b *GoWorld.Being, f *GoWorld.Food) bool {
+	switch b.Type {
+	case "Water":
+		return f.Type == "Water"
+	case "Amphibian":
+		return f.Type == "Water" || f.Type == "Land" || f.Type == "Egg" || f.Type == "Cache"
+	case "Scavenger":
+		return f.Type == "Carrion" || f.Type == "Egg"
+	case "Carnivore":
+		return f.Type == "Carrion" || f.Type == "Egg"
+	case "Insect":
+		return f.Type == "Land" && f.GrowthStage <= insectFoodStage
+	}
+	return f.Type == "Land" || f.Type == "Cache"
+}
+
+// CanEat checks if beings of the type can eat the food (see canEatFood), e.g. for other worlds that share the diets
+func CanEat(beingType string, f *GoWorld.Food) bool {
+	return canEatFood(&GoWorld.Being{Type: beingType}, f)
+}
+
+// leaveCarrion places the body (or the remains) of a dead being with the nutritional value onto the map as food for
+// scavengers and carnivores
+// Bodies of beings that die in water sink, they are also not left on spots already taken by plants
+// Returns the carrion or nil if none was left
+func (w *RandomWorld) leaveCarrion(b *GoWorld.Being, nutrition float64) *GoWorld.Food {
+	spot := w.TerrainSpots[b.Position.X][b.Position.Y]
+	if nutrition <= 0 || spot.Surface.CommonName == "Water" || spot.Object != uuid.Nil ||
+		spot.OccupyingPlant != uuid.Nil {
+		return nil
+	}
+	carrion := &GoWorld.Food{ID: w.newID()}
+	carrion.Type = "Carrion"
+	carrion.NutritionalValue = nutrition
+	carrion.Taste = tasteRange.Max / 2
+	carrion.Wither = carrionWither
+	carrion.Area = 1
+	// Carrion does not grow or drop seeds
+	carrion.GrowthStage = stageRange.Max + 1
+	carrion.Position = b.Position
+	carrion.Habitat = spot.Surface.ID
+	w.updatePlantSpot(b.Position.X, b.Position.Y, carrion.Area, carrion.ID)
+	w.FoodList[carrion.ID.String()] = carrion
+	return carrion
+}
+
+// Rot makes the carrion decompose, its nutritional value slowly goes into the soil around it. Fully decayed carrion
+// leaves the rest of its value in the soil
+// Returns the outcome and the UUIDs of objects affected by the action
+func (w *RandomWorld) Rot(carrion *GoWorld.Food) (GoWorld.Outcome, []uuid.UUID) {
+	carrion.Wither -= 1. / 4
+	decayed := math.Min(carrion.NutritionalValue-carrion.Eaten, carrionDecay)
+	carrion.NutritionalValue -= decayed
+	w.enrichSoil(carrion.Position, decayed)
+	w.recordEnergy("Corpses", "Soil", decayed, decayed)
+	if left := carrion.NutritionalValue - carrion.Eaten; carrion.Wither <= 0 || left <= 0 {
+		w.enrichSoil(carrion.Position, left)
+		w.recordEnergy("Corpses", "Soil", left, left)
+		delete(w.FoodList, carrion.ID.String())
+		w.updatePlantSpot(carrion.Position.X, carrion.Position.Y, carrion.Area, uuid.Nil)
+		return GoWorld.Withered, []uuid.UUID{carrion.ID}
+	}
+	return GoWorld.Rotted, []uuid.UUID{}
+}
+
+// closestCarrion returns the closest carrion spot (or eggs the being can eat) among the provided spots
+// Returns false if there is no carrion among them
+func (w *RandomWorld) closestCarrion(b *GoWorld.Being, spots []GoWorld.Location) (GoWorld.Location, bool) {
+	return w.nearest(b.Position, spots, func(spot GoWorld.Location) bool {
+		terrainSpot := w.TerrainSpots[spot.X][spot.Y]
+		if terrainSpot.Object == uuid.Nil || terrainSpot.Being != uuid.Nil {
+			return false
+		}
+		f := w.FoodList[terrainSpot.Object.String()]
+		return f != nil && canEatFood(b, f)
+	})
+}
+
+// enrichSoil spreads the nutrients evenly over the soil around the location
+func (w *RandomWorld) enrichSoil(location GoWorld.Location, nutrients float64) {
+	if nutrients <= 0 {
+		return
+	}
+	spots := w.MidpointCircleAt(location, soilRadius)
+	for _, spot := range spots {
+		w.TerrainSpots[spot.X][spot.Y].Nutrients += nutrients / float64(len(spots))
+	}
+}
+
+// fertilize lets the seedling use up the soil nutrients on its spot to grow faster
+func (w *RandomWorld) fertilize(seedling *GoWorld.Food) {
+	spot := w.TerrainSpots[seedling.Position.X][seedling.Position.Y]
+	seedling.GrowthSpeed *= 1 + math.Min(spot.Nutrients/soilGrowthNutrients, 1)
+	spot.Nutrients = 0
+}
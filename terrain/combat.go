@@ -0,0 +1,75 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// Energy lost by the attacker when an attack fails
+	failedAttackEnergyCost = 10.
+	// How much of the speed the most severely injured beings lose
+	injuryShare = 0.5
+	// How much injury heals per epoch (durable beings heal up to twice as fast)
+	healRate = 0.2
+	// The chance that a predator overlooks prey with maximum camouflage standing in its habitat
+	camouflageEffect = 0.8
+)
+
+// combatStrength returns how strong the being is in a fight
+// Bigger and more durable beings are stronger, faster beings attack and escape more easily
+func (w *RandomWorld) combatStrength(b *GoWorld.Being) float64 {
+	sizeC := bodySize(b) + 1
+	durableC := 1 + b.Durability/durabilityRange.Max
+	speedC := 1 + w.currentSpeed(b)/speedRange.Max
+	return sizeC * durableC * speedC
+}
+
+// notices returns true if the predator spots the prey
+// Prey standing on its habitat surface is overlooked with a chance based on its camouflage, predators with better
+// vision overlook camouflaged prey less often. Prey next to trees can hide behind them
+func (w *RandomWorld) notices(predator, prey *GoWorld.Being) bool {
+	if w.hiddenByTrees(prey) && w.random.Float64() < treeHidingChance {
+		return false
+	}
+	if w.TerrainSpots[prey.Position.X][prey.Position.Y].Surface.ID != prey.Habitat {
+		// Out of its habitat the prey stands out
+		return true
+	}
+	visionC := 1 - 0.5*w.currentVision(predator)/visionRange.Max
+	return w.random.Float64() >= camouflageEffect*prey.Camouflage/camouflageRange.Max*visionC
+}
+
+// Attack resolves a fight between the attacker and its prey
+// The pack members next to the prey sharing the target help the attacker. The chance of success is the share of the
+// attacking strength in the combined strength of both sides. A failed attack costs energy and the stronger the prey,
+// the more likely the attacker gets injured
+// Returns true if the attack succeeded (the prey can be eaten)
+func (w *RandomWorld) Attack(attacker, prey *GoWorld.Being) bool {
+	attack := w.combatStrength(attacker)
+	for _, member := range w.groupMembers(attacker, w.MidpointCircleAt(prey.Position, packAttackRange)) {
+		if member.Target == prey.ID {
+			attack += w.combatStrength(member)
+		}
+	}
+	defense := w.combatStrength(prey)
+	if w.random.Float64() < attack/(attack+defense) {
+		return true
+	}
+
+	// The attack failed
+	attacker.Energy = math.Max(attacker.Energy-failedAttackEnergyCost, 0)
+	if w.random.Float64() < defense/(attack+defense) {
+		// The prey fought back and injured the attacker
+		attacker.Injury = math.Min(attacker.Injury+w.random.Float64()*bodySize(prey)*2, injuryRange.Max)
+	}
+	return false
+}
+
+// Heal lowers the being injuries, durable beings heal faster
+func (w *RandomWorld) Heal(b *GoWorld.Being) {
+	b.Injury -= healRate * (1 + b.Durability/durabilityRange.Max)
+	if b.Injury < 0 {
+		b.Injury = 0
+	}
+}
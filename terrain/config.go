@@ -0,0 +1,243 @@
+package terrain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// spawners create the initial beings of each type (in this order, so seeded worlds always look the same)
+var spawners = []struct {
+	beingType string
+	create    func(w *RandomWorld, quantity int) (int, error)
+}{
+	{"Carnivore", (*RandomWorld).CreateCarnivores},
+	{"Water", (*RandomWorld).CreateFishies},
+	{"Flying", (*RandomWorld).CreateFlyers},
+	{"Scavenger", (*RandomWorld).CreateScavengers},
+	{"Amphibian", (*RandomWorld).CreateAmphibians},
+	{"Insect", (*RandomWorld).CreateInsects},
+}
+
+// Config describes a whole world: its terrain, the settings and what lives in it at the start (see LoadConfig for the
+// JSON form)
+type Config struct {
+	Width, Height int                    // The size of the terrain
+	Seed          int64                  // Makes the world the same on every run (0 for a random world)
+	Surfaces      []SurfaceConfig        // Changes to the predefined Surfaces (in their order, empty keeps them)
+	ZoneRatios    []float64              // The share of the terrain covered by each surface (empty for the defaults)
+	Ranges        map[string]RangeConfig // Attribute ranges of beings and plants (attribute name: range)
+	Thresholds    struct {
+		Hunger, Stress float64 // See WithThresholds (0 for the defaults)
+	}
+	NeedIncrements struct {
+		Hunger, Thirst, WantsChild, Sleepiness float64 // See WithNeedIncrements (0 for the defaults)
+	}
+	Populations map[string]int   // How many beings of each type live in the world at the start (Type: count)
+	Templates   []TemplateConfig // Additional beings of specific phenotypes created at the start
+	Plants      struct {
+		Land, Water int // How many plants grow in the world at the start
+	}
+	Immigration Immigration // New beings arriving while a population is low (see WithImmigration, none by default)
+	// Where the colored terrain is stored as PNG (terrain.png if missing, an empty path stores nothing)
+	TerrainImage *string
+	// The sprite theme the display draws the world in (see display.LoadTheme), empty for the default sprites
+	Theme string
+}
+
+// SurfaceConfig changes the appearance and habitability of a surface (the names stay, beings rely on them)
+type SurfaceConfig struct {
+	Color     string // The HEX color of the surface (e.g. '#74a7eb', empty keeps the current one)
+	Habitable *bool  // Whether beings can move across the surface and plants can grow on it (nil keeps the current one)
+}
+
+// TemplateConfig creates the count of beings from the template
+type TemplateConfig struct {
+	Count int
+	BeingTemplate
+}
+
+// RangeConfig is the range of values an attribute can have and how its random values spread (both Min and Max 0
+// keep the default range, e.g. when only the distribution changes)
+type RangeConfig struct {
+	Min, Max     float64
+	Distribution Distribution
+}
+
+// DefaultConfig returns the configuration of the world the goworld command shows when it is given no config
+func DefaultConfig() *Config {
+	c := &Config{Width: 1000, Height: 1000}
+	c.Populations = map[string]int{
+		"Carnivore": 15,
+		"Water":     10,
+		"Flying":    15,
+		"Scavenger": 5,
+		"Amphibian": 10,
+		"Insect":    30,
+	}
+	c.Plants.Land = 30
+	c.Plants.Water = 20
+	return c
+}
+
+// LoadConfig reads the JSON config file (field names as in Config, e.g. {"Width": 500, "Populations": {"Insect": 10}})
+// Values missing in the file keep the ones from DefaultConfig
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading world config: %v", err)
+	}
+	defer file.Close()
+	c := DefaultConfig()
+	if err := json.NewDecoder(file).Decode(c); err != nil {
+		return nil, fmt.Errorf("error loading world config %v: %v", path, err)
+	}
+	return c, nil
+}
+
+// Options converts the config into the options for NewRandomWorld
+func (c *Config) Options() []Option {
+	opts := []Option{
+		WithSeed(c.Seed),
+		WithThresholds(c.Thresholds.Hunger, c.Thresholds.Stress),
+		WithNeedIncrements(c.NeedIncrements.Hunger, c.NeedIncrements.Thirst, c.NeedIncrements.WantsChild,
+			c.NeedIncrements.Sleepiness),
+		WithZoneRatios(c.ZoneRatios...),
+	}
+	if len(c.Surfaces) > 0 {
+		opts = append(opts, WithSurfaces(c.Surfaces...))
+	}
+	if c.TerrainImage != nil {
+		opts = append(opts, WithTerrainImage(*c.TerrainImage))
+	}
+	if len(c.Immigration.Floors) > 0 {
+		opts = append(opts, WithImmigration(c.Immigration))
+	}
+	for attribute, r := range c.Ranges {
+		if r.Min != 0 || r.Max != 0 {
+			opts = append(opts, withRange(attribute, r.Min, r.Max))
+		}
+		if r.Distribution.Kind != "" {
+			opts = append(opts, WithDistribution(attribute, r.Distribution))
+		}
+	}
+	return opts
+}
+
+// surfacesWith returns a copy of the predefined Surfaces with the changes (in their order, none keeps them all)
+// Returns an error if the number of changes differs from the number of surfaces or a color is not valid
+func surfacesWith(changes []SurfaceConfig) ([]Surface, error) {
+	surfaces := append([]Surface(nil), Surfaces...)
+	if len(changes) == 0 {
+		return surfaces, nil
+	}
+	if len(changes) != len(surfaces) {
+		return nil, fmt.Errorf("error applying world config: %d surfaces given, but %d are predefined", len(changes),
+			len(surfaces))
+	}
+	for i, sc := range changes {
+		if sc.Color != "" {
+			rgba, err := ParseHexColorFast(sc.Color)
+			if err != nil {
+				return nil, fmt.Errorf("error applying world config: surface %d color %v: %v", i, sc.Color, err)
+			}
+			surfaces[i].Color = rgba
+		}
+		if sc.Habitable != nil {
+			surfaces[i].Habitable = *sc.Habitable
+		}
+	}
+	return surfaces, nil
+}
+
+// NewWorldFromConfig creates the world the config describes: generates the terrain and fills it with beings and plants
+// (the extra options are applied after the ones from the config, e.g. WithProgress)
+func NewWorldFromConfig(c *Config, extra ...Option) (*RandomWorld, error) {
+	for beingType := range c.Populations {
+		known := false
+		for _, spawner := range spawners {
+			known = known || spawner.beingType == beingType
+		}
+		if !known {
+			return nil, fmt.Errorf("error applying world config: unknown being type %v", beingType)
+		}
+	}
+	w, err := NewRandomWorld(c.Width, c.Height, append(c.Options(), extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, spawner := range spawners {
+		if _, err := spawner.create(w, c.Populations[spawner.beingType]); err != nil {
+			return nil, fmt.Errorf("error applying world config: %v", err)
+		}
+	}
+	for _, tc := range c.Templates {
+		if err := w.CreateBeingsFromTemplate(tc.Count, tc.BeingTemplate); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := w.ProvideFood(c.Plants.Land, c.Plants.Water); err != nil {
+		return nil, fmt.Errorf("error applying world config: %v", err)
+	}
+	return w, nil
+}
+
+// Set changes a single value of the config by its name, the field names joined with dots (e.g. NeedIncrements.Hunger,
+// Thresholds.Stress, Populations.Insect, Plants.Land or Ranges.Mutation.Max), counts are rounded down
+// Returns an error if there is no value with the name
+func (c *Config) Set(name string, value float64) error {
+	parts := strings.Split(name, ".")
+	switch {
+	case name == "Width":
+		c.Width = int(value)
+	case name == "Height":
+		c.Height = int(value)
+	case name == "Thresholds.Hunger":
+		c.Thresholds.Hunger = value
+	case name == "Thresholds.Stress":
+		c.Thresholds.Stress = value
+	case name == "NeedIncrements.Hunger":
+		c.NeedIncrements.Hunger = value
+	case name == "NeedIncrements.Thirst":
+		c.NeedIncrements.Thirst = value
+	case name == "NeedIncrements.WantsChild":
+		c.NeedIncrements.WantsChild = value
+	case name == "NeedIncrements.Sleepiness":
+		c.NeedIncrements.Sleepiness = value
+	case name == "Plants.Land":
+		c.Plants.Land = int(value)
+	case name == "Plants.Water":
+		c.Plants.Water = int(value)
+	case len(parts) == 2 && parts[0] == "Populations":
+		if _, known := creators[parts[1]]; !known {
+			return fmt.Errorf("error setting %v: unknown being type %v", name, parts[1])
+		}
+		if c.Populations == nil {
+			c.Populations = make(map[string]int)
+		}
+		c.Populations[parts[1]] = int(value)
+	case len(parts) == 3 && parts[0] == "Ranges" && (parts[2] == "Min" || parts[2] == "Max"):
+		defaultRange, known := defaultRanges[parts[1]]
+		if !known {
+			return fmt.Errorf("error setting %v: unknown attribute %v", name, parts[1])
+		}
+		if c.Ranges == nil {
+			c.Ranges = make(map[string]RangeConfig)
+		}
+		r, ok := c.Ranges[parts[1]]
+		if !ok || r.Min == 0 && r.Max == 0 {
+			// The other end of the range stays the default one
+			r.Min, r.Max = defaultRange.Min, defaultRange.Max
+		}
+		if parts[2] == "Min" {
+			r.Min = value
+		} else {
+			r.Max = value
+		}
+		c.Ranges[parts[1]] = r
+	default:
+		return fmt.Errorf("error setting %v: the config has no such value", name)
+	}
+	return nil
+}
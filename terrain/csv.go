@@ -0,0 +1,214 @@
+package terrain
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// How different (the mean difference of the inherited attributes, as shares of their ranges) two beings of a
+	// species can be at most
+	speciesDistance = 0.2
+	// The columns of the beings table (see BeingsToCSV)
+	beingColumns = []string{"Epoch", "ID", "Type", "Species", "Gender", "Generation", "Lineage", "Parent1", "Parent2",
+		"X", "Y", "Age", "LifeExpectancy", "MaturityAge", "Hunger", "Thirst", "WantsChild", "Sleepiness", "Stress",
+		"Energy", "Injury", "VisionRange", "MemorySize", "Speed", "Durability", "Camouflage", "Resistance", "Size",
+		"Fertility", "MutationRate", "Nocturnal", "PersonalityThirst", "PersonalityHunger", "PersonalityMating",
+		"PersonalityCaution", "Name", "Tags"}
+	// The columns of the plants table (see PlantsToCSV)
+	plantColumns = []string{"Epoch", "ID", "Type", "X", "Y", "GrowthSpeed", "NutritionalValue", "Eaten", "Taste",
+		"Toxicity", "GrowthStage", "StageProgress", "Area", "Seeds", "SeedDisperse", "Wither", "MutationRate", "Name",
+		"Tags"}
+	// The first columns of the species table, a mean and variance column follow for each of the summaryAttributes
+	// (see SpeciesToCSV)
+	speciesColumns = []string{"Epoch", "Species", "Type", "Population", "Deaths", "Lifespan", "Kills"}
+)
+
+// BeingsToCSV stores the living beings as a table with a row for every being (its attributes, species, generation and
+// parents), e.g. for analysis in pandas or R. The name ending with .gz gzips the file
+func (w *RandomWorld) BeingsToCSV(fileName string) error {
+	species := w.Species()
+	ids := make([]string, 0, len(w.BeingList))
+	for id := range w.BeingList {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return writeFile(fileName, func(out io.Writer) error {
+		table := csv.NewWriter(out)
+		if err := table.Write(beingColumns); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			b := w.BeingList[id]
+			row := []string{strconv.FormatUint(w.Epoch, 10), id, b.Type, species[id], b.Gender,
+				strconv.Itoa(b.Generation), idText(b.Lineage), idText(b.Parents[0]), idText(b.Parents[1]),
+				strconv.Itoa(b.Position.X), strconv.Itoa(b.Position.Y)}
+			row = appendValues(row, b.Age, b.LifeExpectancy, b.MaturityAge, b.Hunger, b.Thirst, b.WantsChild,
+				b.Sleepiness, b.Stress, b.Energy, b.Injury, b.VisionRange, b.MemorySize, b.Speed, b.Durability,
+				b.Camouflage, b.Resistance, b.Size, b.Fertility, b.MutationRate)
+			row = append(row, strconv.FormatBool(b.Nocturnal))
+			row = appendValues(row, b.Personality.Thirst, b.Personality.Hunger, b.Personality.Mating,
+				b.Personality.Caution)
+			row = append(row, b.Name, strings.Join(b.Tags, " "))
+			if err := table.Write(row); err != nil {
+				return err
+			}
+		}
+		table.Flush()
+		return table.Error()
+	})
+}
+
+// PlantsToCSV stores the edible plants as a table with a row for every plant (see BeingsToCSV)
+func (w *RandomWorld) PlantsToCSV(fileName string) error {
+	ids := make([]string, 0, len(w.FoodList))
+	for id := range w.FoodList {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return writeFile(fileName, func(out io.Writer) error {
+		table := csv.NewWriter(out)
+		if err := table.Write(plantColumns); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			p := w.FoodList[id]
+			row := []string{strconv.FormatUint(w.Epoch, 10), id, p.Type, strconv.Itoa(p.Position.X),
+				strconv.Itoa(p.Position.Y)}
+			row = appendValues(row, p.GrowthSpeed, p.NutritionalValue, p.Eaten, p.Taste, p.Toxicity, p.GrowthStage,
+				p.StageProgress, p.Area, p.Seeds, p.SeedDisperse, p.Wither, p.MutationRate)
+			row = append(row, p.Name, strings.Join(p.Tags, " "))
+			if err := table.Write(row); err != nil {
+				return err
+			}
+		}
+		table.Flush()
+		return table.Error()
+	})
+}
+
+// SpeciesToCSV stores the summary of every species and every type as a table with a row for each (see SpeciesSummary),
+// e.g. for following how the species change over the exports of a run
+func (w *RandomWorld) SpeciesToCSV(fileName string) error {
+	summaries := w.SpeciesSummary()
+	columns := append([]string{}, speciesColumns...)
+	for _, attribute := range summaryAttributes {
+		columns = append(columns, "Mean"+attribute, "Var"+attribute)
+	}
+	return writeFile(fileName, func(out io.Writer) error {
+		table := csv.NewWriter(out)
+		if err := table.Write(columns); err != nil {
+			return err
+		}
+		for _, s := range summaries {
+			row := []string{strconv.FormatUint(w.Epoch, 10), s.Species, s.Type, strconv.Itoa(s.Population),
+				strconv.Itoa(s.Deaths)}
+			row = appendValues(row, s.Lifespan)
+			row = append(row, strconv.Itoa(s.Kills))
+			for _, attribute := range summaryAttributes {
+				row = appendValues(row, s.Means[attribute], s.Variances[attribute])
+			}
+			if err := table.Write(row); err != nil {
+				return err
+			}
+		}
+		table.Flush()
+		return table.Error()
+	})
+}
+
+// appendValues adds the values to the row (as short as they can be written without losing precision)
+func appendValues(row []string, values ...float64) []string {
+	for _, v := range values {
+		row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return row
+}
+
+// idText returns the identifier as text (empty for nil, e.g. the parents of a founder)
+func idText(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// Species groups the living beings into species: a being belongs to the first species (of its type) whose first
+// member it is close enough to (see speciesDistance), otherwise it starts a new one
+// Returns the species of every being (ID: species, e.g. Insect-2)
+func (w *RandomWorld) Species() map[string]string {
+	ids := make([]string, 0, len(w.BeingList))
+	for id := range w.BeingList {
+		ids = append(ids, id)
+	}
+	// The beings are grouped in the order of their IDs, so the same world is always grouped the same way
+	sort.Strings(ids)
+	founders := make(map[string][][]float64)
+	species := make(map[string]string, len(ids))
+	for _, id := range ids {
+		b := w.BeingList[id]
+		species[id] = fmt.Sprintf("%v-%d", b.Type, speciesIndex(founders, b.Type, w.genome(b))+1)
+	}
+	return species
+}
+
+// speciesOf returns the species the being (one that just died) belongs to among the living beings of its type
+func (w *RandomWorld) speciesOf(b *GoWorld.Being) string {
+	ids := make([]string, 0)
+	for id, other := range w.BeingList {
+		if other.Type == b.Type && other != b {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	founders := make(map[string][][]float64)
+	for _, id := range ids {
+		speciesIndex(founders, b.Type, w.genome(w.BeingList[id]))
+	}
+	return fmt.Sprintf("%v-%d", b.Type, speciesIndex(founders, b.Type, w.genome(b))+1)
+}
+
+// speciesIndex returns the index of the first founder (of the type) the genome is close enough to, the genome becomes
+// the founder of a new species if there is none
+func speciesIndex(founders map[string][][]float64, beingType string, genome []float64) int {
+	for i, founder := range founders[beingType] {
+		if genomeDistance(genome, founder) <= speciesDistance {
+			return i
+		}
+	}
+	founders[beingType] = append(founders[beingType], genome)
+	return len(founders[beingType]) - 1
+}
+
+// genome returns the inherited attributes of the being as shares of their ranges (0 at the minimum, 1 at the maximum)
+func (w *RandomWorld) genome(b *GoWorld.Being) []float64 {
+	ranges := []*attributeRange{w.rangeOf("Vision"), w.rangeOf("Speed"), w.rangeOf("Durability"),
+		w.sizeRangeFor(b.Type), w.fertilityRangeFor(b.Type), w.rangeOf("Camouflage"), w.rangeOf("Resistance"),
+		w.rangeOf("Mutation"), w.rangeOf("MemorySize"), w.maturityRangeFor(b.Type), w.lifeExpectancyRangeFor(b.Type)}
+	genome := []float64{b.VisionRange, b.Speed, b.Durability, b.Size, b.Fertility, b.Camouflage, b.Resistance,
+		b.MutationRate, b.MemorySize, b.MaturityAge, b.LifeExpectancy}
+	for i, r := range ranges {
+		if r == nil || r.Max <= r.Min {
+			genome[i] = 0
+			continue
+		}
+		genome[i] = (genome[i] - r.Min) / (r.Max - r.Min)
+	}
+	return genome
+}
+
+// genomeDistance returns the mean difference of the genomes
+func genomeDistance(a, b []float64) float64 {
+	sum := 0.
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+	return sum / float64(len(a))
+}
@@ -0,0 +1,90 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"image/color"
+)
+
+var (
+	// Water up to this depth (0 at the shore, 1 at the deepest spot) is shallow, seaweed only grows there and large
+	// fish can not live in it
+	shallowDepth = 0.3
+	// Land predators can wade into water up to this depth
+	wadingDepth = 0.08
+	// Fish with a body larger than this share of the size range need deep water
+	largeFishShare = 0.5
+	// Water up to this depth can be a ford land beings can cross
+	fordDepth = 0.15
+	// How many spots of water a ford can be wide (from bank to bank)
+	fordWidth = 4
+	// Fords are painted onto the terrain in a lighter blue than the water
+	fordColor = color.RGBA{R: 160, G: 200, B: 240, A: 255}
+)
+
+// depthAt returns the water depth at the location (0 for land)
+func (w *RandomWorld) depthAt(location GoWorld.Location) float64 {
+	return w.TerrainSpots[location.X][location.Y].Depth
+}
+
+// isLargeFish checks if the being is a fish large enough to need deep water
+func isLargeFish(b *GoWorld.Being) bool {
+	return b.Type == "Water" && bodySize(b) > sizeRange.Max*largeFishShare
+}
+
+// submerged checks if the location is water deep enough for the being (large fish in shallow water are as good as on
+// land)
+func (w *RandomWorld) submerged(b *GoWorld.Being, location GoWorld.Location) bool {
+	if w.TerrainSpots[location.X][location.Y].Surface.CommonName != "Water" {
+		return false
+	}
+	return !isLargeFish(b) || w.depthAt(location) > shallowDepth
+}
+
+// MarkFords marks the shallow water spots in narrow parts of rivers and lakes (with land on both sides close enough)
+// as fords that land beings can cross and paints them onto the terrain
+func (w *RandomWorld) MarkFords() {
+	// Banks are looked for horizontally, vertically and in both diagonals
+	axes := []GoWorld.Location{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: -1}}
+	for x := range w.TerrainSpots {
+		for y, spot := range w.TerrainSpots[x] {
+			if spot.Surface.CommonName != "Water" || spot.Depth > fordDepth {
+				continue
+			}
+			for _, axis := range axes {
+				forward, forwardBank := w.waterUntilBank(GoWorld.Location{X: x, Y: y}, axis)
+				backward, backwardBank := w.waterUntilBank(GoWorld.Location{X: x, Y: y},
+					GoWorld.Location{X: -axis.X, Y: -axis.Y})
+				if forwardBank && backwardBank && forward+backward+1 <= fordWidth {
+					spot.Ford = true
+					w.TerrainZones.Set(x, y, fordColor)
+					break
+				}
+			}
+		}
+	}
+}
+
+// waterUntilBank counts the water spots from the location (excluding it) in the direction until land is reached
+// Returns false if no land is reached within the ford width
+func (w *RandomWorld) waterUntilBank(from, direction GoWorld.Location) (int, bool) {
+	for water := 0; water < fordWidth; water++ {
+		spot := GoWorld.Location{X: from.X + (water+1)*direction.X, Y: from.Y + (water+1)*direction.Y}
+		if w.IsOutOfBounds(spot) {
+			return water, false
+		}
+		if w.TerrainSpots[spot.X][spot.Y].Surface.CommonName != "Water" {
+			return water, true
+		}
+	}
+	return fordWidth, false
+}
+
+// IsFord returns true if land beings can cross the water at the location
+func (w *RandomWorld) IsFord(location GoWorld.Location) bool {
+	return !w.IsOutOfBounds(location) && w.TerrainSpots[location.X][location.Y].Ford
+}
+
+// canWade checks if land predators can wade into the water on the location
+func (w *RandomWorld) canWade(location GoWorld.Location) bool {
+	return w.TerrainSpots[location.X][location.Y].Surface.CommonName == "Water" && w.depthAt(location) <= wadingDepth
+}
@@ -0,0 +1,71 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+// latestUpdate is what a being planned and did in its latest update
+type latestUpdate struct {
+	action string             // The action it chose (empty if it only slept, hibernated or migrated)
+	done   GoWorld.Outcome    // What it did
+	goal   GoWorld.Location   // Where it wanted to take the action
+	path   []GoWorld.Location // The moves towards the goal (shared with the pathfinder, never changed)
+}
+
+// UpdateBeing executes the next action for the being
+// Returns the outcome with the UUIDs of the objects affected (see GoWorld.Outcome)
+func (w *RandomWorld) UpdateBeing(b *GoWorld.Being) GoWorld.ActionResult {
+	id := b.ID.String()
+	// The plan of the previous update is outdated
+	delete(w.latest, id)
+	done, ids := w.updateBeing(b)
+	if _, alive := w.BeingList[id]; alive {
+		latest := w.latest[id]
+		latest.done = done
+		w.latest[id] = latest
+	}
+	return GoWorld.ActionResult{Outcome: done, Actor: b.ID, Targets: ids}
+}
+
+// DescribeBeing returns the being with the values derived from its attributes and its latest update: what it chose to
+// do and did, how much of its path is left, how far it sees and how fast it moves right now, its offspring alive and
+// where its prey is. Nothing in the world changes
+// Returns an error if there is no being with the id
+func (w *RandomWorld) DescribeBeing(id uuid.UUID) (GoWorld.BeingDescription, error) {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return GoWorld.BeingDescription{}, fmt.Errorf("error describing being: no being with id %v", id)
+	}
+	d := GoWorld.BeingDescription{Being: *b}
+	// The remembered locations are the only data beings share through slices
+	d.Memory.Water = append([]GoWorld.Location(nil), b.Memory.Water...)
+	d.Memory.Food = append([]GoWorld.Location(nil), b.Memory.Food...)
+	latest := w.latest[id.String()]
+	d.Action, d.Done, d.Goal = latest.action, latest.done, latest.goal
+	d.PathLeft = len(latest.path)
+	for i := len(latest.path) - 1; i >= 0; i-- {
+		if latest.path[i] == b.Position {
+			d.PathLeft = len(latest.path) - 1 - i
+			break
+		}
+	}
+	d.Sight = w.currentSight(b)
+	d.Pace = w.currentSpeed(b) * w.mediumEfficiency(b, b.Position)
+	if lifetime := b.Age + b.LifeExpectancy; lifetime > 0 {
+		d.Lived = math.Min(math.Max(b.Age/lifetime, 0), 1)
+	}
+	d.Juvenile = isJuvenile(b)
+	for _, other := range w.BeingList {
+		if other.Parents[0] == b.ID || other.Parents[1] == b.ID {
+			d.Living++
+		}
+	}
+	d.Surface = w.TerrainSpots[b.Position.X][b.Position.Y].Surface.CommonName
+	if prey := w.BeingList[b.Target.String()]; prey != nil {
+		d.TargetPos = prey.Position
+	}
+	return d, nil
+}
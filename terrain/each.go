@@ -0,0 +1,36 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"sort"
+)
+
+// ForEachBeing calls fn for every living being the filter accepts (all of them if the filter is nil), in the order of
+// their IDs. Beings that die while iterating (e.g. killed by fn) are skipped, the ones born are not visited
+func (w *RandomWorld) ForEachBeing(filter func(*GoWorld.Being) bool, fn func(*GoWorld.Being)) {
+	ids := make([]string, 0, len(w.BeingList))
+	for id := range w.BeingList {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if b, alive := w.BeingList[id]; alive && (filter == nil || filter(b)) {
+			fn(b)
+		}
+	}
+}
+
+// ForEachFood calls fn for every food (plants, carrion, eggs and caches) the filter accepts (all of it if the filter
+// is nil), in the order of their IDs. Food eaten or removed while iterating is skipped, new food is not visited
+func (w *RandomWorld) ForEachFood(filter func(*GoWorld.Food) bool, fn func(*GoWorld.Food)) {
+	ids := make([]string, 0, len(w.FoodList))
+	for id := range w.FoodList {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if f, exists := w.FoodList[id]; exists && (filter == nil || filter(f)) {
+			fn(f)
+		}
+	}
+}
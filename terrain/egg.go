@@ -0,0 +1,104 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+)
+
+var (
+	// EggLayingTypes are the being types whose offspring hatch from eggs instead of being born right away
+	EggLayingTypes = map[string]bool{
+		"Water":  true,
+		"Flying": true,
+	}
+	// How many epochs it takes for an egg to hatch
+	eggIncubation = 600.
+	// The nutritional value of an egg (for the predators that find it)
+	eggNutrition = 8.
+)
+
+// Egg holds the offspring growing inside an egg (the egg itself is food on the map that predators can eat)
+type Egg struct {
+	Embryo     *GoWorld.Being // The being that hatches from the egg
+	Incubation float64        // How many epochs are left until the egg hatches
+}
+
+// canLayEgg checks if a being of the type can lay an egg on the spot: fish lay them in water, flyers on land. The spot
+// has to be free of other objects and plants
+func (w *RandomWorld) canLayEgg(location GoWorld.Location, beingType string) bool {
+	spot := w.TerrainSpots[location.X][location.Y]
+	if spot.Object != uuid.Nil || spot.OccupyingPlant != uuid.Nil {
+		return false
+	}
+	if beingType == "Water" {
+		return spot.Surface.CommonName == "Water"
+	}
+	habitable, _ := w.IsHabitable(location)
+	return habitable
+}
+
+// LayEgg places an egg with the embryo onto its position, the embryo becomes a being once the egg hatches
+// Returns the egg food that was placed on the map
+func (w *RandomWorld) LayEgg(embryo *GoWorld.Being) *GoWorld.Food {
+	egg := &GoWorld.Food{ID: w.newID()}
+	egg.Type = "Egg"
+	egg.NutritionalValue = eggNutrition
+	egg.Taste = tasteRange.Max
+	egg.Wither = eggIncubation
+	egg.Area = 1
+	// Eggs do not grow or drop seeds
+	egg.GrowthStage = stageRange.Max + 1
+	egg.Position = embryo.Position
+	egg.Habitat = w.TerrainSpots[embryo.Position.X][embryo.Position.Y].Surface.ID
+	w.updatePlantSpot(egg.Position.X, egg.Position.Y, egg.Area, egg.ID)
+	w.FoodList[egg.ID.String()] = egg
+	w.Eggs[egg.ID.String()] = &Egg{Embryo: embryo, Incubation: eggIncubation}
+	w.recordEnergy(trophicLevel(embryo.Type), "Eggs", egg.NutritionalValue, egg.NutritionalValue)
+	return egg
+}
+
+// Incubate brings the egg closer to hatching, when the time comes the embryo is placed onto the map (if there is room
+// around the egg, otherwise it tries again the next epoch)
+// Returns the outcome and the UUIDs of objects affected by the action (the egg and the hatchling)
+func (w *RandomWorld) Incubate(egg *GoWorld.Food) (GoWorld.Outcome, []uuid.UUID) {
+	incubated := w.Eggs[egg.ID.String()]
+	if incubated == nil {
+		// An egg without an embryo can not hatch, remove it
+		w.recordEnergy("Eggs", "Decay", egg.NutritionalValue-egg.Eaten, egg.NutritionalValue-egg.Eaten)
+		w.removeEgg(egg)
+		return GoWorld.Withered, []uuid.UUID{egg.ID}
+	}
+	incubated.Incubation--
+	if incubated.Incubation > 0 {
+		return GoWorld.Incubated, []uuid.UUID{}
+	}
+	hatchSpot := egg.Position
+	if !w.canPlaceBeing(hatchSpot, incubated.Embryo.Type) {
+		found := false
+		for _, direction := range directions8 {
+			hatchSpot = GoWorld.Location{X: egg.Position.X + direction.X, Y: egg.Position.Y + direction.Y}
+			if !w.IsOutOfBounds(hatchSpot) && w.canPlaceBeing(hatchSpot, incubated.Embryo.Type) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return GoWorld.Incubated, []uuid.UUID{}
+		}
+	}
+	w.removeEgg(egg)
+	hatchling := incubated.Embryo
+	w.recordEnergy("Eggs", trophicLevel(hatchling.Type), egg.NutritionalValue-egg.Eaten,
+		egg.NutritionalValue-egg.Eaten)
+	hatchling.Position = hatchSpot
+	w.setBeingAt(hatchSpot, hatchling.ID)
+	w.BeingList[hatchling.ID.String()] = hatchling
+	return GoWorld.Hatched, []uuid.UUID{egg.ID, hatchling.ID}
+}
+
+// removeEgg takes the egg (and its embryo) off the map
+func (w *RandomWorld) removeEgg(egg *GoWorld.Food) {
+	delete(w.FoodList, egg.ID.String())
+	delete(w.Eggs, egg.ID.String())
+	w.updatePlantSpot(egg.Position.X, egg.Position.Y, egg.Area, uuid.Nil)
+}
@@ -0,0 +1,118 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"sort"
+)
+
+var (
+	// How many epochs the energy flows are added up over (see EnergyFlows)
+	energyWindow uint64 = 100
+	// How many of the latest energy windows are kept
+	energyWindowsKept = 100
+)
+
+// trophicLevel returns the level of the food web the beings of the type are at: Carnivores (the PredatorTypes),
+// Scavengers or Herbivores
+func trophicLevel(beingType string) string {
+	if PredatorTypes[beingType] {
+		return "Carnivores"
+	}
+	if beingType == "Scavenger" {
+		return "Scavengers"
+	}
+	return "Herbivores"
+}
+
+// foodLevel returns the level of the food web the food is at: Corpses, Eggs, Caches or Plants
+func foodLevel(f *GoWorld.Food) string {
+	switch f.Type {
+	case "Carrion":
+		return "Corpses"
+	case "Egg":
+		return "Eggs"
+	case "Cache":
+		return "Caches"
+	}
+	return "Plants"
+}
+
+// recordEnergy adds the nutritional energy that went from a level of the food web to another to the current window,
+// taken is what the source lost and absorbed what the consumer gained
+func (w *RandomWorld) recordEnergy(from, to string, taken, absorbed float64) {
+	if w.Stats == nil || (taken <= 0 && absorbed <= 0) {
+		return
+	}
+	for _, flows := range []map[[2]string]*GoWorld.EnergyFlow{w.Stats.flows, w.Stats.totals} {
+		flow := flows[[2]string{from, to}]
+		if flow == nil {
+			flow = &GoWorld.EnergyFlow{From: from, To: to}
+			flows[[2]string{from, to}] = flow
+		}
+		flow.Taken += taken
+		flow.Absorbed += absorbed
+	}
+}
+
+// closeEnergyWindow stores the flows of the current window once it spans energyWindow epochs and starts the next one
+func (s *Stats) closeEnergyWindow() {
+	w := s.world
+	if w.Epoch < s.windowStart+energyWindow {
+		return
+	}
+	s.windows = append(s.windows, GoWorld.EnergyWindow{From: s.windowStart, To: w.Epoch - 1,
+		Flows: sortedFlows(s.flows)})
+	if len(s.windows) > energyWindowsKept {
+		// Copied, so the dropped windows do not stay in memory
+		s.windows = append([]GoWorld.EnergyWindow(nil), s.windows[len(s.windows)-energyWindowsKept:]...)
+	}
+	s.flows = make(map[[2]string]*GoWorld.EnergyFlow)
+	s.windowStart = w.Epoch
+}
+
+// sortedFlows returns copies of the flows ordered by the levels they go from and to
+func sortedFlows(flows map[[2]string]*GoWorld.EnergyFlow) []GoWorld.EnergyFlow {
+	list := make([]GoWorld.EnergyFlow, 0, len(flows))
+	for _, flow := range flows {
+		list = append(list, *flow)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].From != list[j].From {
+			return list[i].From < list[j].From
+		}
+		return list[i].To < list[j].To
+	})
+	return list
+}
+
+// EnergyFlows returns the nutritional energy that went between the levels of the food web in every window of
+// energyWindow epochs (the oldest first, the current window last), see EnergyTotals
+func (s *Stats) EnergyFlows() []GoWorld.EnergyWindow {
+	windows := append([]GoWorld.EnergyWindow(nil), s.windows...)
+	return append(windows, GoWorld.EnergyWindow{From: s.windowStart, To: s.world.Epoch, Flows: sortedFlows(s.flows)})
+}
+
+// EnergyTotals returns the nutritional energy that went between the levels of the food web over the whole run.
+// Sunlight (new and regrowing plants), Nectar (fed on by insects without taking anything from the plants) and the
+// beings themselves (their bodies become food when they are eaten or die) are where the energy comes from, Soil
+// (rotting corpses) and Decay (withered plants, spoiled food and unhatched eggs) is where it ends up apart from the
+// beings. The consumers never absorb more than the source lost, the rest is wasted (poor digestion or being full)
+func (s *Stats) EnergyTotals() []GoWorld.EnergyFlow {
+	return sortedFlows(s.totals)
+}
+
+// EnergyFlows returns the energy flows of the food web in windows of epochs (see Stats.EnergyFlows)
+func (w *RandomWorld) EnergyFlows() []GoWorld.EnergyWindow {
+	if w.Stats == nil {
+		w.Stats = newStats(w)
+	}
+	return w.Stats.EnergyFlows()
+}
+
+// EnergyTotals returns the energy flows of the food web over the whole run (see Stats.EnergyTotals)
+func (w *RandomWorld) EnergyTotals() []GoWorld.EnergyFlow {
+	if w.Stats == nil {
+		w.Stats = newStats(w)
+	}
+	return w.Stats.EnergyTotals()
+}
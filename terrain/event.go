@@ -0,0 +1,20 @@
+package terrain
+
+import "github.com/google/uuid"
+
+// Listener is told about changes made to the world from outside the regular updates (e.g. a teleported being), with
+// the action done and the UUIDs of the objects affected (like the actions returned by UpdateBeing), and about the end
+// of every epoch (with the action ticked)
+type Listener func(action string, ids []uuid.UUID)
+
+// Subscribe adds the listener that is told about every change made to the world from outside
+func (w *RandomWorld) Subscribe(listener Listener) {
+	w.listeners = append(w.listeners, listener)
+}
+
+// emit tells all listeners about the change
+func (w *RandomWorld) emit(action string, ids ...uuid.UUID) {
+	for _, listener := range w.listeners {
+		listener(action, ids)
+	}
+}
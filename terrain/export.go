@@ -0,0 +1,72 @@
+package terrain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExportSchedule decides when the world stores its beings and plants as JSON while the time advances (see Export)
+type ExportSchedule struct {
+	Dir   string   // Where the exports are stored (the working directory if empty)
+	Every uint64   // Export every this many epochs (never if 0)
+	At    []uint64 // Export at these epochs as well
+	Gzip  bool     // Compress the exports
+	CSV   bool     // Store CSV tables (see BeingsToCSV) instead of JSON, with a table of the species as well
+	// Only store the beings (the plants are left out)
+	NoPlants bool
+}
+
+// ScheduleExports makes the world export itself as the schedule says (replacing the previous schedule)
+func (w *RandomWorld) ScheduleExports(s ExportSchedule) {
+	w.exports = s
+}
+
+// Export stores the current beings and plants into beings-<epoch>.json and plants-<epoch>.json (.csv for CSV
+// tables, plus the species summary in species-<epoch>.csv) in the directory of the export schedule (any time, e.g.
+// when the user asks for it)
+func (w *RandomWorld) Export() error {
+	if err := os.MkdirAll(filepath.Join(w.exports.Dir, "."), 0755); err != nil {
+		return fmt.Errorf("error exporting world: %v", err)
+	}
+	suffix, storeBeings, storePlants := ".json", w.BeingsToJSON, w.PlantsToJSON
+	if w.exports.CSV {
+		suffix, storeBeings, storePlants = ".csv", w.BeingsToCSV, w.PlantsToCSV
+	}
+	if w.exports.Gzip {
+		suffix += ".gz"
+	}
+	beings := filepath.Join(w.exports.Dir, fmt.Sprintf("beings-%d%s", w.Epoch, suffix))
+	if err := storeBeings(beings); err != nil {
+		return err
+	}
+	if !w.exports.NoPlants {
+		plants := filepath.Join(w.exports.Dir, fmt.Sprintf("plants-%d%s", w.Epoch, suffix))
+		if err := storePlants(plants); err != nil {
+			return err
+		}
+	}
+	if w.exports.CSV {
+		species := filepath.Join(w.exports.Dir, fmt.Sprintf("species-%d%s", w.Epoch, suffix))
+		if err := w.SpeciesToCSV(species); err != nil {
+			return err
+		}
+	}
+	w.emit("exported")
+	return nil
+}
+
+// exportIfDue exports the world when the schedule says so for the current epoch
+func (w *RandomWorld) exportIfDue() {
+	due := w.exports.Every > 0 && w.Epoch%w.exports.Every == 0
+	for _, epoch := range w.exports.At {
+		due = due || epoch == w.Epoch
+	}
+	if !due {
+		return
+	}
+	if err := w.Export(); err != nil {
+		// The time advances anyway, the next export may work again
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,149 @@
+package terrain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"hash"
+	"math"
+	"sort"
+)
+
+// Hash returns a digest of the whole world state (beings, food, structures and terrain). Two runs with the same seed
+// that stayed identical have the same hash
+func (w *RandomWorld) Hash() string {
+	h := sha256.New()
+	parts := w.HashParts()
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%v:%v;", name, parts[name])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// HashParts returns a digest of every part of the world state separately, comparing them shows where two runs
+// started to differ
+func (w *RandomWorld) HashParts() map[string]string {
+	return map[string]string{
+		"Time":       w.hashOf(func(h hash.Hash) { fmt.Fprintf(h, "%d", w.Epoch) }),
+		"Beings":     w.hashOf(w.hashBeings),
+		"Food":       w.hashOf(w.hashFood),
+		"Structures": w.hashOf(w.hashStructures),
+		"Terrain":    w.hashOf(w.hashTerrain),
+	}
+}
+
+// hashOf returns the digest of what the writer writes into the hash
+func (w *RandomWorld) hashOf(write func(h hash.Hash)) string {
+	h := sha256.New()
+	write(h)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// surfaceIndex returns the position of the surface among Surfaces (surface IDs differ between runs, their order not)
+func surfaceIndex(id uuid.UUID) int {
+	for i := range Surfaces {
+		if Surfaces[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// hashBeing writes the state of the being into the hash (its brain is left out, it holds no state of the world)
+func hashBeing(h hash.Hash, b *GoWorld.Being) {
+	being := *b
+	being.Brain = nil
+	being.Habitat = uuid.Nil
+	fmt.Fprintf(h, "%+v:%d;", being, surfaceIndex(b.Habitat))
+}
+
+// hashBeings writes all beings into the hash (in the order of their IDs, so the map order does not matter)
+func (w *RandomWorld) hashBeings(h hash.Hash) {
+	ids := make([]string, 0, len(w.BeingList))
+	for id := range w.BeingList {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		hashBeing(h, w.BeingList[id])
+	}
+}
+
+// hashFood writes all food into the hash (in the order of their IDs)
+func (w *RandomWorld) hashFood(h hash.Hash) {
+	ids := make([]string, 0, len(w.FoodList))
+	for id := range w.FoodList {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		food := *w.FoodList[id]
+		food.Habitat = uuid.Nil
+		fmt.Fprintf(h, "%+v:%d;", food, surfaceIndex(w.FoodList[id].Habitat))
+	}
+}
+
+// hashStructures writes the homes, eggs, caches, obstacles and territories into the hash
+func (w *RandomWorld) hashStructures(h hash.Hash) {
+	homes := make([]string, 0, len(w.Homes))
+	for id, home := range w.Homes {
+		homes = append(homes, fmt.Sprintf("%v:%+v", id, *home))
+	}
+	obstacles := make([]string, 0, len(w.Obstacles))
+	for id, obstacle := range w.Obstacles {
+		obstacles = append(obstacles, fmt.Sprintf("%v:%+v", id, *obstacle))
+	}
+	territories := make([]string, 0, len(w.Territories))
+	for id, territory := range w.Territories {
+		territories = append(territories, fmt.Sprintf("%v:%+v", id, *territory))
+	}
+	caches := make([]string, 0, len(w.Caches))
+	for id, owner := range w.Caches {
+		caches = append(caches, fmt.Sprintf("%v:%v", id, owner))
+	}
+	for _, entries := range [][]string{homes, obstacles, territories, caches} {
+		sort.Strings(entries)
+		for _, entry := range entries {
+			fmt.Fprintf(h, "%v;", entry)
+		}
+	}
+	eggs := make([]string, 0, len(w.Eggs))
+	for id := range w.Eggs {
+		eggs = append(eggs, id)
+	}
+	sort.Strings(eggs)
+	for _, id := range eggs {
+		fmt.Fprintf(h, "%v:%v:", id, w.Eggs[id].Incubation)
+		hashBeing(h, w.Eggs[id].Embryo)
+	}
+}
+
+// hashTerrain writes every spot and the colored terrain into the hash
+func (w *RandomWorld) hashTerrain(h hash.Hash) {
+	// The surface, 3 UUIDs, 5 floats and a flag per spot
+	buf := make([]byte, 1+3*16+5*8+1)
+	for x := range w.TerrainSpots {
+		for _, spot := range w.TerrainSpots[x] {
+			buf[0] = byte(surfaceIndex(spot.Surface.ID))
+			copy(buf[1:], spot.Object[:])
+			copy(buf[17:], spot.Being[:])
+			copy(buf[33:], spot.OccupyingPlant[:])
+			for i, value := range []float64{spot.PreyScent, spot.PredatorScent, spot.Nutrients, spot.Depth, spot.Puddle} {
+				binary.LittleEndian.PutUint64(buf[49+i*8:], math.Float64bits(value))
+			}
+			buf[len(buf)-1] = 0
+			if spot.Ford {
+				buf[len(buf)-1] = 1
+			}
+			h.Write(buf)
+		}
+	}
+	h.Write(w.TerrainZones.Pix)
+}
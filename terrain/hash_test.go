@@ -0,0 +1,79 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"sort"
+	"testing"
+)
+
+// hashWorld returns a small world created from the seed with beings of several types and plants on it
+func hashWorld(t *testing.T, seed int64) *RandomWorld {
+	t.Helper()
+	LogDeaths = false
+	w, err := NewRandomWorld(100, 100, WithSeed(seed), WithTerrainImage(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, create := range []func(int) (int, error){w.CreateCarnivores, w.CreateFlyers, w.CreateFishies,
+		w.CreateScavengers} {
+		if _, err := create(8); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.ProvideFood(10, 2); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// differentParts returns the names of the parts of the world state that differ between the worlds
+func differentParts(a, b *RandomWorld) []string {
+	partsA, partsB := a.HashParts(), b.HashParts()
+	var differ []string
+	for name, digest := range partsA {
+		if partsB[name] != digest {
+			differ = append(differ, name)
+		}
+	}
+	sort.Strings(differ)
+	return differ
+}
+
+// TestHashSameSeed checks that two worlds created from the same seed stay identical tick after tick
+func TestHashSameSeed(t *testing.T) {
+	a, b := hashWorld(t, 42), hashWorld(t, 42)
+	if a.Hash() != b.Hash() {
+		t.Fatalf("the worlds differ when created in %v", differentParts(a, b))
+	}
+	for tick := 1; tick <= 50; tick++ {
+		a.Step()
+		b.Step()
+		if a.Hash() != b.Hash() {
+			t.Fatalf("the worlds differ after tick %d in %v", tick, differentParts(a, b))
+		}
+	}
+}
+
+// TestHashMovedBeing checks that moving a single being changes the hash (and only the parts holding beings)
+func TestHashMovedBeing(t *testing.T) {
+	a, b := hashWorld(t, 42), hashWorld(t, 42)
+	for _, being := range b.BeingList {
+		for _, direction := range directions8 {
+			to := GoWorld.Location{X: being.Position.X + direction.X, Y: being.Position.Y + direction.Y}
+			if b.IsOutOfBounds(to) || !b.canPlaceBeing(to, being.Type) {
+				continue
+			}
+			if err := b.TeleportBeing(being.ID, to); err != nil {
+				t.Fatal(err)
+			}
+			if a.Hash() == b.Hash() {
+				t.Fatalf("the hash did not change when being %v moved to %v", being.ID, to)
+			}
+			if differ := differentParts(a, b); len(differ) != 2 || differ[0] != "Beings" || differ[1] != "Terrain" {
+				t.Errorf("moving a being changed %v, want [Beings Terrain]", differ)
+			}
+			return
+		}
+	}
+	t.Fatal("no being could be moved")
+}
@@ -0,0 +1,98 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"image/color"
+)
+
+var (
+	// HomeKinds defines which being types build homes and what they build (adults of these types sleep and raise
+	// their young only at home)
+	HomeKinds = map[string]string{
+		"Carnivore": "Den",
+		"Scavenger": "Burrow",
+		"Amphibian": "Burrow",
+		"Flying":    "Nest",
+		"Water":     "Nest",
+	}
+	// How close to its home a being feels safe
+	homeRadius = 6.
+	// The share of stress a being still feels near its home
+	homeStress = 0.5
+	// Homes are painted onto the terrain in brown
+	homeColor = color.RGBA{R: 121, G: 85, B: 58, A: 255}
+)
+
+// Home is a nest, burrow or den a being built in its habitat
+type Home struct {
+	ID       uuid.UUID        // Identifier
+	Owner    uuid.UUID        // The being that built the home
+	Kind     string           // What the home is (e.g. Burrow)
+	Position GoWorld.Location // Where the home was built
+}
+
+// needsHome checks if the being has to have a home to sleep and raise its young (juveniles sleep anywhere)
+func needsHome(b *GoWorld.Being) bool {
+	_, builds := HomeKinds[b.Type]
+	return builds && !isJuvenile(b)
+}
+
+// homeOf returns the home of the being (nil if it has none)
+func (w *RandomWorld) homeOf(b *GoWorld.Being) *Home {
+	if b.Home == uuid.Nil {
+		return nil
+	}
+	return w.Homes[b.Home.String()]
+}
+
+// nearHome checks if the being is close enough to its home to feel safe
+func (w *RandomWorld) nearHome(b *GoWorld.Being) bool {
+	home := w.homeOf(b)
+	return home != nil && w.Distance(b.Position, home.Position) <= homeRadius
+}
+
+// canBuildHome checks if the being can build its home on the spot: it has to be in its habitat and free of objects
+// and plants
+func (w *RandomWorld) canBuildHome(b *GoWorld.Being, location GoWorld.Location) bool {
+	spot := w.TerrainSpots[location.X][location.Y]
+	return spot.Surface.ID == b.Habitat && spot.Object == uuid.Nil && spot.OccupyingPlant == uuid.Nil
+}
+
+// homeSpotFor returns the closest spot among the surroundings where the being could build its home
+// Returns false if there is no such spot in sight
+func (w *RandomWorld) homeSpotFor(b *GoWorld.Being, surroundings []GoWorld.Location) (GoWorld.Location, bool) {
+	if w.canBuildHome(b, b.Position) {
+		return b.Position, true
+	}
+	return w.nearest(b.Position, surroundings, func(spot GoWorld.Location) bool {
+		return w.canBuildHome(b, spot) && w.canPlaceBeing(spot, b.Type)
+	})
+}
+
+// BuildHome builds the home of the being on its current spot (stored as the spot object, plants can not grow over it)
+// Returns the home or nil if the being already has one or can not build it here
+func (w *RandomWorld) BuildHome(b *GoWorld.Being) *Home {
+	kind, builds := HomeKinds[b.Type]
+	if !builds || w.homeOf(b) != nil || !w.canBuildHome(b, b.Position) {
+		return nil
+	}
+	home := &Home{ID: w.newID(), Owner: b.ID, Kind: kind, Position: b.Position}
+	w.Homes[home.ID.String()] = home
+	w.updatePlantSpot(home.Position.X, home.Position.Y, 1, home.ID)
+	w.TerrainZones.Set(home.Position.X, home.Position.Y, homeColor)
+	b.Home = home.ID
+	return home
+}
+
+// abandonHome removes the home of the being (e.g. when it dies)
+func (w *RandomWorld) abandonHome(b *GoWorld.Being) {
+	home := w.homeOf(b)
+	if home == nil {
+		return
+	}
+	delete(w.Homes, home.ID.String())
+	w.updatePlantSpot(home.Position.X, home.Position.Y, 1, uuid.Nil)
+	w.TerrainZones.Set(home.Position.X, home.Position.Y, w.TerrainSpots[home.Position.X][home.Position.Y].Surface.Color)
+	b.Home = uuid.Nil
+}
@@ -0,0 +1,81 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/rubinda/GoWorld"
+	"sort"
+)
+
+// How far from the map edges (in spots) immigrating beings arrive
+var immigrationMargin = 4
+
+// Immigration brings a trickle of new random beings in from the map edges while the population of their type is
+// below its floor, so long runs do not end in total extinction before anything interesting happens
+type Immigration struct {
+	Floors   map[string]int // The smallest population of each being type without immigration (Type: floor)
+	Interval uint64         // How many epochs pass between two arrivals of the same type (every epoch if 0)
+	Arrivals int            // How many beings arrive at once (1 if 0)
+}
+
+// WithImmigration lets new beings arrive at the map edges while a population is below its floor (see Immigration)
+func WithImmigration(immigration Immigration) Option {
+	return func(s *Settings) {
+		s.Immigration = immigration
+	}
+}
+
+// validate checks if all the floors are for known being types and not negative
+func (i Immigration) validate() error {
+	for beingType, floor := range i.Floors {
+		if !KnownType(beingType) {
+			return fmt.Errorf("error applying world settings: unknown being type %v for immigration", beingType)
+		}
+		if floor < 0 {
+			return fmt.Errorf("error applying world settings: negative immigration floor %d for %v", floor,
+				beingType)
+		}
+	}
+	return nil
+}
+
+// immigrate brings new beings in from the map edges for every type below its floor (once every interval)
+func (w *RandomWorld) immigrate() {
+	immigration := w.Settings.Immigration
+	if len(immigration.Floors) == 0 {
+		return
+	}
+	if immigration.Interval > 1 && w.Epoch%immigration.Interval != 0 {
+		return
+	}
+	populations := w.Census()
+	// Go through the types in the same order every time, so seeded worlds stay the same
+	types := make([]string, 0, len(immigration.Floors))
+	for beingType := range immigration.Floors {
+		types = append(types, beingType)
+	}
+	sort.Strings(types)
+	for _, beingType := range types {
+		missing := immigration.Floors[beingType] - populations[beingType]
+		if missing <= 0 {
+			continue
+		}
+		arrivals := immigration.Arrivals
+		if arrivals <= 0 {
+			arrivals = 1
+		}
+		if arrivals > missing {
+			arrivals = missing
+		}
+		// Beings that find no room at the edges stay away, they may arrive later
+		ids, _ := w.spawn(beingType, arrivals, spawnSurface(beingType), w.nearEdge)
+		if len(ids) > 0 {
+			w.emit("immigrated", ids...)
+		}
+	}
+}
+
+// nearEdge checks if the spot is within the immigration margin of the map edges
+func (w *RandomWorld) nearEdge(spot GoWorld.Location) bool {
+	return spot.X < immigrationMargin || spot.Y < immigrationMargin || spot.X >= w.Width-immigrationMargin ||
+		spot.Y >= w.Height-immigrationMargin
+}
@@ -0,0 +1,99 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// Attribute ranges for insects (tiny beings with short lives that grow up and breed fast)
+	insectSizeRange           = &attributeRange{Min: 0, Max: 4}
+	insectFertilityRange      = &attributeRange{Min: 1, Max: 4}
+	insectMaturityRange       = &attributeRange{Min: 2, Max: 6}
+	insectLifeExpectancyRange = &attributeRange{Min: 8, Max: 24}
+	// How much faster the wish for offspring grows for insects
+	insectBreedingRate = 1.5
+	// Insects only feed on plants up to this growth stage (the flowering ones)
+	insectFoodStage = 1.
+	// The share of the plant nutritional value an insect gets from a visit (the plant is not eaten)
+	nectarShare = 0.25
+	// How many seeds a plant gains for every visit of an insect
+	pollinationSeeds = 1.
+)
+
+// flies checks if beings of the type fly (they can move anywhere and do not care about the surface)
+func flies(beingType string) bool {
+	return beingType == "Flying" || beingType == "Insect"
+}
+
+// sizeRangeFor returns the size range for the being type
+func (w *RandomWorld) sizeRangeFor(beingType string) *attributeRange {
+	if beingType == "Insect" {
+		return w.rangeOf("InsectSize")
+	}
+	return w.rangeOf("Size")
+}
+
+// fertilityRangeFor returns the fertility range for the being type
+func (w *RandomWorld) fertilityRangeFor(beingType string) *attributeRange {
+	if beingType == "Insect" {
+		return w.rangeOf("InsectFertility")
+	}
+	return w.rangeOf("Fertility")
+}
+
+// maturityRangeFor returns the maturity age range for the being type
+func (w *RandomWorld) maturityRangeFor(beingType string) *attributeRange {
+	if beingType == "Insect" {
+		return w.rangeOf("InsectMaturity")
+	}
+	return w.rangeOf("Maturity")
+}
+
+// lifeExpectancyRangeFor returns the life expectancy range for the being type
+func (w *RandomWorld) lifeExpectancyRangeFor(beingType string) *attributeRange {
+	if beingType == "Insect" {
+		return w.rangeOf("InsectLifeExpectancy")
+	}
+	return w.rangeOf("LifeExpectancy")
+}
+
+// breedingRateFor returns how fast the wish for offspring grows for the being type
+func breedingRateFor(beingType string) float64 {
+	if beingType == "Insect" {
+		return insectBreedingRate
+	}
+	return 1
+}
+
+// Pollinate lets the insect feed on the plant without eating it, the visit helps the plant produce more seeds
+func (w *RandomWorld) Pollinate(b *GoWorld.Being, plant *GoWorld.Food) {
+	hunger := b.Hunger
+	b.Hunger = math.Max(b.Hunger-plant.NutritionalValue*nectarShare, 0)
+	w.recordEnergy("Nectar", trophicLevel(b.Type), hunger-b.Hunger, hunger-b.Hunger)
+	plant.Seeds = math.Min(plant.Seeds+pollinationSeeds, seedRange.Max)
+}
+
+// CreateInsects generates random instances of tiny flying beings that pollinate plants
+func (w *RandomWorld) CreateInsects(quantity int) (int, error) {
+	return w.createBeings(quantity, w.CreateRandomInsect)
+}
+
+// CreateRandomInsect generates an instance of a tiny flying being that feeds on young plants
+func (w *RandomWorld) CreateRandomInsect() (*GoWorld.Being, error) {
+	// Start from a flying being and shrink it
+	being, err := w.CreateRandomFlyer()
+	if err != nil {
+		return nil, err
+	}
+	being.Type = "Insect"
+	being.Size = w.rangeOf("InsectSize").randomFloat(w.random)
+	being.Fertility = w.rangeOf("InsectFertility").randomFloat(w.random)
+	being.MaturityAge = w.rangeOf("InsectMaturity").randomFloat(w.random)
+	being.LifeExpectancy = w.rangeOf("InsectLifeExpectancy").randomFloat(w.random)
+	being.Nocturnal = w.random.Float64() < nocturnalChance[being.Type]
+	being.Age = w.random.Float64() * 2 * being.MaturityAge
+	// Insects live among the grass
+	being.Habitat = w.surfaces[1].ID
+	return being, nil
+}
@@ -0,0 +1,69 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+// memoryFor returns the remembered locations for the action (water for drinking, food for eating)
+func memoryFor(b *GoWorld.Being, action string) *[]GoWorld.Location {
+	if action == "drink" {
+		return &b.Memory.Water
+	}
+	return &b.Memory.Food
+}
+
+// remember stores the location for the action in the being memory
+// When the memory is full, the oldest location is forgotten
+func (w *RandomWorld) remember(b *GoWorld.Being, action string, location GoWorld.Location) {
+	capacity := int(b.MemorySize)
+	if capacity <= 0 {
+		return
+	}
+	memory := memoryFor(b, action)
+	for _, known := range *memory {
+		// Close enough to a known location, no need to remember it twice
+		if w.Distance(known, location) <= 1 {
+			return
+		}
+	}
+	*memory = append(*memory, location)
+	if len(*memory) > capacity {
+		*memory = (*memory)[len(*memory)-capacity:]
+	}
+}
+
+// recall returns the closest remembered location for the action that is out of sight
+// Locations in sight are forgotten, as the being can see there is nothing left for it there
+// Returns false if the being does not remember anything useful
+func (w *RandomWorld) recall(b *GoWorld.Being, action string, sight float64) (GoWorld.Location, bool) {
+	memory := memoryFor(b, action)
+	remembered := (*memory)[:0]
+	closest := GoWorld.Location{}
+	closestDist := math.Inf(1)
+	for _, location := range *memory {
+		dist := w.Distance(b.Position, location)
+		if dist <= sight {
+			continue
+		}
+		remembered = append(remembered, location)
+		if dist < closestDist {
+			closest = location
+			closestDist = dist
+		}
+	}
+	*memory = remembered
+	if len(remembered) == 0 {
+		return closest, false
+	}
+	if b.Type == "Carnivore" && !w.TerrainSpots[closest.X][closest.Y].Surface.Habitable {
+		// Carnivores can not walk into water, head to the shore next to it
+		for _, direction := range directions8 {
+			shore := GoWorld.Location{X: closest.X + direction.X, Y: closest.Y + direction.Y}
+			if !w.IsOutOfBounds(shore) && w.TerrainSpots[shore.X][shore.Y].Surface.Habitable {
+				return shore, true
+			}
+		}
+	}
+	return closest, true
+}
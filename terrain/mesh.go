@@ -0,0 +1,179 @@
+package terrain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// MeshOptions decide how the terrain is turned into a mesh (see ExportMesh)
+type MeshOptions struct {
+	Height float64 // How much higher the highest point is than the lowest (a tenth of the larger side if 0)
+	Step   int     // Use every Step-th spot as a vertex (every spot if 0), keeps the meshes of large worlds small
+}
+
+// mesh is a grid of vertices (a spot is one unit wide) with the triangles between them
+type mesh struct {
+	positions [][3]float32
+	colors    [][4]uint8
+	triangles [][3]uint32
+}
+
+// ExportMesh stores the heights of the terrain as a 3D mesh with the zone colors on its vertices (e.g. to inspect the
+// world in Blender), the extension of the name picks the format: .obj (Wavefront with vertex colors) or .gltf
+// Returns an error for other extensions or if the file can not be written
+func (w *RandomWorld) ExportMesh(fileName string, o MeshOptions) error {
+	var write func(m *mesh, out io.Writer) error
+	switch strings.ToLower(filepath.Ext(strings.TrimSuffix(fileName, ".gz"))) {
+	case ".obj":
+		write = writeOBJ
+	case ".gltf":
+		write = writeGLTF
+	default:
+		return fmt.Errorf("error exporting mesh %v: unknown format (.obj or .gltf)", fileName)
+	}
+	m := w.mesh(o)
+	return writeFile(fileName, func(out io.Writer) error {
+		return write(m, out)
+	})
+}
+
+// mesh builds the mesh of the terrain
+func (w *RandomWorld) mesh(o MeshOptions) *mesh {
+	step := o.Step
+	if step <= 0 {
+		step = 1
+	}
+	height := o.Height
+	if height <= 0 {
+		height = float64(w.Width) / 10
+		if w.Height > w.Width {
+			height = float64(w.Height) / 10
+		}
+	}
+	// The last row and column are always part of the grid, so the mesh covers the whole world
+	var xs, ys []int
+	for x := 0; x < w.Width; x += step {
+		xs = append(xs, x)
+	}
+	if xs[len(xs)-1] != w.Width-1 {
+		xs = append(xs, w.Width-1)
+	}
+	for y := 0; y < w.Height; y += step {
+		ys = append(ys, y)
+	}
+	if ys[len(ys)-1] != w.Height-1 {
+		ys = append(ys, w.Height-1)
+	}
+	// The heights are stretched between the lowest and the highest point (the noise rarely uses the whole range)
+	lowest, highest := uint8(255), uint8(0)
+	for _, y := range ys {
+		for _, x := range xs {
+			gray := w.TerrainImage.GrayAt(x, y).Y
+			if gray < lowest {
+				lowest = gray
+			}
+			if gray > highest {
+				highest = gray
+			}
+		}
+	}
+	span := math.Max(float64(highest)-float64(lowest), 1)
+	m := &mesh{}
+	for _, y := range ys {
+		for _, x := range xs {
+			h := (float64(w.TerrainImage.GrayAt(x, y).Y) - float64(lowest)) / span * height
+			c := w.TerrainZones.RGBAAt(x, y)
+			m.positions = append(m.positions, [3]float32{float32(x), float32(h), float32(y)})
+			m.colors = append(m.colors, [4]uint8{c.R, c.G, c.B, 255})
+		}
+	}
+	// Two triangles for every cell of the grid, wound so they face up
+	columns := uint32(len(xs))
+	for row := uint32(0); row+1 < uint32(len(ys)); row++ {
+		for column := uint32(0); column+1 < columns; column++ {
+			topLeft := row*columns + column
+			bottomLeft := topLeft + columns
+			m.triangles = append(m.triangles, [3]uint32{topLeft, bottomLeft, topLeft + 1},
+				[3]uint32{topLeft + 1, bottomLeft, bottomLeft + 1})
+		}
+	}
+	return m
+}
+
+// writeOBJ writes the mesh as a Wavefront OBJ (the colors follow the vertex positions, as Blender reads them)
+func writeOBJ(m *mesh, out io.Writer) error {
+	b := bufio.NewWriter(out)
+	fmt.Fprintln(b, "# GoWorld terrain")
+	for i, p := range m.positions {
+		c := m.colors[i]
+		fmt.Fprintf(b, "v %g %g %g %.3f %.3f %.3f\n", p[0], p[1], p[2], float64(c[0])/255, float64(c[1])/255,
+			float64(c[2])/255)
+	}
+	for _, t := range m.triangles {
+		// OBJ counts the vertices from 1
+		fmt.Fprintf(b, "f %d %d %d\n", t[0]+1, t[1]+1, t[2]+1)
+	}
+	return b.Flush()
+}
+
+// writeGLTF writes the mesh as glTF 2.0 JSON with the vertices and triangles embedded in it
+func writeGLTF(m *mesh, out io.Writer) error {
+	var buffer bytes.Buffer
+	min := [3]float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	max := [3]float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for _, p := range m.positions {
+		for i := range p {
+			min[i] = float32(math.Min(float64(min[i]), float64(p[i])))
+			max[i] = float32(math.Max(float64(max[i]), float64(p[i])))
+		}
+	}
+	// The positions, the colors and the indices follow each other in the buffer (all of them 4 byte aligned)
+	if err := binary.Write(&buffer, binary.LittleEndian, m.positions); err != nil {
+		return err
+	}
+	colorsAt := buffer.Len()
+	if err := binary.Write(&buffer, binary.LittleEndian, m.colors); err != nil {
+		return err
+	}
+	indicesAt := buffer.Len()
+	if err := binary.Write(&buffer, binary.LittleEndian, m.triangles); err != nil {
+		return err
+	}
+	type object = map[string]interface{}
+	gltf := object{
+		"asset":  object{"version": "2.0", "generator": "GoWorld"},
+		"scene":  0,
+		"scenes": []object{{"nodes": []int{0}}},
+		"nodes":  []object{{"mesh": 0, "name": "Terrain"}},
+		"meshes": []object{{"primitives": []object{{
+			"attributes": object{"POSITION": 0, "COLOR_0": 1},
+			"indices":    2,
+		}}}},
+		"buffers": []object{{
+			"byteLength": buffer.Len(),
+			"uri":        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()),
+		}},
+		// 34962 holds vertex attributes, 34963 indices
+		"bufferViews": []object{
+			{"buffer": 0, "byteOffset": 0, "byteLength": colorsAt, "target": 34962},
+			{"buffer": 0, "byteOffset": colorsAt, "byteLength": indicesAt - colorsAt, "target": 34962},
+			{"buffer": 0, "byteOffset": indicesAt, "byteLength": buffer.Len() - indicesAt, "target": 34963},
+		},
+		// 5126 are floats, 5121 unsigned bytes and 5125 unsigned ints
+		"accessors": []object{
+			{"bufferView": 0, "componentType": 5126, "count": len(m.positions), "type": "VEC3", "min": min,
+				"max": max},
+			{"bufferView": 1, "componentType": 5121, "normalized": true, "count": len(m.colors), "type": "VEC4"},
+			{"bufferView": 2, "componentType": 5125, "count": 3 * len(m.triangles), "type": "SCALAR"},
+		},
+	}
+	return json.NewEncoder(out).Encode(gltf)
+}
@@ -0,0 +1,101 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"unicode"
+)
+
+// NameEntity gives the being or food a name to follow it over the run (an empty name removes it), the name stays
+// known once the being or food is gone (see EntityName)
+// Returns an error if there is no being or food with the id
+func (w *RandomWorld) NameEntity(id uuid.UUID, name string) error {
+	name = strings.TrimSpace(name)
+	if b, ok := w.BeingList[id.String()]; ok {
+		b.Name = name
+	} else if f, ok := w.FoodList[id.String()]; ok {
+		f.Name = name
+	} else {
+		return fmt.Errorf("error naming entity: no being or food with id %v", id)
+	}
+	w.rememberName(id, name)
+	w.emit("named", id)
+	return nil
+}
+
+// TagEntity adds the tag to the being or food (nothing changes if it already has it), tags are compared without case
+// Returns an error if the tag is empty or holds spaces or if there is no being or food with the id
+func (w *RandomWorld) TagEntity(id uuid.UUID, tag string) error {
+	if tag == "" || strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("error tagging entity: the tag %q is empty or holds spaces", tag)
+	}
+	tags, err := w.entityTags(id)
+	if err != nil {
+		return fmt.Errorf("error tagging entity: %v", err)
+	}
+	for _, t := range *tags {
+		if strings.EqualFold(t, tag) {
+			return nil
+		}
+	}
+	// A new slice, the snapshots share the old one
+	*tags = append((*tags)[:len(*tags):len(*tags)], tag)
+	w.emit("tagged", id)
+	return nil
+}
+
+// UntagEntity removes the tag from the being or food (nothing changes if it does not have it)
+// Returns an error if there is no being or food with the id
+func (w *RandomWorld) UntagEntity(id uuid.UUID, tag string) error {
+	tags, err := w.entityTags(id)
+	if err != nil {
+		return fmt.Errorf("error untagging entity: %v", err)
+	}
+	var kept []string
+	for _, t := range *tags {
+		if !strings.EqualFold(t, tag) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(*tags) {
+		return nil
+	}
+	*tags = kept
+	w.emit("untagged", id)
+	return nil
+}
+
+// EntityName returns the name of the being or food, also of the ones that are gone (empty if it has none)
+func (w *RandomWorld) EntityName(id uuid.UUID) string {
+	if b, ok := w.BeingList[id.String()]; ok {
+		return b.Name
+	}
+	if f, ok := w.FoodList[id.String()]; ok {
+		return f.Name
+	}
+	return w.names[id]
+}
+
+// entityTags returns the tags of the being or food with the id
+func (w *RandomWorld) entityTags(id uuid.UUID) (*[]string, error) {
+	if b, ok := w.BeingList[id.String()]; ok {
+		return &b.Tags, nil
+	}
+	if f, ok := w.FoodList[id.String()]; ok {
+		return &f.Tags, nil
+	}
+	return nil, fmt.Errorf("no being or food with id %v", id)
+}
+
+// rememberName keeps the name of the being or food for when it is gone (only the named ones are kept)
+func (w *RandomWorld) rememberName(id uuid.UUID, name string) {
+	if name == "" {
+		delete(w.names, id)
+		return
+	}
+	if w.names == nil {
+		w.names = make(map[uuid.UUID]string)
+	}
+	w.names[id] = name
+}
@@ -0,0 +1,109 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+)
+
+// FindNearestWater returns the water spot (or puddle) closest to the location in the whole world
+// Returns false if there is no water in the world
+func (w *RandomWorld) FindNearestWater(from GoWorld.Location) (GoWorld.Location, bool) {
+	return w.searchNearest(from, w.hasWater)
+}
+
+// FindNearestFood returns the food closest to the location that a being of the diet (its type) can eat, any food if
+// the diet is empty (the one with the lower ID of equally close food)
+// Returns nil if there is no such food in the world
+func (w *RandomWorld) FindNearestFood(from GoWorld.Location, diet string) *GoWorld.Food {
+	eater := &GoWorld.Being{Type: diet}
+	var closest *GoWorld.Food
+	closestDist := 0.
+	for id, f := range w.FoodList {
+		if diet != "" && !canEatFood(eater, f) {
+			continue
+		}
+		dist := w.Distance(from, f.Position)
+		if closest == nil || dist < closestDist || dist == closestDist && id < closest.ID.String() {
+			closest, closestDist = f, dist
+		}
+	}
+	return closest
+}
+
+// FindNearestBeing returns the being closest to the location that the filter accepts (any being if the filter is
+// nil), the being standing on the location included (the filter can leave it out). The beings are looked up in the
+// being list, not all of them are on the map (e.g. fish and flyers)
+// Returns nil if no being in the world is accepted
+func (w *RandomWorld) FindNearestBeing(from GoWorld.Location, filter func(*GoWorld.Being) bool) *GoWorld.Being {
+	var closest *GoWorld.Being
+	closestDist := 0.
+	for id, b := range w.BeingList {
+		if filter != nil && !filter(b) {
+			continue
+		}
+		dist := w.Distance(from, b.Position)
+		if closest == nil || dist < closestDist || dist == closestDist && id < closest.ID.String() {
+			closest, closestDist = b, dist
+		}
+	}
+	return closest
+}
+
+// nearest returns the spot closest to the location among the spots that match (the first one of equally close spots)
+// Returns false if none of the spots match
+func (w *RandomWorld) nearest(from GoWorld.Location, spots []GoWorld.Location,
+	match func(GoWorld.Location) bool) (GoWorld.Location, bool) {
+	closest := GoWorld.Location{}
+	closestDist := 0.
+	found := false
+	for _, spot := range spots {
+		if !match(spot) {
+			continue
+		}
+		if dist := w.Distance(from, spot); !found || dist < closestDist {
+			closest, closestDist, found = spot, dist, true
+		}
+	}
+	return closest, found
+}
+
+// searchNearest returns the matching spot closest to the location in the whole world. The spots are searched in
+// square rings growing around the location, until the rings are further away than the closest match found
+// Returns false if no spot in the world matches
+func (w *RandomWorld) searchNearest(from GoWorld.Location, match func(GoWorld.Location) bool) (GoWorld.Location,
+	bool) {
+	// The ring that reaches the furthest corner of the world
+	last := 0
+	for _, d := range []int{from.X, w.Width - 1 - from.X, from.Y, w.Height - 1 - from.Y} {
+		if d < 0 {
+			d = -d
+		}
+		if d > last {
+			last = d
+		}
+	}
+	closest := GoWorld.Location{}
+	closestDist := 0.
+	found := false
+	visit := func(x, y int) {
+		spot := GoWorld.Location{X: x, Y: y}
+		if w.IsOutOfBounds(spot) || !match(spot) {
+			return
+		}
+		if dist := w.Distance(from, spot); !found || dist < closestDist {
+			closest, closestDist, found = spot, dist, true
+		}
+	}
+	visit(from.X, from.Y)
+	// Every spot of a ring is at least as far away as the ring
+	for r := 1; r <= last && (!found || closestDist > float64(r)); r++ {
+		for x := from.X - r; x <= from.X+r; x++ {
+			visit(x, from.Y-r)
+			visit(x, from.Y+r)
+		}
+		for y := from.Y - r + 1; y < from.Y+r; y++ {
+			visit(from.X-r, y)
+			visit(from.X+r, y)
+		}
+	}
+	return closest, found
+}
@@ -0,0 +1,126 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"image/color"
+)
+
+var (
+	// The share of forest spots covered by trees
+	treeDensity = 0.05
+	// The chance that a predator overlooks prey standing next to a tree
+	treeHidingChance = 0.3
+	// Trees are painted onto the terrain in a darker green than the forest
+	treeColor = color.RGBA{R: 24, G: 92, B: 32, A: 255}
+	// The share of grassland spots covered by boulders
+	boulderDensity = 0.003
+	// Boulders are painted onto the terrain in dark gray
+	boulderColor = color.RGBA{R: 105, G: 105, B: 105, A: 255}
+)
+
+// Obstacle is an inert object on the map that land beings can not move through
+type Obstacle struct {
+	ID       uuid.UUID        // Identifier
+	Kind     string           // What the obstacle is (e.g. Tree)
+	Position GoWorld.Location // Where the obstacle stands
+}
+
+// PlantTrees places trees onto the forest spots (stored as spot objects) and paints them onto the terrain
+func (w *RandomWorld) PlantTrees() {
+	for x := range w.TerrainSpots {
+		for y, spot := range w.TerrainSpots[x] {
+			if spot.Surface.CommonName != "Forest" || spot.Object != uuid.Nil || w.random.Float64() >= treeDensity {
+				continue
+			}
+			tree := &Obstacle{ID: w.newID(), Kind: "Tree", Position: GoWorld.Location{X: x, Y: y}}
+			w.Obstacles[tree.ID.String()] = tree
+			spot.Object = tree.ID
+			w.TerrainZones.Set(x, y, treeColor)
+		}
+	}
+}
+
+// ScatterBoulders places boulders onto the grassland spots (stored as spot objects) and paints them onto the terrain
+func (w *RandomWorld) ScatterBoulders() {
+	for x := range w.TerrainSpots {
+		for y, spot := range w.TerrainSpots[x] {
+			if spot.Surface.CommonName != "Grassland" || spot.Object != uuid.Nil || w.random.Float64() >= boulderDensity {
+				continue
+			}
+			boulder := &Obstacle{ID: w.newID(), Kind: "Boulder", Position: GoWorld.Location{X: x, Y: y}}
+			w.Obstacles[boulder.ID.String()] = boulder
+			spot.Object = boulder.ID
+			w.TerrainZones.Set(x, y, boulderColor)
+		}
+	}
+}
+
+// visibleSpots removes the spots hidden behind boulders (as seen from the location) from the provided spots
+func (w *RandomWorld) visibleSpots(from GoWorld.Location, spots []GoWorld.Location) []GoWorld.Location {
+	var boulders []GoWorld.Location
+	for _, spot := range spots {
+		if obstacle := w.obstacleAt(spot); obstacle != nil && obstacle.Kind == "Boulder" && spot != from {
+			boulders = append(boulders, spot)
+		}
+	}
+	if len(boulders) == 0 {
+		return spots
+	}
+	visible := make([]GoWorld.Location, 0, len(spots))
+	for _, spot := range spots {
+		hidden := false
+		for _, boulder := range boulders {
+			if blocksLine(from, spot, boulder) {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			visible = append(visible, spot)
+		}
+	}
+	return visible
+}
+
+// blocksLine checks if the obstacle stands on the line between the two locations (the locations themselves are not
+// blocked)
+func blocksLine(from, to, obstacle GoWorld.Location) bool {
+	dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+	ox, oy := float64(obstacle.X-from.X), float64(obstacle.Y-from.Y)
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 || obstacle == to {
+		return false
+	}
+	// How far along the line the obstacle is (0 at from, 1 at to)
+	along := (ox*dx + oy*dy) / lengthSq
+	if along <= 0 || along >= 1 {
+		return false
+	}
+	// The obstacle covers the spot it stands on, so it blocks lines passing closer than half a spot
+	cross := ox*dy - oy*dx
+	return cross*cross/lengthSq < 0.25
+}
+
+// obstacleAt returns the obstacle on the location (nil if there is none)
+func (w *RandomWorld) obstacleAt(location GoWorld.Location) *Obstacle {
+	id := w.TerrainSpots[location.X][location.Y].Object
+	if id == uuid.Nil {
+		return nil
+	}
+	return w.Obstacles[id.String()]
+}
+
+// hiddenByTrees checks if the being stands next to a tree
+func (w *RandomWorld) hiddenByTrees(b *GoWorld.Being) bool {
+	for _, direction := range directions8 {
+		spot := GoWorld.Location{X: b.Position.X + direction.X, Y: b.Position.Y + direction.Y}
+		if w.IsOutOfBounds(spot) {
+			continue
+		}
+		if obstacle := w.obstacleAt(spot); obstacle != nil && obstacle.Kind == "Tree" {
+			return true
+		}
+	}
+	return false
+}
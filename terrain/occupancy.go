@@ -0,0 +1,147 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+)
+
+// How many free spots are picked at random before all of them are searched for one that fits (spawning on crowded or
+// water-scarce maps)
+var freeSpotDraws = 64
+
+// spotSet holds locations in a slice for picking one at random and remembers where each of them is for removing it
+type spotSet struct {
+	spots []GoWorld.Location
+	at    map[GoWorld.Location]int
+}
+
+// add puts the location into the set (if it is not in it yet)
+func (s *spotSet) add(location GoWorld.Location) {
+	if _, in := s.at[location]; in {
+		return
+	}
+	s.at[location] = len(s.spots)
+	s.spots = append(s.spots, location)
+}
+
+// remove takes the location out of the set, the last location takes its place
+func (s *spotSet) remove(location GoWorld.Location) {
+	i, in := s.at[location]
+	if !in {
+		return
+	}
+	last := s.spots[len(s.spots)-1]
+	s.spots[i] = last
+	s.at[last] = i
+	s.spots = s.spots[:len(s.spots)-1]
+	delete(s.at, location)
+}
+
+// indexFreeSpots sorts every spot without a being by its surface (see freeSpot)
+func (w *RandomWorld) indexFreeSpots() {
+	w.freeSpots = make(map[uuid.UUID]*spotSet, len(w.surfaces))
+	for i := range w.surfaces {
+		w.freeSpots[w.surfaces[i].ID] = &spotSet{at: make(map[GoWorld.Location]int)}
+	}
+	for x := range w.TerrainSpots {
+		for y := range w.TerrainSpots[x] {
+			w.indexSpot(GoWorld.Location{X: x, Y: y})
+		}
+	}
+}
+
+// indexSpot adds the location to the free spots of its surface if there is no being on it
+func (w *RandomWorld) indexSpot(location GoWorld.Location) {
+	spot := w.TerrainSpots[location.X][location.Y]
+	if w.freeSpots == nil || spot.Surface == nil || spot.Being != uuid.Nil {
+		return
+	}
+	if free := w.freeSpots[spot.Surface.ID]; free != nil {
+		free.add(location)
+	}
+}
+
+// unindexSpot takes the location out of the free spots of its surface
+func (w *RandomWorld) unindexSpot(location GoWorld.Location) {
+	spot := w.TerrainSpots[location.X][location.Y]
+	if w.freeSpots == nil || spot.Surface == nil {
+		return
+	}
+	if free := w.freeSpots[spot.Surface.ID]; free != nil {
+		free.remove(location)
+	}
+}
+
+// setBeingAt puts the being with the id onto the location (uuid.Nil frees it) and keeps the free spots up to date
+func (w *RandomWorld) setBeingAt(location GoWorld.Location, id uuid.UUID) {
+	w.unindexSpot(location)
+	w.TerrainSpots[location.X][location.Y].Being = id
+	w.indexSpot(location)
+}
+
+// freeSpot picks a random spot without a being on one of the surfaces that fits. A few spots are drawn at random
+// first, if none of them fits every free spot on the surfaces is tried (in random order)
+// Returns false only if no free spot on the surfaces fits
+func (w *RandomWorld) freeSpot(onSurface func(s *Surface) bool, fits func(spot GoWorld.Location) bool) (
+	GoWorld.Location, bool) {
+	if w.freeSpots == nil {
+		w.indexFreeSpots()
+	}
+	// Go through the surfaces in their order (not the map order), so seeded worlds always look the same
+	var sets []*spotSet
+	total := 0
+	for i := range w.surfaces {
+		if onSurface(&w.surfaces[i]) {
+			sets = append(sets, w.freeSpots[w.surfaces[i].ID])
+			total += len(w.freeSpots[w.surfaces[i].ID].spots)
+		}
+	}
+	if total == 0 {
+		return GoWorld.Location{}, false
+	}
+	for i := 0; i < freeSpotDraws; i++ {
+		n := w.random.Intn(total)
+		for _, set := range sets {
+			if n < len(set.spots) {
+				if fits(set.spots[n]) {
+					return set.spots[n], true
+				}
+				break
+			}
+			n -= len(set.spots)
+		}
+	}
+	candidates := make([]GoWorld.Location, 0, total)
+	for _, set := range sets {
+		candidates = append(candidates, set.spots...)
+	}
+	for n := len(candidates); n > 0; n-- {
+		i := w.random.Intn(n)
+		if fits(candidates[i]) {
+			return candidates[i], true
+		}
+		candidates[i] = candidates[n-1]
+	}
+	return GoWorld.Location{}, false
+}
+
+// anySurface lets beings spawn on every surface (flying beings)
+func anySurface(*Surface) bool {
+	return true
+}
+
+// waterSurface lets beings spawn only in water
+func waterSurface(s *Surface) bool {
+	return s.CommonName == "Water"
+}
+
+// plantSurface lets land plants grow only on the surfaces they can grow on
+func plantSurface(s *Surface) bool {
+	return s.Habitable
+}
+
+// walkableSurface lets beings spawn on the surfaces they can walk on, water is included for fords, wading and
+// amphibians (the spot check decides)
+func walkableSurface(s *Surface) bool {
+	return s.Habitable || s.CommonName == "Water"
+}
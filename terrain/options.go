@@ -0,0 +1,349 @@
+package terrain
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"image"
+	"math/rand"
+	"time"
+)
+
+var (
+	// The default share of the terrain covered by each surface (in the order of Surfaces)
+	defaultZoneRatios = []float64{0.20, 0.50, 0.10, 0.15, 0.025, 0.025}
+	// How far the sum of the zone ratios can be from 1 (it is rarely exact because of the floating point precision)
+	zoneRatiosPrecision = 1e-9
+	// The attribute ranges used for random beings and plants (and for the values inherited by their offspring), see
+	// WithBeingRange and WithPlantRange
+	defaultRanges = map[string]*attributeRange{
+		"Hunger":               hungerRange,
+		"Thirst":               thirstRange,
+		"WantsChild":           wantsChildRange,
+		"LifeExpectancy":       lifeExpectancyRange,
+		"Vision":               visionRange,
+		"Speed":                speedRange,
+		"Durability":           durabilityRange,
+		"Stress":               stressRange,
+		"Size":                 sizeRange,
+		"Fertility":            fertilityRange,
+		"Mutation":             mutationRange,
+		"Maturity":             maturityRange,
+		"Energy":               energyRange,
+		"Sleepiness":           sleepinessRange,
+		"Camouflage":           camouflageRange,
+		"MemorySize":           memorySizeRange,
+		"Resistance":           resistanceRange,
+		"InsectSize":           insectSizeRange,
+		"InsectFertility":      insectFertilityRange,
+		"InsectMaturity":       insectMaturityRange,
+		"InsectLifeExpectancy": insectLifeExpectancyRange,
+		"Growth":               growthRange,
+		"Nutrition":            nutritionRange,
+		"Taste":                tasteRange,
+		"Stage":                stageRange,
+		"StageProgress":        stageProgressRange,
+		"Area":                 areaRange,
+		"Seeds":                seedRange,
+		"Wither":               witherRange,
+		"Disperse":             disperseRange,
+		"Toxicity":             toxicityRange,
+	}
+)
+
+// Settings configure a world, unset (zero) values are replaced by the defaults when the world is created
+type Settings struct {
+	Seed               int64     // Seeds the random generator and the terrain (0 seeds from the clock, default terrain)
+	HungerThreshold    float64   // Beings with hunger (or thirst) above it are starving and take more risks
+	StressThreshold    float64   // Beings with stress above it flee to their habitat
+	HungerIncrease     float64   // How much the hunger grows every epoch
+	ThirstIncrease     float64   // How much the thirst grows every epoch
+	WantsChildIncrease float64   // How much the wish for offspring grows every epoch
+	SleepinessIncrease float64   // How much the need for sleep grows every epoch
+	ZoneRatios         []float64 // The share of the terrain covered by each surface (in the order of Surfaces)
+	// The terrain heights to use instead of generated noise (of the world size, darker is lower)
+	Heightmap *image.Gray
+	// Where the colored terrain is stored as PNG once it is generated (terrain.png if empty, see WithTerrainImage)
+	TerrainImagePath string
+	NoTerrainImage   bool // Do not store the colored terrain at all
+	// New beings arriving at the map edges while a population is low (none if it has no floors, see WithImmigration)
+	Immigration Immigration
+	// Plants only produce viable seeds with another plant of their type nearby (see WithPollination)
+	RequirePollination bool
+	// The being types that hibernate during winter (see WithHibernation)
+	HibernatingTypes map[string]bool
+	// How the attributes of each being type change with age (Type: attribute name: curve, see WithAgeCurve)
+	AgeCurves map[string]map[string]AgeCurve
+	// The being types that move in groups while wandering and how they steer (see WithSteering)
+	SteeringModes map[string]SteeringWeights
+	// Attribute ranges that replace the default ones (attribute name: range)
+	ranges map[string]*attributeRange
+	// Changes to the predefined surfaces for this world (see WithSurfaces)
+	surfaces []SurfaceConfig
+	// Told how far the terrain generation got, it stops once the context is cancelled (see WithProgress)
+	progress Progress
+	ctx      context.Context
+}
+
+// Option changes the settings of a world created with NewRandomWorld
+type Option func(s *Settings)
+
+// NewRandomWorld creates a world of the size with the options applied and generates its terrain
+func NewRandomWorld(width, height int, opts ...Option) (*RandomWorld, error) {
+	w := &RandomWorld{Width: width, Height: height}
+	for _, opt := range opts {
+		opt(&w.Settings)
+	}
+	if err := w.New(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// WithSeed makes the world (terrain, randomness and IDs) the same on every run with the same seed, whatever other
+// worlds in the process do
+func WithSeed(seed int64) Option {
+	return func(s *Settings) {
+		s.Seed = seed
+	}
+}
+
+// WithThresholds sets the hunger and stress thresholds of beings
+func WithThresholds(hunger, stress float64) Option {
+	return func(s *Settings) {
+		s.HungerThreshold = hunger
+		s.StressThreshold = stress
+	}
+}
+
+// WithNeedIncrements sets how much the needs of beings grow every epoch
+func WithNeedIncrements(hunger, thirst, wantsChild, sleepiness float64) Option {
+	return func(s *Settings) {
+		s.HungerIncrease = hunger
+		s.ThirstIncrease = thirst
+		s.WantsChildIncrease = wantsChild
+		s.SleepinessIncrease = sleepiness
+	}
+}
+
+// WithZoneRatios sets the share of the terrain covered by each surface (in the order of Surfaces)
+func WithZoneRatios(ratios ...float64) Option {
+	return func(s *Settings) {
+		s.ZoneRatios = ratios
+	}
+}
+
+// WithSurfaces changes the colors and habitability of the surfaces of the world (in the order of Surfaces), the
+// predefined Surfaces and other worlds keep theirs
+func WithSurfaces(changes ...SurfaceConfig) Option {
+	return func(s *Settings) {
+		s.surfaces = changes
+	}
+}
+
+// WithPollination makes plants produce viable seeds only when another plant of the same type grows within the
+// pollination radius (isolated plants can not reproduce, plants thrive in groves)
+func WithPollination(required bool) Option {
+	return func(s *Settings) {
+		s.RequirePollination = required
+	}
+}
+
+// WithHibernation sets the being types that retreat to their habitat and hibernate during winter (none if no types
+// are given, carnivores by default)
+func WithHibernation(beingTypes ...string) Option {
+	return func(s *Settings) {
+		s.HibernatingTypes = make(map[string]bool, len(beingTypes))
+		for _, beingType := range beingTypes {
+			s.HibernatingTypes[beingType] = true
+		}
+	}
+}
+
+// WithAgeCurve sets how an attribute (Speed or VisionRange) of the being type changes with age, the other curves keep
+// their defaults
+func WithAgeCurve(beingType, attribute string, curve AgeCurve) Option {
+	return func(s *Settings) {
+		if s.AgeCurves == nil {
+			s.AgeCurves = make(map[string]map[string]AgeCurve, len(defaultAgeCurves))
+			for t, curves := range defaultAgeCurves {
+				s.AgeCurves[t] = make(map[string]AgeCurve, len(curves))
+				for a, c := range curves {
+					s.AgeCurves[t][a] = c
+				}
+			}
+		}
+		if s.AgeCurves[beingType] == nil {
+			s.AgeCurves[beingType] = make(map[string]AgeCurve)
+		}
+		s.AgeCurves[beingType][attribute] = curve
+	}
+}
+
+// WithSteering makes the beings of the type move in a group while wandering with the weights (see SteeringWeights),
+// the other types keep their defaults
+func WithSteering(beingType string, weights SteeringWeights) Option {
+	return func(s *Settings) {
+		if s.SteeringModes == nil {
+			s.SteeringModes = make(map[string]SteeringWeights, len(defaultSteeringModes))
+			for t, mode := range defaultSteeringModes {
+				s.SteeringModes[t] = mode
+			}
+		}
+		s.SteeringModes[beingType] = weights
+	}
+}
+
+// WithHeightmap makes the terrain follow the heightmap (darker is lower) instead of the generated noise
+func WithHeightmap(heightmap *image.Gray) Option {
+	return func(s *Settings) {
+		s.Heightmap = heightmap
+	}
+}
+
+// WithTerrainImage stores the colored terrain as PNG at the path once it is generated (an empty path stores nothing)
+func WithTerrainImage(path string) Option {
+	return func(s *Settings) {
+		s.TerrainImagePath = path
+		s.NoTerrainImage = path == ""
+	}
+}
+
+// WithBeingRange sets the range of a being attribute (e.g. Speed, see defaultRanges for the names)
+func WithBeingRange(attribute string, min, max float64) Option {
+	return withRange(attribute, min, max)
+}
+
+// WithPlantRange sets the range of a plant attribute (e.g. Nutrition, see defaultRanges for the names)
+func WithPlantRange(attribute string, min, max float64) Option {
+	return withRange(attribute, min, max)
+}
+
+// WithDistribution sets how the random values of an attribute spread across its range (e.g. Distribution{Kind:
+// "Normal", Mean: 8, StdDev: 2} for Speed)
+func WithDistribution(attribute string, d Distribution) Option {
+	return func(s *Settings) {
+		s.rangeFor(attribute).Distribution = d
+	}
+}
+
+// withRange replaces the default range of the attribute (keeping its distribution)
+func withRange(attribute string, min, max float64) Option {
+	return func(s *Settings) {
+		r := s.rangeFor(attribute)
+		r.Min, r.Max = min, max
+	}
+}
+
+// rangeFor returns the range of the attribute set for the world, starting from a copy of the default one
+func (s *Settings) rangeFor(attribute string) *attributeRange {
+	if s.ranges == nil {
+		s.ranges = make(map[string]*attributeRange)
+	}
+	if _, ok := s.ranges[attribute]; !ok {
+		r := attributeRange{}
+		if defaultRange, known := defaultRanges[attribute]; known {
+			r = *defaultRange
+		}
+		s.ranges[attribute] = &r
+	}
+	return s.ranges[attribute]
+}
+
+// applySettings fills the unset settings with the defaults, validates them and seeds the random generator
+func (w *RandomWorld) applySettings() error {
+	s := &w.Settings
+	if s.HungerThreshold == 0 {
+		s.HungerThreshold = hungerThreshold
+	}
+	if s.StressThreshold == 0 {
+		s.StressThreshold = stressThreshold
+	}
+	if s.HungerIncrease == 0 {
+		s.HungerIncrease = hungerIncrease
+	}
+	if s.ThirstIncrease == 0 {
+		s.ThirstIncrease = thirstIncrease
+	}
+	if s.WantsChildIncrease == 0 {
+		s.WantsChildIncrease = wantsChildIncrease
+	}
+	if s.SleepinessIncrease == 0 {
+		s.SleepinessIncrease = sleepinessIncrease
+	}
+	if s.TerrainImagePath == "" {
+		s.TerrainImagePath = "terrain.png"
+	}
+	if len(s.ZoneRatios) == 0 {
+		s.ZoneRatios = defaultZoneRatios
+	}
+	if s.HibernatingTypes == nil {
+		s.HibernatingTypes = defaultHibernatingTypes
+	}
+	if s.AgeCurves == nil {
+		s.AgeCurves = defaultAgeCurves
+	}
+	if s.SteeringModes == nil {
+		s.SteeringModes = defaultSteeringModes
+	}
+	if len(s.ZoneRatios) != len(Surfaces) {
+		return fmt.Errorf("error applying world settings: %d zone ratios given for %d surfaces", len(s.ZoneRatios),
+			len(Surfaces))
+	}
+	if err := checkZoneRatios(s.ZoneRatios); err != nil {
+		return fmt.Errorf("error applying world settings: %v", err)
+	}
+	surfaces, err := surfacesWith(s.surfaces)
+	if err != nil {
+		return err
+	}
+	w.surfaces = surfaces
+	for attribute, r := range s.ranges {
+		if _, known := defaultRanges[attribute]; !known {
+			return fmt.Errorf("error applying world settings: unknown attribute %v", attribute)
+		}
+		if r.Min > r.Max {
+			return fmt.Errorf("error applying world settings: the range of %v is empty (%v > %v)", attribute, r.Min,
+				r.Max)
+		}
+		if !r.Distribution.known() {
+			return fmt.Errorf("error applying world settings: unknown distribution %v for %v", r.Distribution.Kind,
+				attribute)
+		}
+		if r.Distribution.StdDev < 0 {
+			return fmt.Errorf("error applying world settings: negative standard deviation for %v", attribute)
+		}
+	}
+	if err := s.Immigration.validate(); err != nil {
+		return err
+	}
+	w.seedRandom(s.Seed)
+	return nil
+}
+
+// seedRandom gives the world its own random numbers and IDs of new objects (both are truly random if the seed is 0),
+// so worlds in the same process do not draw from each other's stream
+func (w *RandomWorld) seedRandom(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	w.random = rand.New(rand.NewSource(seed))
+}
+
+// newID returns a random (version 4) UUID drawn from the world random numbers, so seeded worlds give their objects
+// the same IDs on every run (see Hash)
+func (w *RandomWorld) newID() uuid.UUID {
+	var id uuid.UUID
+	w.random.Read(id[:])
+	id[6] = (id[6] & 0x0f) | 0x40 // Version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // Variant 10
+	return id
+}
+
+// rangeOf returns the range of the attribute (the one set for this world or the default one)
+func (w *RandomWorld) rangeOf(attribute string) *attributeRange {
+	if r, ok := w.Settings.ranges[attribute]; ok {
+		return r
+	}
+	return defaultRanges[attribute]
+}
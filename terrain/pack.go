@@ -0,0 +1,90 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// Pack members closer than this to the prey take part in the attack
+	packAttackRange = 3.
+)
+
+// packPreyFor returns the prey that a member of the being's pack is already hunting, if it is within sight
+// Returns nil if no pack member in the surroundings is hunting visible prey
+func (w *RandomWorld) packPreyFor(b *GoWorld.Being, surroundings []GoWorld.Location, sight float64) *GoWorld.Being {
+	for _, member := range w.groupMembers(b, surroundings) {
+		if member.Target == uuid.Nil {
+			continue
+		}
+		prey := w.BeingList[member.Target.String()]
+		if prey != nil && w.Distance(b.Position, prey.Position) <= sight {
+			return prey
+		}
+	}
+	return nil
+}
+
+// packStrength returns the combined size of the being and its pack members in the surroundings
+// A pack can take down prey up to its combined size, while a lone hunter only manages prey up to its own size
+func (w *RandomWorld) packStrength(b *GoWorld.Being, surroundings []GoWorld.Location) float64 {
+	strength := bodySize(b)
+	for _, member := range w.groupMembers(b, surroundings) {
+		strength += bodySize(member)
+	}
+	return strength
+}
+
+// packCanOverpower checks if the being and the pack members close to its target are strong enough to take it down
+func (w *RandomWorld) packCanOverpower(b *GoWorld.Being) bool {
+	prey := w.BeingList[b.Target.String()]
+	if prey == nil {
+		return false
+	}
+	strength := bodySize(b)
+	closeBy := w.MidpointCircleAt(prey.Position, packAttackRange)
+	for _, member := range w.groupMembers(b, closeBy) {
+		if member.Target == b.Target {
+			strength += bodySize(member)
+		}
+	}
+	return bodySize(prey) <= strength
+}
+
+// flankSpotFor returns a free spot next to the prey that is as far as possible from the other pack members hunting
+// it, so the pack surrounds the prey instead of following each other. Returns the prey location if no spot is free
+func (w *RandomWorld) flankSpotFor(b *GoWorld.Being, preySpot GoWorld.Location) GoWorld.Location {
+	var hunters []*GoWorld.Being
+	for _, spot := range w.MidpointCircleAt(preySpot, w.currentVision(b)) {
+		id := w.TerrainSpots[spot.X][spot.Y].Being
+		if id == uuid.Nil || id == b.ID {
+			continue
+		}
+		if other := w.BeingList[id.String()]; other != nil && other.Target == b.Target {
+			hunters = append(hunters, other)
+		}
+	}
+
+	flank := preySpot
+	bestSpread := -1.0
+	for _, direction := range directions8 {
+		spot := GoWorld.Location{X: preySpot.X + direction.X, Y: preySpot.Y + direction.Y}
+		if w.IsOutOfBounds(spot) {
+			continue
+		}
+		if spot != b.Position && !w.canPlaceBeing(spot, b.Type) {
+			continue
+		}
+		// The spread is the distance to the closest other hunter
+		spread := math.Inf(1)
+		for _, h := range hunters {
+			spread = math.Min(spread, w.Distance(spot, h.Position))
+		}
+		if spread > bestSpread {
+			flank = spot
+			bestSpread = spread
+		}
+	}
+	return flank
+}
@@ -0,0 +1,67 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// The parasite load a being can carry (see Being.Parasites)
+	parasiteRange = &attributeRange{Min: 0, Max: 64}
+	// ParasiteChance is the share of the random beings that already carry parasites when they are created
+	ParasiteChance = 0.05
+	// The load of the parasites a being starts out with
+	parasiteStart = 4.
+	// How fast the parasites multiply every epoch (the load grows until the being can not carry any more)
+	parasiteGrowth = 0.002
+	// How much faster a being carrying the full load gets hungry and how much durability it loses every epoch
+	parasiteHunger = 0.5
+	parasiteDrain  = 0.01
+	// The chance that parasites jump onto the partner during mating and the share of the load that jumps (a predator
+	// always gets the share of the load of the prey it eats)
+	parasiteJumpChance = 0.5
+	parasiteJumpShare  = 0.25
+)
+
+// infest gives the new random being parasites (ParasiteChance of them carry some)
+func (w *RandomWorld) infest(b *GoWorld.Being) {
+	if w.random.Float64() < ParasiteChance {
+		b.Parasites = parasiteStart
+	}
+}
+
+// parasiteHungerC returns how much faster the parasites make the being hungry
+func parasiteHungerC(b *GoWorld.Being) float64 {
+	return 1 + parasiteHunger*b.Parasites/parasiteRange.Max
+}
+
+// FeedParasites lets the parasites of the being multiply for the epoch, they wear down its durability the more of
+// them there are
+func (w *RandomWorld) FeedParasites(b *GoWorld.Being) {
+	if b.Parasites <= 0 {
+		return
+	}
+	b.Parasites = math.Min(b.Parasites+parasiteGrowth*b.Parasites*(1-b.Parasites/parasiteRange.Max),
+		parasiteRange.Max)
+	b.Durability = math.Max(b.Durability-parasiteDrain*b.Parasites/parasiteRange.Max, durabilityRange.Min)
+}
+
+// jumpParasites moves the share of the parasite load (load of the host) onto the being, with the chance
+func (w *RandomWorld) jumpParasites(b *GoWorld.Being, load, chance float64) {
+	if load <= 0 || w.random.Float64() >= chance {
+		return
+	}
+	b.Parasites = math.Min(b.Parasites+load*parasiteJumpShare, parasiteRange.Max)
+}
+
+// MingleParasites lets the parasites of two mating beings jump onto each other
+func (w *RandomWorld) MingleParasites(b, partner *GoWorld.Being) {
+	load, partnerLoad := b.Parasites, partner.Parasites
+	w.jumpParasites(partner, load, parasiteJumpChance)
+	w.jumpParasites(b, partnerLoad, parasiteJumpChance)
+}
+
+// CatchParasites passes the parasites of the prey onto the predator that eats it
+func (w *RandomWorld) CatchParasites(predator, prey *GoWorld.Being) {
+	w.jumpParasites(predator, prey.Parasites, 1)
+}
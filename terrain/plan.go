@@ -0,0 +1,51 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+)
+
+// TraceBeing keeps the plans of the being from now on (see PlannedPath), uuid.Nil stops tracing
+func (w *RandomWorld) TraceBeing(id uuid.UUID) {
+	w.traced = id
+	w.tracedPlan = nil
+}
+
+// PlannedPath returns the latest plan of the traced being, false if it planned nothing in its latest update (e.g. it
+// slept) or no being is traced
+func (w *RandomWorld) PlannedPath() (GoWorld.Plan, bool) {
+	if w.tracedPlan == nil {
+		return GoWorld.Plan{}, false
+	}
+	return *w.tracedPlan, true
+}
+
+// plan remembers the action and the path the being planned towards the goal (see DescribeBeing), the traced one keeps
+// a copy of the path (see PlannedPath)
+func (w *RandomWorld) plan(b *GoWorld.Being, action string, goal GoWorld.Location, path []GoWorld.Location) {
+	w.latest[b.ID.String()] = latestUpdate{action: action, goal: goal, path: path}
+	if b.ID != w.traced {
+		return
+	}
+	w.tracedPlan = &GoWorld.Plan{Action: action, From: b.Position, Goal: goal,
+		Path: append([]GoWorld.Location(nil), path...)}
+}
+
+// PathCost returns the cost of the path a being of the type would take from one location to the other (the sum of the
+// costs of the surfaces along it, see pathing.AStar) without building the path, e.g. to weigh a far away meal against
+// a near one. Nothing in the world changes
+// Returns an error if the type is unknown, a location is outside the world or there is no path
+func (w *RandomWorld) PathCost(from, to GoWorld.Location, beingType string) (float64, error) {
+	if !KnownType(beingType) {
+		return 0, fmt.Errorf("error finding path cost: unknown being type %v", beingType)
+	}
+	if w.IsOutOfBounds(from) || w.IsOutOfBounds(to) {
+		return 0, fmt.Errorf("error finding path cost: %v or %v is outside the world", from, to)
+	}
+	cost, found := w.pathFinder.GetCost(from, to, crossesWater(beingType))
+	if !found {
+		return 0, fmt.Errorf("error finding path cost: no path from %v to %v for %v", from, to, beingType)
+	}
+	return cost, nil
+}
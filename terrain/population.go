@@ -0,0 +1,170 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+// LogDeaths prints every death (with its cause) to the standard output
+var LogDeaths = true
+
+// AddBeing places the being onto the map at its position (its habitat is the surface there if it has none yet)
+// Returns an error if the being is of an unknown type, already lives in the world or can not be at its position
+func (w *RandomWorld) AddBeing(b *GoWorld.Being) error {
+	if b == nil {
+		return fmt.Errorf("error adding being: no being given")
+	}
+	if _, known := creators[b.Type]; !known {
+		return fmt.Errorf("error adding being: unknown being type %v", b.Type)
+	}
+	if b.ID == uuid.Nil {
+		b.ID = w.newID()
+	}
+	if _, exists := w.BeingList[b.ID.String()]; exists {
+		return fmt.Errorf("error adding being: being %v already lives in the world", b.ID)
+	}
+	if w.IsOutOfBounds(b.Position) {
+		return fmt.Errorf("error adding being: position %v is out of bounds", b.Position)
+	}
+	// Beings made with the CreateRandom* helpers already stand on their spot
+	if w.TerrainSpots[b.Position.X][b.Position.Y].Being != b.ID && !w.canPlaceBeing(b.Position, b.Type) {
+		return fmt.Errorf("error adding being: a being of type %v can not be placed at %v", b.Type, b.Position)
+	}
+	if b.Habitat == uuid.Nil {
+		b.Habitat = w.TerrainSpots[b.Position.X][b.Position.Y].Surface.ID
+	}
+	w.setBeingAt(b.Position, b.ID)
+	w.BeingList[b.ID.String()] = b
+	w.emit("added", b.ID)
+	return nil
+}
+
+// RemoveBeing takes the being off the map without leaving anything behind (its home and territory are abandoned)
+// Returns an error if there is no being with the id
+func (w *RandomWorld) RemoveBeing(id uuid.UUID) error {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return fmt.Errorf("error removing being: no being with id %v", id)
+	}
+	w.removeBeing(b)
+	w.emit("removed", b.ID)
+	return nil
+}
+
+// removeBeing deletes the being from the world and frees everything it occupied
+func (w *RandomWorld) removeBeing(b *GoWorld.Being) {
+	delete(w.BeingList, b.ID.String())
+	delete(w.Territories, b.ID.String())
+	delete(w.latest, b.ID.String())
+	w.abandonHome(b)
+	if w.TerrainSpots[b.Position.X][b.Position.Y].Being == b.ID {
+		w.setBeingAt(b.Position, uuid.Nil)
+	}
+}
+
+// die removes the being from the world and leaves its body behind for scavengers
+// Returns the UUIDs of the being and of its body (if there was room for it)
+func (w *RandomWorld) die(b *GoWorld.Being, cause string) []uuid.UUID {
+	if LogDeaths {
+		if b.Name != "" {
+			fmt.Printf("Being (%v) %v %q ... died of %v\n", b.Type, b.ID, b.Name, cause)
+		} else {
+			fmt.Printf("Being (%v) %v ... died of %v\n", b.Type, b.ID, cause)
+		}
+	}
+	// remove being from BeingList & TerrainSpots
+	w.removeBeing(b)
+	if w.Stats != nil {
+		w.Stats.recordDeath(b)
+	}
+	// The food it carried is lost, the body stays behind for scavengers
+	w.recordEnergy("Caches", "Decay", b.Carrying, b.Carrying)
+	if carrion := w.leaveCarrion(b, bodySize(b)*carrionNutrition); carrion != nil {
+		w.recordEnergy(trophicLevel(b.Type), "Corpses", carrion.NutritionalValue, carrion.NutritionalValue)
+		return []uuid.UUID{b.ID, carrion.ID}
+	}
+	return []uuid.UUID{b.ID}
+}
+
+// KillBeing makes the being die of the cause (e.g. lightning), it leaves its body behind like any other dead being
+// Returns an error if there is no being with the id
+func (w *RandomWorld) KillBeing(id uuid.UUID, cause string) error {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return fmt.Errorf("error killing being: no being with id %v", id)
+	}
+	w.emit("died", w.die(b, cause)...)
+	return nil
+}
+
+// FeedBeing lowers the hunger of the being by the amount (not below zero)
+// Returns an error if there is no being with the id or the amount is negative
+func (w *RandomWorld) FeedBeing(id uuid.UUID, amount float64) error {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return fmt.Errorf("error feeding being: no being with id %v", id)
+	}
+	if amount < 0 {
+		return fmt.Errorf("error feeding being: negative amount %v", amount)
+	}
+	b.Hunger = math.Max(b.Hunger-amount, 0)
+	return nil
+}
+
+// HealBeing lowers the injury of the being by the amount (not below zero)
+// Returns an error if there is no being with the id or the amount is negative
+func (w *RandomWorld) HealBeing(id uuid.UUID, amount float64) error {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return fmt.Errorf("error healing being: no being with id %v", id)
+	}
+	if amount < 0 {
+		return fmt.Errorf("error healing being: negative amount %v", amount)
+	}
+	b.Injury = math.Max(b.Injury-amount, 0)
+	return nil
+}
+
+// SetAttribute sets the named numeric attribute of the being (named as the Being field, e.g. Speed)
+// Returns an error if there is no being with the id, no such attribute or the value is outside the attribute range
+func (w *RandomWorld) SetAttribute(id uuid.UUID, name string, value float64) error {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return fmt.Errorf("error setting attribute: no being with id %v", id)
+	}
+	attribute, valueRange := w.attributeOf(b, name)
+	if attribute == nil {
+		return fmt.Errorf("error setting attribute: unknown attribute %v", name)
+	}
+	if value < valueRange.Min || value > valueRange.Max {
+		return fmt.Errorf("error setting attribute: %v is outside the range of %v (%v - %v)", value, name,
+			valueRange.Min, valueRange.Max)
+	}
+	*attribute = value
+	return nil
+}
+
+// TeleportBeing moves the being straight to the location (it has to be a spot the being could walk onto)
+// Returns an error if there is no being with the id or it can not be at the location
+func (w *RandomWorld) TeleportBeing(id uuid.UUID, to GoWorld.Location) error {
+	b := w.BeingList[id.String()]
+	if b == nil {
+		return fmt.Errorf("error teleporting being: no being with id %v", id)
+	}
+	if w.IsOutOfBounds(to) {
+		return fmt.Errorf("error teleporting being: location %v is out of bounds", to)
+	}
+	if to == b.Position {
+		return nil
+	}
+	if !w.canPlaceBeing(to, b.Type) {
+		return fmt.Errorf("error teleporting being: a being of type %v can not be placed at %v", b.Type, to)
+	}
+	w.setBeingAt(b.Position, uuid.Nil)
+	w.setBeingAt(to, b.ID)
+	b.Position = to
+	w.emit("moved", b.ID)
+	return nil
+}
@@ -0,0 +1,33 @@
+package terrain
+
+import (
+	"context"
+	"fmt"
+)
+
+// Progress is told how far the terrain generation got (0-100 percent) and which of its phases runs (Noise, Zones,
+// Trees, Boulders, Fords or Done)
+type Progress func(percent float64, phase string)
+
+// WithProgress reports the progress of the terrain generation and stops it once the context is cancelled (the world
+// is then not created), either can be nil
+func WithProgress(ctx context.Context, progress Progress) Option {
+	return func(s *Settings) {
+		s.ctx = ctx
+		s.progress = progress
+	}
+}
+
+// progressed reports that the generation got to the percent within the phase
+// Returns an error if the generation was cancelled
+func (w *RandomWorld) progressed(phase string, percent float64) error {
+	if w.Settings.progress != nil {
+		w.Settings.progress(percent, phase)
+	}
+	if w.Settings.ctx != nil {
+		if err := w.Settings.ctx.Err(); err != nil {
+			return fmt.Errorf("error generating terrain: %v", err)
+		}
+	}
+	return nil
+}
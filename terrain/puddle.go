@@ -0,0 +1,77 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"image/color"
+	"math"
+)
+
+var (
+	// RainChance is the chance that it starts raining in an epoch
+	RainChance = 0.002
+	// How many puddles a rain shower leaves behind
+	rainPuddles = 30
+	// How much water a new puddle holds
+	puddleWater = 600.
+	// How much water evaporates from a puddle every epoch
+	puddleEvaporation = 1.
+	// How much water a being drinks from a puddle at once
+	puddleSip = 100.
+	// Puddles are painted onto the terrain in the color of water
+	puddleColor = color.RGBA{R: 116, G: 167, B: 235, A: 255}
+)
+
+// Rain leaves puddles on random land spots
+func (w *RandomWorld) Rain() {
+	for i := 0; i < rainPuddles; i++ {
+		w.AddPuddle(GoWorld.Location{X: w.random.Intn(w.Width), Y: w.random.Intn(w.Height)}, puddleWater)
+	}
+}
+
+// AddPuddle leaves a puddle with the amount of water on the location, it has to be land that can be walked on and is
+// not covered by an object (puddles on the same spot add up)
+// Returns false if no puddle could be left there
+func (w *RandomWorld) AddPuddle(location GoWorld.Location, water float64) bool {
+	if w.IsOutOfBounds(location) || water <= 0 {
+		return false
+	}
+	spot := w.TerrainSpots[location.X][location.Y]
+	if !spot.Surface.Habitable || spot.Object != uuid.Nil {
+		return false
+	}
+	spot.Puddle += water
+	w.TerrainZones.Set(location.X, location.Y, puddleColor)
+	if w.puddleSpots == nil {
+		w.puddleSpots = make(map[GoWorld.Location]bool)
+	}
+	w.puddleSpots[location] = true
+	return true
+}
+
+// Evaporate dries the puddles a bit, dry ones disappear
+func (w *RandomWorld) Evaporate() {
+	for location := range w.puddleSpots {
+		w.drainPuddle(location, puddleEvaporation)
+	}
+}
+
+// hasWater checks if beings can drink at the location (water surface or a puddle)
+func (w *RandomWorld) hasWater(location GoWorld.Location) bool {
+	spot := w.TerrainSpots[location.X][location.Y]
+	return spot.Surface.CommonName == "Water" || spot.Puddle > 0
+}
+
+// drainPuddle takes the amount of water from the puddle on the location, the puddle is removed once it is empty
+func (w *RandomWorld) drainPuddle(location GoWorld.Location, water float64) {
+	spot := w.TerrainSpots[location.X][location.Y]
+	spot.Puddle = math.Max(spot.Puddle-water, 0)
+	if spot.Puddle > 0 {
+		return
+	}
+	delete(w.puddleSpots, location)
+	// Homes built next to the puddle keep their color
+	if w.Homes[spot.Object.String()] == nil {
+		w.TerrainZones.Set(location.X, location.Y, spot.Surface.Color)
+	}
+}
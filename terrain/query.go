@@ -0,0 +1,411 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query selects beings and food by an expression comparing their fields with values, e.g.
+// "type == 'Carnivore' && hunger > 200" or "kind == 'Food' && !(type == 'Land' || type == 'Water')". The fields are
+// the ones of GoWorld.Being and GoWorld.Food (in any case, nested ones with dots, e.g. personality.caution), x and y
+// for the position and kind (Being or Food). The operators are ==, !=, <, <=, >, >=, &&, || and !, strings are
+// compared without case. A list of strings (tags) equals a value it holds ("tags == 'study'"). A comparison with a
+// field the entity does not have (e.g. taste of a being) is false
+type Query struct {
+	expression string
+	root       queryNode
+}
+
+// queryNode is a part of a parsed query, it tells if it holds for the entity (a being or food)
+type queryNode func(entity reflect.Value, kind string) bool
+
+// queryOperand is a field or a value in a comparison, it returns false if the entity has no such field
+type queryOperand func(entity reflect.Value, kind string) (interface{}, bool)
+
+// ParseQuery parses the expression (see Query)
+// Returns an error if the expression is malformed or names a field neither beings nor food have
+func ParseQuery(expression string) (*Query, error) {
+	tokens, err := tokenize(expression)
+	var root queryNode
+	if err == nil {
+		p := &queryParser{tokens: tokens}
+		root, err = p.or()
+		if err == nil && p.pos < len(p.tokens) {
+			err = fmt.Errorf("unexpected %v", p.tokens[p.pos])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing query %q: %v", expression, err)
+	}
+	return &Query{expression: expression, root: root}, nil
+}
+
+// Query returns the IDs of the beings and then the food matching the expression (see terrain.Query), each in the
+// order of their IDs
+// Returns an error if the expression can not be parsed
+func (w *RandomWorld) Query(expression string) ([]uuid.UUID, error) {
+	q, err := ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	return q.IDs(w), nil
+}
+
+// IDs returns the IDs of the beings and then the food in the world matching the query, each in the order of their IDs
+func (q *Query) IDs(w *RandomWorld) []uuid.UUID {
+	var ids []uuid.UUID
+	w.ForEachBeing(q.MatchBeing, func(b *GoWorld.Being) {
+		ids = append(ids, b.ID)
+	})
+	w.ForEachFood(q.MatchFood, func(f *GoWorld.Food) {
+		ids = append(ids, f.ID)
+	})
+	return ids
+}
+
+// MatchBeing checks if the being matches the query
+func (q *Query) MatchBeing(b *GoWorld.Being) bool {
+	return q.root(reflect.ValueOf(b).Elem(), "Being")
+}
+
+// MatchFood checks if the food matches the query
+func (q *Query) MatchFood(f *GoWorld.Food) bool {
+	return q.root(reflect.ValueOf(f).Elem(), "Food")
+}
+
+// String returns the expression of the query
+func (q *Query) String() string {
+	return q.expression
+}
+
+// queryToken is a piece of a query expression: an operator, a parenthesis, a name or a value
+type queryToken struct {
+	text   string
+	quoted bool // The token is a string value
+}
+
+func (t queryToken) String() string {
+	if t.quoted {
+		return strconv.Quote(t.text)
+	}
+	return t.text
+}
+
+// tokenize splits the expression into its tokens
+func tokenize(expression string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string %v", string(runes[i:]))
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		case strings.ContainsRune("=!<>&|", r):
+			end := i + 1
+			if end < len(runes) && strings.ContainsRune("=&|", runes[end]) {
+				end++
+			}
+			switch op := string(runes[i:end]); op {
+			case "==", "!=", "<", "<=", ">", ">=", "&&", "||", "!":
+				tokens = append(tokens, queryToken{text: op})
+			default:
+				return nil, fmt.Errorf("unknown operator %v", op)
+			}
+			i = end
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{text: string(r)})
+			i++
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) ||
+				runes[end] == '.' || runes[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i:end])})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// queryParser turns the tokens into query nodes, every method parses a level of the grammar
+//	or         = and { "||" and }
+//	and        = not { "&&" not }
+//	not        = "!" not | "(" or ")" | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand ]
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// next returns the next token if it is one of the operators (and moves past it)
+func (p *queryParser) next(operators ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].quoted {
+		return "", false
+	}
+	for _, op := range operators {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *queryParser) or() (queryNode, error) {
+	left, err := p.and()
+	for err == nil {
+		if _, ok := p.next("||"); !ok {
+			return left, nil
+		}
+		var right queryNode
+		if right, err = p.and(); err == nil {
+			l := left
+			left = func(e reflect.Value, kind string) bool { return l(e, kind) || right(e, kind) }
+		}
+	}
+	return nil, err
+}
+
+func (p *queryParser) and() (queryNode, error) {
+	left, err := p.not()
+	for err == nil {
+		if _, ok := p.next("&&"); !ok {
+			return left, nil
+		}
+		var right queryNode
+		if right, err = p.not(); err == nil {
+			l := left
+			left = func(e reflect.Value, kind string) bool { return l(e, kind) && right(e, kind) }
+		}
+	}
+	return nil, err
+}
+
+func (p *queryParser) not() (queryNode, error) {
+	if _, ok := p.next("!"); ok {
+		inner, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return func(e reflect.Value, kind string) bool { return !inner(e, kind) }, nil
+	}
+	if _, ok := p.next("("); ok {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.next(")"); !ok {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+func (p *queryParser) comparison() (queryNode, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.next("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		// A lone operand holds if it is true (e.g. the field migrating)
+		return func(e reflect.Value, kind string) bool {
+			value, ok := left(e, kind)
+			return ok && value == true
+		}, nil
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(e reflect.Value, kind string) bool {
+		a, ok := left(e, kind)
+		if !ok {
+			return false
+		}
+		b, ok := right(e, kind)
+		return ok && compare(a, op, b)
+	}, nil
+}
+
+// operand parses a value (a number, string, true or false) or the name of a field
+func (p *queryParser) operand() (queryOperand, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	t := p.tokens[p.pos]
+	if strings.ContainsAny(t.text, "=!<>&|()") && !t.quoted {
+		return nil, fmt.Errorf("unexpected %v", t)
+	}
+	p.pos++
+	if t.quoted {
+		return constant(t.text), nil
+	}
+	if number, err := strconv.ParseFloat(t.text, 64); err == nil {
+		return constant(number), nil
+	}
+	switch name := strings.ToLower(t.text); name {
+	case "true", "false":
+		return constant(name == "true"), nil
+	case "kind":
+		return func(_ reflect.Value, kind string) (interface{}, bool) { return kind, true }, nil
+	case "x", "y":
+		t.text = "position." + name
+	}
+	path := strings.Split(t.text, ".")
+	if !hasField(reflect.TypeOf(GoWorld.Being{}), path) && !hasField(reflect.TypeOf(GoWorld.Food{}), path) {
+		return nil, fmt.Errorf("unknown field %v", t.text)
+	}
+	return func(e reflect.Value, _ string) (interface{}, bool) { return fieldValue(e, path) }, nil
+}
+
+// constant returns an operand that is always the value
+func constant(value interface{}) queryOperand {
+	return func(reflect.Value, string) (interface{}, bool) { return value, true }
+}
+
+// hasField checks if the type has a field of a comparable kind at the path (names in any case)
+func hasField(t reflect.Type, path []string) bool {
+	for _, name := range path {
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		field, ok := t.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if !ok {
+			return false
+		}
+		t = field.Type
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int64, reflect.Uint64, reflect.Bool, reflect.String:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return t == reflect.TypeOf(uuid.UUID{})
+}
+
+// fieldValue returns the value of the field at the path (a float64, bool, string or []string)
+// Returns false if the entity has no such field
+func fieldValue(e reflect.Value, path []string) (interface{}, bool) {
+	for _, name := range path {
+		if e.Kind() != reflect.Struct {
+			return nil, false
+		}
+		e = e.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if !e.IsValid() {
+			return nil, false
+		}
+	}
+	switch e.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return e.Float(), true
+	case reflect.Int, reflect.Int64:
+		return float64(e.Int()), true
+	case reflect.Uint64:
+		return float64(e.Uint()), true
+	case reflect.Bool:
+		return e.Bool(), true
+	case reflect.String:
+		return e.String(), true
+	case reflect.Slice:
+		if list, ok := e.Interface().([]string); ok {
+			return list, true
+		}
+		return nil, false
+	}
+	if id, ok := e.Interface().(uuid.UUID); ok {
+		return id.String(), true
+	}
+	return nil, false
+}
+
+// compare applies the operator to the values, values of different kinds are never equal (nor ordered). A list equals
+// a string it holds (on either side) and can not be ordered
+func compare(a interface{}, op string, b interface{}) bool {
+	// The list goes first
+	if _, ok := b.([]string); ok {
+		a, b = b, a
+	}
+	switch a := a.(type) {
+	case []string:
+		if op != "==" && op != "!=" {
+			return false
+		}
+		held := false
+		for _, s := range a {
+			held = held || compare(s, "==", b)
+		}
+		return held == (op == "==")
+	case float64:
+		b, ok := b.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		}
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return false
+		}
+		a, b = strings.ToLower(a), strings.ToLower(b)
+		switch op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		}
+	case bool:
+		b, ok := b.(bool)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"reflect"
+	"testing"
+)
+
+// queryWorld returns a world holding only three beings and two plants (named so the tests can tell them apart)
+func queryWorld() *RandomWorld {
+	beings := []*GoWorld.Being{
+		{ID: uuid.MustParse("10000000-0000-0000-0000-000000000000"), Name: "Akela", Type: "Carnivore", Hunger: 210,
+			Thirst: 10, Tags: []string{"study", "pack"}, Position: GoWorld.Location{X: 3, Y: 4}},
+		{ID: uuid.MustParse("20000000-0000-0000-0000-000000000000"), Name: "Nemo", Type: "Water", Hunger: 50,
+			Position: GoWorld.Location{X: 10, Y: 2}},
+		{ID: uuid.MustParse("30000000-0000-0000-0000-000000000000"), Name: "Tweety", Type: "Flying", Hunger: 150,
+			Migrating: true, Personality: GoWorld.Personality{Caution: 0.5}, Position: GoWorld.Location{X: 7, Y: 7}},
+	}
+	food := []*GoWorld.Food{
+		{ID: uuid.MustParse("40000000-0000-0000-0000-000000000000"), Name: "Carrot", Type: "Land", Taste: 7,
+			Position: GoWorld.Location{X: 20, Y: 20}},
+		{ID: uuid.MustParse("50000000-0000-0000-0000-000000000000"), Name: "Weed", Type: "Water", Taste: 2,
+			Position: GoWorld.Location{X: 30, Y: 30}},
+	}
+	w := &RandomWorld{BeingList: make(map[string]*GoWorld.Being), FoodList: make(map[string]*GoWorld.Food)}
+	for _, b := range beings {
+		w.BeingList[b.ID.String()] = b
+	}
+	for _, f := range food {
+		w.FoodList[f.ID.String()] = f
+	}
+	return w
+}
+
+// TestQuery checks which beings and food the expressions select
+func TestQuery(t *testing.T) {
+	w := queryWorld()
+	tests := []struct {
+		name       string
+		expression string
+		want       []string // The names of the selected entities
+	}{
+		{"number", "hunger > 100", []string{"Akela", "Tweety"}},
+		{"and before or", "type == 'Water' || type == 'Flying' && hunger > 200", []string{"Nemo", "Weed"}},
+		{"parentheses", "(type == 'Water' || type == 'Flying') && hunger > 100", []string{"Tweety"}},
+		{"not", "!(kind == 'Food') && !migrating", []string{"Akela", "Nemo"}},
+		{"double not", "!!migrating", []string{"Tweety"}},
+		{"not before and", "!migrating && hunger > 100", []string{"Akela"}},
+		{"string without case", "name == 'AKELA'", []string{"Akela"}},
+		{"ordered strings", "kind == 'Being' && name >= 'n'", []string{"Nemo", "Tweety"}},
+		{"number is not a string", "hunger == '210'", nil},
+		{"string is not a number", "name != 5", nil},
+		{"position", "x < 5 && y == 4", []string{"Akela"}},
+		{"nested field", "personality.caution >= 0.5", []string{"Tweety"}},
+		{"field of food only", "taste > 5", []string{"Carrot"}},
+		{"tags hold", "tags == 'study'", []string{"Akela"}},
+		{"tags on the right", "'pack' == tags", []string{"Akela"}},
+		{"tags do not hold", "tags != 'study'", []string{"Nemo", "Tweety", "Carrot", "Weed"}},
+		{"tags are not ordered", "tags < 'z'", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, err := w.Query(test.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, id := range ids {
+				if b := w.BeingList[id.String()]; b != nil {
+					got = append(got, b.Name)
+				} else {
+					got = append(got, w.FoodList[id.String()].Name)
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("%q selected %v, want %v", test.expression, got, test.want)
+			}
+		})
+	}
+}
+
+// TestQueryMalformed checks that malformed expressions return an error instead of a query (or a panic)
+func TestQueryMalformed(t *testing.T) {
+	w := queryWorld()
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"empty", ""},
+		{"unterminated string", "type == 'Carnivore"},
+		{"missing right operand", "hunger >"},
+		{"missing left operand", "&& hunger > 1"},
+		{"missing or operand", "hunger > 1 ||"},
+		{"lone not", "!"},
+		{"unknown field", "colour == 'red'"},
+		{"unknown nested field", "personality.courage > 1"},
+		{"unknown operator", "hunger = 1"},
+		{"unexpected character", "hunger # 1"},
+		{"missing closing parenthesis", "(hunger > 1"},
+		{"missing opening parenthesis", "hunger > 1)"},
+		{"two operators", "hunger > > 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("%q panicked: %v", test.expression, r)
+				}
+			}()
+			if ids, err := w.Query(test.expression); err == nil {
+				t.Errorf("%q selected %v, want an error", test.expression, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+)
+
+// Region restricts where beings spawn (e.g. to study isolated populations). Its parts add up, the zero value is the
+// whole map
+type Region struct {
+	Min, Max  GoWorld.Location // The corners of the bounding box (both included), the whole map if both are zero
+	Surface   string           // The name of the surface (e.g. Forest), any surface if empty
+	WaterBody int              // The water body the beings spawn in (see WaterBodies), any spot if 0
+}
+
+// WaterBody is a lake, river or sea: water spots connected to each other
+type WaterBody struct {
+	ID       int              // The number of the water body (starting at 1)
+	Size     int              // The number of its spots
+	Min, Max GoWorld.Location // The corners of the bounding box around it
+}
+
+// waterBodies labels every water spot with the ID of its water body (0 on land), the IDs are given in the order the
+// spots are scanned (column by column), so they only change when the surfaces do
+func (w *RandomWorld) waterBodies() ([][]int, []WaterBody) {
+	labels := make([][]int, w.Width)
+	for x := range labels {
+		labels[x] = make([]int, w.Height)
+	}
+	var bodies []WaterBody
+	for x := range w.TerrainSpots {
+		for y, spot := range w.TerrainSpots[x] {
+			if spot.Surface.CommonName != "Water" || labels[x][y] != 0 {
+				continue
+			}
+			start := GoWorld.Location{X: x, Y: y}
+			body := WaterBody{ID: len(bodies) + 1, Min: start, Max: start}
+			labels[x][y] = body.ID
+			queue := []GoWorld.Location{start}
+			for len(queue) > 0 {
+				at := queue[0]
+				queue = queue[1:]
+				body.Size++
+				if at.X < body.Min.X {
+					body.Min.X = at.X
+				} else if at.X > body.Max.X {
+					body.Max.X = at.X
+				}
+				if at.Y < body.Min.Y {
+					body.Min.Y = at.Y
+				} else if at.Y > body.Max.Y {
+					body.Max.Y = at.Y
+				}
+				for _, direction := range directions8 {
+					next := GoWorld.Location{X: at.X + direction.X, Y: at.Y + direction.Y}
+					if w.IsOutOfBounds(next) || labels[next.X][next.Y] != 0 ||
+						w.TerrainSpots[next.X][next.Y].Surface.CommonName != "Water" {
+						continue
+					}
+					labels[next.X][next.Y] = body.ID
+					queue = append(queue, next)
+				}
+			}
+			bodies = append(bodies, body)
+		}
+	}
+	return labels, bodies
+}
+
+// WaterBodies returns the lakes, rivers and seas of the world (their IDs change when the surfaces do, e.g. after a
+// drought)
+func (w *RandomWorld) WaterBodies() []WaterBody {
+	_, bodies := w.waterBodies()
+	return bodies
+}
+
+// SpawnIn creates quantity random beings of the type at free spots within the region (their habitat is the surface
+// they spawn on, flying beings keep theirs)
+// Returns how many beings were placed and an error if the type, surface or water body is unknown or there was no
+// room in the region for the rest
+func (w *RandomWorld) SpawnIn(beingType string, quantity int, region Region) (int, error) {
+	if _, known := creators[beingType]; !known {
+		return 0, fmt.Errorf("error spawning beings: unknown being type %v", beingType)
+	}
+	onSurface := spawnSurface(beingType)
+	if region.WaterBody != 0 {
+		onSurface = waterSurface
+	}
+	if region.Surface != "" {
+		known := false
+		for i := range w.surfaces {
+			known = known || w.surfaces[i].CommonName == region.Surface
+		}
+		if !known {
+			return 0, fmt.Errorf("error spawning beings: unknown surface %v", region.Surface)
+		}
+		typeSurface := onSurface
+		onSurface = func(s *Surface) bool { return s.CommonName == region.Surface && typeSurface(s) }
+	}
+	var labels [][]int
+	if region.WaterBody != 0 {
+		var bodies []WaterBody
+		labels, bodies = w.waterBodies()
+		if region.WaterBody < 0 || region.WaterBody > len(bodies) {
+			return 0, fmt.Errorf("error spawning beings: no water body %d (there are %d)", region.WaterBody,
+				len(bodies))
+		}
+	}
+	box := region.Min != GoWorld.Location{} || region.Max != GoWorld.Location{}
+	ids, err := w.spawn(beingType, quantity, onSurface, func(spot GoWorld.Location) bool {
+		if box && (spot.X < region.Min.X || spot.X > region.Max.X || spot.Y < region.Min.Y || spot.Y > region.Max.Y) {
+			return false
+		}
+		return labels == nil || labels[spot.X][spot.Y] == region.WaterBody
+	})
+	return len(ids), err
+}
+
+// spawnSurface returns the surfaces beings of the type can spawn on
+func spawnSurface(beingType string) func(s *Surface) bool {
+	if flies(beingType) {
+		return anySurface
+	}
+	if beingType == "Water" {
+		return waterSurface
+	}
+	return walkableSurface
+}
+
+// spawn creates quantity random beings of the type at free spots on the surfaces that are inside (their habitat is
+// the surface they spawn on, flying beings keep theirs)
+// Returns the IDs of the beings placed and an error if there was no room for the rest
+func (w *RandomWorld) spawn(beingType string, quantity int, onSurface func(s *Surface) bool,
+	inside func(spot GoWorld.Location) bool) ([]uuid.UUID, error) {
+	create := creators[beingType]
+	ids := make([]uuid.UUID, 0, quantity)
+	for i := 0; i < quantity; i++ {
+		b, err := create(w)
+		if err != nil {
+			return ids, err
+		}
+		// Take the random being from the spot it was thrown onto
+		w.setBeingAt(b.Position, uuid.Nil)
+		spot, found := w.freeSpot(onSurface, func(spot GoWorld.Location) bool {
+			if !inside(spot) {
+				return false
+			}
+			if beingType == "Water" {
+				// Fish stay in the water (not on the grassland they could flop onto)
+				return true
+			}
+			return w.canPlaceBeing(spot, beingType)
+		})
+		if !found {
+			return ids, fmt.Errorf("error spawning beings: no free spot in the region fits a being of type %v",
+				beingType)
+		}
+		b.Position = spot
+		w.setBeingAt(spot, b.ID)
+		if !flies(beingType) {
+			b.Habitat = w.TerrainSpots[spot.X][spot.Y].Surface.ID
+		}
+		w.BeingList[b.ID.String()] = b
+		ids = append(ids, b.ID)
+	}
+	return ids, nil
+}
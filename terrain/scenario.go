@@ -0,0 +1,248 @@
+package terrain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"image"
+	"image/draw"
+	_ "image/png" // Heightmaps are stored as PNG images
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Scenario is a repeatable setup: a world config with an optional heightmap, beings placed at chosen spots and events
+// that happen at given epochs (see LoadScenario for the JSON form)
+type Scenario struct {
+	Config
+	Heightmap  string          // Path to a grayscale PNG the terrain follows (relative to the scenario file)
+	Placements []Placement     // Beings placed at chosen spots
+	Events     []ScenarioEvent // What happens during the run
+}
+
+// Placement puts a being of the type at the position, with the chosen attributes (the rest are random)
+type Placement struct {
+	Type       string             // The being type (e.g. Carnivore)
+	Position   GoWorld.Location   // Where the being is placed
+	Gender     string             // The gender of the being (random if empty)
+	Attributes map[string]float64 // Being attributes named as the Being fields (see SetAttribute)
+}
+
+// ScenarioEvent is something that happens to the world at the epoch. Its Kind is one of
+// Drought (water shallower than Amount, 0.3 if 0, dries up into grassland), Rain (puddles appear all over the land),
+// Spawn (Count beings of the Type appear at random spots, within the Region if there is one), Plants (Count plants of
+// the Type, Land or Water, start growing) or Cull (the Amount share of the beings of the Type, all types if empty,
+// dies)
+type ScenarioEvent struct {
+	Tick   uint64  // The epoch the event happens at
+	Kind   string  // What happens (see above)
+	Type   string  // The being or plant type the event affects
+	Count  int     // How many beings or plants the event adds
+	Amount float64 // How strong the event is
+	Region *Region // Where Spawn places the beings (anywhere if nil)
+}
+
+// LoadScenario reads the JSON scenario file (the fields of Config plus Heightmap, Placements and Events, e.g.
+// {"Seed": 7, "Events": [{"Tick": 2000, "Kind": "Drought"}]}), values missing in the file are the DefaultConfig ones
+func LoadScenario(path string) (*Scenario, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading scenario: %v", err)
+	}
+	defer file.Close()
+	s := &Scenario{Config: *DefaultConfig()}
+	if err := json.NewDecoder(file).Decode(s); err != nil {
+		return nil, fmt.Errorf("error loading scenario %v: %v", path, err)
+	}
+	if s.Heightmap != "" && !filepath.IsAbs(s.Heightmap) {
+		s.Heightmap = filepath.Join(filepath.Dir(path), s.Heightmap)
+	}
+	return s, nil
+}
+
+// loadHeightmap reads the grayscale heights from the image file
+func loadHeightmap(path string) (*image.Gray, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading heightmap: %v", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("error loading heightmap %v: %v", path, err)
+	}
+	bounds := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Rect, img, bounds.Min, draw.Src)
+	return gray, nil
+}
+
+// NewWorldFromScenario creates the world the scenario describes and schedules its events (the world takes the size
+// of the heightmap if there is one, the options are applied after the ones from the scenario)
+func NewWorldFromScenario(s *Scenario, extra ...Option) (*RandomWorld, error) {
+	config := s.Config
+	var opts []Option
+	if s.Heightmap != "" {
+		heightmap, err := loadHeightmap(s.Heightmap)
+		if err != nil {
+			return nil, err
+		}
+		config.Width, config.Height = heightmap.Rect.Dx(), heightmap.Rect.Dy()
+		opts = append(opts, WithHeightmap(heightmap))
+	}
+	for _, event := range s.Events {
+		if !KnownEvent(event.Kind) {
+			return nil, fmt.Errorf("error loading scenario: unknown event %v at tick %d", event.Kind, event.Tick)
+		}
+	}
+	w, err := NewWorldFromConfig(&config, append(opts, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range s.Placements {
+		if err := w.Place(p); err != nil {
+			return nil, err
+		}
+	}
+	w.schedule = append([]ScenarioEvent(nil), s.Events...)
+	sort.SliceStable(w.schedule, func(i, j int) bool { return w.schedule[i].Tick < w.schedule[j].Tick })
+	return w, nil
+}
+
+// Place creates the being the placement describes
+// Returns an error if the being can not be placed there or has an unknown attribute
+func (w *RandomWorld) Place(p Placement) error {
+	create, known := creators[p.Type]
+	if !known {
+		return fmt.Errorf("error placing being: unknown being type %v", p.Type)
+	}
+	if w.IsOutOfBounds(p.Position) {
+		return fmt.Errorf("error placing being: position %v is out of bounds", p.Position)
+	}
+	b, err := create(w)
+	if err != nil {
+		return err
+	}
+	// Take the random being from the spot it was thrown onto, its habitat is the surface of the chosen spot instead
+	w.setBeingAt(b.Position, uuid.Nil)
+	b.Habitat = uuid.Nil
+	b.Position = p.Position
+	if p.Gender != "" {
+		b.Gender = p.Gender
+	}
+	if err := w.AddBeing(b); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(p.Attributes))
+	for name := range p.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := w.SetAttribute(b.ID, name, p.Attributes[name]); err != nil {
+			w.removeBeing(b)
+			return err
+		}
+	}
+	return nil
+}
+
+// runSchedule makes the scenario events of the current epoch happen (events of past epochs are dropped, e.g. when
+// the world was restored from a later save)
+func (w *RandomWorld) runSchedule() {
+	for len(w.schedule) > 0 && w.schedule[0].Tick <= w.Epoch {
+		event := w.schedule[0]
+		w.schedule = w.schedule[1:]
+		if event.Tick == w.Epoch {
+			if err := w.Happen(event); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// KnownEvent checks if the kind of scenario event can happen (see ScenarioEvent)
+func KnownEvent(kind string) bool {
+	switch kind {
+	case "Drought", "Rain", "Spawn", "Plants", "Cull":
+		return true
+	}
+	return false
+}
+
+// KnownType checks if beings of the type can be spawned (see the Spawn event)
+func KnownType(beingType string) bool {
+	for _, spawner := range spawners {
+		if spawner.beingType == beingType {
+			return true
+		}
+	}
+	return false
+}
+
+// Happen makes the event happen right away
+// Returns an error if not all the beings or plants of a Spawn or Plants event could be placed (the rest are)
+func (w *RandomWorld) Happen(event ScenarioEvent) error {
+	var err error
+	switch event.Kind {
+	case "Drought":
+		level := event.Amount
+		if level == 0 {
+			level = shallowDepth
+		}
+		for x := range w.TerrainSpots {
+			for y, spot := range w.TerrainSpots[x] {
+				if spot.Surface.CommonName == "Water" && spot.Depth < level {
+					_ = w.SetSurfaceAt(GoWorld.Location{X: x, Y: y}, w.surfaces[1].ID)
+				}
+			}
+		}
+	case "Rain":
+		w.Rain()
+	case "Spawn":
+		if event.Region != nil {
+			if placed, spawnErr := w.SpawnIn(event.Type, event.Count, *event.Region); spawnErr != nil {
+				err = fmt.Errorf("error spawning beings: placed %d of %d %v: %v", placed, event.Count, event.Type,
+					spawnErr)
+			}
+			break
+		}
+		for _, spawner := range spawners {
+			if spawner.beingType != event.Type {
+				continue
+			}
+			if placed, spawnErr := spawner.create(w, event.Count); spawnErr != nil {
+				err = fmt.Errorf("error spawning beings: placed %d of %d %v: %v", placed, event.Count, event.Type,
+					spawnErr)
+			}
+		}
+	case "Plants":
+		placed, plantErr := 0, error(nil)
+		if event.Type == "Water" {
+			placed, plantErr = w.ProvideFood(0, event.Count)
+		} else {
+			placed, plantErr = w.ProvideFood(event.Count, 0)
+		}
+		if plantErr != nil {
+			err = fmt.Errorf("error growing plants: placed %d of %d: %v", placed, event.Count, plantErr)
+		}
+	case "Cull":
+		// Go through the beings in the same order every time, so seeded worlds stay the same
+		ids := make([]string, 0, len(w.BeingList))
+		for id, b := range w.BeingList {
+			if event.Type == "" || b.Type == event.Type {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if w.random.Float64() < event.Amount {
+				w.emit("died", w.die(w.BeingList[id], "culling")...)
+			}
+		}
+	}
+	w.emit(event.Kind)
+	return err
+}
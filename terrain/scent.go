@@ -0,0 +1,123 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"sort"
+)
+
+var (
+	// How much scent a being leaves on every spot it moves to
+	scentDeposit = 50.
+	// The most scent a spot can hold
+	scentMax = 255.
+	// The share of scent that evaporates every epoch
+	scentDecay = 0.02
+	// The share of scent that spreads to the adjacent spots every epoch
+	scentDiffusion = 0.04
+	// Scent weaker than this is gone
+	scentMinimum = 1.
+	// Prey runs away from predator scent that is at least this strong
+	scentAlarm = 20.
+)
+
+// leaveScent marks the being position with its scent (predator scent for carnivores, prey scent for others)
+func (w *RandomWorld) leaveScent(b *GoWorld.Being) {
+	spot := w.TerrainSpots[b.Position.X][b.Position.Y]
+	if b.Type == "Carnivore" {
+		spot.PredatorScent += scentDeposit
+		if spot.PredatorScent > scentMax {
+			spot.PredatorScent = scentMax
+		}
+	} else {
+		spot.PreyScent += scentDeposit
+		if spot.PreyScent > scentMax {
+			spot.PreyScent = scentMax
+		}
+	}
+	if w.scentSpots == nil {
+		w.scentSpots = make(map[GoWorld.Location]bool)
+	}
+	w.scentSpots[b.Position] = true
+}
+
+// UpdateScents lets the scent on the map spread to adjacent spots and evaporate
+// Only spots with scent are visited, spots where the scent became too weak are cleared
+func (w *RandomWorld) UpdateScents() {
+	if len(w.scentSpots) == 0 {
+		return
+	}
+	spread := make(map[GoWorld.Location][2]float64)
+	// The shares add up in the same order every time, so seeded worlds stay the same (to the last bit)
+	for _, location := range sortedLocations(w.scentSpots) {
+		spot := w.TerrainSpots[location.X][location.Y]
+		// Share some of the scent with the neighbours
+		preyShare := spot.PreyScent * scentDiffusion / float64(len(directions8))
+		predatorShare := spot.PredatorScent * scentDiffusion / float64(len(directions8))
+		for _, direction := range directions8 {
+			neighbour := GoWorld.Location{X: location.X + direction.X, Y: location.Y + direction.Y}
+			if w.IsOutOfBounds(neighbour) {
+				continue
+			}
+			shares := spread[neighbour]
+			shares[0] += preyShare
+			shares[1] += predatorShare
+			spread[neighbour] = shares
+		}
+		spot.PreyScent *= 1 - scentDecay - scentDiffusion
+		spot.PredatorScent *= 1 - scentDecay - scentDiffusion
+	}
+	for location, shares := range spread {
+		spot := w.TerrainSpots[location.X][location.Y]
+		spot.PreyScent += shares[0]
+		spot.PredatorScent += shares[1]
+		w.scentSpots[location] = true
+	}
+	// Forget spots where the scent is gone
+	for location := range w.scentSpots {
+		spot := w.TerrainSpots[location.X][location.Y]
+		if spot.PreyScent < scentMinimum && spot.PredatorScent < scentMinimum {
+			spot.PreyScent = 0
+			spot.PredatorScent = 0
+			delete(w.scentSpots, location)
+		}
+	}
+}
+
+// sortedLocations returns the locations of the set ordered by their X and then Y coordinates
+func sortedLocations(set map[GoWorld.Location]bool) []GoWorld.Location {
+	locations := make([]GoWorld.Location, 0, len(set))
+	for location := range set {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].X != locations[j].X {
+			return locations[i].X < locations[j].X
+		}
+		return locations[i].Y < locations[j].Y
+	})
+	return locations
+}
+
+// strongestScent returns the spot with the strongest predator (or prey) scent in the provided spots
+// The being's own spot is skipped. Returns false if no spot has noticeable scent
+func (w *RandomWorld) strongestScent(b *GoWorld.Being, spots []GoWorld.Location,
+	predator bool) (GoWorld.Location, bool) {
+	strongest := GoWorld.Location{}
+	strength := scentMinimum
+	found := false
+	for _, location := range spots {
+		if location == b.Position {
+			continue
+		}
+		scent := w.TerrainSpots[location.X][location.Y].PreyScent
+		if predator {
+			scent = w.TerrainSpots[location.X][location.Y].PredatorScent
+		}
+		if scent > strength {
+			strongest = location
+			strength = scent
+			found = true
+		}
+	}
+	return strongest, found
+}
@@ -0,0 +1,113 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"image"
+	"sort"
+)
+
+// How far apart the seeds of the random numbers are for consecutive epochs (see PrepareReplay)
+const epochSeedStep = 1000003
+
+// Snapshot returns a deep copy of the current state of the world (beings, food and the colored terrain), readers can
+// keep it as long as they want without seeing later updates
+func (w *RandomWorld) Snapshot() *GoWorld.Snapshot {
+	s := &GoWorld.Snapshot{
+		Epoch:  w.Epoch,
+		Seed:   w.Settings.Seed,
+		Season: w.Season(),
+		Night:  w.IsNight(),
+		Width:  w.Width,
+		Height: w.Height,
+		Beings: make(map[string]GoWorld.Being, len(w.BeingList)),
+		Food:   make(map[string]GoWorld.Food, len(w.FoodList)),
+	}
+	for id, b := range w.BeingList {
+		s.Beings[id] = copyBeing(*b)
+	}
+	for id, f := range w.FoodList {
+		s.Food[id] = copyFood(*f)
+	}
+	terrain := image.NewRGBA(w.TerrainZones.Bounds())
+	copy(terrain.Pix, w.TerrainZones.Pix)
+	s.Terrain = terrain
+	return s
+}
+
+// Restore replaces the beings, food and time with the ones from the snapshot. The world has to be created the same way
+// as the one the snapshot was taken of (size and seed), the terrain is not part of the snapshot. Homes, eggs and caches
+// are not part of it either, beings build new homes and the eggs and caches spoil
+// Returns an error if the snapshot was taken of a different world
+func (w *RandomWorld) Restore(s *GoWorld.Snapshot) error {
+	if s.Width != w.Width || s.Height != w.Height {
+		return fmt.Errorf("error restoring snapshot: the snapshot is of a %dx%d world, this one is %dx%d", s.Width,
+			s.Height, w.Width, w.Height)
+	}
+	if s.Seed != w.Settings.Seed {
+		return fmt.Errorf("error restoring snapshot: the snapshot is of a world with seed %d, this one has %d", s.Seed,
+			w.Settings.Seed)
+	}
+	for _, b := range w.BeingList {
+		w.removeBeing(b)
+	}
+	for _, f := range w.FoodList {
+		w.removeFood(f)
+	}
+	w.Epoch = s.Epoch
+	// Placed in the order of their IDs, so overlapping plants always end up the same way
+	ids := make([]string, 0, len(s.Food))
+	for id := range s.Food {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		food := copyFood(s.Food[id])
+		w.updatePlantSpot(food.Position.X, food.Position.Y, food.Area, food.ID)
+		w.FoodList[id] = &food
+		w.rememberName(food.ID, food.Name)
+	}
+	ids = make([]string, 0, len(s.Beings))
+	for id := range s.Beings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		// Copied, the world changes the restored beings while the snapshot may be kept (e.g. the start of a replay)
+		being := copyBeing(s.Beings[id])
+		being.Home = uuid.Nil
+		w.setBeingAt(being.Position, being.ID)
+		w.BeingList[id] = &being
+		w.rememberName(being.ID, being.Name)
+	}
+	return nil
+}
+
+// copyBeing returns a copy of the being that shares no slices with it (the remembered locations and the tags)
+func copyBeing(being GoWorld.Being) GoWorld.Being {
+	being.Memory.Water = append([]GoWorld.Location(nil), being.Memory.Water...)
+	being.Memory.Food = append([]GoWorld.Location(nil), being.Memory.Food...)
+	being.Tags = append([]string(nil), being.Tags...)
+	return being
+}
+
+// copyFood returns a copy of the food that shares no slices with it (the tags)
+func copyFood(food GoWorld.Food) GoWorld.Food {
+	food.Tags = append([]string(nil), food.Tags...)
+	return food
+}
+
+// PrepareReplay seeds the random numbers again from the seed of the world and its epoch and rebuilds the indexes that
+// depend on the order things happened in, so two seeded worlds created the same way and restored from the same
+// snapshot go on the same way (see package replay)
+// Returns an error if the world is not seeded
+func (w *RandomWorld) PrepareReplay() error {
+	if w.Settings.Seed == 0 {
+		return fmt.Errorf("error preparing replay: only seeded worlds can be replayed")
+	}
+	w.seedRandom(w.Settings.Seed + int64(w.Epoch)*epochSeedStep)
+	w.indexFreeSpots()
+	w.UpdateRegionStats()
+	return nil
+}
@@ -0,0 +1,192 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"sort"
+)
+
+var (
+	// PredatorTypes are the being types counted as predators (the ones that hunt other beings), the others are their
+	// prey (see PredatorPrey)
+	PredatorTypes = map[string]bool{"Carnivore": true, "Flying": true}
+	// How many epochs pass between two predator and prey samples
+	predatorPreyEvery uint64 = 10
+	// How many of the latest predator and prey samples are kept
+	predatorPreyKept = 2000
+	// summaryAttributes are the attributes summarized for every species (named as the Being fields, see attributeOf)
+	summaryAttributes = []string{"LifeExpectancy", "MaturityAge", "VisionRange", "MemorySize", "Speed", "Durability",
+		"Camouflage", "Resistance", "Size", "Fertility", "MutationRate", "Hunger", "Thirst", "Stress", "Energy",
+		"Injury", "Parasites"}
+)
+
+// Stats follows the species of the world over the run: the beings that died and the beings they killed, the living
+// ones are summarized when asked for (see SpeciesSummary). The predator and prey populations are sampled every few
+// epochs (see PredatorPrey) and the energy going through the food web is added up (see EnergyFlows)
+type Stats struct {
+	world        *RandomWorld
+	dead         map[string]*deadSummary // The members that died (species or type: summary)
+	kills        int                     // The beings the predators killed since the latest sample
+	predatorPrey []GoWorld.PredatorPreySample
+	sampled      uint64 // The epoch of the latest sample
+	// The energy flows of the current window and of the whole run (from and to level: flow)
+	flows, totals map[[2]string]*GoWorld.EnergyFlow
+	windows       []GoWorld.EnergyWindow // The windows already closed
+	windowStart   uint64                 // The first epoch of the current window
+}
+
+// deadSummary adds up the members of a species (or type) that died
+type deadSummary struct {
+	beingType string
+	deaths    int
+	lived     float64 // The ages they reached
+	kills     int     // The beings they killed
+}
+
+// newStats starts following the species of the world
+func newStats(w *RandomWorld) *Stats {
+	return &Stats{world: w, dead: make(map[string]*deadSummary), flows: make(map[[2]string]*GoWorld.EnergyFlow),
+		totals: make(map[[2]string]*GoWorld.EnergyFlow), windowStart: w.Epoch}
+}
+
+// recordDeath counts the being that died towards its species and type (call it once it is off the map)
+func (s *Stats) recordDeath(b *GoWorld.Being) {
+	for _, name := range []string{s.world.speciesOf(b), b.Type} {
+		d := s.dead[name]
+		if d == nil {
+			d = &deadSummary{beingType: b.Type}
+			s.dead[name] = d
+		}
+		d.deaths++
+		d.lived += b.Age
+		d.kills += b.Kills
+	}
+}
+
+// recordKill counts a being killed by a predator towards the next sample
+func (s *Stats) recordKill() {
+	s.kills++
+}
+
+// tick samples the predators and prey and closes the energy window when it is time (call it at the end of every
+// epoch)
+func (s *Stats) tick() {
+	s.closeEnergyWindow()
+	w := s.world
+	if w.Epoch%predatorPreyEvery != 0 || w.Epoch == s.sampled {
+		return
+	}
+	sample := GoWorld.PredatorPreySample{Epoch: w.Epoch, Kills: s.kills}
+	for _, b := range w.BeingList {
+		if PredatorTypes[b.Type] {
+			sample.Predators++
+		} else {
+			sample.Prey++
+		}
+	}
+	if sample.Predators > 0 && w.Epoch > s.sampled {
+		sample.KillRate = float64(s.kills) / float64(sample.Predators) / float64(w.Epoch-s.sampled)
+	}
+	s.kills = 0
+	s.sampled = w.Epoch
+	s.predatorPrey = append(s.predatorPrey, sample)
+	if len(s.predatorPrey) > predatorPreyKept {
+		// Copied, so the dropped samples do not stay in memory
+		kept := s.predatorPrey[len(s.predatorPrey)-predatorPreyKept:]
+		s.predatorPrey = append([]GoWorld.PredatorPreySample(nil), kept...)
+	}
+}
+
+// PredatorPrey returns the samples of the predator and prey populations and the kill rates (the oldest first), e.g.
+// to plot the predators against the prey and see the cycles of their populations
+func (s *Stats) PredatorPrey() []GoWorld.PredatorPreySample {
+	return append([]GoWorld.PredatorPreySample(nil), s.predatorPrey...)
+}
+
+// SpeciesSummary returns the summary of every species of the living beings and of every type (the ones that died out
+// included), ordered by their names. The beings that died count towards the species they belonged to at the time
+func (s *Stats) SpeciesSummary() []GoWorld.SpeciesSummary {
+	w := s.world
+	summaries := make(map[string]*GoWorld.SpeciesSummary)
+	summaryOf := func(name, beingType string) *GoWorld.SpeciesSummary {
+		summary := summaries[name]
+		if summary == nil {
+			summary = &GoWorld.SpeciesSummary{Species: name, Type: beingType, Means: make(map[string]float64),
+				Variances: make(map[string]float64)}
+			summaries[name] = summary
+		}
+		return summary
+	}
+	// Add up the living members (the sums of the values and of their squares give the means and variances)
+	squares := make(map[string]map[string]float64)
+	for id, species := range w.Species() {
+		b := w.BeingList[id]
+		for _, name := range []string{species, b.Type} {
+			summary := summaryOf(name, b.Type)
+			summary.Population++
+			summary.Kills += b.Kills
+			if squares[name] == nil {
+				squares[name] = make(map[string]float64)
+			}
+			for _, attribute := range summaryAttributes {
+				value, _ := w.attributeOf(b, attribute)
+				summary.Means[attribute] += *value
+				squares[name][attribute] += *value * *value
+			}
+		}
+	}
+	for name, d := range s.dead {
+		// The species (or type) may have no living members left
+		summary := summaryOf(name, d.beingType)
+		summary.Deaths = d.deaths
+		summary.Lifespan = d.lived / float64(d.deaths)
+		summary.Kills += d.kills
+	}
+	names := make([]string, 0, len(summaries))
+	for name, summary := range summaries {
+		names = append(names, name)
+		if summary.Population == 0 {
+			continue
+		}
+		n := float64(summary.Population)
+		for _, attribute := range summaryAttributes {
+			mean := summary.Means[attribute] / n
+			summary.Means[attribute] = mean
+			summary.Variances[attribute] = squares[name][attribute]/n - mean*mean
+			if summary.Variances[attribute] < 0 {
+				// Rounding errors of (nearly) equal values
+				summary.Variances[attribute] = 0
+			}
+		}
+	}
+	sort.Strings(names)
+	list := make([]GoWorld.SpeciesSummary, 0, len(names))
+	for _, name := range names {
+		list = append(list, *summaries[name])
+	}
+	return list
+}
+
+// SpeciesSummary returns the summary of every species and every type of the world (see Stats.SpeciesSummary)
+func (w *RandomWorld) SpeciesSummary() []GoWorld.SpeciesSummary {
+	if w.Stats == nil {
+		w.Stats = newStats(w)
+	}
+	return w.Stats.SpeciesSummary()
+}
+
+// Census returns how many beings of each type live in the world (types without living beings are left out)
+func (w *RandomWorld) Census() map[string]int {
+	population := make(map[string]int)
+	for _, b := range w.BeingList {
+		population[b.Type]++
+	}
+	return population
+}
+
+// PredatorPrey returns the predator and prey samples of the run (see Stats.PredatorPrey)
+func (w *RandomWorld) PredatorPrey() []GoWorld.PredatorPreySample {
+	if w.Stats == nil {
+		w.Stats = newStats(w)
+	}
+	return w.Stats.PredatorPrey()
+}
@@ -0,0 +1,178 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// Which being types move in groups while wandering and how strongly they follow each rule (see WithSteering)
+	// Flying beings move in herds, water beings in schools that stay in deep water and insects in swarms
+	defaultSteeringModes = map[string]SteeringWeights{
+		"Flying": {Cohesion: 1.0, Separation: 1.5, Alignment: 1.0, Distance: 3},
+		"Water":  {Cohesion: 1.5, Separation: 1.0, Alignment: 1.5, DeepWater: 2.0, Distance: 2},
+		"Insect": {Cohesion: 2.0, Separation: 0.5, Alignment: 0.5, Distance: 1},
+	}
+	// Water spots without land this close are deep water
+	deepWaterDistance = 3
+	// How far (in radians) scattering beings can stray from running directly away from the predator
+	scatterAngle = math.Pi / 3
+	// Beings with at least this many group members close by are only hunted by starving predators
+	herdProtectionSize = 3
+)
+
+// SteeringWeights define how a being steers when moving with nearby beings of the same type (boids)
+type SteeringWeights struct {
+	Cohesion   float64 // Pull towards the center of the group
+	Separation float64 // Push away from group members that are too close
+	Alignment  float64 // Follow the average heading of the group
+	DeepWater  float64 // Pull away from the shore into deep water
+	Distance   float64 // Group members closer than this are too close
+}
+
+// vector is a 2D direction used for steering
+type vector struct {
+	X, Y float64
+}
+
+// normalized returns the vector with length 1 (or the zero vector)
+func (v vector) normalized() vector {
+	length := math.Hypot(v.X, v.Y)
+	if length == 0 {
+		return vector{}
+	}
+	return vector{v.X / length, v.Y / length}
+}
+
+// BeingsAround returns all beings within the radius around the location
+func (w *RandomWorld) BeingsAround(location GoWorld.Location, radius float64) []*GoWorld.Being {
+	return w.beingsIn(w.MidpointCircleAt(location, radius))
+}
+
+// beingsIn returns the beings standing on the provided spots
+func (w *RandomWorld) beingsIn(spots []GoWorld.Location) []*GoWorld.Being {
+	var beings []*GoWorld.Being
+	for _, spot := range spots {
+		id := w.TerrainSpots[spot.X][spot.Y].Being
+		if id == uuid.Nil {
+			continue
+		}
+		if other := w.BeingList[id.String()]; other != nil {
+			beings = append(beings, other)
+		}
+	}
+	return beings
+}
+
+// groupMembers returns the beings of the same type inside the provided spots (without the being itself)
+func (w *RandomWorld) groupMembers(b *GoWorld.Being, spots []GoWorld.Location) []*GoWorld.Being {
+	var members []*GoWorld.Being
+	for _, other := range w.beingsIn(spots) {
+		if other.ID != b.ID && other.Type == b.Type {
+			members = append(members, other)
+		}
+	}
+	return members
+}
+
+// protectedByHerd returns true if enough members of the being's herd are close enough to protect it from predators
+func (w *RandomWorld) protectedByHerd(b *GoWorld.Being) bool {
+	weights, ok := w.Settings.SteeringModes[b.Type]
+	if !ok {
+		return false
+	}
+	closeBy := w.MidpointCircleAt(b.Position, weights.Distance*2)
+	return len(w.groupMembers(b, closeBy)) >= herdProtectionSize
+}
+
+// shoreDirection returns the direction away from land around the location (zero vector in deep water)
+func (w *RandomWorld) shoreDirection(location GoWorld.Location) vector {
+	var away vector
+	for _, direction := range directions8 {
+		spot := GoWorld.Location{
+			X: location.X + direction.X*deepWaterDistance,
+			Y: location.Y + direction.Y*deepWaterDistance,
+		}
+		if w.IsOutOfBounds(spot) || w.TerrainSpots[spot.X][spot.Y].Surface.CommonName != "Water" {
+			away.X -= float64(direction.X)
+			away.Y -= float64(direction.Y)
+		}
+	}
+	return away.normalized()
+}
+
+// scatterFrom returns a spot away from the predator in a random direction (so the group splits up)
+// Returns false if no suitable spot was found
+func (w *RandomWorld) scatterFrom(b *GoWorld.Being, predatorSpot GoWorld.Location) (GoWorld.Location, bool) {
+	angle := math.Atan2(float64(b.Position.Y-predatorSpot.Y), float64(b.Position.X-predatorSpot.X))
+	angle += (w.random.Float64()*2 - 1) * scatterAngle
+	for step := math.Max(w.currentSpeed(b), 1); step >= 1; step /= 2 {
+		spot := GoWorld.Location{
+			X: b.Position.X + int(math.Round(math.Cos(angle)*step)),
+			Y: b.Position.Y + int(math.Round(math.Sin(angle)*step)),
+		}
+		if spot != b.Position && !w.IsOutOfBounds(spot) && w.canPlaceBeing(spot, b.Type) {
+			return spot, true
+		}
+	}
+	return GoWorld.Location{}, false
+}
+
+// steeringFor combines the cohesion, separation and alignment rules with the group members into one direction
+func (w *RandomWorld) steeringFor(b *GoWorld.Being, members []*GoWorld.Being, weights SteeringWeights) vector {
+	var center, heading, away vector
+	for _, m := range members {
+		center.X += float64(m.Position.X)
+		center.Y += float64(m.Position.Y)
+		heading.X += float64(m.Heading.X)
+		heading.Y += float64(m.Heading.Y)
+		dX := float64(b.Position.X - m.Position.X)
+		dY := float64(b.Position.Y - m.Position.Y)
+		if dist := math.Hypot(dX, dY); dist < weights.Distance && dist > 0 {
+			// The closer the member, the stronger the push
+			away.X += dX / (dist * dist)
+			away.Y += dY / (dist * dist)
+		}
+	}
+	n := float64(len(members))
+	cohesion := vector{center.X/n - float64(b.Position.X), center.Y/n - float64(b.Position.Y)}.normalized()
+	alignment := heading.normalized()
+	separation := away.normalized()
+	depth := vector{}
+	if weights.DeepWater != 0 {
+		depth = w.shoreDirection(b.Position)
+	}
+
+	return vector{
+		X: cohesion.X*weights.Cohesion + separation.X*weights.Separation + alignment.X*weights.Alignment +
+			depth.X*weights.DeepWater,
+		Y: cohesion.Y*weights.Cohesion + separation.Y*weights.Separation + alignment.Y*weights.Alignment +
+			depth.Y*weights.DeepWater,
+	}
+}
+
+// steerWithGroup returns the spot the being should move to when moving with its group
+// Returns false if no group members are in sight or no suitable spot was found in the steering direction
+func (w *RandomWorld) steerWithGroup(b *GoWorld.Being, surroundings []GoWorld.Location,
+	weights SteeringWeights) (GoWorld.Location, bool) {
+	members := w.groupMembers(b, surroundings)
+	if len(members) == 0 {
+		return GoWorld.Location{}, false
+	}
+	direction := w.steeringFor(b, members, weights).normalized()
+	if direction.X == 0 && direction.Y == 0 {
+		return GoWorld.Location{}, false
+	}
+	// Add some randomness so the group does not move in perfect lines, shorten the step if the spot is not suitable
+	for step := w.currentSpeed(b); step >= 1; step /= 2 {
+		spot := GoWorld.Location{
+			X: b.Position.X + int(math.Round(direction.X*step+w.random.NormFloat64()*0.5)),
+			Y: b.Position.Y + int(math.Round(direction.Y*step+w.random.NormFloat64()*0.5)),
+		}
+		if spot != b.Position && !w.IsOutOfBounds(spot) && w.canPlaceBeing(spot, b.Type) {
+			return spot, true
+		}
+	}
+	return GoWorld.Location{}, false
+}
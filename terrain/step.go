@@ -0,0 +1,31 @@
+package terrain
+
+import "sort"
+
+// Step moves the world one epoch forward without a display: every plant and being is updated once (in the order of
+// their IDs, so seeded worlds stay the same) and the time advances
+func (w *RandomWorld) Step() {
+	plants := make([]string, 0, len(w.FoodList))
+	for id := range w.FoodList {
+		plants = append(plants, id)
+	}
+	sort.Strings(plants)
+	for _, id := range plants {
+		// Plants and eggs eaten earlier in the epoch are skipped
+		if f, ok := w.FoodList[id]; ok {
+			w.UpdatePlant(f)
+		}
+	}
+	beings := make([]string, 0, len(w.BeingList))
+	for id := range w.BeingList {
+		beings = append(beings, id)
+	}
+	sort.Strings(beings)
+	for _, id := range beings {
+		// Beings that died earlier in the epoch are skipped
+		if b, ok := w.BeingList[id]; ok {
+			w.UpdateBeing(b)
+		}
+	}
+	w.AdvanceTime()
+}
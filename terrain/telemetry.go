@@ -0,0 +1,71 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"sort"
+	"time"
+)
+
+var (
+	// How many of the latest ticks the timing percentiles are computed from
+	timingWindow = 600
+)
+
+// telemetry keeps how long every phase of the latest ticks took (see Timings)
+type telemetry struct {
+	current map[string]time.Duration   // How long every phase took so far in the tick in progress
+	windows map[string][]time.Duration // How long every phase took in the latest ticks (a ring for each phase)
+	next    int                        // Where the next tick goes into the rings
+	ticks   int                        // How many ticks the rings hold
+}
+
+// timeSince adds the time passed since the start to the phase of the current tick (defer it at the start of a phase)
+func (w *RandomWorld) timeSince(phase string, started time.Time) {
+	w.RecordTiming(phase, time.Since(started))
+}
+
+// RecordTiming adds the duration to the phase of the current tick, the world times its own phases (Beings, Sensing,
+// Pathing, Plants and Time), others can add theirs (e.g. the display adds Rendering)
+func (w *RandomWorld) RecordTiming(phase string, d time.Duration) {
+	if w.timings.current == nil {
+		w.timings.current = make(map[string]time.Duration)
+	}
+	w.timings.current[phase] += d
+}
+
+// endTick moves the phase durations of the current tick into the rings
+func (t *telemetry) endTick() {
+	if t.windows == nil {
+		t.windows = make(map[string][]time.Duration)
+	}
+	for phase := range t.current {
+		if t.windows[phase] == nil {
+			t.windows[phase] = make([]time.Duration, timingWindow)
+		}
+	}
+	for phase, window := range t.windows {
+		// Phases that did not happen in the tick took no time
+		window[t.next] = t.current[phase]
+		delete(t.current, phase)
+	}
+	t.next = (t.next + 1) % timingWindow
+	if t.ticks < timingWindow {
+		t.ticks++
+	}
+}
+
+// Timings returns the rolling percentiles of how long every phase of a tick took (over the latest ticks)
+func (w *RandomWorld) Timings() map[string]GoWorld.Timing {
+	t := &w.timings
+	timings := make(map[string]GoWorld.Timing, len(t.windows))
+	for phase, window := range t.windows {
+		latest := make([]time.Duration, t.ticks)
+		copy(latest, window)
+		sort.Slice(latest, func(i, j int) bool { return latest[i] < latest[j] })
+		at := func(percentile int) time.Duration {
+			return latest[(len(latest)-1)*percentile/100]
+		}
+		timings[phase] = GoWorld.Timing{P50: at(50), P90: at(90), P99: at(99), Max: at(100)}
+	}
+	return timings
+}
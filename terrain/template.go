@@ -0,0 +1,117 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/rubinda/GoWorld"
+	"sort"
+)
+
+// creators create a random being of the type (placed onto the map)
+var creators = map[string]func(w *RandomWorld) (*GoWorld.Being, error){
+	"Carnivore": (*RandomWorld).CreateRandomCarnivore,
+	"Water":     (*RandomWorld).CreateRandomFish,
+	"Flying":    (*RandomWorld).CreateRandomFlyer,
+	"Scavenger": (*RandomWorld).CreateRandomScavenger,
+	"Amphibian": (*RandomWorld).CreateRandomAmphibian,
+	"Insect":    (*RandomWorld).CreateRandomInsect,
+}
+
+// BeingTemplate describes a phenotype: beings created from it are random, except for the attributes it pins (Fixed
+// distribution) or biases (Normal distribution), e.g. {Type: "Amphibian", Attributes: {"Speed": {Kind: "Fixed", Mean:
+// 2}, "Durability": {Kind: "Normal", Mean: 200, StdDev: 20}}} for slow but durable grazers
+type BeingTemplate struct {
+	Type       string                  // The being type (e.g. Carnivore)
+	Gender     string                  // The gender of the beings (random if empty)
+	Attributes map[string]Distribution // Being attributes (named as the Being fields) and how their values spread
+}
+
+// attributeOf returns the named numeric attribute of the being and the range of its values
+// Returns nil if the being has no such attribute
+func (w *RandomWorld) attributeOf(b *GoWorld.Being, name string) (*float64, *attributeRange) {
+	switch name {
+	case "Hunger":
+		return &b.Hunger, w.rangeOf("Hunger")
+	case "Thirst":
+		return &b.Thirst, w.rangeOf("Thirst")
+	case "WantsChild":
+		return &b.WantsChild, w.rangeOf("WantsChild")
+	case "Sleepiness":
+		return &b.Sleepiness, w.rangeOf("Sleepiness")
+	case "LifeExpectancy":
+		return &b.LifeExpectancy, w.lifeExpectancyRangeFor(b.Type)
+	case "MaturityAge":
+		return &b.MaturityAge, w.maturityRangeFor(b.Type)
+	case "VisionRange":
+		return &b.VisionRange, w.rangeOf("Vision")
+	case "MemorySize":
+		return &b.MemorySize, w.rangeOf("MemorySize")
+	case "Speed":
+		return &b.Speed, w.rangeOf("Speed")
+	case "Durability":
+		return &b.Durability, w.rangeOf("Durability")
+	case "Energy":
+		return &b.Energy, w.rangeOf("Energy")
+	case "Injury":
+		return &b.Injury, injuryRange
+	case "Parasites":
+		return &b.Parasites, parasiteRange
+	case "Camouflage":
+		return &b.Camouflage, w.rangeOf("Camouflage")
+	case "Resistance":
+		return &b.Resistance, w.rangeOf("Resistance")
+	case "Stress":
+		return &b.Stress, w.rangeOf("Stress")
+	case "Size":
+		return &b.Size, w.sizeRangeFor(b.Type)
+	case "Fertility":
+		return &b.Fertility, w.fertilityRangeFor(b.Type)
+	case "MutationRate":
+		return &b.MutationRate, w.rangeOf("Mutation")
+	}
+	return nil, nil
+}
+
+// CreateBeingsFromTemplate generates n beings of the phenotype the template describes and places them onto the map
+// Returns an error if the template has an unknown being type, attribute or distribution or there is no room for all
+// the beings
+func (w *RandomWorld) CreateBeingsFromTemplate(n int, tpl BeingTemplate) error {
+	create, known := creators[tpl.Type]
+	if !known {
+		return fmt.Errorf("error creating beings from template: unknown being type %v", tpl.Type)
+	}
+	// Pick the values in the same order every time, so seeded worlds stay the same
+	names := make([]string, 0, len(tpl.Attributes))
+	probe := &GoWorld.Being{Type: tpl.Type}
+	for name, d := range tpl.Attributes {
+		names = append(names, name)
+		if value, _ := w.attributeOf(probe, name); value == nil {
+			return fmt.Errorf("error creating beings from template: unknown attribute %v", name)
+		}
+		if !d.known() {
+			return fmt.Errorf("error creating beings from template: unknown distribution %v for %v", d.Kind, name)
+		}
+	}
+	sort.Strings(names)
+	for i := 0; i < n; i++ {
+		b, err := create(w)
+		if err != nil {
+			return fmt.Errorf("error creating beings from template: placed %d of %d: %v", i, n, err)
+		}
+		for _, name := range names {
+			value, valueRange := w.attributeOf(b, name)
+			// Pick the value from the attribute range with the template distribution
+			r := *valueRange
+			r.Distribution = tpl.Attributes[name]
+			*value = r.randomFloat(w.random)
+		}
+		if _, pinned := tpl.Attributes["MaturityAge"]; pinned {
+			// The initial population is a mix of juveniles and adults
+			b.Age = w.random.Float64() * 2 * b.MaturityAge
+		}
+		if tpl.Gender != "" {
+			b.Gender = tpl.Gender
+		}
+		w.BeingList[b.ID.String()] = b
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package terrain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"image/color"
+)
+
+// surfaceWithID returns the surface of the world with the id (nil if there is none)
+func (w *RandomWorld) surfaceWithID(id uuid.UUID) *Surface {
+	for i := range w.surfaces {
+		if w.surfaces[i].ID == id {
+			return &w.surfaces[i]
+		}
+	}
+	return nil
+}
+
+// SetSurfaceAt changes the surface at the location (e.g. for floods, fires or a map editor). Whatever can not stay on
+// the new surface is removed: food withers, trees and homes are gone and beings step to a free spot next to it (or die
+// if there is none)
+// Returns an error if the location is out of bounds or there is no surface with the id
+func (w *RandomWorld) SetSurfaceAt(location GoWorld.Location, surfaceID uuid.UUID) error {
+	if w.IsOutOfBounds(location) {
+		return fmt.Errorf("error setting surface: location %v is out of bounds", location)
+	}
+	surface := w.surfaceWithID(surfaceID)
+	if surface == nil {
+		return fmt.Errorf("error setting surface: no surface with id %v", surfaceID)
+	}
+	spot := w.TerrainSpots[location.X][location.Y]
+	if spot.Surface == surface {
+		return nil
+	}
+	if w.regionCap != nil {
+		w.regionCap[location.X/regionSize][location.Y/regionSize] += (biomeCapacity[surface.CommonName] -
+			biomeCapacity[spot.Surface.CommonName]) * carryingDensity
+	}
+	// The free spot moves over to the new surface
+	w.unindexSpot(location)
+	spot.Surface = surface
+	w.indexSpot(location)
+	// New water is as shallow as it gets and has no fords
+	spot.Depth = 0
+	spot.Ford = false
+	if spot.Puddle > 0 && !surface.Habitable {
+		w.drainPuddle(location, spot.Puddle)
+	}
+	w.clearObjectAt(location)
+	w.displaceBeingAt(location)
+	w.TerrainZones.Set(location.X, location.Y, w.spotColor(location))
+	return nil
+}
+
+// clearObjectAt removes the object on the location if it can not stay on the current surface
+func (w *RandomWorld) clearObjectAt(location GoWorld.Location) {
+	spot := w.TerrainSpots[location.X][location.Y]
+	id := spot.Object.String()
+	if food := w.FoodList[id]; food != nil && !w.foodFits(food, spot.Surface) {
+		w.recordEnergy(foodLevel(food), "Decay", food.NutritionalValue-food.Eaten, food.NutritionalValue-food.Eaten)
+		w.removeFood(food)
+		w.emit("withered", food.ID)
+	} else if obstacle := w.Obstacles[id]; obstacle != nil && !spot.Surface.Habitable {
+		delete(w.Obstacles, id)
+		w.updatePlantSpot(location.X, location.Y, 1, uuid.Nil)
+	} else if home := w.Homes[id]; home != nil {
+		if owner := w.BeingList[home.Owner.String()]; owner == nil || owner.Habitat != spot.Surface.ID {
+			delete(w.Homes, id)
+			w.updatePlantSpot(location.X, location.Y, 1, uuid.Nil)
+			if owner != nil {
+				owner.Home = uuid.Nil
+			}
+		}
+	}
+}
+
+// displaceBeingAt moves the being on the location to a free spot next to it if it can not stay on the current
+// surface, beings with nowhere to go die
+func (w *RandomWorld) displaceBeingAt(location GoWorld.Location) {
+	spot := w.TerrainSpots[location.X][location.Y]
+	b := w.BeingList[spot.Being.String()]
+	if b == nil {
+		return
+	}
+	// Free the spot to see if the being could step onto it
+	w.setBeingAt(location, uuid.Nil)
+	if w.canPlaceBeing(location, b.Type) {
+		w.setBeingAt(location, b.ID)
+		return
+	}
+	for _, direction := range directions8 {
+		to := GoWorld.Location{X: location.X + direction.X, Y: location.Y + direction.Y}
+		if !w.IsOutOfBounds(to) && w.canPlaceBeing(to, b.Type) {
+			w.setBeingAt(to, b.ID)
+			b.Position = to
+			w.emit("moved", b.ID)
+			return
+		}
+	}
+	w.emit("died", w.die(b, "losing its ground")...)
+}
+
+// foodFits checks if the food can stay on the surface: water plants (and fish eggs) only in water, the rest on land
+func (w *RandomWorld) foodFits(food *GoWorld.Food, surface *Surface) bool {
+	inWater := food.Type == "Water"
+	if egg := w.Eggs[food.ID.String()]; egg != nil {
+		inWater = egg.Embryo.Type == "Water"
+	}
+	if inWater {
+		return surface.CommonName == "Water"
+	}
+	return surface.Habitable
+}
+
+// removeFood takes the food (plant, egg, cache or carrion) off the map
+func (w *RandomWorld) removeFood(food *GoWorld.Food) {
+	delete(w.FoodList, food.ID.String())
+	delete(w.Eggs, food.ID.String())
+	delete(w.Caches, food.ID.String())
+	w.updatePlantSpot(food.Position.X, food.Position.Y, food.Area, uuid.Nil)
+}
+
+// spotColor returns the color the location is painted with on the terrain (of the object on it, the puddle or the
+// surface)
+func (w *RandomWorld) spotColor(location GoWorld.Location) color.RGBA {
+	spot := w.TerrainSpots[location.X][location.Y]
+	if w.Homes[spot.Object.String()] != nil {
+		return homeColor
+	}
+	if obstacle := w.Obstacles[spot.Object.String()]; obstacle != nil {
+		if obstacle.Kind == "Tree" {
+			return treeColor
+		}
+		return boulderColor
+	}
+	if spot.Puddle > 0 {
+		return puddleColor
+	}
+	if spot.Ford {
+		return fordColor
+	}
+	return spot.Surface.Color
+}
@@ -2,6 +2,7 @@ package terrain
 
 import (
 	. "bufio"
+	"compress/gzip"
 	. "encoding/json"
 	"errors"
 	"fmt"
@@ -12,9 +13,11 @@ import (
 	"image"
 	"image/color"
 	"image/png"
+	"io"
 	"math"
 	"math/rand"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -31,38 +34,109 @@ var (
 	// Used when converting HEX color to RGB
 	errInvalidFormat = errors.New("invalid HEX string format")
 
-	// The ranges of being attributes used when randomly generating a new being
-	// Todo move these ranges to a config file (or better yet allow the user to specify them)
-	hungerRange         = &attributeRange{0, 255}
-	thirstRange         = &attributeRange{0, 255}
-	wantsChildRange     = &attributeRange{0, 255}
-	lifeExpectancyRange = &attributeRange{1, 64}
-	visionRange         = &attributeRange{1, 64}
-	speedRange          = &attributeRange{1, 16}
-	durabilityRange     = &attributeRange{0, 255}
-	stressRange         = &attributeRange{0, 255}
-	sizeRange           = &attributeRange{0, 64}
-	fertilityRange      = &attributeRange{0, 4}
-	mutationRange       = &attributeRange{0, 31}
+	// The ranges of being attributes used when randomly generating a new being (uniformly distributed by default, see
+	// WithBeingRange and WithDistribution to change them)
+	hungerRange         = &attributeRange{Min: 0, Max: 255}
+	thirstRange         = &attributeRange{Min: 0, Max: 255}
+	wantsChildRange     = &attributeRange{Min: 0, Max: 255}
+	lifeExpectancyRange = &attributeRange{Min: 1, Max: 64}
+	visionRange         = &attributeRange{Min: 1, Max: 64}
+	speedRange          = &attributeRange{Min: 1, Max: 16}
+	durabilityRange     = &attributeRange{Min: 0, Max: 255}
+	stressRange         = &attributeRange{Min: 0, Max: 255}
+	sizeRange           = &attributeRange{Min: 0, Max: 64}
+	fertilityRange      = &attributeRange{Min: 0, Max: 4}
+	mutationRange       = &attributeRange{Min: 0, Max: 31}
+	maturityRange       = &attributeRange{Min: 1, Max: 16}
+	energyRange         = &attributeRange{Min: 0, Max: 255}
+	sleepinessRange     = &attributeRange{Min: 0, Max: 255}
+	injuryRange         = &attributeRange{Min: 0, Max: 255}
+	camouflageRange     = &attributeRange{Min: 0, Max: 255}
+	memorySizeRange     = &attributeRange{Min: 0, Max: 8}
+	resistanceRange     = &attributeRange{Min: 0, Max: 128}
 
 	// Attribute ranges for food
-	growthRange        = &attributeRange{0, 15}
-	nutritionRange     = &attributeRange{0, 128}
-	tasteRange         = &attributeRange{0, 255}
-	stageRange         = &attributeRange{0, 3}
-	stageProgressRange = &attributeRange{0, 255}
-	areaRange          = &attributeRange{4, 32}
-	seedRange          = &attributeRange{3, 8}
-	witherRange        = &attributeRange{1, 256}
-	disperseRange      = &attributeRange{1, 8}
-
-	// Being thresholds for action
+	growthRange        = &attributeRange{Min: 0, Max: 15}
+	nutritionRange     = &attributeRange{Min: 0, Max: 128}
+	tasteRange         = &attributeRange{Min: 0, Max: 255}
+	stageRange         = &attributeRange{Min: 0, Max: 3}
+	stageProgressRange = &attributeRange{Min: 0, Max: 255}
+	areaRange          = &attributeRange{Min: 4, Max: 32}
+	seedRange          = &attributeRange{Min: 3, Max: 8}
+	witherRange        = &attributeRange{Min: 1, Max: 256}
+	disperseRange      = &attributeRange{Min: 1, Max: 8}
+	toxicityRange      = &attributeRange{Min: 0, Max: 128}
+
+	// How close another plant of the same type has to grow for a plant to be pollinated (see WithPollination)
+	pollinationRadius = 64.
+	// How much of the eaten nutritional value a plant regrows every update
+	plantRegrowth = 0.1
+	// The share of the stage progress a plant loses when a being takes a bite of it
+	biteProgressLoss = 0.5
+
+	// Being thresholds for action (defaults for the world settings)
 	hungerThreshold = 150.
 	stressThreshold = 175.
-	// Being increments for basic necessities
+	// Beings with energy below the exhaustion threshold must rest, below the rest threshold they rest when no other
+	// need is urgent. Carnivores only sprint after prey when they have more energy than the sprint threshold
+	exhaustedThreshold = 10.
+	restThreshold      = 64.
+	sprintThreshold    = 96.
+	// Energy used for moving one spot (scaled by speed and surface), sprinting and restored by resting per epoch
+	moveEnergyCost   = 0.05
+	sprintEnergyCost = 5.
+	restEnergyGain   = 2.
+	// Being increments for basic necessities (defaults for the world settings)
 	hungerIncrease     = 0.2
 	thirstIncrease     = 0.3
 	wantsChildIncrease = 0.05
+	sleepinessIncrease = 0.1
+	// How much sleepiness lowers for every epoch of sleep
+	sleepRecovery = 1.
+	// The number of epochs in a day (the second half of the day is the night)
+	defaultDayLength uint64 = 3600
+	// The number of days in a year (every season lasts a quarter of the year)
+	defaultYearLength uint64 = 4
+	// Seasons in the order they follow each other
+	seasons = [4]string{"Spring", "Summer", "Autumn", "Winter"}
+	// The being types that retreat to their habitat and hibernate during winter (see WithHibernation)
+	defaultHibernatingTypes = map[string]bool{
+		"Carnivore": true,
+	}
+	// How much of the usual hunger and thirst increase hibernating beings have
+	hibernationMetabolism = 0.1
+	// The map is divided into square regions of this size for regional statistics (e.g. food for migrations)
+	regionSize = 100
+	// How often (in epochs) the regional statistics are refreshed
+	regionUpdateInterval uint64 = 60
+	// Hungry beings migrate if their region has less food sources than this and a region with more is known
+	migrationThreshold = 2
+	// The chance that a randomly created being of a type is active at night
+	nocturnalChance = map[string]float64{
+		"Carnivore": 0.5,
+		"Flying":    0.2,
+		"Water":     0.1,
+		"Scavenger": 0.3,
+		"Amphibian": 0.4,
+		"Insect":    0.1,
+	}
+	// How the attributes of each being type change with age (see WithAgeCurve). Attributes without a curve stay the
+	// same for the whole life of the being
+	defaultAgeCurves = map[string]map[string]AgeCurve{
+		"Carnivore": {
+			"Speed":       {Birth: 0.4, Peak: 0.3, Old: 0.5},
+			"VisionRange": {Birth: 0.6, Peak: 0.4, Old: 0.6},
+		},
+		"Flying": {
+			"Speed":       {Birth: 0.3, Peak: 0.2, Old: 0.6},
+			"VisionRange": {Birth: 0.5, Peak: 0.3, Old: 0.7},
+		},
+		"Water": {
+			"Speed":       {Birth: 0.5, Peak: 0.3, Old: 0.7},
+			"VisionRange": {Birth: 0.7, Peak: 0.5, Old: 0.8},
+		},
+	}
+
 	// Movespeed of water plants (is fixed)
 	seaweedMoveSpeed = 3
 
@@ -94,15 +168,45 @@ var (
 
 // RandomWorld represents the world implementation using Perlin Noise as terrain
 type RandomWorld struct {
-	// TODO introduce MaxBeings based on world size / terrain
 	Width, Height int
+	MaxBeings     int         // The carrying capacity, births stop once it is reached (derived from the terrain if 0)
+	Settings      Settings    // The world configuration (see NewRandomWorld for setting it with options)
 	TerrainImage  *image.Gray // TerrainImage holds the terrain surface image (like a DEM model)
 	TerrainZones  *image.RGBA // TerrainZones is a colored version of TerrainImage (based on defined zones and ratios)
 	TerrainSpots  [][]*Spot   // TerrainSpots holds data about each spot on the map (what surface, what object or being
 	// occupies it)
 	BeingList  map[string]*GoWorld.Being // The list of world inhabitants
 	FoodList   map[string]*GoWorld.Food  // List of all edible food
-	pathFinder GoWorld.Pathfinder
+	Epoch      uint64                    // The number of epochs (updates) since the world was created
+	DayLength  uint64                    // The number of epochs in a day and night cycle (defaults to 3600)
+	YearLength uint64                    // The number of days in a year (defaults to 4, a day per season)
+	// Territories claimed by beings (owner ID: Territory)
+	Territories map[string]*Territory
+	Obstacles   map[string]*Obstacle // Inert objects (trees, boulders) that land beings can not move through
+	Homes       map[string]*Home     // Nests and burrows built by beings
+	Eggs        map[string]*Egg      // The offspring in the eggs laid on the map (egg food ID: Egg)
+	Caches      map[string]uuid.UUID // Food cached by hoarding beings (cache food ID: owner ID)
+	// Replace the built-in decision making for all beings of a type (Type: Brain). A brain set on the being itself
+	// takes precedence
+	Brains      map[string]GoWorld.Brain
+	pathFinder  GoWorld.Pathfinder
+	surfaces    []Surface                 // The surfaces of this world, the ones its spots point to (see WithSurfaces)
+	regionFood  [][]map[string]int        // The number of food sources per region for each being type that can eat them
+	regionPop   [][]int                   // The number of beings per region
+	regionCap   [][]float64               // How many beings every region supports (based on its surfaces)
+	scentSpots  map[GoWorld.Location]bool // The spots with scent on them
+	puddleSpots map[GoWorld.Location]bool // The spots with puddles on them
+	listeners   []Listener                // Told about the changes made to the world from outside (see Subscribe)
+	timings     telemetry                 // How long the phases of the latest ticks took (see Timings)
+	exports     ExportSchedule            // When the beings and plants are exported (see ScheduleExports)
+	schedule    []ScenarioEvent           // The scenario events still to happen (ordered by their epochs)
+	traced      uuid.UUID                 // The being whose plans are kept (see TraceBeing)
+	tracedPlan  *GoWorld.Plan             // The latest plan of the traced being (nil if it planned nothing)
+	names       map[uuid.UUID]string      // The names of beings and food, kept once they are gone (see EntityName)
+	latest      map[string]latestUpdate   // What every being planned and did in its latest update (see DescribeBeing)
+	freeSpots   map[uuid.UUID]*spotSet    // The spots without a being on each surface (surface ID: spots, see freeSpot)
+	Stats       *Stats                    // Follows the species over the run (see Stats.SpeciesSummary)
+	random      *rand.Rand                // The random numbers and IDs of this world (seeded by Settings.Seed)
 }
 
 // Spot is a place on the map with a defined surface type.
@@ -113,6 +217,12 @@ type Spot struct {
 	Being          uuid.UUID // The being on the spot (nil for noone)
 	OccupyingPlant uuid.UUID // The plant using this spot for growth (see Food.Area) not necessarily visible on surface
 	// if this is nil, a plant can be placed here (given enough room around for its area)
+	PreyScent     float64 // The scent left behind by prey beings (decays and spreads over time)
+	PredatorScent float64 // The scent left behind by carnivores
+	Nutrients     float64 // Soil nutrients left by decomposed carrion (plants seeded here grow faster)
+	Depth         float64 // How deep the water is (0 at the shore and on land, 1 at the lowest point of the terrain)
+	Ford          bool    // Shallow and narrow water that land beings can cross
+	Puddle        float64 // Water in a puddle left by rain (0 if there is no puddle)
 }
 
 // Surface represents the data about a certain zone
@@ -125,26 +235,76 @@ type Surface struct {
 	// water) or if a plant can grow here
 }
 
+// AgeCurve describes how an attribute rises to its peak and declines afterwards during the life of a being
+// The attribute value is multiplied with the share the curve provides at the current age
+type AgeCurve struct {
+	Birth float64 // The share of the attribute available at birth
+	Peak  float64 // The share of the whole lifetime (0-1) at which the attribute reaches its full value
+	Old   float64 // The share of the attribute left at the end of life
+}
+
+// share returns the attribute multiplier at the provided share of lifetime (0 at birth, 1 at death)
+func (c AgeCurve) share(lived float64) float64 {
+	if lived < c.Peak {
+		// Rising towards the peak
+		return c.Birth + (1-c.Birth)*lived/c.Peak
+	}
+	if c.Peak >= 1 {
+		return 1
+	}
+	// Declining towards old age
+	return 1 - (1-c.Old)*(lived-c.Peak)/(1-c.Peak)
+}
+
+// Distribution describes how random values of an attribute spread across its range
+type Distribution struct {
+	Kind   string  // Uniform (default if empty), Normal or Fixed
+	Mean   float64 // The mean of a normal distribution or the value of a fixed one
+	StdDev float64 // The standard deviation of a normal distribution
+}
+
+// known checks if the distribution is of a kind values can be picked with
+func (d Distribution) known() bool {
+	switch d.Kind {
+	case "", "Uniform", "Normal", "Fixed":
+		return true
+	}
+	return false
+}
+
 // attributeRange is used to define the minimum and maximum value of an attribute
 type attributeRange struct {
-	Min float64
-	Max float64
+	Min          float64
+	Max          float64
+	Distribution Distribution // How the random values are picked from the range
 }
 
-// randomFloat returns a random floating point number for the given attribute range
-func (r *attributeRange) randomFloat() float64 {
-	return rand.Float64()*r.Max + r.Min
+// randomFloat returns a random floating point number for the given attribute range (picked by its distribution from
+// the random generator)
+func (r *attributeRange) randomFloat(random *rand.Rand) float64 {
+	switch r.Distribution.Kind {
+	case "Fixed":
+		return r.clamp(r.Distribution.Mean)
+	case "Normal":
+		return r.clamp(random.NormFloat64()*r.Distribution.StdDev + r.Distribution.Mean)
+	default:
+		return r.Min + random.Float64()*(r.Max-r.Min)
+	}
 }
 
 // randomInt returns a random integer value from the range
-func (r *attributeRange) randomInt() int {
-	return int(rand.Float64()*r.Max + r.Min)
+func (r *attributeRange) randomInt(random *rand.Rand) int {
+	return int(r.randomFloat(random))
+}
+
+// clamp limits the value to the range
+func (r *attributeRange) clamp(value float64) float64 {
+	return math.Max(r.Min, math.Min(r.Max, value))
 }
 
 // randomGender picks a gender with a 50/50 chance
-func randomGender() string {
-	rand.Seed(time.Now().UnixNano())
-	coinFlip := rand.Intn(2)
+func (w *RandomWorld) randomGender() string {
+	coinFlip := w.random.Intn(2)
 	if coinFlip > 0 {
 		return "female"
 	}
@@ -152,28 +312,97 @@ func randomGender() string {
 
 }
 
-// PlantsToJSON stores the current edible plants in the world into a json file
-func (w *RandomWorld) PlantsToJSON(fileName string) {
-	fi, _ := os.Create(fileName)
-	defer fi.Close()
-	fz := NewWriter(fi)
-	e := NewEncoder(fz)
+// isJuvenile returns true if the being has not yet reached its maturity age
+// Juveniles can not mate, can only hunt prey up to their own size and are easier targets for predators
+func isJuvenile(b *GoWorld.Being) bool {
+	return b.Age < b.MaturityAge
+}
 
-	if err := e.Encode(w.FoodList); err != nil {
-		panic(err)
+// bodySize returns the current physical size of the being
+// Juveniles grow into their Size, starting at half of it when born and reaching the full size when maturing
+func bodySize(b *GoWorld.Being) float64 {
+	if isJuvenile(b) {
+		return b.Size * (0.5 + 0.5*b.Age/b.MaturityAge)
 	}
+	return b.Size
 }
 
-// BeingsToJSON stores the current living beings to a file
-func (w *RandomWorld) BeingsToJSON(fileName string) {
-	fi, _ := os.Create(fileName)
-	defer fi.Close()
-	fz := NewWriter(fi)
-	e := NewEncoder(fz)
+// ageShare returns the multiplier for the named attribute of the being based on its age and the age curves of its type
+func (w *RandomWorld) ageShare(b *GoWorld.Being, attribute string) float64 {
+	curve, ok := w.Settings.AgeCurves[b.Type][attribute]
+	if !ok {
+		return 1
+	}
+	// The whole lifetime is the age plus the epochs the being has left
+	lifetime := b.Age + b.LifeExpectancy
+	if lifetime <= 0 {
+		return curve.Old
+	}
+	lived := math.Min(math.Max(b.Age/lifetime, 0), 1)
+	return curve.share(lived)
+}
+
+// currentSpeed returns the speed of the being adjusted for its age and injuries
+func (w *RandomWorld) currentSpeed(b *GoWorld.Being) float64 {
+	return b.Speed * w.ageShare(b, "Speed") * (1 - injuryShare*b.Injury/injuryRange.Max)
+}
+
+// currentVision returns the vision range of the being adjusted for its age
+func (w *RandomWorld) currentVision(b *GoWorld.Being) float64 {
+	return b.VisionRange * w.ageShare(b, "VisionRange")
+}
 
-	if err := e.Encode(w.BeingList); err != nil {
-		panic(err)
+// PlantsToJSON stores the current edible plants in the world into a json file (gzipped if the name ends with .gz)
+func (w *RandomWorld) PlantsToJSON(fileName string) error {
+	return writeJSON(fileName, w.FoodList)
+}
+
+// BeingsToJSON stores the current living beings to a file (gzipped if the name ends with .gz)
+func (w *RandomWorld) BeingsToJSON(fileName string) error {
+	return writeJSON(fileName, w.BeingList)
+}
+
+// writeJSON encodes the value into the file (see writeFile)
+func writeJSON(fileName string, v interface{}) error {
+	return writeFile(fileName, func(out io.Writer) error {
+		return NewEncoder(out).Encode(v)
+	})
+}
+
+// writeFile stores what write produces into the file (gzipped if the name ends with .gz). The file is written next to
+// its place first and then moved there, so readers never see half of it
+func writeFile(fileName string, write func(out io.Writer) error) error {
+	fi, err := os.Create(fileName + ".tmp")
+	if err != nil {
+		return fmt.Errorf("error exporting %v: %v", fileName, err)
 	}
+	fz := NewWriter(fi)
+	var out io.Writer = fz
+	var zipped *gzip.Writer
+	if strings.HasSuffix(fileName, ".gz") {
+		zipped = gzip.NewWriter(fz)
+		out = zipped
+	}
+	err = write(out)
+	if zipped != nil {
+		if closeErr := zipped.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if flushErr := fz.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := fi.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(fileName+".tmp", fileName)
+	}
+	if err != nil {
+		os.Remove(fileName + ".tmp")
+		return fmt.Errorf("error exporting %v: %v", fileName, err)
+	}
+	return nil
 }
 
 // IsOutOfBounds check if a location is inside the terrain zone. Returns true if location outside the bounds.
@@ -220,21 +449,16 @@ func ParseHexColorFast(s string) (c color.RGBA, err error) {
 
 // CalculateZoneLimits returns the upper bound values for zones if ratios are given of how much area each zone covers
 // The number of zones can vary but sum(ratios) must equal to 1.0
-func (w *RandomWorld) CalculateZoneLimits(hist []int, ratios ...float64) []uint8 {
-	// Check if ratios add up to ~1 (otherwise parts of the terrain / zones will be left untouched)
-	var binSum float64
-	for _, r := range ratios {
-		binSum += r
-	}
-	// Check if larger than 1.00..001 (added a delta of 1e-14 because of precision error ...)
-	if binSum > 1.0+1e-14 || binSum < 1.0-1e-14 {
-		panic(fmt.Errorf("error while calculating zone limits: the given ratios (%v) do not add up to 1 (%v)",
-			ratios, binSum))
+// Returns an error if the ratios are not valid (see checkZoneRatios) or there are more of them than surfaces
+func (w *RandomWorld) CalculateZoneLimits(hist []int, ratios ...float64) ([]uint8, error) {
+	// Otherwise parts of the terrain / zones will be left untouched
+	if err := checkZoneRatios(ratios); err != nil {
+		return nil, fmt.Errorf("error while calculating zone limits: %v", err)
 	}
 	// Check if enough surfaces (zones) are defined
 	if len(Surfaces) < len(ratios) {
-		panic(fmt.Errorf("error while calculating zone limits: given %d ratios, but only %d Surfaces defined",
-			len(ratios), len(Surfaces)))
+		return nil, fmt.Errorf("error while calculating zone limits: given %d ratios, but only %d Surfaces defined",
+			len(ratios), len(Surfaces))
 	}
 	// The limits are based on 8bit grayscale
 	limits := make([]uint8, len(ratios))
@@ -242,7 +466,7 @@ func (w *RandomWorld) CalculateZoneLimits(hist []int, ratios ...float64) []uint8
 	// Count how many pixels lie in each bin and add up bins until the bin pixel to all pixel ratio is as close
 	// to the wanted one as possible
 	currentBin := 0
-	var previousSum float64
+	var binSum, previousSum float64
 	allPixels := float64(w.Width * w.Height)
 	for i, r := range ratios {
 		binSum = float64(hist[currentBin])
@@ -273,325 +497,620 @@ func (w *RandomWorld) CalculateZoneLimits(hist []int, ratios ...float64) []uint8
 	// can take up more space (or less) and it causes the last zone to undershoot its upper border. In a perfect
 	// scenario it should already be 255
 	limits[len(limits)-1] = 255
-	return limits
+	return limits, nil
+}
+
+// checkZoneRatios checks that none of the ratios is negative and that they add up to 1 (give or take the precision
+// error, see zoneRatiosPrecision)
+// Returns an error describing the first problem found
+func checkZoneRatios(ratios []float64) error {
+	var sum float64
+	for i, r := range ratios {
+		if r < 0 {
+			return fmt.Errorf("the zone ratio %d is negative (%v)", i, r)
+		}
+		sum += r
+	}
+	if math.Abs(sum-1) > zoneRatiosPrecision {
+		return fmt.Errorf("the zone ratios (%v) do not add up to 1 (%v)", ratios, sum)
+	}
+	return nil
 }
 
 // CreateCarnivores generates instances of beings and fills them with random attributes
 // Provide the number of beings to create
 // Note that the beings are added to the world and previously created beings are kept
-func (w *RandomWorld) CreateCarnivores(quantity int) {
-	// Initialize each being to a random one
-	for i := 0; i < quantity; i++ {
-		// Create random being and place it into the map
-		b := w.CreateRandomCarnivore()
-		w.BeingList[b.ID.String()] = b
-	}
+// Returns how many beings were placed and an error if there was no room for the rest (the same for all Create*s)
+func (w *RandomWorld) CreateCarnivores(quantity int) (int, error) {
+	return w.createBeings(quantity, w.CreateRandomCarnivore)
 }
 
 // CreateFishies generates random instances of beings that live in water
-func (w *RandomWorld) CreateFishies(quantity int) {
-	// Initialize each being to a random one
-	for i := 0; i < quantity; i++ {
-		// Create random being and place it into the map
-		b := w.CreateRandomFish()
-		w.BeingList[b.ID.String()] = b
-	}
+func (w *RandomWorld) CreateFishies(quantity int) (int, error) {
+	return w.createBeings(quantity, w.CreateRandomFish)
 }
 
 // CreateFlyers generates instances of random flying beings
-func (w *RandomWorld) CreateFlyers(quantity int) {
-	// Initialize each being to a random one
+func (w *RandomWorld) CreateFlyers(quantity int) (int, error) {
+	return w.createBeings(quantity, w.CreateRandomFlyer)
+}
+
+// CreateScavengers generates random instances of beings that feed on carrion
+func (w *RandomWorld) CreateScavengers(quantity int) (int, error) {
+	return w.createBeings(quantity, w.CreateRandomScavenger)
+}
+
+// CreateAmphibians generates random instances of beings that live both in water and on land
+func (w *RandomWorld) CreateAmphibians(quantity int) (int, error) {
+	return w.createBeings(quantity, w.CreateRandomAmphibian)
+}
+
+// createBeings creates the beings one by one and adds them to the world, it stops at the first one without room
+// Returns how many beings were placed and an error if there was no room for the rest
+func (w *RandomWorld) createBeings(quantity int, create func() (*GoWorld.Being, error)) (int, error) {
 	for i := 0; i < quantity; i++ {
-		// Create random being and place it into the map
-		b := w.CreateRandomFlyer()
+		b, err := create()
+		if err != nil {
+			return i, err
+		}
 		w.BeingList[b.ID.String()] = b
 	}
+	return quantity, nil
 }
 
 // CreateRandomCarnivore returns a new being with random parameters (places it onto the map)
-func (w *RandomWorld) CreateRandomCarnivore() *GoWorld.Being {
+// Returns an error if there is no room for it (the same for all CreateRandom*s)
+func (w *RandomWorld) CreateRandomCarnivore() (*GoWorld.Being, error) {
 	// Create an empty being
-	being := &GoWorld.Being{ID: uuid.New()}
+	being := &GoWorld.Being{ID: w.newID()}
 	being.Type = "Carnivore"
 
 	// Give the being the basic necessities
-	being.Hunger = hungerRange.randomFloat()
-	being.Thirst = thirstRange.randomFloat()
-	being.WantsChild = wantsChildRange.randomFloat()
+	being.Hunger = w.rangeOf("Hunger").randomFloat(w.random)
+	being.Thirst = w.rangeOf("Thirst").randomFloat(w.random)
+	being.WantsChild = w.rangeOf("WantsChild").randomFloat(w.random)
 
 	// Shape the being
-	being.LifeExpectancy = lifeExpectancyRange.randomFloat()
-	being.VisionRange = visionRange.randomFloat()
-	being.Speed = speedRange.randomFloat()
-	being.Durability = durabilityRange.randomFloat()
-	being.Stress = stressRange.randomFloat()
-	being.Size = sizeRange.randomFloat()
-	being.Gender = randomGender()
-	being.Fertility = fertilityRange.randomFloat()
-	being.MutationRate = mutationRange.randomFloat()
+	being.LifeExpectancy = w.rangeOf("LifeExpectancy").randomFloat(w.random)
+	being.VisionRange = w.rangeOf("Vision").randomFloat(w.random)
+	being.Speed = w.rangeOf("Speed").randomFloat(w.random)
+	being.Durability = w.rangeOf("Durability").randomFloat(w.random)
+	being.Stress = w.rangeOf("Stress").randomFloat(w.random)
+	being.Energy = w.rangeOf("Energy").randomFloat(w.random)
+	being.Sleepiness = w.rangeOf("Sleepiness").randomFloat(w.random)
+	being.Nocturnal = w.random.Float64() < nocturnalChance[being.Type]
+	being.Size = w.rangeOf("Size").randomFloat(w.random)
+	being.Gender = w.randomGender()
+	being.Fertility = w.rangeOf("Fertility").randomFloat(w.random)
+	being.MutationRate = w.rangeOf("Mutation").randomFloat(w.random)
+	being.MaturityAge = w.rangeOf("Maturity").randomFloat(w.random)
+	being.Camouflage = w.rangeOf("Camouflage").randomFloat(w.random)
+	being.MemorySize = w.rangeOf("MemorySize").randomFloat(w.random)
+	being.Resistance = w.rangeOf("Resistance").randomFloat(w.random)
+	being.Personality = w.randomPersonality()
+	// The initial population is a mix of juveniles and adults
+	being.Age = w.random.Float64() * 2 * being.MaturityAge
 
 	// Pick a random (valid) position and check which habitat it is
-	w.ThrowBeing(being)
+	if err := w.ThrowBeing(being); err != nil {
+		return nil, err
+	}
+	w.infest(being)
+	return being, nil
+}
 
-	return being
+// CreateRandomScavenger returns a new being that feeds on carrion instead of hunting (places it onto the map)
+func (w *RandomWorld) CreateRandomScavenger() (*GoWorld.Being, error) {
+	// Create an empty being
+	being := &GoWorld.Being{ID: w.newID()}
+	being.Type = "Scavenger"
+
+	// Give the being the basic necessities
+	being.Hunger = w.rangeOf("Hunger").randomFloat(w.random)
+	being.Thirst = w.rangeOf("Thirst").randomFloat(w.random)
+	being.WantsChild = w.rangeOf("WantsChild").randomFloat(w.random)
+
+	// Shape the being
+	being.LifeExpectancy = w.rangeOf("LifeExpectancy").randomFloat(w.random)
+	being.VisionRange = w.rangeOf("Vision").randomFloat(w.random)
+	being.Speed = w.rangeOf("Speed").randomFloat(w.random)
+	being.Durability = w.rangeOf("Durability").randomFloat(w.random)
+	being.Stress = w.rangeOf("Stress").randomFloat(w.random)
+	being.Energy = w.rangeOf("Energy").randomFloat(w.random)
+	being.Sleepiness = w.rangeOf("Sleepiness").randomFloat(w.random)
+	being.Nocturnal = w.random.Float64() < nocturnalChance[being.Type]
+	being.Size = w.rangeOf("Size").randomFloat(w.random)
+	being.Gender = w.randomGender()
+	being.Fertility = w.rangeOf("Fertility").randomFloat(w.random)
+	being.MutationRate = w.rangeOf("Mutation").randomFloat(w.random)
+	being.MaturityAge = w.rangeOf("Maturity").randomFloat(w.random)
+	being.Camouflage = w.rangeOf("Camouflage").randomFloat(w.random)
+	being.MemorySize = w.rangeOf("MemorySize").randomFloat(w.random)
+	being.Resistance = w.rangeOf("Resistance").randomFloat(w.random)
+	being.Personality = w.randomPersonality()
+	// The initial population is a mix of juveniles and adults
+	being.Age = w.random.Float64() * 2 * being.MaturityAge
+
+	// Pick a random (valid) position and check which habitat it is
+	if err := w.ThrowBeing(being); err != nil {
+		return nil, err
+	}
+	w.infest(being)
+	return being, nil
+}
+
+// CreateRandomAmphibian returns a new being that moves and eats both in water and on land. Its habitat (the spot it
+// is placed on) decides which of them is its primary medium
+func (w *RandomWorld) CreateRandomAmphibian() (*GoWorld.Being, error) {
+	// Create an empty being
+	being := &GoWorld.Being{ID: w.newID()}
+	being.Type = "Amphibian"
+
+	// Give the being the basic necessities
+	being.Hunger = w.rangeOf("Hunger").randomFloat(w.random)
+	being.Thirst = w.rangeOf("Thirst").randomFloat(w.random)
+	being.WantsChild = w.rangeOf("WantsChild").randomFloat(w.random)
+
+	// Shape the being
+	being.LifeExpectancy = w.rangeOf("LifeExpectancy").randomFloat(w.random)
+	being.VisionRange = w.rangeOf("Vision").randomFloat(w.random)
+	being.Speed = w.rangeOf("Speed").randomFloat(w.random)
+	being.Durability = w.rangeOf("Durability").randomFloat(w.random)
+	being.Stress = w.rangeOf("Stress").randomFloat(w.random)
+	being.Energy = w.rangeOf("Energy").randomFloat(w.random)
+	being.Sleepiness = w.rangeOf("Sleepiness").randomFloat(w.random)
+	being.Nocturnal = w.random.Float64() < nocturnalChance[being.Type]
+	being.Size = w.rangeOf("Size").randomFloat(w.random)
+	being.Gender = w.randomGender()
+	being.Fertility = w.rangeOf("Fertility").randomFloat(w.random)
+	being.MutationRate = w.rangeOf("Mutation").randomFloat(w.random)
+	being.MaturityAge = w.rangeOf("Maturity").randomFloat(w.random)
+	being.Camouflage = w.rangeOf("Camouflage").randomFloat(w.random)
+	being.MemorySize = w.rangeOf("MemorySize").randomFloat(w.random)
+	being.Resistance = w.rangeOf("Resistance").randomFloat(w.random)
+	being.Personality = w.randomPersonality()
+	// The initial population is a mix of juveniles and adults
+	being.Age = w.random.Float64() * 2 * being.MaturityAge
+
+	// Pick a random (valid) position and check which habitat it is
+	if err := w.ThrowBeing(being); err != nil {
+		return nil, err
+	}
+	w.infest(being)
+	return being, nil
 }
 
 // CreateRandomFlyer generate an instance of a being that can fly
-func (w *RandomWorld) CreateRandomFlyer() *GoWorld.Being {
+func (w *RandomWorld) CreateRandomFlyer() (*GoWorld.Being, error) {
 	// Create an empty being
-	being := &GoWorld.Being{ID: uuid.New()}
+	being := &GoWorld.Being{ID: w.newID()}
 	being.Type = "Flying"
 
 	// Give the being the basic necessities
-	being.Hunger = hungerRange.randomFloat()
-	being.Thirst = thirstRange.randomFloat()
-	being.WantsChild = wantsChildRange.randomFloat()
+	being.Hunger = w.rangeOf("Hunger").randomFloat(w.random)
+	being.Thirst = w.rangeOf("Thirst").randomFloat(w.random)
+	being.WantsChild = w.rangeOf("WantsChild").randomFloat(w.random)
 
 	// Shape the being
-	being.LifeExpectancy = lifeExpectancyRange.randomFloat()
-	being.VisionRange = visionRange.randomFloat()
-	being.Speed = speedRange.randomFloat()
-	being.Durability = durabilityRange.randomFloat()
-	being.Stress = stressRange.randomFloat()
-	being.Size = sizeRange.randomFloat()
-	being.Gender = randomGender()
-	being.Fertility = fertilityRange.randomFloat()
-	being.MutationRate = mutationRange.randomFloat()
+	being.LifeExpectancy = w.rangeOf("LifeExpectancy").randomFloat(w.random)
+	being.VisionRange = w.rangeOf("Vision").randomFloat(w.random)
+	being.Speed = w.rangeOf("Speed").randomFloat(w.random)
+	being.Durability = w.rangeOf("Durability").randomFloat(w.random)
+	being.Stress = w.rangeOf("Stress").randomFloat(w.random)
+	being.Energy = w.rangeOf("Energy").randomFloat(w.random)
+	being.Sleepiness = w.rangeOf("Sleepiness").randomFloat(w.random)
+	being.Nocturnal = w.random.Float64() < nocturnalChance[being.Type]
+	being.Size = w.rangeOf("Size").randomFloat(w.random)
+	being.Gender = w.randomGender()
+	being.Fertility = w.rangeOf("Fertility").randomFloat(w.random)
+	being.MutationRate = w.rangeOf("Mutation").randomFloat(w.random)
+	being.MaturityAge = w.rangeOf("Maturity").randomFloat(w.random)
+	being.Camouflage = w.rangeOf("Camouflage").randomFloat(w.random)
+	being.MemorySize = w.rangeOf("MemorySize").randomFloat(w.random)
+	being.Resistance = w.rangeOf("Resistance").randomFloat(w.random)
+	being.Personality = w.randomPersonality()
+	// The initial population is a mix of juveniles and adults
+	being.Age = w.random.Float64() * 2 * being.MaturityAge
 
 	// Flying beings 'feel' home in the forest, but can spawn anywhere
-	// Create some random coordinates within the world limits
-	rX := rand.Intn(w.Width)
-	rY := rand.Intn(w.Height)
-	overflow := 0
-	// If no being present at location set it as the spawn point
-	for w.TerrainSpots[rX][rY].Being != uuid.Nil {
-		rX = rand.Intn(w.Width)
-		rY = rand.Intn(w.Height)
-		// Recover somehow if we look for a location for too long
-		overflow++
-		if overflow > 100000 {
-			// Todo handle the infinite loop a little nicer than panicking
-			panic("error placing flying being: tried 100k random spots and all occupied")
-		}
+	// Any spot without a being is fine as the spawn point
+	spot, found := w.freeSpot(anySurface, func(GoWorld.Location) bool { return true })
+	if !found {
+		return nil, fmt.Errorf("error placing flying being: all spots occupied")
 	}
-	being.Position.X = rX
-	being.Position.Y = rY
-	being.Habitat = Surfaces[2].ID
+	being.Position = spot
+	w.setBeingAt(spot, being.ID)
+	being.Habitat = w.surfaces[2].ID
+	w.infest(being)
 
-	return being
+	return being, nil
 }
 
 // CreateRandomFish generates an instance of a being that lives in water
-func (w *RandomWorld) CreateRandomFish() *GoWorld.Being {
+func (w *RandomWorld) CreateRandomFish() (*GoWorld.Being, error) {
 	// Create an empty being
-	being := &GoWorld.Being{ID: uuid.New()}
+	being := &GoWorld.Being{ID: w.newID()}
 	being.Type = "Water"
 
 	// Give the being the basic necessities
-	being.Hunger = hungerRange.randomFloat()
-	being.Thirst = thirstRange.randomFloat()
-	being.WantsChild = wantsChildRange.randomFloat()
+	being.Hunger = w.rangeOf("Hunger").randomFloat(w.random)
+	being.Thirst = w.rangeOf("Thirst").randomFloat(w.random)
+	being.WantsChild = w.rangeOf("WantsChild").randomFloat(w.random)
 
 	// Shape the being
-	being.LifeExpectancy = lifeExpectancyRange.randomFloat()
-	being.VisionRange = visionRange.randomFloat()
-	being.Speed = speedRange.randomFloat()
-	being.Durability = durabilityRange.randomFloat()
-	being.Stress = stressRange.randomFloat()
-	being.Size = sizeRange.randomFloat()
-	being.Gender = randomGender()
-	being.Fertility = fertilityRange.randomFloat()
-	being.MutationRate = mutationRange.randomFloat()
+	being.LifeExpectancy = w.rangeOf("LifeExpectancy").randomFloat(w.random)
+	being.VisionRange = w.rangeOf("Vision").randomFloat(w.random)
+	being.Speed = w.rangeOf("Speed").randomFloat(w.random)
+	being.Durability = w.rangeOf("Durability").randomFloat(w.random)
+	being.Stress = w.rangeOf("Stress").randomFloat(w.random)
+	being.Energy = w.rangeOf("Energy").randomFloat(w.random)
+	being.Sleepiness = w.rangeOf("Sleepiness").randomFloat(w.random)
+	being.Nocturnal = w.random.Float64() < nocturnalChance[being.Type]
+	being.Size = w.rangeOf("Size").randomFloat(w.random)
+	being.Gender = w.randomGender()
+	being.Fertility = w.rangeOf("Fertility").randomFloat(w.random)
+	being.MutationRate = w.rangeOf("Mutation").randomFloat(w.random)
+	being.MaturityAge = w.rangeOf("Maturity").randomFloat(w.random)
+	being.Camouflage = w.rangeOf("Camouflage").randomFloat(w.random)
+	being.MemorySize = w.rangeOf("MemorySize").randomFloat(w.random)
+	being.Resistance = w.rangeOf("Resistance").randomFloat(w.random)
+	being.Personality = w.randomPersonality()
+	// The initial population is a mix of juveniles and adults
+	being.Age = w.random.Float64() * 2 * being.MaturityAge
 
 	// Water beings should spawn in water
-	rX := rand.Intn(w.Width)
-	rY := rand.Intn(w.Height)
-	overflow := 0
-	// If no being present at location set it as the spawn point
-	for w.TerrainSpots[rX][rY].Surface.CommonName != "Water" && w.TerrainSpots[rX][rY].Being == uuid.Nil {
-		rX = rand.Intn(w.Width)
-		rY = rand.Intn(w.Height)
-		// Recover somehow if we look for a location for too long
-		overflow++
-		if overflow > 100000 {
-			// Todo handle the infinite loop a little nicer than panicking
-			panic("error placing water being: tried 100k random spots and all occupied / not water")
-		}
-	}
-	being.Position.X = rX
-	being.Position.Y = rY
+	// Any free water spot is fine as the spawn point (large fish need deep water)
+	spot, found := w.freeSpot(waterSurface, func(spot GoWorld.Location) bool {
+		return w.submerged(being, spot)
+	})
+	if !found && isLargeFish(being) {
+		// Small worlds may have no deep water, the large fish make do with shallow water there
+		spot, found = w.freeSpot(waterSurface, func(GoWorld.Location) bool { return true })
+	}
+	if !found {
+		return nil, fmt.Errorf("error placing water being: all water spots occupied")
+	}
+	being.Position = spot
+	w.setBeingAt(spot, being.ID)
 	// Should always be water ID
-	being.Habitat = w.TerrainSpots[rX][rY].Surface.ID
+	being.Habitat = w.TerrainSpots[spot.X][spot.Y].Surface.ID
+	w.infest(being)
 
-	return being
+	return being, nil
 }
 
 // ThrowBeing randomly places the a being onto the map (onto walkable surfaces)
 // Use with caution as it adjusts the beings habitat to that spot
-func (w *RandomWorld) ThrowBeing(b *GoWorld.Being) {
+// Returns an error if there is no terrain or no free spot fits the being
+func (w *RandomWorld) ThrowBeing(b *GoWorld.Being) error {
 	// Check if the terrain to place the being exists
 	if w.TerrainSpots == nil {
-		panic(fmt.Errorf("error while creating being: no terrain to place being on"))
+		return fmt.Errorf("error while creating being: no terrain to place being on")
 	}
-
-	// Create some random coordinates within the world limits
-	randomSpot := GoWorld.Location{}
-	randomSpot.X = rand.Intn(w.Width)
-	randomSpot.Y = rand.Intn(w.Height)
-
 	// Check if the chosen spot was valid (no being already present and surface is walkable)
 	// If not repeat the random process until we find a suitable spot
-	for !w.canPlaceBeing(randomSpot, b.Type) {
-		randomSpot.X = rand.Intn(w.Width)
-		randomSpot.Y = rand.Intn(w.Height)
+	onSurface := walkableSurface
+	if flies(b.Type) {
+		onSurface = anySurface
+	}
+	randomSpot, found := w.freeSpot(onSurface, func(spot GoWorld.Location) bool {
+		return w.canPlaceBeing(spot, b.Type)
+	})
+	if !found {
+		return fmt.Errorf("error while creating being: no free spot fits a being of type %v", b.Type)
 	}
 	// Set the location of the being
-	b.Position.X = randomSpot.X
-	b.Position.Y = randomSpot.Y
-	w.TerrainSpots[randomSpot.X][randomSpot.Y].Being = b.ID
+	b.Position = randomSpot
+	w.setBeingAt(randomSpot, b.ID)
 
 	// Specify into which habitat (surface type) it falls
 	b.Habitat = w.TerrainSpots[randomSpot.X][randomSpot.Y].Surface.ID
+	return nil
 }
 
 // ThrowPlant randomly places a plant (food) onto the map
-func (w *RandomWorld) ThrowPlant(p *GoWorld.Food) {
+// Returns an error if there is no terrain or no free spot has room for the plant
+func (w *RandomWorld) ThrowPlant(p *GoWorld.Food) error {
 	// Check if the terrain to place the plant exists
 	if w.TerrainSpots == nil {
-		panic(fmt.Errorf("error while throwing plant: no terrain"))
+		return fmt.Errorf("error while throwing plant: no terrain")
 	}
-
-	// Create some random coordinates within the world limits
-	rX := rand.Intn(w.Width)
-	rY := rand.Intn(w.Height)
-
-	for !w.canPlacePlant(rX, rY, p.Area) {
-		rX = rand.Intn(w.Width)
-		rY = rand.Intn(w.Height)
+	spot, found := w.freeSpot(plantSurface, func(spot GoWorld.Location) bool {
+		return w.canPlacePlant(spot.X, spot.Y, p.Area)
+	})
+	if !found {
+		return fmt.Errorf("error while throwing plant: no free spot has room for a plant of area %v", p.Area)
 	}
 	// Place the plant on the surface and occupy spots in area
-	w.updatePlantSpot(rX, rY, p.Area, p.ID)
-	p.Position.X = rX
-	p.Position.Y = rY
+	w.updatePlantSpot(spot.X, spot.Y, p.Area, p.ID)
+	p.Position = spot
+	return nil
 }
 
 // LaunchPlant randomly places a plant onto water (will become a water plant)
-func (w *RandomWorld) LaunchPlant(p *GoWorld.Food) {
+// Returns an error if there is no terrain or no free spot in water has room for the plant
+func (w *RandomWorld) LaunchPlant(p *GoWorld.Food) error {
 	// Check if the terrain to place the being exists
 	if w.TerrainSpots == nil {
-		panic(fmt.Errorf("error while launching water plant: no terrain"))
+		return fmt.Errorf("error while launching water plant: no terrain")
 	}
-	// Create some random coordinates within the world limits
-	rX := rand.Intn(w.Width)
-	rY := rand.Intn(w.Height)
-
-	for !w.canPlaceWaterPlant(rX, rY, p.Area, p.ID) {
-		rX = rand.Intn(w.Width)
-		rY = rand.Intn(w.Height)
+	spot, found := w.freeSpot(waterSurface, func(spot GoWorld.Location) bool {
+		return w.canPlaceWaterPlant(spot.X, spot.Y, p.Area, p.ID)
+	})
+	if !found {
+		return fmt.Errorf("error while launching water plant: no free spot in shallow water has room for a plant of "+
+			"area %v", p.Area)
 	}
 	// Place the plant on the surface and occupy spots in area
-	w.updatePlantSpot(rX, rY, p.Area, p.ID)
-	p.Position.X = rX
-	p.Position.Y = rY
+	w.updatePlantSpot(spot.X, spot.Y, p.Area, p.ID)
+	p.Position = spot
+	return nil
 }
 
-// UpdateBeing executes the next action for the being
-// Returns action done as string and UUIDs of objects affected by action
-func (w *RandomWorld) UpdateBeing(b *GoWorld.Being) (string, []uuid.UUID) {
+// updateBeing executes the next action for the being (see UpdateBeing)
+func (w *RandomWorld) updateBeing(b *GoWorld.Being) (GoWorld.Outcome, []uuid.UUID) {
+	defer w.timeSince("Beings", time.Now())
+	if b.ID == w.traced {
+		// The plan of the previous update is outdated
+		w.tracedPlan = nil
+	}
 	// Check if it is time for the being to die
 	if b.LifeExpectancy <= 0 || b.Thirst >= 255 || b.Hunger >= 255 {
 		// Being has reached EOL
-		fmt.Printf("Being (%v) %v ", b.Type, b.ID)
+		cause := "hunger"
 		if b.LifeExpectancy <= 0 {
-			fmt.Println("... died of old age")
+			cause = "old age"
 		} else if b.Thirst >= 255 {
-			fmt.Println("... died of thirst")
-		} else if b.Hunger >= 255 {
-			fmt.Println("... died of hunger")
+			cause = "thirst"
 		}
-		// remove being from BeingList & TerrainSpots
-		delete(w.BeingList, b.ID.String())
-		w.TerrainSpots[b.Position.X][b.Position.Y].Being = uuid.Nil
-		return "died", []uuid.UUID{b.ID}
+		return GoWorld.Died, w.die(b, cause)
 	}
 	// Increase the age (=> lower life expectancy for 1 epoch)
 	b.LifeExpectancy -= 1. / 60 // Age roughly every second (60 FPS)
-	actionDone := "wandered"
+	b.Age += 1. / 60
+	if b.MateCooldown > 0 {
+		b.MateCooldown--
+	}
+	// Actions that take several epochs (e.g. eating a large prey) keep the being busy until they are done
+	if outcome, ok := w.goOn(b); ok {
+		return outcome, []uuid.UUID{}
+	}
+	// Some beings spend the winter hibernating in their habitat instead of acting
+	if w.Season() == "Winter" && w.Settings.HibernatingTypes[b.Type] || b.Hibernating {
+		if action, ok := w.Hibernate(b); ok {
+			return action, []uuid.UUID{}
+		}
+	}
+	actionDone := GoWorld.Wandered
 	var objectsAffected []uuid.UUID
+	w.ClaimTerritory(b)
+	sensing := time.Now()
 	actionToDo, actionSpot := w.SenseActionFor(b)
-	allowInhabitable := false
-	if b.Type == "Water" || b.Type == "Flying" {
-		allowInhabitable = true
+	w.timeSince("Sensing", sensing)
+	// Beings that have nothing better to do (and no food around) migrate towards regions with more food
+	if actionToDo == "wander" {
+		if actionDone, ok := w.Migrate(b); ok {
+			w.AdjustStressFor(b)
+			w.AdjustNeeds(b)
+			return actionDone, objectsAffected
+		}
 	}
+	allowInhabitable := crossesWater(b.Type)
 
-	pathToAction := w.pathFinder.GetPath(b.Position, actionSpot, allowInhabitable)
-	// Whether carnivore beings successfully ate
-	successfulHunt := false
+	pathSpot := actionSpot
+	if actionToDo == "eat" && b.Target != uuid.Nil {
+		// Hunters approach the prey from a side not yet covered by their pack
+		pathSpot = w.flankSpotFor(b, actionSpot)
+	}
+	pathing := time.Now()
+	pathToAction := w.pathFinder.GetPath(b.Position, pathSpot, allowInhabitable)
+	w.timeSince("Pathing", pathing)
+	w.plan(b, actionToDo, pathSpot, pathToAction)
+	// How far the being can move this epoch (amphibians are slower outside their primary medium)
+	speed := int(w.currentSpeed(b) * w.mediumEfficiency(b, b.Position))
+	// Carnivores sprint after prey if they have the energy to spare
+	if actionToDo == "eat" && b.Type == "Carnivore" && b.Energy >= sprintThreshold {
+		speed *= 2
+		b.Energy -= sprintEnergyCost
+	}
 	if len(pathToAction) == 0 {
 		// Todo investigate which paths are not found
 	}
 	switch actionToDo {
 	case "drink":
 		// Check if being has to move to take the action
-		if int(b.Speed) >= len(pathToAction) || b.Type == "Water" {
+		if speed >= len(pathToAction) || b.Type == "Water" {
 			// We are fast enough to get to action spot in one move
 			if len(pathToAction) >= 1 {
 				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-1])
 			}
 			w.QuenchThirst(b)
+			keepBusy(b, "drink", drinkingTime)
 		} else {
 			// We see further than we can move in one epoch
-			w.MoveBeingToLocation(b, pathToAction[int(b.Speed)])
+			w.MoveBeingToLocation(b, pathToAction[speed])
 		}
-		actionDone = "drank"
+		actionDone = GoWorld.Drank
 	case "eat":
-		if int(b.Speed) >= len(pathToAction) {
+		if speed >= len(pathToAction) {
 			// We are fast enough to get to action spot in one move
 			if len(pathToAction) >= 1 {
 				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-1])
 			}
-			if (b.Type == "Flying" || b.Type == "Carnivore") &&
-				w.TerrainSpots[actionSpot.X][actionSpot.Y].Being != uuid.Nil {
+			if b.Target != uuid.Nil && !w.packCanOverpower(b) {
+				// The prey is too strong, wait for the rest of the pack before attacking
+				actionDone = GoWorld.Stalked
+				break
+			}
+			if prey := w.BeingList[b.Target.String()]; prey != nil && !w.Attack(b, prey) {
+				// The prey fought back or escaped
+				actionDone = GoWorld.AttackFailed
+				break
+			}
+			if preyID := w.TerrainSpots[actionSpot.X][actionSpot.Y].Being; (b.Type == "Flying" ||
+				b.Type == "Carnivore") && preyID != uuid.Nil && preyID != b.ID {
 				// We are eating a being, rename action done accordingly
-				actionDone = "ate being"
-				objectsAffected = append(objectsAffected, w.TerrainSpots[actionSpot.X][actionSpot.Y].Being)
+				actionDone = GoWorld.AteBeing
+				objectsAffected = append(objectsAffected, preyID)
+				// Larger prey take longer to eat
+				if prey := w.BeingList[preyID.String()]; prey != nil {
+					keepBusy(b, "eat", eatingTime*bodySize(prey))
+					w.CatchParasites(b, prey)
+					b.Kills++
+					if w.Stats != nil {
+						w.Stats.recordKill()
+					}
+				}
 				//fmt.Printf("Being (%v) %v ate being\n", b.Type, b.ID)
+				w.QuenchHunger(b, actionSpot)
+				// The remains of prey too large to eat whole are left behind as carrion
+				remainsID := w.TerrainSpots[actionSpot.X][actionSpot.Y].Object
+				if remains := w.GetFoodWithID(remainsID); remains != nil && remains.Type == "Carrion" {
+					objectsAffected = append(objectsAffected, remainsID)
+				}
+				break
 			} else {
 				// We are eating a plant
-				objectsAffected = append(objectsAffected, w.TerrainSpots[actionSpot.X][actionSpot.Y].OccupyingPlant)
+				plantID := w.TerrainSpots[actionSpot.X][actionSpot.Y].OccupyingPlant
+				objectsAffected = append(objectsAffected, plantID)
 				//fmt.Printf("Being (%v) %v ate plant\n", b.Type, b.ID)
-				actionDone = "ate plant"
+				actionDone = GoWorld.AtePlant
+				if b.Type == "Insect" {
+					// Insects only feed on the plant and pollinate it
+					actionDone = GoWorld.Pollinated
+				} else if owner, cached := w.Caches[plantID.String()]; cached {
+					// Eating food cached by another being is stealing
+					actionDone = GoWorld.AteCache
+					if owner != b.ID {
+						actionDone = GoWorld.StoleCache
+					}
+				}
 			}
 			w.QuenchHunger(b, actionSpot)
-			// Carnivore Being ate, so lower speed before stress update
-			if b.Type == "Carnivore" {
-				b.Speed /= 2
-				successfulHunt = true
+			if actionDone == GoWorld.AtePlant && w.GetFoodWithID(objectsAffected[len(objectsAffected)-1]) != nil {
+				// Only a bite was taken, the plant lives on
+				actionDone = GoWorld.TookBite
 			}
 		} else {
 			// We see further than we can move in one epoch
-			w.MoveBeingToLocation(b, pathToAction[int(b.Speed)])
-			actionDone = "ate fail"
+			w.MoveBeingToLocation(b, pathToAction[speed])
+			actionDone = GoWorld.AteFail
 		}
 
 	case "mate":
-		if int(b.Speed) >= len(pathToAction) {
+		if speed >= len(pathToAction) {
 			// We are fast enough to get to action spot in one move
 			if len(pathToAction) >= 1 {
 				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-1])
 			}
 			objectsAffected = append(objectsAffected, w.MateBeing(b)...)
-			actionDone = "mated"
+			actionDone = GoWorld.Mated
+			if EggLayingTypes[b.Type] {
+				// The offspring are still in their eggs
+				actionDone = GoWorld.LaidEggs
+			}
 		} else {
 			// We see further than we can move in one epoch
-			w.MoveBeingToLocation(b, pathToAction[int(b.Speed)])
+			w.MoveBeingToLocation(b, pathToAction[speed])
+		}
+	case "eat carried":
+		w.EatCarried(b)
+		actionDone = GoWorld.AteCarried
+	case "cache":
+		if speed >= len(pathToAction) {
+			// We reached the spot to cache the food on
+			if len(pathToAction) >= 1 {
+				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-1])
+			}
+			actionDone = GoWorld.CacheFailed
+			if cache := w.CacheFood(b); cache != nil {
+				actionDone = GoWorld.Cached
+				objectsAffected = append(objectsAffected, cache.ID)
+			}
+		} else {
+			// Carry the food towards the cache spot
+			w.MoveBeingToLocation(b, pathToAction[speed])
 		}
 	case "wander":
 		w.MoveBeingToLocation(b, actionSpot)
-		actionDone = "wandered"
+		actionDone = GoWorld.Wandered
+	case "sleep":
+		if speed >= len(pathToAction) {
+			// We reached the spot to sleep on
+			if len(pathToAction) >= 1 {
+				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-1])
+			}
+			w.Sleep(b)
+			actionDone = GoWorld.Slept
+			// Beings without a home build one on the spot they sleep on
+			if needsHome(b) {
+				if home := w.BuildHome(b); home != nil {
+					actionDone = GoWorld.BuiltHome
+					objectsAffected = append(objectsAffected, home.ID)
+				}
+			}
+		} else {
+			// Move towards a safe spot to sleep on
+			w.MoveBeingToLocation(b, pathToAction[speed])
+		}
+	case "build":
+		if speed >= len(pathToAction) {
+			// We reached the spot to build the home on
+			if len(pathToAction) >= 1 {
+				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-1])
+			}
+			actionDone = GoWorld.BuildFailed
+			if home := w.BuildHome(b); home != nil {
+				actionDone = GoWorld.BuiltHome
+				objectsAffected = append(objectsAffected, home.ID)
+			}
+		} else {
+			// Move towards the spot for the home
+			w.MoveBeingToLocation(b, pathToAction[speed])
+		}
+	case "chase":
+		if len(pathToAction) == 0 {
+			// The intruder can not be reached
+			actionDone = GoWorld.Froze
+			break
+		}
+		if speed >= len(pathToAction)-1 {
+			// Caught up with the intruder (stop next to it) and chase it away
+			if len(pathToAction) >= 2 {
+				w.MoveBeingToLocation(b, pathToAction[len(pathToAction)-2])
+			}
+			if intruderID := w.TerrainSpots[actionSpot.X][actionSpot.Y].Being; intruderID != uuid.Nil {
+				if intruder := w.BeingList[intruderID.String()]; intruder != nil {
+					if fleeSpot, fled := w.scatterFrom(intruder, b.Position); fled {
+						w.MoveBeingToLocation(intruder, fleeSpot)
+					}
+					objectsAffected = append(objectsAffected, intruderID)
+				}
+			}
+			actionDone = GoWorld.ChasedAway
+		} else {
+			w.MoveBeingToLocation(b, pathToAction[speed])
+			actionDone = GoWorld.Chased
+		}
+	case "recall":
+		// Travel towards a remembered spot as far as we can move this epoch
+		if len(pathToAction) > 0 {
+			w.MoveBeingToLocation(b, pathToAction[int(math.Min(float64(speed), float64(len(pathToAction)-1)))])
+		}
+		actionDone = GoWorld.Recalled
+	case "track":
+		// Follow the scent trail as far as we can move this epoch
+		if len(pathToAction) > 0 {
+			w.MoveBeingToLocation(b, pathToAction[int(math.Min(float64(speed), float64(len(pathToAction)-1)))])
+		}
+		actionDone = GoWorld.Tracked
+	case "rest":
+		// Stay in place and regain energy
+		w.Rest(b)
+		actionDone = GoWorld.Rested
 	case "hold":
 		// Do nothing, we cannot move to any surrounding spot inside vision range
-		actionDone = "froze"
+		actionDone = GoWorld.Froze
 	}
 
 	// Update stress:
@@ -600,11 +1119,6 @@ func (w *RandomWorld) UpdateBeing(b *GoWorld.Being) (string, []uuid.UUID) {
 	w.AdjustStressFor(b)
 	w.AdjustNeeds(b)
 
-	// If being didn't eat this round (speed lower than vision range), reset the hunting speed after needs and stress
-	// update
-	if b.Type == "Carnivore" && actionDone == "ate fail" && !successfulHunt {
-		b.Speed /= 2
-	}
 	return actionDone, objectsAffected
 }
 
@@ -615,8 +1129,9 @@ func (w *RandomWorld) UpdateBeing(b *GoWorld.Being) (string, []uuid.UUID) {
 //  - the previous position is the current position of the being
 //  - the next position is recalculated until a valid one is found
 func (w *RandomWorld) Wander(b *GoWorld.Being) error {
-	dX := math.Sqrt(b.Speed) * (rand.NormFloat64() * 5)
-	dY := math.Sqrt(b.Speed) * (rand.NormFloat64() * 5)
+	speed := w.currentSpeed(b)
+	dX := math.Sqrt(speed) * (w.random.NormFloat64() * 5)
+	dY := math.Sqrt(speed) * (w.random.NormFloat64() * 5)
 	wanderSpot := GoWorld.Location{}
 	wanderSpot.X = b.Position.X + int(dX)
 	wanderSpot.Y = b.Position.Y + int(dY)
@@ -633,8 +1148,8 @@ func (w *RandomWorld) Wander(b *GoWorld.Being) error {
 	}
 
 	for !w.canPlaceBeing(wanderSpot, b.Type) {
-		dX = math.Sqrt(b.Speed) * (rand.NormFloat64() * 5)
-		dY = math.Sqrt(b.Speed) * (rand.NormFloat64() * 5)
+		dX = math.Sqrt(speed) * (w.random.NormFloat64() * 5)
+		dY = math.Sqrt(speed) * (w.random.NormFloat64() * 5)
 		wanderSpot.X = b.Position.X + int(dX)
 		wanderSpot.Y = b.Position.Y + int(dY)
 
@@ -650,8 +1165,8 @@ func (w *RandomWorld) Wander(b *GoWorld.Being) error {
 		}
 	}
 	// Update the spot map
-	w.TerrainSpots[b.Position.X][b.Position.Y].Being = uuid.Nil
-	w.TerrainSpots[wanderSpot.X][wanderSpot.Y].Being = b.ID
+	w.setBeingAt(b.Position, uuid.Nil)
+	w.setBeingAt(wanderSpot, b.ID)
 
 	// Tell the being where it is going
 	b.Position.X = wanderSpot.X
@@ -664,10 +1179,14 @@ func (w *RandomWorld) Wander(b *GoWorld.Being) error {
 // Returns false if the spot is already occupied by another being or if the surface type does not allow to walk on it
 // (e.g. water or mountain peaks)
 func (w *RandomWorld) canPlaceBeing(spot GoWorld.Location, beingType string) bool {
+	// Only flying beings can pass obstacles
+	if !flies(beingType) && w.obstacleAt(spot) != nil {
+		return false
+	}
 	// Is there perhaps another being present?
 	if w.TerrainSpots[spot.X][spot.Y].Being == uuid.Nil {
 		// Can being move anywhere (Flying)
-		if beingType == "Flying" {
+		if flies(beingType) {
 			return true
 		} else if beingType == "Water" {
 			// Water beings can move on water
@@ -675,12 +1194,22 @@ func (w *RandomWorld) canPlaceBeing(spot GoWorld.Location, beingType string) boo
 			if spotName == "Water" || spotName == "Grassland" {
 				return true
 			}
+		} else if beingType == "Amphibian" {
+			// Amphibians can move on water and on land
+			spotName, _ := w.GetSurfaceNameAt(spot)
+			if spotName == "Water" || w.TerrainSpots[spot.X][spot.Y].Surface.Habitable {
+				return true
+			}
 		} else {
 			if w.TerrainSpots[spot.X][spot.Y].Surface.Habitable {
 				// Spot can be moved on, {
 				// No being present and habitable, we can safely move a being to this spot
 				return true
 			}
+			if beingType == "Carnivore" && w.canWade(spot) || w.TerrainSpots[spot.X][spot.Y].Ford {
+				// Land predators can wade into very shallow water, all land beings can cross fords
+				return true
+			}
 		}
 	}
 	// Spot was not habitable or a being was present
@@ -688,16 +1217,40 @@ func (w *RandomWorld) canPlaceBeing(spot GoWorld.Location, beingType string) boo
 }
 
 // UpdatePlant updates the attributes for plant. It can grow, produce seeds or wither
-// Returns action done as string and list of UUIDs of objects affected by action
-func (w *RandomWorld) UpdatePlant(p *GoWorld.Food) (string, []uuid.UUID) {
+// Returns the outcome with the UUIDs of the objects affected (see GoWorld.Outcome)
+func (w *RandomWorld) UpdatePlant(p *GoWorld.Food) GoWorld.ActionResult {
+	outcome, ids := w.updatePlant(p)
+	return GoWorld.ActionResult{Outcome: outcome, Actor: p.ID, Targets: ids}
+}
+
+// updatePlant updates the plant (see UpdatePlant)
+func (w *RandomWorld) updatePlant(p *GoWorld.Food) (GoWorld.Outcome, []uuid.UUID) {
+	defer w.timeSince("Plants", time.Now())
+	// Carrion only rots away
+	if p.Type == "Carrion" {
+		return w.Rot(p)
+	}
+	// Eggs only wait to hatch
+	if p.Type == "Egg" {
+		return w.Incubate(p)
+	}
+	// Cached food does not grow, it only spoils
+	if p.Type == "Cache" {
+		return w.Spoil(p)
+	}
 	// Simulation runs at around 60FPS, so wither 15x per second
 	p.Wither -= 1. / 4
 	if p.Wither <= 0 {
 		// Kill the plant :(
+		w.recordEnergy("Plants", "Decay", p.NutritionalValue-p.Eaten, p.NutritionalValue-p.Eaten)
 		delete(w.FoodList, p.ID.String())
 		w.updatePlantSpot(p.Position.X, p.Position.Y, p.Area, uuid.Nil)
-		return "withered", []uuid.UUID{p.ID}
+		return GoWorld.Withered, []uuid.UUID{p.ID}
 	}
+	// Bitten off parts grow back
+	regrown := math.Min(p.Eaten, plantRegrowth)
+	p.Eaten -= regrown
+	w.recordEnergy("Sunlight", "Plants", regrown, regrown)
 	// Make the plant grow if not in last stage
 	if p.GrowthStage <= stageRange.Max {
 		p.StageProgress += p.GrowthSpeed
@@ -709,26 +1262,30 @@ func (w *RandomWorld) UpdatePlant(p *GoWorld.Food) (string, []uuid.UUID) {
 		// Reset stage progress and increase stage -> can get to maxStage+1
 		p.StageProgress = 0.0
 		p.GrowthStage++
+		// Seeds of plants without a mate nearby are not viable
+		if w.Settings.RequirePollination && !w.hasPollinationMate(p) {
+			seedsProduced = 0
+		}
 		// Plant some seeds :)
 		ids := w.DisperseSeeds(p, seedsProduced)
 		// Return
 		if len(ids) == 0 {
-			return "planted fail", ids
+			return GoWorld.PlantedFail, ids
 		}
-		return "planted seeds", ids
+		return GoWorld.PlantedSeeds, ids
 	}
 
 	// If water plant: move the plants slightly in one direction
 	if p.Type == "Water" {
 		// Move if possible to adjacent field
-		direction := directions8[rand.Intn(len(directions8))]
+		direction := directions8[w.random.Intn(len(directions8))]
 		adjacentSpot := GoWorld.Location{
 			X: p.Position.X + direction.X,
 			Y: p.Position.Y + direction.Y,
 		}
 		// Find adjacent spot inside map bounds
 		for w.IsOutOfBounds(adjacentSpot) {
-			direction = directions8[rand.Intn(len(directions8))]
+			direction = directions8[w.random.Intn(len(directions8))]
 			adjacentSpot.X = p.Position.X + direction.X
 			adjacentSpot.Y = p.Position.Y + direction.Y
 		}
@@ -746,7 +1303,17 @@ func (w *RandomWorld) UpdatePlant(p *GoWorld.Food) (string, []uuid.UUID) {
 	}
 
 	// Default return
-	return "grew", []uuid.UUID{}
+	return GoWorld.Grew, []uuid.UUID{}
+}
+
+// hasPollinationMate checks if another plant of the same type grows within the pollination radius of the plant
+func (w *RandomWorld) hasPollinationMate(p *GoWorld.Food) bool {
+	for _, other := range w.FoodList {
+		if other.ID != p.ID && other.Type == p.Type && w.Distance(p.Position, other.Position) <= pollinationRadius {
+			return true
+		}
+	}
+	return false
 }
 
 // DisperseSeeds plants seeds within some range from plant
@@ -756,8 +1323,8 @@ func (w *RandomWorld) DisperseSeeds(p *GoWorld.Food, seeds int) []uuid.UUID {
 	spots := w.MidpointCircleAt(p.Position, p.Area+p.SeedDisperse)
 	for i := 0; i < seeds; i++ {
 		// Create mutated plant, but only the required attributes to check if we can place this plant
-		seedling := &GoWorld.Food{ID: uuid.New()}
-		seedling.Area = MutateValue(p.Area, p.MutationRate, *areaRange)
+		seedling := &GoWorld.Food{ID: w.newID()}
+		seedling.Area = w.MutateValue(p.Area, p.MutationRate, *w.rangeOf("Area"))
 		// Find a location around the parent
 		// SeedDisperse tells how far away from Parent area a seedling can be placed
 		// Create an array of available spots which will be marked as visited (deleted from array)
@@ -766,7 +1333,7 @@ func (w *RandomWorld) DisperseSeeds(p *GoWorld.Food, seeds int) []uuid.UUID {
 			unvisitedSpots[i] = i
 		}
 		// Position in unvisited spots list
-		rnd := rand.Intn(len(unvisitedSpots))
+		rnd := w.random.Intn(len(unvisitedSpots))
 		// Unvisited spot index
 		spotIdx := unvisitedSpots[rnd]
 		foundSpot := true
@@ -800,7 +1367,7 @@ func (w *RandomWorld) DisperseSeeds(p *GoWorld.Food, seeds int) []uuid.UUID {
 			}
 
 			// Pick new spot from unvisited
-			rnd = rand.Intn(len(unvisitedSpots))
+			rnd = w.random.Intn(len(unvisitedSpots))
 			spotIdx = unvisitedSpots[rnd]
 		}
 
@@ -809,13 +1376,14 @@ func (w *RandomWorld) DisperseSeeds(p *GoWorld.Food, seeds int) []uuid.UUID {
 			// We can fill in the other parameters for plant
 			seedling.GrowthStage = 0.0
 			seedling.StageProgress = 0.0
-			seedling.SeedDisperse = MutateValue(p.SeedDisperse, p.MutationRate, *disperseRange)
-			seedling.Taste = MutateValue(p.Taste, p.MutationRate, *tasteRange)
-			seedling.NutritionalValue = MutateValue(p.NutritionalValue, p.MutationRate, *nutritionRange)
-			seedling.Seeds = MutateValue(p.Seeds, p.MutationRate, *seedRange)
-			seedling.Wither = witherRange.randomFloat()
-			seedling.MutationRate = MutateValue(p.MutationRate, p.MutationRate, *mutationRange)
-			seedling.GrowthSpeed = MutateValue(p.GrowthSpeed, p.MutationRate, *mutationRange)
+			seedling.SeedDisperse = w.MutateValue(p.SeedDisperse, p.MutationRate, *w.rangeOf("Disperse"))
+			seedling.Taste = w.MutateValue(p.Taste, p.MutationRate, *w.rangeOf("Taste"))
+			seedling.Toxicity = w.MutateValue(p.Toxicity, p.MutationRate, *w.rangeOf("Toxicity"))
+			seedling.NutritionalValue = w.MutateValue(p.NutritionalValue, p.MutationRate, *w.rangeOf("Nutrition"))
+			seedling.Seeds = w.MutateValue(p.Seeds, p.MutationRate, *w.rangeOf("Seeds"))
+			seedling.Wither = w.rangeOf("Wither").randomFloat(w.random)
+			seedling.MutationRate = w.MutateValue(p.MutationRate, p.MutationRate, *w.rangeOf("Mutation"))
+			seedling.GrowthSpeed = w.MutateValue(p.GrowthSpeed, p.MutationRate, *w.rangeOf("Mutation"))
 			seedling.Type = p.Type
 
 			// Place the plant on the free spot
@@ -823,8 +1391,11 @@ func (w *RandomWorld) DisperseSeeds(p *GoWorld.Food, seeds int) []uuid.UUID {
 			seedling.Habitat = w.TerrainSpots[spots[spotIdx].X][spots[spotIdx].Y].Surface.ID
 			seedling.Position.X = spots[spotIdx].X
 			seedling.Position.Y = spots[spotIdx].Y
+			// Soil enriched by decomposed carrion makes the seedling grow faster
+			w.fertilize(seedling)
 			// Append to food list
 			w.FoodList[seedling.ID.String()] = seedling
+			w.recordEnergy("Sunlight", "Plants", seedling.NutritionalValue, seedling.NutritionalValue)
 			// ... and to return list
 			producedIDs = append(producedIDs, seedling.ID)
 		}
@@ -851,8 +1422,8 @@ func (w *RandomWorld) updatePlantSpot(x, y int, plantDiameter float64, id uuid.U
 
 // MutateValue produces a new value from the parent value
 // It uses a normal distribution with standard deviation of mutation rate and it does not overflow attribute range
-func MutateValue(parentAttribute, mutationRate float64, valueRange attributeRange) float64 {
-	modifier := rand.NormFloat64() * mutationRate
+func (w *RandomWorld) MutateValue(parentAttribute, mutationRate float64, valueRange attributeRange) float64 {
+	modifier := w.random.NormFloat64() * mutationRate
 	parentAttribute += modifier
 	// Check if produced value still in specified range
 	if parentAttribute < valueRange.Min {
@@ -864,17 +1435,17 @@ func MutateValue(parentAttribute, mutationRate float64, valueRange attributeRang
 }
 
 // Mutate values produces a value between first two parameters with a standard deviation of mutation rate
-func MutateValues(value1, value2, mutationRate float64, valueRange attributeRange) float64 {
+func (w *RandomWorld) MutateValues(value1, value2, mutationRate float64, valueRange attributeRange) float64 {
 	// Find out which values are lower bound and which is higher
 	low, high := value1, value2
 	if value1 > value2 {
 		low, high = value2, value1
 	}
 	// Calculate mutation multiplier
-	multiplier := rand.NormFloat64() * mutationRate
+	multiplier := w.random.NormFloat64() * mutationRate
 
 	// Calculate the random value between the given values and mutate it
-	newValue := (rand.Float64()*high + low) * multiplier
+	newValue := (w.random.Float64()*high + low) * multiplier
 	// Limit the value to the minimum and maximum range
 	if newValue < valueRange.Min {
 		newValue = valueRange.Min
@@ -960,7 +1531,7 @@ func (w *RandomWorld) MidpointCircleAt(center GoWorld.Location, radius float64)
 // Method returns false if any of the previous conditions are not fulfilled
 func (w *RandomWorld) canPlacePlant(x, y int, plantArea float64) bool {
 	// Check if surface allows plants to grow
-	if w.TerrainSpots[x][y].Surface.Habitable {
+	if habitable, _ := w.IsHabitable(GoWorld.Location{X: x, Y: y}); habitable {
 		// Spot can be planted on, is it occupied by a plant?
 		if w.TerrainSpots[x][y].OccupyingPlant == uuid.Nil {
 			// Current spot is free, check the circle with radius plantArea if enough space provided
@@ -987,6 +1558,10 @@ func (w *RandomWorld) canPlaceWaterPlant(x, y int, plantArea float64, plantID uu
 		// Non water surface provided
 		return false
 	}
+	if w.TerrainSpots[x][y].Depth > shallowDepth {
+		// Seaweed only grows in shallow water
+		return false
+	}
 
 	if w.TerrainSpots[x][y].OccupyingPlant == uuid.Nil || w.TerrainSpots[x][y].OccupyingPlant == plantID {
 		// Get a circular area around the spot (2D, depth not accounted for) and check if a plant is too close
@@ -1015,9 +1590,24 @@ func (w *RandomWorld) New() error {
 		return fmt.Errorf("the terrain size can't be less than or equal to zero (given WxH: %dx%d)", w.Width,
 			w.Height)
 	}
+	if err := w.applySettings(); err != nil {
+		return err
+	}
+	if hm := w.Settings.Heightmap; hm != nil && (hm.Rect.Dx() != w.Width || hm.Rect.Dy() != w.Height) {
+		return fmt.Errorf("the heightmap size does not match the terrain size (given %dx%d, terrain WxH: %dx%d)",
+			hm.Rect.Dx(), hm.Rect.Dy(), w.Width, w.Height)
+	}
 	// Initialize the food and being map
 	w.BeingList = make(map[string]*GoWorld.Being)
 	w.FoodList = make(map[string]*GoWorld.Food)
+	w.Territories = make(map[string]*Territory)
+	w.Obstacles = make(map[string]*Obstacle)
+	w.Homes = make(map[string]*Home)
+	w.Eggs = make(map[string]*Egg)
+	w.Caches = make(map[string]uuid.UUID)
+	w.Brains = make(map[string]GoWorld.Brain)
+	w.latest = make(map[string]latestUpdate)
+	w.Stats = newStats(w)
 
 	// Set the pathfinder
 	w.pathFinder = pathing.NewPathfinder(w)
@@ -1036,17 +1626,29 @@ func (w *RandomWorld) New() error {
 
 	// Get an instance of a Perlin noise generator
 	perl := noise.NewPerlin(6, 0.4, 0)
+	// Seeded worlds sample a different part of the noise for every seed
+	noiseOffset := 0.
+	if w.Settings.Seed != 0 {
+		noiseOffset = float64(w.random.Intn(1 << 16))
+	}
 	var g color.Gray
 	var grayNoise uint8
 	// Histogram to calculate how many pixels belong to each value (grayscale, so 256 bins with size 1)
 	hist := make([]int, 256)
 	// Fill the grayscale image with Perlin noise
 	for x := 0; x < w.Width; x++ {
+		if err := w.progressed("Noise", 60*float64(x)/float64(w.Width)); err != nil {
+			return err
+		}
 		for y := 0; y < w.Height; y++ {
-			floatNoise := perl.OctaveNoise2D(float64(x)/255, float64(y)/255)
-
+			if hm := w.Settings.Heightmap; hm != nil {
+				// The terrain follows the given heights
+				grayNoise = hm.GrayAt(hm.Rect.Min.X+x, hm.Rect.Min.Y+y).Y
+			} else {
+				floatNoise := perl.OctaveNoise2D(noiseOffset+float64(x)/255, noiseOffset+float64(y)/255)
+				grayNoise = uint8(floatNoise * 255)
+			}
 			// Paint the grayscale (pseudo DEM) terrain
-			grayNoise = uint8(floatNoise * 255)
 			g = color.Gray{
 				Y: grayNoise,
 			}
@@ -1056,72 +1658,138 @@ func (w *RandomWorld) New() error {
 		}
 	}
 	// Calculate at which height (0-255 grayscale) a zone begins and ends with custom ratios for each zone
-	zoneLimits := w.CalculateZoneLimits(hist, 0.20, 0.50, 0.10, 0.15, 0.025, 0.025)
+	zoneLimits, err := w.CalculateZoneLimits(hist, w.Settings.ZoneRatios...)
+	if err != nil {
+		return err
+	}
 
 	var c color.RGBA
 	for x := 0; x < w.Width; x++ {
+		if err := w.progressed("Zones", 60+25*float64(x)/float64(w.Width)); err != nil {
+			return err
+		}
 		for y := 0; y < w.Height; y++ {
 			grayNoise = w.TerrainImage.GrayAt(x, y).Y
 			// Paint the zones using colors
 			for i, l := range zoneLimits {
 				if grayNoise <= l {
 					// Found the appropriate zone, paint it with the i-th color
-					c = Surfaces[i].Color
-					w.TerrainSpots[x][y].Surface = &Surfaces[i]
+					c = w.surfaces[i].Color
+					w.TerrainSpots[x][y].Surface = &w.surfaces[i]
+					if i == 0 && l > 0 {
+						// Water gets deeper the lower the terrain is
+						w.TerrainSpots[x][y].Depth = 1 - float64(grayNoise)/float64(l)
+					}
 					break
 				}
 			}
 			w.TerrainZones.Set(x, y, c)
 		}
 	}
+	// Grow trees in the forests and scatter boulders over the grassland
+	if err := w.progressed("Trees", 85); err != nil {
+		return err
+	}
+	w.PlantTrees()
+	if err := w.progressed("Boulders", 90); err != nil {
+		return err
+	}
+	w.ScatterBoulders()
+	// Let land beings cross rivers and lake necks where they are shallow
+	if err := w.progressed("Fords", 95); err != nil {
+		return err
+	}
+	w.MarkFords()
+	// Beings spawn on the free spots of the surfaces they can live on
+	w.indexFreeSpots()
+	if w.MaxBeings == 0 {
+		w.MaxBeings = w.carryingCapacity()
+	}
+	// The terrain is done, cancelling no longer stops anything
+	_ = w.progressed("Done", 100)
 	// Store the terrain image
-	f, _ := os.Create("terrain.png")
-	defer f.Close()
-	_ = png.Encode(f, w.TerrainZones)
+	if !w.Settings.NoTerrainImage {
+		if err := w.storeTerrainImage(w.Settings.TerrainImagePath); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Provide food generates random plants across the terrain
-func (w *RandomWorld) ProvideFood(landPlants, waterPlants int) {
-	// Initialize each food with random values
-	for i := 0; i < landPlants; i++ {
-		p := w.RandomPlant(false)
-		w.FoodList[p.ID.String()] = p
+// storeTerrainImage writes the colored terrain as PNG to the path
+func (w *RandomWorld) storeTerrainImage(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error storing terrain image: %v", err)
+	}
+	err = png.Encode(f, w.TerrainZones)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
 	}
-	for i := 0; i < waterPlants; i++ {
-		p := w.RandomPlant(true)
-		w.FoodList[p.ID.String()] = p
+	if err != nil {
+		return fmt.Errorf("error storing terrain image %v: %v", path, err)
 	}
+	return nil
+}
+
+// Provide food generates random plants across the terrain
+// Returns how many plants were placed and an error if there was no room for the rest (of the land or water plants)
+func (w *RandomWorld) ProvideFood(landPlants, waterPlants int) (int, error) {
+	placed := 0
+	var err error
+	// Initialize each food with random values, a sea with no room left still lets the land plants grow and vice versa
+	for _, plants := range []struct {
+		quantity int
+		inWater  bool
+	}{{landPlants, false}, {waterPlants, true}} {
+		for i := 0; i < plants.quantity; i++ {
+			p, placeErr := w.RandomPlant(plants.inWater)
+			if placeErr != nil {
+				err = placeErr
+				break
+			}
+			w.FoodList[p.ID.String()] = p
+			w.recordEnergy("Sunlight", "Plants", p.NutritionalValue, p.NutritionalValue)
+			placed++
+		}
+	}
+	return placed, err
 }
 
 // randomPlant returns a food object with random parameters
-func (w *RandomWorld) RandomPlant(inWater bool) *GoWorld.Food {
-	f := &GoWorld.Food{ID: uuid.New()}
+// Returns an error if there is no room for the plant
+func (w *RandomWorld) RandomPlant(inWater bool) (*GoWorld.Food, error) {
+	f := &GoWorld.Food{ID: w.newID()}
 
 	// Randomly select attributes
-	f.GrowthSpeed = growthRange.randomFloat()
-	f.NutritionalValue = nutritionRange.randomFloat()
-	f.Taste = tasteRange.randomFloat()
-	f.GrowthStage = float64(stageRange.randomInt()) // keep as float for possible future expandability
-	f.StageProgress = stageProgressRange.randomFloat()
-	f.Area = areaRange.randomFloat()
-	f.Seeds = seedRange.randomFloat()
-	f.SeedDisperse = disperseRange.randomFloat()
-	f.Wither = witherRange.randomFloat()
-	f.MutationRate = mutationRange.randomFloat()
+	f.GrowthSpeed = w.rangeOf("Growth").randomFloat(w.random)
+	f.NutritionalValue = w.rangeOf("Nutrition").randomFloat(w.random)
+	f.Taste = w.rangeOf("Taste").randomFloat(w.random)
+	f.Toxicity = w.rangeOf("Toxicity").randomFloat(w.random)
+	f.GrowthStage = float64(w.rangeOf("Stage").randomInt(w.random)) // keep as float for possible future expandability
+	f.StageProgress = w.rangeOf("StageProgress").randomFloat(w.random)
+	f.Area = w.rangeOf("Area").randomFloat(w.random)
+	f.Seeds = w.rangeOf("Seeds").randomFloat(w.random)
+	f.SeedDisperse = w.rangeOf("Disperse").randomFloat(w.random)
+	f.Wither = w.rangeOf("Wither").randomFloat(w.random)
+	f.MutationRate = w.rangeOf("Mutation").randomFloat(w.random)
 
 	// place the plant onto the map (check if we want a water plant or not
+	var err error
 	if inWater {
 		f.Type = "Water"
-		w.LaunchPlant(f)
+		err = w.LaunchPlant(f)
 	} else {
 		f.Type = "Land"
-		w.ThrowPlant(f)
+		err = w.ThrowPlant(f)
+	}
+	if err != nil {
+		return nil, err
 	}
 	// Tell the plant what habitat it belongs to
 	f.Habitat = w.TerrainSpots[f.Position.X][f.Position.Y].Surface.ID
 
-	return f
+	return f, nil
 }
 
 // GetTerrainImage is a getter for the colored terrain (zones)
@@ -1160,6 +1828,16 @@ func (w *RandomWorld) GetSurfaceNameAt(location GoWorld.Location) (string, error
 	return w.TerrainSpots[location.X][location.Y].Surface.CommonName, nil
 }
 
+// GetHeightAt returns the height of the terrain at the location (from the generated heights, 0 lowest, 1 highest)
+func (w *RandomWorld) GetHeightAt(location GoWorld.Location) (float64, error) {
+	if w.IsOutOfBounds(location) {
+		return 0, fmt.Errorf(
+			"error providing height at spot: the location (%d, %d) is out of bounds. WorldSize (%v, %v)",
+			location.X, location.Y, w.Width, w.Height)
+	}
+	return float64(w.TerrainImage.GrayAt(location.X, location.Y).Y) / 255, nil
+}
+
 // GetBeingAt returns the ID of the being at the provided location
 // Returns uuid.Nil if no being present
 func (w *RandomWorld) GetBeingAt(location GoWorld.Location) (uuid.UUID, error) {
@@ -1187,241 +1865,157 @@ func (w *RandomWorld) GetBeingWithID(id uuid.UUID) *GoWorld.Being {
 	return nil
 }
 
-// IsHabitable returns if the provided spot allows movement and seeding plants
+// IsHabitable returns if the provided spot allows movement and seeding plants (obstacles block both)
 func (w *RandomWorld) IsHabitable(location GoWorld.Location) (bool, error) {
 	if w.IsOutOfBounds(location) {
 		return false, fmt.Errorf(
 			"error checking inhabitable spot: the location (%d, %d) is out of bounds. WorldSize (%v, %v)",
 			location.X, location.Y, w.Width, w.Height)
 	}
-	return w.TerrainSpots[location.X][location.Y].Surface.Habitable, nil
+	return w.TerrainSpots[location.X][location.Y].Surface.Habitable && w.obstacleAt(location) == nil, nil
 }
 
-// SenseActionFor uses the sense range of the being to decide on its next action
+// instinctFor is the built-in decision making of beings, it uses what the being perceives to decide on its next action
 // Rules:
-//  1. priorities are in this order: drinks, food, mating, stress
-//  2. if any value is above threshold prefer its action, in case many are above threshold follow the previous order
+//  1. every need (drinks, food, mating) that can be fulfilled in sight gets a score based on how big the need is,
+//     how far the spot is and how many predators are around it, weighted by the being personality. The best wins
+//  2. the most urgent need (drinks, food, mating in this order when equal) decides whether the being sleeps or rests
+//     and what it looks for (memory, scent) when nothing in sight can fulfill it
 //  3. if stress is above threshold and can not eat/drink or mate try to move to natural habitat
 //  4. if nothing in sensing range, or all need fulfilled (values at 0) move randomly
+//  5. exhausted beings rest regardless of their needs, tired ones rest if none of the needs are urgent
+//  6. beings sleep during their resting hours (day for nocturnal beings, night for others) if sleep is their biggest
+//     need, they look for a spot in their natural habitat to sleep in (or go home if their type builds homes)
+//  7. adults of types that build homes build one before mating, if they do not have one yet
+//  8. beings carrying food eat it when hungry, otherwise they cache it close to their home if no need is urgent
 // Returns action to do as string and the location it picked for the action
-func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location) {
-	// Get the spots that are visible to the being
-	// Vision range is influenced by stress:
-	//  a stress value of 0 represents the beings natural senses, stress of maxStress represents sense range * 2
-	stressShare := 1 + b.Stress/stressRange.Max
-	surroundings := w.MidpointCircleAt(b.Position, b.VisionRange*stressShare)
+func (w *RandomWorld) instinctFor(b *GoWorld.Being, p GoWorld.Perception) (string, GoWorld.Location) {
+	surroundings := p.Surroundings
 	// Get the attribute that is most needed (highest threshold value)
 	actionToDo := "wander"
 	actionThreshold := 0.0
+	// Juveniles can not mate, so their wish for a child is ignored until they grow up (and while they recover from
+	// mating)
+	wantsChild := b.WantsChild
+	if !canMate(b) {
+		wantsChild = 0
+	}
 	// Find out which of 3 basic needs has highest threshold (if > 0)
 	// If they have the same threshold if will prefer thirst over hunger over child wishes
 	if b.Thirst >= b.Hunger {
 		// Thirst is more than hunger (if same prefer thirst)
-		if b.Thirst >= b.WantsChild {
+		if b.Thirst >= wantsChild {
 			// Being needs water more than other basic necessities
 			actionToDo = "drink"
 			actionThreshold = b.Thirst
 		} else {
 			// Being wants child more than water
 			actionToDo = "mate"
-			actionThreshold = b.WantsChild
+			actionThreshold = wantsChild
 		}
 	} else {
 		// Being is needs food more than water
-		if b.Hunger >= b.WantsChild {
+		if b.Hunger >= wantsChild {
 			// Being has highest need for food
 			actionToDo = "eat"
 			actionThreshold = b.Hunger
 		} else {
 			// Being wants to have a child more than food or water
 			actionToDo = "mate"
-			actionThreshold = b.WantsChild
+			actionThreshold = wantsChild
 		}
 	}
 	// If the highest threshold was 0 reset the action to wander (being has needs fulfilled)
 	if actionThreshold <= 0 {
 		actionToDo = "wander"
 	}
+	// The hunting target is picked again every epoch
+	b.Target = uuid.Nil
+	// Beings sleep in their resting hours if sleep is their biggest need (or anywhere if they can not stay awake)
+	if b.Sleepiness >= sleepinessRange.Max || w.isRestingTime(b) && b.Sleepiness > actionThreshold {
+		if !needsHome(b) || b.Sleepiness >= sleepinessRange.Max {
+			return "sleep", w.sleepSpotFor(b, surroundings)
+		}
+		// Beings that build homes sleep at home, the homeless look for a spot to build one (and otherwise carry on)
+		if home := w.homeOf(b); home != nil {
+			return "sleep", home.Position
+		}
+		if homeSpot, found := w.homeSpotFor(b, surroundings); found {
+			return "sleep", homeSpot
+		}
+	}
+	// Young can only be raised at home, so the homeless build one before looking for a partner
+	if actionToDo == "mate" && needsHome(b) && w.homeOf(b) == nil {
+		if homeSpot, found := w.homeSpotFor(b, surroundings); found {
+			return "build", homeSpot
+		}
+	}
+	// Exhausted beings must stop and rest, tired ones rest when no need is urgent
+	if b.Energy <= exhaustedThreshold || b.Energy < restThreshold && actionThreshold < w.Settings.HungerThreshold {
+		return "rest", b.Position
+	}
 
-	// Check the surrounding spots for a suitable place to execute the action
-	chosenSpot := GoWorld.Location{}
-	chosenMetric := 0.0
-	spotUnset := true
-	for _, spot := range surroundings {
-		spotSurface, _ := w.GetSurfaceNameAt(spot)
-
-		switch actionToDo {
-		case "drink":
-			// Find the closest water spot
-			if spotSurface == "Water" {
-				if spotUnset {
-					// Set the first spot found
-					chosenSpot.X = spot.X
-					chosenSpot.Y = spot.Y
-					chosenMetric = w.Distance(b.Position, spot)
-					spotUnset = false
-				} else {
-					// Check if this spot is closer than the chosen one
-					if dist := w.Distance(b.Position, spot); dist < chosenMetric {
-						chosenSpot.X = spot.X
-						chosenSpot.Y = spot.Y
-						chosenMetric = dist
-					}
-				}
+	// Hungry beings eat the food they carry, the others take it to a cache close to their home
+	if b.Carrying > 0 {
+		if actionToDo == "eat" {
+			return "eat carried", b.Position
+		}
+		if actionThreshold < w.Settings.HungerThreshold {
+			if cacheSpot, found := w.cacheSpotFor(b, surroundings); found {
+				return "cache", cacheSpot
 			}
-		case "eat":
-			// If being is too hungry find closest food, otherwise tastiest
-			if w.TerrainSpots[spot.X][spot.Y].Being == uuid.Nil && b.Type != "Carnivore" {
-				if foodId := w.TerrainSpots[spot.X][spot.Y].Object; foodId != uuid.Nil {
-					if w.FoodList[foodId.String()] == nil {
-						// FixME why is nil food on the map?
-						//panic(fmt.Errorf("food present on map is not in food list"))
-						w.TerrainSpots[spot.X][spot.Y].Object = uuid.Nil
-						continue
-					}
-					// Water beings can only eat seaweed
-					if w.FoodList[foodId.String()].Type == "Water" && b.Type != "Water" {
-						// Non water beings cannot eat seaweed
-						continue
-					} else if b.Type == "Water" && w.FoodList[foodId.String()].Type != "Water" {
-						// Water beings only eat seaweed
-						continue
-					}
+		}
+	}
 
-					// Found food with no being on it
-					if spotUnset {
-						chosenSpot.X = spot.X
-						chosenSpot.Y = spot.Y
-						// Being wants something tasty
-						// Make a metric combined of taste and age -> older food is even tastier
-						// Invert value because we are using a minimization metric for code simplicity
-						// The final growth exceeds growthRange.Max for 1 to disperse seeds for last time
-						chosenMetric = tasteRange.Max - w.FoodList[foodId.String()].Taste*
-							w.FoodList[foodId.String()].GrowthStage/(growthRange.Max+1)
-						if b.Hunger >= hungerThreshold {
-							// Being is too hungry to care about taste
-							chosenMetric = w.Distance(b.Position, spot)
-						}
-						spotUnset = false
-					} else {
-						// Convert to minimization problem for code simplicity
-						thisMetric := tasteRange.Max - w.FoodList[foodId.String()].Taste*
-							w.FoodList[foodId.String()].GrowthStage/(growthRange.Max+1)
-						if b.Hunger >= hungerThreshold {
-							// Being is too hungry to care about taste
-							thisMetric = w.Distance(b.Position, spot)
-						}
-						// Check if this food is better (closer or tastier depending on being)
-						if thisMetric < chosenMetric {
-							chosenSpot.X = spot.X
-							chosenSpot.Y = spot.Y
-							chosenMetric = thisMetric
-						}
-					}
-				}
-			} else if b.Type == "Carnivore" && w.TerrainSpots[spot.X][spot.Y].Being != uuid.Nil {
-				// Found spot with being: metric is being size -> nutritional value x2
-				if spotSurface == "Forest" && w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Type == "Flying" {
-					// Flying beings hide inside forests and are invisible to predators
-					continue
-				}
-				if b.Type == w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Type {
-					// We do not encourage cannibalism
-					continue
-				}
+	// Territory owners chase away intruders if nothing more urgent needs to be done
+	if actionThreshold < w.Settings.HungerThreshold {
+		if intruder := w.intruderFor(b, surroundings); intruder != nil {
+			return "chase", intruder.Position
+		}
+	}
 
-				if spotUnset {
-					chosenSpot.X = spot.X
-					chosenSpot.Y = spot.Y
-					chosenMetric = w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Size
-					spotUnset = false
-					if b.Hunger >= hungerThreshold {
-						// Being is too hungry to care about being size
-						chosenMetric = w.Distance(b.Position, spot)
-					}
-				} else {
-					newSize := w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Size
-					if b.Hunger >= hungerThreshold {
-						// Being is too hungry to care about being size
-						newSize = w.Distance(b.Position, spot)
-					}
-					// Pick the largest being around
-					if newSize > chosenMetric {
-						chosenSpot.X = spot.X
-						chosenSpot.Y = spot.Y
-						chosenMetric = newSize
-					}
-				}
-			} else if b.Type == "Flying" {
-				if spotSurface == "Forest" && w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Type == "Flying" {
-					// Flying beings hide inside forests and are invisible to predators
-					continue
-				}
-				if b.Type == w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Type {
-					// We do not encourage cannibalism
-					continue
-				}
-				// Flying beings can only eat beings that are at most half their size
-				// It can also eat plants -> metric is compared with plant food
-				// (Tastiest + oldest plant == largest being)
-				if w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Size <= b.Size/2 {
-					if spotUnset {
-						chosenSpot.X = spot.X
-						chosenSpot.Y = spot.Y
-						// Convert size range to taste range
-						// NewValue = (((OldValue - OldMin) * (NewMax - NewMin)) / (OldMax - OldMin)) + NewMin
-						chosenMetric = tasteRange.Max - (((w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Size -
-							sizeRange.Min) * (tasteRange.Max - tasteRange.Min)) / (sizeRange.Max - sizeRange.Min)) + tasteRange.Min
-						if b.Hunger >= hungerThreshold {
-							// Being is too hungry to care about being size
-							chosenMetric = w.Distance(b.Position, spot)
-						}
-						spotUnset = false
-					} else {
-						// Minimization problem, so we can also work with plants and their taste levels and also distance
-						newSize := tasteRange.Max - (((w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()].Size -
-							sizeRange.Min) * (tasteRange.Max - tasteRange.Min)) / (sizeRange.Max - sizeRange.Min)) + tasteRange.Min
-						if b.Hunger >= hungerThreshold {
-							// Being is too hungry to care about being size
-							newSize = w.Distance(b.Position, spot)
-						}
-						// Pick being if "tastier" than previous beings / plants
-						if newSize < chosenMetric {
-							chosenSpot.X = spot.X
-							chosenSpot.Y = spot.Y
-							chosenMetric = newSize
-						}
-					}
-				}
-			}
-		case "mate":
-			// Find the closest being of opposite gender
-			if beingID := w.TerrainSpots[spot.X][spot.Y].Being; beingID != uuid.Nil {
-				otherBeing := w.BeingList[beingID.String()]
-				// Check if other being has a different gender but same type
-				if otherBeing.Gender != b.Gender && otherBeing.Type == b.Type {
-					if spotUnset {
-						// Set the first being
-						chosenSpot.X = spot.X
-						chosenSpot.Y = spot.Y
-						chosenMetric = w.Distance(b.Position, spot)
-						spotUnset = false
-					} else {
-						if dist := w.Distance(b.Position, spot); dist < chosenMetric {
-							// This being is closer
-							chosenSpot.X = spot.X
-							chosenSpot.Y = spot.Y
-							chosenMetric = dist
-						}
-					}
-				}
-			}
+	// Carnivores join the hunt of their pack if they can see the prey, otherwise they can only pick prey that they
+	// (with the help of their pack) can overpower
+	packStrength := 0.0
+	if actionToDo == "eat" && b.Type == "Carnivore" {
+		if prey := w.packPreyFor(b, surroundings, p.Sight); prey != nil {
+			b.Target = prey.ID
+			return actionToDo, prey.Position
+		}
+	}
+	if b.Type == "Carnivore" && b.Hunger > 0 {
+		packStrength = w.packStrength(b, surroundings)
+	}
+
+	// Score every need that can be fulfilled in sight and go for the best one
+	urgentAction := actionToDo
+	actionToDo, chosenSpot, found := w.bestActionFor(b, surroundings, p.Sight, packStrength)
+	spotUnset := !found
+	if spotUnset {
+		actionToDo = urgentAction
+	}
+	if spotUnset && (actionToDo == "drink" || actionToDo == "eat" && b.Type != "Carnivore") {
+		// Nothing in sight, try to remember where water or food was found before
+		if memorySpot, found := w.recall(b, actionToDo, p.Sight); found {
+			return "recall", memorySpot
+		}
+	} else if !spotUnset && (actionToDo == "drink" || actionToDo == "eat" && b.Type != "Carnivore") &&
+		w.TerrainSpots[chosenSpot.X][chosenSpot.Y].Being == uuid.Nil {
+		w.remember(b, actionToDo, chosenSpot)
+	}
+	if spotUnset && actionToDo == "eat" && b.Type == "Carnivore" {
+		// No prey in sight, follow the strongest prey scent
+		if scentSpot, found := w.strongestScent(b, surroundings, false); found {
+			return "track", scentSpot
 		}
 	}
 	if spotUnset {
 		// No spot was found, meaning surroundings do not offer the desired place
 		// Wander and try from next spot
 		actionToDo = "wander"
+	} else if actionToDo == "eat" {
+		// Remember which prey we are after (and let the pack know), nil if eating plants
+		b.Target = w.TerrainSpots[chosenSpot.X][chosenSpot.Y].Being
 	}
 	// Flying or water beings do not need to move to adjacent space to drink, only for mating
 	if b.Type == "Carnivore" && actionToDo == "drink" || actionToDo == "mate" {
@@ -1459,7 +2053,7 @@ func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location
 				predator := w.BeingList[possiblePredatorID.String()]
 				// Predators can only hunt other species, cannibalism is not allowed
 				if predator.Type != b.Type {
-					if predator.Type == "Carnivore" || predator.Type == "Flying" && predator.Size > 2*b.Size {
+					if predator.Type == "Carnivore" || predator.Type == "Flying" && bodySize(predator) > 2*bodySize(b) {
 						hideFromPredator = true
 						predatorSpot.X = spot.X
 						predatorSpot.Y = spot.Y
@@ -1482,6 +2076,15 @@ func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location
 				break
 			}
 		}
+		if hideFromPredator && b.Type == "Water" {
+			// Schools of fish scatter when a flying predator dives at them
+			predatorID := w.TerrainSpots[predatorSpot.X][predatorSpot.Y].Being
+			if predator := w.BeingList[predatorID.String()]; predator != nil && predator.Type == "Flying" {
+				if scatterSpot, scattered := w.scatterFrom(b, predatorSpot); scattered {
+					chosenSpot = scatterSpot
+				}
+			}
+		}
 		if hideFromPredator && !safeSpotFound {
 			// We need to RUN from the predator, move in opposite direction on a valid spot
 
@@ -1506,8 +2109,10 @@ func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location
 				// When both deltas differ from zero we move diagonally
 				// Calculate as if the path forms an orthogonal triangle
 				// c = sqrt(a^2 + b^2) -> b = sqrt(c^2 - a^2)
-				spotsToMoveX := rand.Intn(int(b.Speed))
-				spotsToMoveY := int(math.Sqrt(b.Speed*b.Speed - float64(spotsToMoveX)*float64(spotsToMoveX)))
+				// Old or very young beings can be slower than one spot per epoch, but should still try to run
+				speed := math.Max(w.currentSpeed(b), 1)
+				spotsToMoveX := w.random.Intn(int(speed))
+				spotsToMoveY := int(math.Sqrt(speed*speed - float64(spotsToMoveX)*float64(spotsToMoveX)))
 				// Move into opposite directions of deltas
 				chosenSpot.X = b.Position.X + (-predatorDeltaX * spotsToMoveX)
 				chosenSpot.Y = b.Position.Y + (-predatorDeltaY * spotsToMoveY)
@@ -1533,11 +2138,10 @@ func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location
 			}
 			foundSpot := false
 			spotIdx := 0
-			rand.Seed(time.Now().Unix())
-			rnd := rand.Intn(len(unvisitedSpots))
+			rnd := w.random.Intn(len(unvisitedSpots))
 			for len(unvisitedSpots) > 0 {
 				// Position in unvisited spots list
-				rnd = rand.Intn(len(unvisitedSpots))
+				rnd = w.random.Intn(len(unvisitedSpots))
 				// Unvisited spot index
 				spotIdx = unvisitedSpots[rnd]
 				// Spot was not available for plant, remove it from the unvisited array
@@ -1561,8 +2165,32 @@ func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location
 				chosenSpot.X = surroundings[spotIdx].X
 				chosenSpot.Y = surroundings[spotIdx].Y
 
+				// Beings that move in groups steer with their neighbours instead of wandering randomly
+				if weights, ok := w.Settings.SteeringModes[b.Type]; ok {
+					if groupSpot, steered := w.steerWithGroup(b, surroundings, weights); steered {
+						chosenSpot = groupSpot
+					}
+				}
+
+				// Prey avoids places where predators were recently
+				if b.Type != "Carnivore" {
+					if scentSpot, found := w.strongestScent(b, surroundings, true); found &&
+						w.TerrainSpots[scentSpot.X][scentSpot.Y].PredatorScent >= scentAlarm {
+						if fleeSpot, fled := w.scatterFrom(b, scentSpot); fled {
+							chosenSpot = fleeSpot
+						}
+					}
+				}
+
+				// Intruders leave the territory they are in
+				if territory := w.IntrudedTerritory(b); territory != nil {
+					if leaveSpot, left := w.scatterFrom(b, territory.Center); left {
+						chosenSpot = leaveSpot
+					}
+				}
+
 				// If neccessary, try to wander to nautral habitat to lower stress
-				if b.Stress >= stressThreshold && safeSpotFound {
+				if b.Stress >= w.Settings.StressThreshold && safeSpotFound {
 					chosenSpot.X = safeSpot.X
 					chosenSpot.Y = safeSpot.Y
 				}
@@ -1616,13 +2244,194 @@ func (w *RandomWorld) SenseActionFor(b *GoWorld.Being) (string, GoWorld.Location
 
 		}
 	}
-	// Increase being speed if it's ready for the hunt
-	if actionToDo == "eat" && b.Type == "Carnivore" {
-		// Resets after being tries to eat
-		b.Speed *= 2
+	return actionToDo, chosenSpot
+}
+
+// findActionSpot looks through the surroundings for the best spot to execute the action
+// Drinking picks the closest water, eating the tastiest food or largest prey (closest when very hungry) and mating
+// the closest partner. Carnivores only consider prey their pack strength can take down
+// Returns the chosen spot and false if no suitable spot is in sight
+func (w *RandomWorld) findActionSpot(b *GoWorld.Being, actionToDo string, surroundings []GoWorld.Location,
+	packStrength float64) (GoWorld.Location, bool) {
+	switch actionToDo {
+	case "drink":
+		// Find the closest water spot (or puddle)
+		return w.nearest(b.Position, surroundings, w.hasWater)
+	case "mate":
+		// Find the closest being of opposite gender (of the same type and old enough to mate)
+		return w.nearest(b.Position, surroundings, func(spot GoWorld.Location) bool {
+			beingID := w.TerrainSpots[spot.X][spot.Y].Being
+			if beingID == uuid.Nil {
+				return false
+			}
+			otherBeing := w.BeingList[beingID.String()]
+			return otherBeing.Gender != b.Gender && otherBeing.Type == b.Type && canMate(otherBeing)
+		})
+	}
+	if actionToDo != "eat" {
+		return GoWorld.Location{}, false
 	}
+	chosenSpot := GoWorld.Location{}
+	chosenMetric := 0.0
+	spotUnset := true
+	for _, spot := range surroundings {
+		spotSurface, _ := w.GetSurfaceNameAt(spot)
 
-	return actionToDo, chosenSpot
+		// If being is too hungry find closest food, otherwise tastiest
+		if w.TerrainSpots[spot.X][spot.Y].Being == uuid.Nil && b.Type != "Carnivore" {
+			if foodId := w.TerrainSpots[spot.X][spot.Y].Object; foodId != uuid.Nil {
+				if w.Obstacles[foodId.String()] != nil || w.Homes[foodId.String()] != nil {
+					// Obstacles and homes can not be eaten
+					continue
+				}
+				if w.FoodList[foodId.String()] == nil {
+					// FixME why is nil food on the map?
+					//panic(fmt.Errorf("food present on map is not in food list"))
+					w.TerrainSpots[spot.X][spot.Y].Object = uuid.Nil
+					continue
+				}
+				// Water beings can only eat seaweed, scavengers only carrion
+				if !canEatFood(b, w.FoodList[foodId.String()]) {
+					continue
+				}
+
+				// Found food with no being on it
+				if spotUnset {
+					chosenSpot.X = spot.X
+					chosenSpot.Y = spot.Y
+					// Being wants something tasty
+					// Make a metric combined of taste and age -> older food is even tastier, toxic food is avoided
+					// Invert value because we are using a minimization metric for code simplicity
+					// The final growth exceeds growthRange.Max for 1 to disperse seeds for last time
+					chosenMetric = tasteRange.Max - w.FoodList[foodId.String()].Taste*
+						w.FoodList[foodId.String()].GrowthStage/(growthRange.Max+1) +
+						harmfulToxicity(b, w.FoodList[foodId.String()])
+					if b.Hunger >= w.Settings.HungerThreshold {
+						// Being is too hungry to care about taste
+						chosenMetric = w.Distance(b.Position, spot)
+					}
+					spotUnset = false
+				} else {
+					// Convert to minimization problem for code simplicity
+					thisMetric := tasteRange.Max - w.FoodList[foodId.String()].Taste*
+						w.FoodList[foodId.String()].GrowthStage/(growthRange.Max+1) +
+						harmfulToxicity(b, w.FoodList[foodId.String()])
+					if b.Hunger >= w.Settings.HungerThreshold {
+						// Being is too hungry to care about taste
+						thisMetric = w.Distance(b.Position, spot)
+					}
+					// Check if this food is better (closer or tastier depending on being)
+					if thisMetric < chosenMetric {
+						chosenSpot.X = spot.X
+						chosenSpot.Y = spot.Y
+						chosenMetric = thisMetric
+					}
+				}
+			}
+		} else if b.Type == "Carnivore" && w.TerrainSpots[spot.X][spot.Y].Being != uuid.Nil {
+			// Found spot with being: metric is being size -> nutritional value x2
+			prey := w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()]
+			if spotSurface == "Forest" && prey.Type == "Flying" && !isJuvenile(prey) {
+				// Flying beings hide inside forests and are invisible to predators (juveniles have yet to learn
+				// how to hide)
+				continue
+			}
+			if !w.notices(b, prey) {
+				// The prey blends into its habitat
+				continue
+			}
+			if b.Type == prey.Type {
+				// We do not encourage cannibalism
+				continue
+			}
+			if isJuvenile(b) && bodySize(prey) > bodySize(b) {
+				// Juveniles can not hunt prey larger than themselves
+				continue
+			}
+			if b.Hunger < w.Settings.HungerThreshold && w.protectedByHerd(prey) {
+				// Beings in herds are hard to pick off, only starving predators try
+				continue
+			}
+			if bodySize(prey) > packStrength {
+				// Too large for the hunter and its pack to take down
+				continue
+			}
+
+			if spotUnset {
+				chosenSpot.X = spot.X
+				chosenSpot.Y = spot.Y
+				chosenMetric = bodySize(prey)
+				spotUnset = false
+				if b.Hunger >= w.Settings.HungerThreshold {
+					// Being is too hungry to care about being size
+					chosenMetric = w.Distance(b.Position, spot)
+				}
+			} else {
+				newSize := bodySize(prey)
+				if b.Hunger >= w.Settings.HungerThreshold {
+					// Being is too hungry to care about being size
+					newSize = w.Distance(b.Position, spot)
+				}
+				// Pick the largest being around
+				if newSize > chosenMetric {
+					chosenSpot.X = spot.X
+					chosenSpot.Y = spot.Y
+					chosenMetric = newSize
+				}
+			}
+		} else if b.Type == "Flying" {
+			prey := w.BeingList[w.TerrainSpots[spot.X][spot.Y].Being.String()]
+			if spotSurface == "Forest" && prey.Type == "Flying" && !isJuvenile(prey) {
+				// Flying beings hide inside forests and are invisible to predators
+				continue
+			}
+			if !w.notices(b, prey) {
+				// The prey blends into its habitat
+				continue
+			}
+			if b.Type == prey.Type {
+				// We do not encourage cannibalism
+				continue
+			}
+			// Flying beings can only eat beings that are at most half their size
+			// It can also eat plants -> metric is compared with plant food
+			// (Tastiest + oldest plant == largest being)
+			if bodySize(prey) <= bodySize(b)/2 {
+				if spotUnset {
+					chosenSpot.X = spot.X
+					chosenSpot.Y = spot.Y
+					// Convert size range to taste range
+					// NewValue = (((OldValue - OldMin) * (NewMax - NewMin)) / (OldMax - OldMin)) + NewMin
+					chosenMetric = tasteRange.Max - (((bodySize(prey) -
+						sizeRange.Min) * (tasteRange.Max - tasteRange.Min)) / (sizeRange.Max - sizeRange.Min)) + tasteRange.Min
+					if b.Hunger >= w.Settings.HungerThreshold {
+						// Being is too hungry to care about being size
+						chosenMetric = w.Distance(b.Position, spot)
+					}
+					spotUnset = false
+				} else {
+					// Minimization problem, so we can also work with plants and their taste levels and also distance
+					newSize := tasteRange.Max - (((bodySize(prey) -
+						sizeRange.Min) * (tasteRange.Max - tasteRange.Min)) / (sizeRange.Max - sizeRange.Min)) + tasteRange.Min
+					if b.Hunger >= w.Settings.HungerThreshold {
+						// Being is too hungry to care about being size
+						newSize = w.Distance(b.Position, spot)
+					}
+					// Pick being if "tastier" than previous beings / plants
+					if newSize < chosenMetric {
+						chosenSpot.X = spot.X
+						chosenSpot.Y = spot.Y
+						chosenMetric = newSize
+					}
+				}
+			}
+		}
+	}
+	if spotUnset && actionToDo == "eat" && b.Type == "Carnivore" {
+		// No prey around, feed on carrion instead
+		return w.closestCarrion(b, surroundings)
+	}
+	return chosenSpot, !spotUnset
 }
 
 // Distance returns the euclidean distance between two locations. To speed up we leave out the square root
@@ -1637,18 +2446,312 @@ func (w *RandomWorld) MoveBeingToLocation(b *GoWorld.Being, to GoWorld.Location)
 	//	panic(err.Error())
 	//	return err
 	//}
+	// Moving uses up energy
+	w.spendEnergy(b, to)
+
 	// Update the terrain spots with the new being
-	w.TerrainSpots[b.Position.X][b.Position.Y].Being = uuid.Nil
-	w.TerrainSpots[to.X][to.Y].Being = b.ID
+	w.setBeingAt(b.Position, uuid.Nil)
+	w.setBeingAt(to, b.ID)
 
-	// Update being position
+	// Update being position and remember where it was heading
+	b.Heading.X = to.X - b.Position.X
+	b.Heading.Y = to.Y - b.Position.Y
 	b.Position.X = to.X
 	b.Position.Y = to.Y
+	w.leaveScent(b)
 
 	return nil
 }
 
-// QuenchThirst tries to drink water if being is located 1 field away from water
+// spendEnergy lowers the being energy for moving from its position to the provided location
+// Faster beings use more energy and rough surfaces are harder to cross (flying beings do not care about the surface)
+func (w *RandomWorld) spendEnergy(b *GoWorld.Being, to GoWorld.Location) {
+	effort := 1.0
+	surfaceName, _ := w.GetSurfaceNameAt(to)
+	switch {
+	case flies(b.Type):
+		effort = 1.0
+	case b.Type == "Water":
+		// Water beings swim easily, but struggle on land
+		if surfaceName != "Water" {
+			effort = 2.0
+		}
+	case b.Type == "Amphibian":
+		// Amphibians struggle outside their primary medium
+		effort = 1 / w.mediumEfficiency(b, to)
+	case surfaceName == "Grassland":
+		effort = 1.0
+	case surfaceName == "Gravel":
+		effort = 1.5
+	case surfaceName == "Forest":
+		effort = 2.0
+	case surfaceName == "Mountain":
+		effort = 2.5
+	default:
+		effort = 3.0
+	}
+	speedC := 1 + w.currentSpeed(b)/speedRange.Max
+	b.Energy -= w.Distance(b.Position, to) * moveEnergyCost * effort * speedC
+	if b.Energy < 0 {
+		b.Energy = 0
+	}
+}
+
+// Sleep lowers the need for sleep and restores energy (the being does not move while sleeping)
+func (w *RandomWorld) Sleep(b *GoWorld.Being) {
+	b.Sleepiness -= sleepRecovery
+	if b.Sleepiness < 0 {
+		b.Sleepiness = 0
+	}
+	w.Rest(b)
+}
+
+// sleepSpotFor returns the closest spot in natural habitat where the being can sleep safely
+// If no such spot is visible the being sleeps where it is
+func (w *RandomWorld) sleepSpotFor(b *GoWorld.Being, surroundings []GoWorld.Location) GoWorld.Location {
+	if w.TerrainSpots[b.Position.X][b.Position.Y].Surface.ID == b.Habitat {
+		return b.Position
+	}
+	sleepSpot, found := w.nearest(b.Position, surroundings, func(spot GoWorld.Location) bool {
+		return w.TerrainSpots[spot.X][spot.Y].Surface.ID == b.Habitat && w.canPlaceBeing(spot, b.Type)
+	})
+	if !found {
+		return b.Position
+	}
+	return sleepSpot
+}
+
+// isRestingTime returns true if the being should be sleeping at this time of day
+func (w *RandomWorld) isRestingTime(b *GoWorld.Being) bool {
+	return b.Nocturnal != w.IsNight()
+}
+
+// Hibernate handles the winter state of beings that hibernate
+// Hibernation ends when winter is over. Before hibernating the being retreats to its natural habitat, but only if it
+// is not too hungry or thirsty (it keeps looking for food and water otherwise)
+// Returns the outcome and true if the being was busy with hibernation, false if it should act normally
+func (w *RandomWorld) Hibernate(b *GoWorld.Being) (GoWorld.Outcome, bool) {
+	if w.Season() != "Winter" {
+		// Spring has come, wake up
+		b.Hibernating = false
+		return "", false
+	}
+	if b.Hibernating {
+		// Low metabolism, needs barely rise and the being does nothing
+		b.Hunger += w.Settings.HungerIncrease * hibernationMetabolism
+		b.Thirst += w.Settings.ThirstIncrease * hibernationMetabolism
+		w.Rest(b)
+		return GoWorld.Hibernated, true
+	}
+	if b.Hunger >= w.Settings.HungerThreshold || b.Thirst >= w.Settings.HungerThreshold {
+		// Too hungry or thirsty to hibernate, act normally
+		return "", false
+	}
+	// Retreat towards the natural habitat
+	surroundings := w.MidpointCircleAt(b.Position, w.currentVision(b))
+	habitatSpot := w.sleepSpotFor(b, surroundings)
+	if habitatSpot == b.Position {
+		// The being can not find a better spot than this one, hibernate here
+		b.Hibernating = true
+		return GoWorld.Hibernated, true
+	}
+	pathing := time.Now()
+	path := w.pathFinder.GetPath(b.Position, habitatSpot, crossesWater(b.Type))
+	w.timeSince("Pathing", pathing)
+	w.plan(b, "hibernate", habitatSpot, path)
+	if len(path) == 0 {
+		// Can not reach the habitat spot
+		return "", false
+	}
+	speed := int(w.currentSpeed(b))
+	if speed >= len(path) {
+		speed = len(path) - 1
+	}
+	w.MoveBeingToLocation(b, path[speed])
+	w.AdjustNeeds(b)
+	return GoWorld.Retreated, true
+}
+
+// Migrate moves the being a step closer to the region it is migrating to
+// Hungry beings in regions with little food start migrating towards the most promising region (the one with the most
+// food for the distance it takes to get there). As all beings of a type in a region choose the same target, they
+// travel as a group. Migration stops once the being arrives or can not move any closer
+// Returns the outcome and true if the being migrated, false if it should act normally
+func (w *RandomWorld) Migrate(b *GoWorld.Being) (GoWorld.Outcome, bool) {
+	if !b.Migrating {
+		if b.Hunger < w.Settings.HungerThreshold || w.FoodInRegion(b.Position, b.Type) >= migrationThreshold {
+			// Not hungry or enough food around, no need to leave
+			return "", false
+		}
+		target, found := w.migrationTargetFor(b)
+		if !found {
+			return "", false
+		}
+		b.Migrating = true
+		b.Destination = target
+	}
+	distance := w.Distance(b.Position, b.Destination)
+	if distance <= float64(regionSize)/2 {
+		// Arrived at the target region
+		b.Migrating = false
+		return "", false
+	}
+	// Move in a straight line towards the target, shorten the step if the spot is not suitable
+	speed := math.Min(w.currentSpeed(b), distance)
+	for step := speed; step >= 1; step /= 2 {
+		nextSpot := GoWorld.Location{
+			X: b.Position.X + int(math.Round(float64(b.Destination.X-b.Position.X)/distance*step)),
+			Y: b.Position.Y + int(math.Round(float64(b.Destination.Y-b.Position.Y)/distance*step)),
+		}
+		if nextSpot != b.Position && !w.IsOutOfBounds(nextSpot) && w.canPlaceBeing(nextSpot, b.Type) {
+			w.MoveBeingToLocation(b, nextSpot)
+			return GoWorld.Migrated, true
+		}
+	}
+	// The way is blocked, give up on migrating
+	b.Migrating = false
+	return "", false
+}
+
+// migrationTargetFor returns the center of the region the being should migrate to
+// Returns false if no region has enough food
+func (w *RandomWorld) migrationTargetFor(b *GoWorld.Being) (GoWorld.Location, bool) {
+	w.ensureRegionStats()
+	target := GoWorld.Location{}
+	found := false
+	bestScore := 0.0
+	for rx := range w.regionFood {
+		for ry := range w.regionFood[rx] {
+			food := w.regionFood[rx][ry][b.Type]
+			if food < migrationThreshold {
+				continue
+			}
+			center := GoWorld.Location{
+				X: int(math.Min(float64(rx*regionSize+regionSize/2), float64(w.Width-1))),
+				Y: int(math.Min(float64(ry*regionSize+regionSize/2), float64(w.Height-1))),
+			}
+			// Prefer regions with a lot of food that are not too far away
+			score := float64(food) / (1 + w.Distance(b.Position, center)/float64(regionSize))
+			if score > bestScore {
+				target = center
+				bestScore = score
+				found = true
+			}
+		}
+	}
+	return target, found
+}
+
+// FoodInRegion returns the number of food sources for the being type in the region of the location
+func (w *RandomWorld) FoodInRegion(location GoWorld.Location, beingType string) int {
+	if w.IsOutOfBounds(location) {
+		return 0
+	}
+	w.ensureRegionStats()
+	return w.regionFood[location.X/regionSize][location.Y/regionSize][beingType]
+}
+
+// ensureRegionStats computes the regional statistics if they were not computed yet
+func (w *RandomWorld) ensureRegionStats() {
+	if w.regionFood == nil {
+		w.UpdateRegionStats()
+	}
+}
+
+// UpdateRegionStats counts the food sources and the beings in every region of the map
+// Land plants feed flying beings, water plants feed water beings and all non carnivore beings feed carnivores
+func (w *RandomWorld) UpdateRegionStats() {
+	regionsX := (w.Width + regionSize - 1) / regionSize
+	regionsY := (w.Height + regionSize - 1) / regionSize
+	w.regionFood = make([][]map[string]int, regionsX)
+	for rx := range w.regionFood {
+		w.regionFood[rx] = make([]map[string]int, regionsY)
+		for ry := range w.regionFood[rx] {
+			w.regionFood[rx][ry] = make(map[string]int)
+		}
+	}
+	for _, f := range w.FoodList {
+		region := w.regionFood[f.Position.X/regionSize][f.Position.Y/regionSize]
+		switch f.Type {
+		case "Water":
+			region["Water"]++
+			region["Amphibian"]++
+		case "Carrion":
+			region["Scavenger"]++
+		case "Egg":
+			region["Scavenger"]++
+			region["Amphibian"]++
+		default:
+			region["Flying"]++
+			region["Amphibian"]++
+			if f.GrowthStage <= insectFoodStage {
+				region["Insect"]++
+			}
+		}
+	}
+	for _, b := range w.BeingList {
+		if b.Type != "Carnivore" {
+			w.regionFood[b.Position.X/regionSize][b.Position.Y/regionSize]["Carnivore"]++
+		}
+	}
+	w.countRegionBeings(regionsX, regionsY)
+}
+
+// Season returns the current season of the world
+func (w *RandomWorld) Season() string {
+	dayLength := w.DayLength
+	if dayLength == 0 {
+		dayLength = defaultDayLength
+	}
+	yearLength := w.YearLength
+	if yearLength == 0 {
+		yearLength = defaultYearLength
+	}
+	day := (w.Epoch / dayLength) % yearLength
+	return seasons[day*uint64(len(seasons))/yearLength]
+}
+
+// AdvanceTime moves the world clock one epoch forward
+func (w *RandomWorld) AdvanceTime() {
+	started := time.Now()
+	w.Epoch++
+	w.runSchedule()
+	w.immigrate()
+	w.UpdateScents()
+	if w.random.Float64() < RainChance {
+		w.Rain()
+	}
+	w.Evaporate()
+	if w.Epoch%regionUpdateInterval == 0 {
+		w.UpdateRegionStats()
+	}
+	w.timeSince("Time", started)
+	w.timings.endTick()
+	if w.Stats != nil {
+		w.Stats.tick()
+	}
+	w.exportIfDue()
+	w.emit("ticked")
+}
+
+// IsNight returns true during the second half of the day and night cycle
+func (w *RandomWorld) IsNight() bool {
+	dayLength := w.DayLength
+	if dayLength == 0 {
+		dayLength = defaultDayLength
+	}
+	return w.Epoch%dayLength >= dayLength/2
+}
+
+// Rest restores some of the being energy (the being does not move while resting)
+func (w *RandomWorld) Rest(b *GoWorld.Being) {
+	b.Energy += restEnergyGain
+	if b.Energy > energyRange.Max {
+		b.Energy = energyRange.Max
+	}
+}
+
+// QuenchThirst tries to drink water if being is located 1 field away from water (or next to or in a puddle)
 // Returns true when being was able to drink, otherwise returns false
 func (w *RandomWorld) QuenchThirst(b *GoWorld.Being) bool {
 	// Set true if water found
@@ -1660,12 +2763,21 @@ func (w *RandomWorld) QuenchThirst(b *GoWorld.Being) bool {
 			// Not on map, simply continue
 			continue
 		}
-		// Check if surface type is water
-		if w.TerrainSpots[b.Position.X+d.X][b.Position.Y+d.Y].Surface.CommonName == "Water" {
+		// Check if surface type is water (or a puddle)
+		spot := GoWorld.Location{X: b.Position.X + d.X, Y: b.Position.Y + d.Y}
+		if w.hasWater(spot) {
+			if w.TerrainSpots[spot.X][spot.Y].Puddle > 0 {
+				w.drainPuddle(spot, puddleSip)
+			}
 			drank = true
 			break
 		}
 	}
+	// Beings can also drink from a puddle they stand in
+	if !drank && w.TerrainSpots[b.Position.X][b.Position.Y].Puddle > 0 {
+		w.drainPuddle(b.Position, puddleSip)
+		drank = true
+	}
 	// If Being was able to drink, lower its thirst
 	if drank {
 		b.Thirst = 0
@@ -1688,18 +2800,26 @@ func (w *RandomWorld) QuenchHunger(b *GoWorld.Being, foodSpot GoWorld.Location)
 	if distanceToFood < 2 {
 		// Food spot is an adjacent field, we can eat
 		// Do we eat beings or plants?
-		if beingID := w.TerrainSpots[foodSpot.X][foodSpot.Y].Being; beingID != uuid.Nil && (b.Type == "Carnivore" ||
-			b.Type == "Flying") {
+		if beingID := w.TerrainSpots[foodSpot.X][foodSpot.Y].Being; beingID != uuid.Nil && beingID != b.ID &&
+			(b.Type == "Carnivore" || b.Type == "Flying") {
 
 			// Being is present on the spot, EAT IT
 			beingToEat := w.BeingList[beingID.String()]
-			b.Hunger -= beingToEat.Size * 4 // Nutritional value of being is 4x its size
+			nutrition := bodySize(beingToEat) * carrionNutrition // Nutritional value of being is 4x its size
+			hunger := b.Hunger
+			b.Hunger -= nutrition
 			ate = true
-			delete(w.BeingList, beingID.String())
-			w.TerrainSpots[foodSpot.X][foodSpot.Y].Being = uuid.Nil
+			w.removeBeing(beingToEat)
+			preyLevel := trophicLevel(beingToEat.Type)
 			if b.Hunger < 0 {
+				// The being is full, what it could not eat stays behind
+				if remains := w.leaveCarrion(beingToEat, -b.Hunger); remains != nil {
+					nutrition -= remains.NutritionalValue
+					w.recordEnergy(preyLevel, "Corpses", remains.NutritionalValue, remains.NutritionalValue)
+				}
 				b.Hunger = 0
 			}
+			w.recordEnergy(preyLevel, trophicLevel(b.Type), nutrition, hunger-b.Hunger)
 
 		} else {
 			// Herbivore: eat plants
@@ -1713,18 +2833,44 @@ func (w *RandomWorld) QuenchHunger(b *GoWorld.Being, foodSpot GoWorld.Location)
 				return false
 			}
 			food := w.FoodList[foodID.String()]
-			// Eat the whole thing -> lowers hunger by nutritional value
-			b.Hunger -= food.NutritionalValue
+			if b.Type == "Insect" {
+				// Insects feed on the plant without eating it
+				w.Pollinate(b, food)
+				return true
+			}
+			// Take a bite as big as the hunger (or what is left of the food) -> lowers hunger by the bitten nutritional
+			// value (amphibians digest food from outside their primary medium less efficiently)
+			// Biting sets the plant growth back, the food is gone only when all of it was eaten
+			efficiency := w.mediumEfficiency(b, food.Position)
+			hunger := b.Hunger
+			bite := math.Min(food.NutritionalValue-food.Eaten, b.Hunger/efficiency)
+			if food.Type == "Egg" || food.Type == "Cache" {
+				// Eggs and caches are eaten whole
+				bite = food.NutritionalValue - food.Eaten
+			}
+			b.Hunger -= bite * efficiency
+			if bite > 0 {
+				food.Eaten += bite
+				food.StageProgress *= 1 - biteProgressLoss
+				w.Poison(b, food, bite)
+			}
 			ate = true
-			delete(w.FoodList, food.ID.String())
-			w.TerrainSpots[food.Position.X][food.Position.Y].Object = uuid.Nil
-			w.updatePlantSpot(food.Position.X, food.Position.Y, food.Area, uuid.Nil)
+			// Hoarders take what they could not eat with them
+			w.PickUpFood(b, food)
+			if food.Eaten >= food.NutritionalValue {
+				delete(w.FoodList, food.ID.String())
+				delete(w.Eggs, food.ID.String())
+				delete(w.Caches, food.ID.String())
+				w.TerrainSpots[food.Position.X][food.Position.Y].Object = uuid.Nil
+				w.updatePlantSpot(food.Position.X, food.Position.Y, food.Area, uuid.Nil)
+			}
 
 			// Hunger should not be negative
 			if b.Hunger < 0 {
 				// The being can not eat more, so break out of the loop
 				b.Hunger = 0
 			}
+			w.recordEnergy(foodLevel(food), trophicLevel(b.Type), bite, hunger-b.Hunger)
 			// Todo also lower thirst with a small chance
 		}
 	}
@@ -1744,6 +2890,12 @@ func (w *RandomWorld) AdjustStressFor(b *GoWorld.Being) {
 	if w.TerrainSpots[b.Position.X][b.Position.Y].Surface.ID != b.Habitat {
 		feelsSafe = 2.0
 	}
+	// Intruding into the territory of another being of the same type is stressful as well
+	if w.IntrudedTerritory(b) != nil {
+		feelsSafe++
+	}
+	// So is living in an overcrowded region
+	feelsSafe += w.overcrowding(b.Position) * crowdingStress
 	// How much every necessity contributes
 	// (2 * len(basicNecessities) * contribution = 2 * 3 * contribution = 1)
 	c := 1.0 / 6
@@ -1753,6 +2905,10 @@ func (w *RandomWorld) AdjustStressFor(b *GoWorld.Being) {
 	// Update stress
 	// Fixme somehow goes over 255
 	b.Stress = feelsSafe * c * (b.Thirst + b.Hunger + b.WantsChild) * sizeC
+	// Being close to home is calming
+	if w.nearHome(b) {
+		b.Stress *= homeStress
+	}
 	if b.Stress > 255 {
 		b.Stress = 255
 	}
@@ -1765,38 +2921,53 @@ func (w *RandomWorld) AdjustStressFor(b *GoWorld.Being) {
 //  - Speed
 //  - Stress
 //  - Size
+//  - Parasites
 func (w *RandomWorld) AdjustNeeds(b *GoWorld.Being) {
 	// Most durable beings (compared to least) need only ~30% food
 	// 0.3 = 1 - x / (x*1.43) for any x
 	durableC := 1 - b.Durability/(durabilityRange.Max*1.43)
 
 	// Increase other values proportional to attribute shares
-	speedC := 1 + b.Speed/(speedRange.Max)
+	speedC := 1 + w.currentSpeed(b)/(speedRange.Max)
 	stressC := 1 + b.Stress/(stressRange.Max)
 	sizeC := 1 + b.Size/(sizeRange.Max)
+	// Remembering more needs a bigger brain that needs up to 25% more food and water
+	memoryC := 1 + 0.25*b.MemorySize/memorySizeRange.Max
+	// Food is scarcer for everyone in overcrowded regions
+	crowdingC := 1 + w.overcrowding(b.Position)*crowdingHunger
 	// Calculate the multiplier for increase per epoch values
-	multiplier := durableC * speedC * stressC * sizeC
+	multiplier := durableC * speedC * stressC * sizeC * memoryC * crowdingC * parasiteHungerC(b)
 
 	// Update the basic needs with the given multiplier
-	b.Hunger += hungerIncrease * multiplier
+	b.Hunger += w.Settings.HungerIncrease * multiplier
 
-	// Water beings thirst does not increase while in water, but increases twice as fast outside of water
-	beingSurface, _ := w.GetSurfaceNameAt(b.Position)
-	if b.Type == "Water" && beingSurface != "Water" {
-		b.Thirst += thirstIncrease * multiplier * 2
+	// Water beings thirst does not increase while in water, but increases twice as fast outside of water (or in water
+	// too shallow for them)
+	if b.Type == "Water" && !w.submerged(b, b.Position) {
+		b.Thirst += w.Settings.ThirstIncrease * multiplier * 2
 	} else if b.Type != "Water" {
 		// Normal increase for other beings
 		// Water beings' thirst does not increase while they are in water
-		b.Thirst += thirstIncrease * multiplier
+		b.Thirst += w.Settings.ThirstIncrease * multiplier
 	}
 
-	b.WantsChild += wantsChildIncrease
+	b.WantsChild += w.Settings.WantsChildIncrease * breedingRateFor(b.Type)
+	w.Heal(b)
+	w.FeedParasites(b)
+	b.Sleepiness += w.Settings.SleepinessIncrease
+	if b.Sleepiness > sleepinessRange.Max {
+		b.Sleepiness = sleepinessRange.Max
+	}
 }
 
 // MateBeing tries to mate two adjacent beings with opposite genders and produce offspring
-// The mutation rate is taken from the initiator
-// Returns IDs of children produced
+// The mutation rate is taken from the initiator. Egg laying types lay the offspring in eggs next to the initiator
+// Returns IDs of children produced (or of the eggs laid)
 func (w *RandomWorld) MateBeing(b *GoWorld.Being) []uuid.UUID {
+	// Juveniles are too young to produce offspring, beings that just mated need to recover
+	if !canMate(b) {
+		return []uuid.UUID{}
+	}
 	// Find a partner of opposite gender on adjacent fields
 	var otherBeing *GoWorld.Being
 	for _, direction := range directions8 {
@@ -1804,8 +2975,8 @@ func (w *RandomWorld) MateBeing(b *GoWorld.Being) []uuid.UUID {
 		if !w.IsOutOfBounds(adjacentSpot) {
 			// Check if being there
 			if beingID := w.TerrainSpots[adjacentSpot.X][adjacentSpot.Y].Being; beingID != uuid.Nil {
-				// Check if opposite gender
-				if w.BeingList[beingID.String()].Gender != b.Gender {
+				// Check if opposite gender and old enough to mate
+				if w.BeingList[beingID.String()].Gender != b.Gender && canMate(w.BeingList[beingID.String()]) {
 					// Chose this being to mate with
 					otherBeing = w.BeingList[beingID.String()]
 					break
@@ -1817,9 +2988,15 @@ func (w *RandomWorld) MateBeing(b *GoWorld.Being) []uuid.UUID {
 		// No adjacent being found, cannot mate
 		return []uuid.UUID{}
 	}
+	if needsHome(b) && w.homeOf(b) == nil && w.homeOf(otherBeing) == nil {
+		// Neither of the parents has a home to raise the young in
+		return []uuid.UUID{}
+	}
+	// Parasites jump between the partners
+	w.MingleParasites(b, otherBeing)
 	var babyIDs []uuid.UUID
 	// Both beings are present, make some babies
-	babiesToMake := int(MutateValues(b.Fertility, otherBeing.Fertility, b.MutationRate, *fertilityRange))
+	babiesToMake := int(w.MutateValues(b.Fertility, otherBeing.Fertility, b.MutationRate, *w.fertilityRangeFor(b.Type)))
 	for i := 0; i < babiesToMake; i++ {
 		babyHasSpot := false
 		// Find empty spot first, then create being
@@ -1829,31 +3006,73 @@ func (w *RandomWorld) MateBeing(b *GoWorld.Being) []uuid.UUID {
 				// Ignore spots out of bounds
 				continue
 			}
-			if w.canPlaceBeing(adjacentSpot, b.Type) {
+			if !EggLayingTypes[b.Type] && w.canPlaceBeing(adjacentSpot, b.Type) ||
+				EggLayingTypes[b.Type] && w.canLayEgg(adjacentSpot, b.Type) {
+				if !w.roomForBirth() {
+					// The world can not support more beings
+					break
+				}
 				// Yay being has spot, give birth to it there
 				babyHasSpot = true
 
 				// Create baby from parents values and some mutation
-				baby := &GoWorld.Being{ID: uuid.New()}
-				baby.Hunger = MutateValues(b.Hunger, otherBeing.Hunger, b.MutationRate, *hungerRange)
-				baby.Thirst = MutateValues(b.Thirst, otherBeing.Thirst, b.MutationRate, *thirstRange)
-				baby.WantsChild = MutateValues(b.WantsChild, otherBeing.WantsChild, b.MutationRate, *wantsChildRange)
-				baby.LifeExpectancy = MutateValues(b.LifeExpectancy, otherBeing.LifeExpectancy, b.MutationRate, *lifeExpectancyRange)
-				baby.VisionRange = MutateValues(b.VisionRange, otherBeing.VisionRange, b.MutationRate, *visionRange)
-				baby.Speed = MutateValues(b.Speed, otherBeing.Speed, b.MutationRate, *speedRange)
-				baby.Durability = MutateValues(b.Durability, otherBeing.Durability, b.MutationRate, *durabilityRange)
-				baby.Stress = MutateValues(b.Stress, otherBeing.Stress, b.MutationRate, *stressRange)
+				baby := &GoWorld.Being{ID: w.newID()}
+				baby.Hunger = w.MutateValues(b.Hunger, otherBeing.Hunger, b.MutationRate, *w.rangeOf("Hunger"))
+				baby.Thirst = w.MutateValues(b.Thirst, otherBeing.Thirst, b.MutationRate, *w.rangeOf("Thirst"))
+				baby.WantsChild = w.MutateValues(b.WantsChild, otherBeing.WantsChild, b.MutationRate,
+					*w.rangeOf("WantsChild"))
+				baby.LifeExpectancy = w.MutateValues(b.LifeExpectancy, otherBeing.LifeExpectancy, b.MutationRate,
+					*w.lifeExpectancyRangeFor(b.Type))
+				baby.VisionRange = w.MutateValues(b.VisionRange, otherBeing.VisionRange, b.MutationRate,
+					*w.rangeOf("Vision"))
+				baby.Speed = w.MutateValues(b.Speed, otherBeing.Speed, b.MutationRate, *w.rangeOf("Speed"))
+				baby.Durability = w.MutateValues(b.Durability, otherBeing.Durability, b.MutationRate,
+					*w.rangeOf("Durability"))
+				baby.Stress = w.MutateValues(b.Stress, otherBeing.Stress, b.MutationRate, *w.rangeOf("Stress"))
+				baby.Energy = w.rangeOf("Energy").Max
+				baby.Sleepiness = 0
+				// Active hours are inherited from one of the parents
+				baby.Nocturnal = b.Nocturnal
+				if w.random.Intn(2) > 0 {
+					baby.Nocturnal = otherBeing.Nocturnal
+				}
 				baby.Habitat = b.Habitat
-				baby.Gender = randomGender()
-				baby.Size = MutateValues(b.Size, otherBeing.Size, b.MutationRate, *sizeRange)
-				baby.Fertility = MutateValues(b.Fertility, otherBeing.Fertility, b.MutationRate, *fertilityRange)
-				baby.MutationRate = MutateValues(b.MutationRate, otherBeing.MutationRate, b.MutationRate, *mutationRange)
+				baby.Gender = w.randomGender()
+				baby.Size = w.MutateValues(b.Size, otherBeing.Size, b.MutationRate, *w.sizeRangeFor(b.Type))
+				baby.Fertility = w.MutateValues(b.Fertility, otherBeing.Fertility, b.MutationRate,
+					*w.fertilityRangeFor(b.Type))
+				baby.MutationRate = w.MutateValues(b.MutationRate, otherBeing.MutationRate, b.MutationRate,
+					*w.rangeOf("Mutation"))
+				baby.MaturityAge = w.MutateValues(b.MaturityAge, otherBeing.MaturityAge, b.MutationRate,
+					*w.maturityRangeFor(b.Type))
+				baby.Camouflage = w.MutateValues(b.Camouflage, otherBeing.Camouflage, b.MutationRate,
+					*w.rangeOf("Camouflage"))
+				baby.MemorySize = w.MutateValues(b.MemorySize, otherBeing.MemorySize, b.MutationRate,
+					*w.rangeOf("MemorySize"))
+				baby.Resistance = w.MutateValues(b.Resistance, otherBeing.Resistance, b.MutationRate,
+					*w.rangeOf("Resistance"))
+				baby.Personality = w.inheritPersonality(b, otherBeing)
+				baby.Lineage = b.Lineage
+				if baby.Lineage == uuid.Nil {
+					// The parent founded the family
+					baby.Lineage = b.ID
+				}
+				baby.Generation = b.Generation + 1
+				baby.Parents = [2]uuid.UUID{b.ID, otherBeing.ID}
+				b.Offspring++
+				otherBeing.Offspring++
+				baby.Age = 0
 				baby.Position.X = adjacentSpot.X
 				baby.Position.Y = adjacentSpot.Y
 				baby.Type = b.Type
 
+				if EggLayingTypes[b.Type] {
+					// The baby hatches later (if the egg is not eaten before that)
+					babyIDs = append(babyIDs, w.LayEgg(baby).ID)
+					continue
+				}
 				// Add the baby to the being list and place on map
-				w.TerrainSpots[adjacentSpot.X][adjacentSpot.Y].Being = baby.ID
+				w.setBeingAt(adjacentSpot, baby.ID)
 				w.BeingList[baby.ID.String()] = baby
 				babyIDs = append(babyIDs, baby.ID)
 			}
@@ -1863,10 +3082,12 @@ func (w *RandomWorld) MateBeing(b *GoWorld.Being) []uuid.UUID {
 			break
 		}
 	}
-	// If at least one baby was born remove the wish for babies from both beings
+	// If at least one baby was born remove the wish for babies from both beings, they can not mate for a while
 	if len(babyIDs) > 0 {
 		b.WantsChild = 0
 		otherBeing.WantsChild = 0
+		b.MateCooldown = matingCooldown
+		otherBeing.MateCooldown = matingCooldown
 	}
 	return babyIDs
 }
@@ -0,0 +1,82 @@
+package terrain
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+)
+
+var (
+	// TerritoryRadius defines which being types claim territories and how large they are
+	TerritoryRadius = map[string]float64{
+		"Carnivore": 40,
+	}
+)
+
+// Territory is an area claimed by a being, same type beings inside it are intruders and get chased away
+type Territory struct {
+	Owner  uuid.UUID        // The being that claimed the territory
+	Type   string           // The type of the owner (only the same type beings are intruders)
+	Center GoWorld.Location // The center of the territory
+	Radius float64          // How far the territory reaches from the center
+}
+
+// Contains returns true if the location is inside the territory
+func (t *Territory) Contains(location GoWorld.Location, w GoWorld.World) bool {
+	return w.Distance(t.Center, location) <= t.Radius
+}
+
+// ClaimTerritory marks a territory around the being's position if its type is territorial, it is an adult without a
+// territory and no other being of its type claimed the area around
+func (w *RandomWorld) ClaimTerritory(b *GoWorld.Being) {
+	radius, territorial := TerritoryRadius[b.Type]
+	if !territorial || isJuvenile(b) || w.Territories == nil {
+		return
+	}
+	if _, claimed := w.Territories[b.ID.String()]; claimed {
+		return
+	}
+	for _, t := range w.Territories {
+		// Territories can overlap, but not more than half (any one too close prevents the claim, so the order in which
+		// they are checked does not matter)
+		if t.Type == b.Type && w.Distance(t.Center, b.Position) < t.Radius {
+			return
+		}
+	}
+	w.Territories[b.ID.String()] = &Territory{
+		Owner:  b.ID,
+		Type:   b.Type,
+		Center: b.Position,
+		Radius: radius,
+	}
+}
+
+// IntrudedTerritory returns the territory of another being of the same type that the being is in (nil if none). In
+// overlapping territories it is the one with the closest center (the lower owner ID if they are as close)
+func (w *RandomWorld) IntrudedTerritory(b *GoWorld.Being) *Territory {
+	var intruded *Territory
+	closest := 0.
+	for _, t := range w.Territories {
+		if t.Owner == b.ID || t.Type != b.Type || !t.Contains(b.Position, w) {
+			continue
+		}
+		dist := w.Distance(t.Center, b.Position)
+		if intruded == nil || dist < closest || dist == closest && t.Owner.String() < intruded.Owner.String() {
+			intruded, closest = t, dist
+		}
+	}
+	return intruded
+}
+
+// intruderFor returns a visible being of the same type inside the being's territory (nil if none)
+func (w *RandomWorld) intruderFor(b *GoWorld.Being, surroundings []GoWorld.Location) *GoWorld.Being {
+	territory, ok := w.Territories[b.ID.String()]
+	if !ok {
+		return nil
+	}
+	for _, other := range w.groupMembers(b, surroundings) {
+		if territory.Contains(other.Position, w) {
+			return other
+		}
+	}
+	return nil
+}
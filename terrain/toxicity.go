@@ -0,0 +1,30 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// How much harm a full meal of food with harmful toxicity of 1 does (durability lost and stress gained)
+	toxinEffect = 0.5
+)
+
+// harmfulToxicity returns how much of the food toxicity the being can not resist
+func harmfulToxicity(b *GoWorld.Being, f *GoWorld.Food) float64 {
+	return math.Max(f.Toxicity-b.Resistance, 0)
+}
+
+// Poison harms the being for the bite it took of the toxic food, the harm grows with the size of the bite and the
+// toxicity the being can not resist. It lowers the being durability and raises its stress
+func (w *RandomWorld) Poison(b *GoWorld.Being, f *GoWorld.Food, bite float64) {
+	if f.NutritionalValue <= 0 {
+		return
+	}
+	harm := harmfulToxicity(b, f) * bite / f.NutritionalValue * toxinEffect
+	if harm <= 0 {
+		return
+	}
+	b.Durability = math.Max(b.Durability-harm, durabilityRange.Min)
+	b.Stress = math.Min(b.Stress+harm, stressRange.Max)
+}
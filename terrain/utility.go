@@ -0,0 +1,107 @@
+package terrain
+
+import (
+	"github.com/rubinda/GoWorld"
+	"math"
+)
+
+var (
+	// Attribute ranges for the personality weights
+	needWeightRange = &attributeRange{Min: 0.5, Max: 1.5}
+	cautionRange    = &attributeRange{Min: 0, Max: 1}
+
+	// How far around a spot beings look for predators when judging its risk
+	riskRadius = 8.
+	// The number of predators around a spot that make it as risky as it gets
+	maxRiskPredators = 3.
+)
+
+// randomPersonality creates a personality with random weights
+func (w *RandomWorld) randomPersonality() GoWorld.Personality {
+	return GoWorld.Personality{
+		Thirst:  needWeightRange.randomFloat(w.random),
+		Hunger:  needWeightRange.randomFloat(w.random),
+		Mating:  needWeightRange.randomFloat(w.random),
+		Caution: cautionRange.randomFloat(w.random),
+	}
+}
+
+// inheritPersonality mixes the personalities of both parents (with mutations)
+func (w *RandomWorld) inheritPersonality(b1, b2 *GoWorld.Being) GoWorld.Personality {
+	p1, p2 := b1.Personality, b2.Personality
+	return GoWorld.Personality{
+		Thirst:  w.MutateValues(p1.Thirst, p2.Thirst, b1.MutationRate, *needWeightRange),
+		Hunger:  w.MutateValues(p1.Hunger, p2.Hunger, b1.MutationRate, *needWeightRange),
+		Mating:  w.MutateValues(p1.Mating, p2.Mating, b1.MutationRate, *needWeightRange),
+		Caution: w.MutateValues(p1.Caution, p2.Caution, b1.MutationRate, *cautionRange),
+	}
+}
+
+// needFor returns how big the need behind the action is, weighted by the being personality
+func needFor(b *GoWorld.Being, action string) float64 {
+	switch action {
+	case "drink":
+		return b.Thirst * b.Personality.Thirst
+	case "eat":
+		return b.Hunger * b.Personality.Hunger
+	case "mate":
+		// Juveniles can not mate (nor beings that just mated)
+		if !canMate(b) {
+			return 0
+		}
+		return b.WantsChild * b.Personality.Mating
+	}
+	return 0
+}
+
+// isPredatorOf checks if the other being could eat the being
+func isPredatorOf(predator, b *GoWorld.Being) bool {
+	if predator.Type == b.Type {
+		return false
+	}
+	return predator.Type == "Carnivore" || predator.Type == "Flying" && bodySize(predator) > 2*bodySize(b)
+}
+
+// riskAt returns how dangerous the spot is for the being (0 no predators around, 1 very dangerous)
+func (w *RandomWorld) riskAt(b *GoWorld.Being, spot GoWorld.Location) float64 {
+	predators := 0.
+	for _, other := range w.BeingsAround(spot, riskRadius) {
+		if other.ID != b.ID && isPredatorOf(other, b) {
+			predators++
+		}
+	}
+	return math.Min(predators/maxRiskPredators, 1)
+}
+
+// utilityOf scores going to the spot for the action. The score grows with the need and drops with the distance to
+// the spot (relative to what the being can see) and with the predators around it (for cautious beings)
+func (w *RandomWorld) utilityOf(b *GoWorld.Being, action string, spot GoWorld.Location, sight float64) float64 {
+	distanceC := 1 / (1 + w.Distance(b.Position, spot)/math.Max(sight, 1))
+	riskC := 1 - b.Personality.Caution*w.riskAt(b, spot)
+	return needFor(b, action) * distanceC * riskC
+}
+
+// bestActionFor scores every need that can be fulfilled in sight and picks the best scoring one
+// Returns the action, its spot and false if no need can be fulfilled in sight
+func (w *RandomWorld) bestActionFor(b *GoWorld.Being, surroundings []GoWorld.Location, sight,
+	packStrength float64) (string, GoWorld.Location, bool) {
+	bestAction := ""
+	bestSpot := GoWorld.Location{}
+	bestScore := 0.
+	for _, action := range []string{"drink", "eat", "mate"} {
+		// The score can never be higher than the need itself, so skip searching when it can not win anyway
+		if need := needFor(b, action); need <= 0 || need <= bestScore {
+			continue
+		}
+		spot, found := w.findActionSpot(b, action, surroundings, packStrength)
+		if !found {
+			continue
+		}
+		if score := w.utilityOf(b, action, spot, sight); score > bestScore {
+			bestAction = action
+			bestSpot = spot
+			bestScore = score
+		}
+	}
+	return bestAction, bestSpot, bestAction != ""
+}
@@ -0,0 +1,79 @@
+package viewer
+
+// page draws the frames streamed from /state over the terrain (beings as dots in the color of their type, plants as
+// smaller dots) and reconnects when the stream breaks
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GoWorld</title>
+<style>
+body { font-family: sans-serif; background: #222; color: #ddd; margin: 1em; }
+canvas { image-rendering: pixelated; border: 1px solid #444; max-width: 100%; }
+#legend span { margin-right: 1em; }
+</style>
+</head>
+<body>
+<div id="status">Connecting ...</div>
+<canvas id="world"></canvas>
+<div id="legend"></div>
+<script>
+const colors = {
+	Carnivore: "#d62728", Water: "#1f77b4", Flying: "#ffffff", Scavenger: "#8c564b", Amphibian: "#2ca02c",
+	Insect: "#ffdd33", Land: "#7fbf3f", Carrion: "#555555", Egg: "#f4e7c3", Cache: "#c49c6b"
+};
+const canvas = document.getElementById("world");
+const context = canvas.getContext("2d");
+const status = document.getElementById("status");
+const terrain = new Image();
+terrain.src = "terrain.png";
+let latest = null;
+
+document.getElementById("legend").innerHTML = ["Carnivore", "Water", "Flying", "Scavenger", "Amphibian", "Insect"]
+	.map(type => '<span style="color: ' + colors[type] + '">&#9679; ' + type + '</span>').join("");
+
+function dot(d, size, fallback) {
+	context.fillStyle = colors[d.Type] || fallback;
+	context.fillRect(d.X - size / 2, d.Y - size / 2, size, size);
+}
+
+function draw() {
+	requestAnimationFrame(draw);
+	if (latest === null) {
+		return;
+	}
+	const f = latest;
+	latest = null;
+	if (canvas.width !== f.Width || canvas.height !== f.Height) {
+		canvas.width = f.Width;
+		canvas.height = f.Height;
+	}
+	if (terrain.complete) {
+		context.drawImage(terrain, 0, 0);
+	}
+	if (f.Night) {
+		context.fillStyle = "rgba(0, 0, 40, 0.4)";
+		context.fillRect(0, 0, f.Width, f.Height);
+	}
+	f.Plants.forEach(p => dot(p, 2, "#3a7d2c"));
+	f.Beings.forEach(b => dot(b, 4, "#ff00ff"));
+	status.textContent = "Epoch " + f.Epoch + ", " + f.Season + (f.Night ? " night" : "") + ", " +
+		f.Beings.length + " beings, " + f.Plants.length + " plants";
+}
+
+function connect() {
+	const scheme = location.protocol === "https:" ? "wss://" : "ws://";
+	const socket = new WebSocket(scheme + location.host + location.pathname.replace(/[^/]*$/, "") + "state");
+	socket.onmessage = message => { latest = JSON.parse(message.data); };
+	socket.onclose = () => {
+		status.textContent = "Disconnected, reconnecting ...";
+		setTimeout(connect, 1000);
+	};
+}
+
+connect();
+requestAnimationFrame(draw);
+</script>
+</body>
+</html>
+`
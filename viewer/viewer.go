@@ -0,0 +1,178 @@
+// Package viewer serves a page that shows a running world in the browser: the state of the world is streamed over a
+// WebSocket and drawn onto a canvas, so the simulation can be watched remotely or on machines without a display
+package viewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/rubinda/GoWorld/terrain"
+	"image/png"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// How many frames a slow client can fall behind before frames are skipped for it
+	frameBuffer = 4
+	// How long sending a frame to a client may take before the client is dropped
+	writeTimeout = 10 * time.Second
+	upgrader     = websocket.Upgrader{}
+)
+
+// Frame is the state of the world streamed to the viewers
+type Frame struct {
+	Epoch         uint64
+	Season        string
+	Night         bool
+	Width, Height int
+	Beings        []Dot
+	Plants        []Dot
+}
+
+// Dot is a being or a plant on the map
+type Dot struct {
+	X, Y int
+	Type string
+}
+
+// Server streams the state of a world to the browsers watching it
+type Server struct {
+	Every   uint64 // How many epochs pass between two frames
+	world   *terrain.RandomWorld
+	terrain []byte // The PNG of the colored terrain (at the start of the run)
+	lock    sync.Mutex
+	clients map[chan []byte]bool
+}
+
+// New starts following the world for the viewers, a frame is sent every few epochs
+// Returns an error if the terrain can not be encoded
+func New(world *terrain.RandomWorld, every uint64) (*Server, error) {
+	if every == 0 {
+		every = 1
+	}
+	var img bytes.Buffer
+	if err := png.Encode(&img, world.GetTerrainImage()); err != nil {
+		return nil, fmt.Errorf("error creating viewer: %v", err)
+	}
+	s := &Server{Every: every, world: world, terrain: img.Bytes(), clients: make(map[chan []byte]bool)}
+	world.Subscribe(func(action string, _ []uuid.UUID) {
+		if action == "ticked" && world.Epoch%s.Every == 0 {
+			s.broadcast()
+		}
+	})
+	return s, nil
+}
+
+// broadcast sends the current frame to every client (called while the world does not change)
+func (s *Server) broadcast() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.clients) == 0 {
+		// Nobody is watching
+		return
+	}
+	frame, err := json.Marshal(s.frame())
+	if err != nil {
+		fmt.Printf("error streaming world: %v\n", err)
+		return
+	}
+	for frames := range s.clients {
+		select {
+		case frames <- frame:
+		default:
+			// The client is too slow, it gets the next frame
+		}
+	}
+}
+
+// frame describes where the beings and plants currently are
+func (s *Server) frame() Frame {
+	width, height := s.world.GetSize()
+	f := Frame{
+		Epoch:  s.world.Epoch,
+		Season: s.world.Season(),
+		Night:  s.world.IsNight(),
+		Width:  width,
+		Height: height,
+		Beings: make([]Dot, 0, len(s.world.BeingList)),
+		Plants: make([]Dot, 0, len(s.world.FoodList)),
+	}
+	for _, b := range s.world.BeingList {
+		f.Beings = append(f.Beings, Dot{X: b.Position.X, Y: b.Position.Y, Type: b.Type})
+	}
+	for _, p := range s.world.FoodList {
+		f.Plants = append(f.Plants, Dot{X: p.Position.X, Y: p.Position.Y, Type: p.Type})
+	}
+	return f
+}
+
+// Handler returns the handler of the viewer: the page (/), the terrain (/terrain.png) and the stream (/state)
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	})
+	mux.HandleFunc("/terrain.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(s.terrain)
+	})
+	mux.HandleFunc("/state", s.stream)
+	return mux
+}
+
+// stream sends the frames to the client until it disconnects
+func (s *Server) stream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader already answered the request
+		return
+	}
+	defer conn.Close()
+	frames := make(chan []byte, frameBuffer)
+	s.lock.Lock()
+	s.clients[frames] = true
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.clients, frames)
+		s.lock.Unlock()
+	}()
+	// The client sends nothing, reading only notices when it is gone
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case frame := <-frames:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// ListenAndServe serves the viewer on the address (e.g. localhost:8080), it returns only when serving fails
+func (s *Server) ListenAndServe(address string) error {
+	if err := http.ListenAndServe(address, s.Handler()); err != nil {
+		return fmt.Errorf("error serving viewer: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package webhook posts JSON payloads to URLs when major events happen in a world (a being type dying out, the
+// population reaching a milestone, an autosave being written), so the owners of long unattended runs are alerted
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld/terrain"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// How long a post to a hook may take
+	timeout = 10 * time.Second
+	// The events hooks can receive
+	events = []string{"Extinction", "Milestone", "Autosave"}
+)
+
+// Hook is a URL that receives the events it asks for
+type Hook struct {
+	URL    string
+	Events []string // The events posted to the URL (all of them if empty): Extinction, Milestone or Autosave
+}
+
+// Payload is the JSON posted to the hooks
+type Payload struct {
+	Event      string
+	Time       time.Time // When the event happened (on the clock of the computer running the world)
+	Epoch      uint64    // The epoch the event happened at
+	Population int       // The number of living beings then
+	Type       string    `json:",omitempty"` // The being type that died out (Extinction)
+	Milestone  int       `json:",omitempty"` // The population reached (Milestone)
+	Path       string    `json:",omitempty"` // The file the world was saved into (Autosave)
+}
+
+// Notifier follows a world and posts its events to the hooks
+type Notifier struct {
+	Hooks      []Hook
+	Milestones []int // The populations that are posted once the world reaches them (each only the first time)
+	world      *terrain.RandomWorld
+	living     map[string]int // The number of beings of each type at the last epoch
+	reached    map[int]bool
+	client     *http.Client
+	posting    sync.WaitGroup
+}
+
+// New starts following the world for the hooks, the milestones the population already reached are not posted
+// Returns an error if a hook asks for an unknown event
+func New(world *terrain.RandomWorld, hooks []Hook, milestones []int) (*Notifier, error) {
+	for _, hook := range hooks {
+		for _, event := range hook.Events {
+			if !known(event) {
+				return nil, fmt.Errorf("error creating webhook %v: unknown event %v (one of %v)", hook.URL, event,
+					events)
+			}
+		}
+	}
+	n := &Notifier{
+		Hooks:      hooks,
+		Milestones: append([]int(nil), milestones...),
+		world:      world,
+		living:     world.Census(),
+		reached:    make(map[int]bool),
+		client:     &http.Client{Timeout: timeout},
+	}
+	sort.Ints(n.Milestones)
+	for _, milestone := range n.Milestones {
+		if len(world.BeingList) >= milestone {
+			n.reached[milestone] = true
+		}
+	}
+	world.Subscribe(func(action string, _ []uuid.UUID) {
+		if action == "ticked" {
+			n.tick()
+		}
+	})
+	return n, nil
+}
+
+// known checks if hooks can receive the event
+func known(event string) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// tick posts the types that died out in the epoch and the milestones the population reached
+func (n *Notifier) tick() {
+	living := n.world.Census()
+	types := make([]string, 0, len(n.living))
+	for beingType := range n.living {
+		types = append(types, beingType)
+	}
+	sort.Strings(types)
+	for _, beingType := range types {
+		if living[beingType] == 0 {
+			n.Notify(Payload{Event: "Extinction", Type: beingType})
+		}
+	}
+	n.living = living
+	for _, milestone := range n.Milestones {
+		if !n.reached[milestone] && len(n.world.BeingList) >= milestone {
+			n.reached[milestone] = true
+			n.Notify(Payload{Event: "Milestone", Milestone: milestone})
+		}
+	}
+}
+
+// Saved posts the autosave written into the path (set it as the Saved function of an autosave.Saver)
+func (n *Notifier) Saved(epoch uint64, path string) {
+	n.Notify(Payload{Event: "Autosave", Epoch: epoch, Path: path})
+}
+
+// Notify posts the event to every hook that asks for it, in the background (the world does not wait for slow hooks)
+// The time, epoch (unless set) and population of the payload are filled in from the world
+func (n *Notifier) Notify(p Payload) {
+	p.Time = time.Now()
+	if p.Epoch == 0 {
+		p.Epoch = n.world.Epoch
+	}
+	p.Population = len(n.world.BeingList)
+	body, err := json.Marshal(p)
+	if err != nil {
+		fmt.Printf("error posting %v to webhooks: %v\n", p.Event, err)
+		return
+	}
+	for _, hook := range n.Hooks {
+		if !hook.wants(p.Event) {
+			continue
+		}
+		n.posting.Add(1)
+		go n.post(hook.URL, body)
+	}
+}
+
+// wants checks if the hook asks for the event
+func (h Hook) wants(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// post sends the body to the URL (failures are only printed, the run goes on)
+func (n *Notifier) post(url string, body []byte) {
+	defer n.posting.Done()
+	response, err := n.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Printf("error posting to webhook: %v\n", err)
+		return
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		fmt.Printf("error posting to webhook %v: %v\n", url, response.Status)
+	}
+}
+
+// Wait waits for the posts still being sent (call it before the program exits)
+func (n *Notifier) Wait() {
+	n.posting.Wait()
+}
@@ -0,0 +1,439 @@
+package worldtest
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/terrain"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	// The number of epochs in a day and the days in a year when the world does not set them (as in the terrain)
+	defaultDayLength  uint64 = 3600
+	defaultYearLength uint64 = 4
+	// Seasons in the order they follow each other
+	seasons = [4]string{"Spring", "Summer", "Autumn", "Winter"}
+	// Adjacent directions (the order Wander tries them in)
+	directions8 = [8]GoWorld.Location{{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 0}, {X: 1, Y: 1},
+		{X: 0, Y: 1}, {X: -1, Y: 1}, {X: -1, Y: 0}}
+)
+
+// crossesWater checks if beings of the type move across the surfaces land beings can not (as in the terrain)
+func crossesWater(beingType string) bool {
+	switch beingType {
+	case "Flying", "Insect", "Water", "Amphibian":
+		return true
+	}
+	return false
+}
+
+// UpdateBeing asks the brain of the being (the World Brain if it has none) what to do and takes one step of the path
+// towards the spot of the action (see Decision), beings without any brain wander. The fake carries out no actions:
+// beings that sleep stay in place (Slept), the ones that rest or already are at the spot too (Rested), the others
+// only move (Wandered, Froze if another being stands on the next step or there is no path)
+func (w *World) UpdateBeing(b *GoWorld.Being) GoWorld.ActionResult {
+	result := GoWorld.ActionResult{Outcome: GoWorld.Wandered, Actor: b.ID}
+	brain := b.Brain
+	if brain == nil {
+		brain = w.Brain
+	}
+	if brain == nil {
+		if w.Wander(b) != nil {
+			result.Outcome = GoWorld.Froze
+		}
+		w.latest[b.ID] = latestUpdate{action: GoWorld.Action{Name: "wander"}, done: result.Outcome}
+		return result
+	}
+	action := brain.Decide(b, w.perceive(b))
+	var path []GoWorld.Location
+	switch {
+	case action.Name == "sleep":
+		result.Outcome = GoWorld.Slept
+	case action.Name == "rest" || action.Location == b.Position:
+		result.Outcome = GoWorld.Rested
+	default:
+		path = w.pathFor(b, action.Location)
+		if len(path) < 2 || !w.canStand(path[1], b.Type) {
+			result.Outcome = GoWorld.Froze
+		}
+	}
+	if b.ID == w.traced {
+		w.plan = &GoWorld.Plan{Action: action.Name, From: b.Position, Goal: action.Location,
+			Path: append([]GoWorld.Location(nil), path...)}
+	}
+	if result.Outcome == GoWorld.Wandered {
+		w.moveBeing(b, path[1])
+	}
+	w.latest[b.ID] = latestUpdate{action: action, decided: true, done: result.Outcome, path: path}
+	return result
+}
+
+// pathFor returns the moves of the being towards the goal (its own spot first), with the Pathfinder or in a straight
+// line without one
+func (w *World) pathFor(b *GoWorld.Being, goal GoWorld.Location) []GoWorld.Location {
+	if w.Pathfinder != nil {
+		return w.Pathfinder.GetPath(b.Position, goal, crossesWater(b.Type))
+	}
+	path := []GoWorld.Location{b.Position}
+	for spot := b.Position; spot != goal; {
+		spot = GoWorld.Location{X: spot.X + sign(goal.X-spot.X), Y: spot.Y + sign(goal.Y-spot.Y)}
+		path = append(path, spot)
+	}
+	return path
+}
+
+// sign returns -1, 0 or 1 for negative, zero and positive numbers
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	if n > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Decision returns the action the being decided on in its latest update (false if it was not updated since it was
+// added or it wandered without a brain)
+func (w *World) Decision(id uuid.UUID) (GoWorld.Action, bool) {
+	latest := w.latest[id]
+	return latest.action, latest.decided
+}
+
+// perceive returns the spots within the vision range of the being (at least the neighbouring ones, nothing hides
+// spots in the fake)
+func (w *World) perceive(b *GoWorld.Being) GoWorld.Perception {
+	sight := math.Max(b.VisionRange, 1.5)
+	var surroundings []GoWorld.Location
+	reach := int(sight)
+	for x := b.Position.X - reach; x <= b.Position.X+reach; x++ {
+		for y := b.Position.Y - reach; y <= b.Position.Y+reach; y++ {
+			spot := GoWorld.Location{X: x, Y: y}
+			if spot != b.Position && !w.IsOutOfBounds(spot) && w.Distance(b.Position, spot) <= sight {
+				surroundings = append(surroundings, spot)
+			}
+		}
+	}
+	return GoWorld.Perception{World: w, Surroundings: surroundings, Sight: sight}
+}
+
+// Wander moves the being to the first neighbouring spot it can stand on (trying them clockwise from the upper left,
+// so tests know where it goes)
+// Returns an error if the being can not move anywhere
+func (w *World) Wander(b *GoWorld.Being) error {
+	for _, d := range directions8 {
+		to := GoWorld.Location{X: b.Position.X + d.X, Y: b.Position.Y + d.Y}
+		if w.canStand(to, b.Type) {
+			w.moveBeing(b, to)
+			return nil
+		}
+	}
+	return fmt.Errorf("error wandering: being %v can not move from %v", b.ID, b.Position)
+}
+
+// ThrowBeing places the new being onto the first spot it can stand on (going through the columns from the left) and
+// adds it to the world
+// Returns an error if there is no room for the being or it can not be added (see AddBeing)
+func (w *World) ThrowBeing(b *GoWorld.Being) error {
+	for x := 0; x < w.Width; x++ {
+		for y := 0; y < w.Height; y++ {
+			if spot := (GoWorld.Location{X: x, Y: y}); w.canStand(spot, b.Type) {
+				b.Position = spot
+				return w.AddBeing(b)
+			}
+		}
+	}
+	return fmt.Errorf("error throwing being: no room for a being of type %v", b.Type)
+}
+
+// UpdatePlant does nothing, the plants of the fake do not grow (Grew)
+func (w *World) UpdatePlant(p *GoWorld.Food) GoWorld.ActionResult {
+	return GoWorld.ActionResult{Outcome: GoWorld.Grew, Actor: p.ID}
+}
+
+// AdvanceTime moves the world clock one epoch forward
+func (w *World) AdvanceTime() {
+	w.Epoch++
+}
+
+// IsNight returns true during the second half of the day
+func (w *World) IsNight() bool {
+	dayLength := w.DayLength
+	if dayLength == 0 {
+		dayLength = defaultDayLength
+	}
+	return w.Epoch%dayLength >= dayLength/2
+}
+
+// Season returns the current season (every season lasts a quarter of the year)
+func (w *World) Season() string {
+	dayLength := w.DayLength
+	if dayLength == 0 {
+		dayLength = defaultDayLength
+	}
+	yearLength := w.YearLength
+	if yearLength == 0 {
+		yearLength = defaultYearLength
+	}
+	day := (w.Epoch / dayLength) % yearLength
+	return seasons[day*uint64(len(seasons))/yearLength]
+}
+
+// TraceBeing keeps the plans of the being from now on (see PlannedPath), uuid.Nil stops tracing
+func (w *World) TraceBeing(id uuid.UUID) {
+	w.traced = id
+	w.plan = nil
+}
+
+// PlannedPath returns the latest plan of the traced being, false if it planned nothing in its latest update
+func (w *World) PlannedPath() (GoWorld.Plan, bool) {
+	if w.plan == nil {
+		return GoWorld.Plan{}, false
+	}
+	return *w.plan, true
+}
+
+// PerceptionFor returns the spots the being sees with the food and beings on them, along with its latest decision
+// Returns an error if there is no being with the ID
+func (w *World) PerceptionFor(id uuid.UUID) (GoWorld.PerceptionView, error) {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return GoWorld.PerceptionView{}, fmt.Errorf("error providing perception: no being with id %v", id)
+	}
+	p := w.perceive(b)
+	view := GoWorld.PerceptionView{Sight: p.Sight, Visible: p.Surroundings, Target: b.Target}
+	visible := make(map[GoWorld.Location]bool, len(p.Surroundings))
+	for _, spot := range p.Surroundings {
+		visible[spot] = true
+		if other := w.beingAt[spot]; other != uuid.Nil {
+			view.Beings = append(view.Beings, other)
+		}
+	}
+	w.ForEachFood(func(f *GoWorld.Food) bool {
+		return visible[f.Position]
+	}, func(f *GoWorld.Food) {
+		view.Food = append(view.Food, f.ID)
+	})
+	latest := w.latest[id]
+	view.Action, view.Goal = latest.action.Name, latest.action.Location
+	return view, nil
+}
+
+// DescribeBeing returns the being with its latest update (what it decided and did, the moves left) and the values
+// derived from it. The fake does not weigh age, stress and injuries in (the sight and pace are the attributes)
+// Returns an error if there is no being with the ID
+func (w *World) DescribeBeing(id uuid.UUID) (GoWorld.BeingDescription, error) {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return GoWorld.BeingDescription{}, fmt.Errorf("error describing being: no being with id %v", id)
+	}
+	d := GoWorld.BeingDescription{Being: *b}
+	d.Memory.Water = append([]GoWorld.Location(nil), b.Memory.Water...)
+	d.Memory.Food = append([]GoWorld.Location(nil), b.Memory.Food...)
+	latest := w.latest[id]
+	d.Action, d.Done, d.Goal = latest.action.Name, latest.done, latest.action.Location
+	for i := len(latest.path) - 1; i >= 0; i-- {
+		if latest.path[i] == b.Position {
+			d.PathLeft = len(latest.path) - 1 - i
+			break
+		}
+	}
+	d.Sight, d.Pace = w.perceive(b).Sight, b.Speed
+	if lifetime := b.Age + b.LifeExpectancy; lifetime > 0 {
+		d.Lived = math.Min(math.Max(b.Age/lifetime, 0), 1)
+	}
+	d.Juvenile = b.Age < b.MaturityAge
+	for _, other := range w.Beings {
+		if other.Parents[0] == b.ID || other.Parents[1] == b.ID {
+			d.Living++
+		}
+	}
+	d.Surface, _ = w.GetSurfaceNameAt(b.Position)
+	if prey := w.Beings[b.Target.String()]; prey != nil {
+		d.TargetPos = prey.Position
+	}
+	return d, nil
+}
+
+// PathCost returns the cost of the path the Pathfinder finds for a being of the type between the locations
+// Returns an error if the type is unknown, the world has no Pathfinder or there is no path
+func (w *World) PathCost(from, to GoWorld.Location, beingType string) (float64, error) {
+	if !terrain.KnownType(beingType) {
+		return 0, fmt.Errorf("error finding path cost: unknown being type %v", beingType)
+	}
+	if w.Pathfinder == nil {
+		return 0, fmt.Errorf("error finding path cost: the world has no pathfinder")
+	}
+	if w.IsOutOfBounds(from) || w.IsOutOfBounds(to) {
+		return 0, fmt.Errorf("error finding path cost: %v or %v is outside the world", from, to)
+	}
+	cost, found := w.Pathfinder.GetCost(from, to, crossesWater(beingType))
+	if !found {
+		return 0, fmt.Errorf("error finding path cost: no path from %v to %v for %v", from, to, beingType)
+	}
+	return cost, nil
+}
+
+// Species puts every being into the single species of its type (e.g. Insect-1), the fake does not compare genomes
+func (w *World) Species() map[string]string {
+	species := make(map[string]string, len(w.Beings))
+	for id, b := range w.Beings {
+		species[id] = b.Type + "-1"
+	}
+	return species
+}
+
+// SpeciesSummary returns how many beings of every type live (in the order of the types), the fake keeps no other
+// statistics
+func (w *World) SpeciesSummary() []GoWorld.SpeciesSummary {
+	populations := make(map[string]int)
+	for _, b := range w.Beings {
+		populations[b.Type]++
+	}
+	summaries := make([]GoWorld.SpeciesSummary, 0, len(populations))
+	for beingType, population := range populations {
+		summaries = append(summaries, GoWorld.SpeciesSummary{Species: beingType, Type: beingType,
+			Population: population})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Type < summaries[j].Type
+	})
+	return summaries
+}
+
+// PredatorPrey returns nothing, the fake takes no samples
+func (w *World) PredatorPrey() []GoWorld.PredatorPreySample {
+	return nil
+}
+
+// EnergyFlows returns nothing, the fake does not follow the energy of the food web
+func (w *World) EnergyFlows() []GoWorld.EnergyWindow {
+	return nil
+}
+
+// EnergyTotals returns nothing, the fake does not follow the energy of the food web
+func (w *World) EnergyTotals() []GoWorld.EnergyFlow {
+	return nil
+}
+
+// RecordTiming adds the duration to the phase (every duration is kept, see Timings)
+func (w *World) RecordTiming(phase string, d time.Duration) {
+	if w.timings == nil {
+		w.timings = make(map[string][]time.Duration)
+	}
+	w.timings[phase] = append(w.timings[phase], d)
+}
+
+// Timings returns the percentiles of the durations recorded for every phase (the fake times no phases itself)
+func (w *World) Timings() map[string]GoWorld.Timing {
+	timings := make(map[string]GoWorld.Timing, len(w.timings))
+	for phase, recorded := range w.timings {
+		sorted := append([]time.Duration(nil), recorded...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i] < sorted[j]
+		})
+		at := func(p float64) time.Duration {
+			return sorted[int(p*float64(len(sorted)-1))]
+		}
+		timings[phase] = GoWorld.Timing{P50: at(0.5), P90: at(0.9), P99: at(0.99), Max: sorted[len(sorted)-1]}
+	}
+	return timings
+}
+
+// The fake creates nothing at random, the beings and food are added by hand (see AddBeing and AddFood)
+
+// CreateCarnivores creates no beings
+// Returns an error (unless the quantity is 0)
+func (w *World) CreateCarnivores(quantity int) (int, error) {
+	return 0, notCreated("carnivores", quantity)
+}
+
+// CreateFishies creates no beings
+// Returns an error (unless the quantity is 0)
+func (w *World) CreateFishies(quantity int) (int, error) {
+	return 0, notCreated("fishies", quantity)
+}
+
+// CreateFlyers creates no beings
+// Returns an error (unless the quantity is 0)
+func (w *World) CreateFlyers(quantity int) (int, error) {
+	return 0, notCreated("flyers", quantity)
+}
+
+// CreateScavengers creates no beings
+// Returns an error (unless the quantity is 0)
+func (w *World) CreateScavengers(quantity int) (int, error) {
+	return 0, notCreated("scavengers", quantity)
+}
+
+// CreateAmphibians creates no beings
+// Returns an error (unless the quantity is 0)
+func (w *World) CreateAmphibians(quantity int) (int, error) {
+	return 0, notCreated("amphibians", quantity)
+}
+
+// CreateInsects creates no beings
+// Returns an error (unless the quantity is 0)
+func (w *World) CreateInsects(quantity int) (int, error) {
+	return 0, notCreated("insects", quantity)
+}
+
+// CreateRandomCarnivore creates no being
+// Returns an error
+func (w *World) CreateRandomCarnivore() (*GoWorld.Being, error) {
+	return nil, notCreated("carnivore", 1)
+}
+
+// ProvideFood creates no food
+// Returns an error (unless both quantities are 0)
+func (w *World) ProvideFood(landPlants, waterPlants int) (int, error) {
+	return 0, notCreated("food", landPlants+waterPlants)
+}
+
+// notCreated returns the error of the Create functions, nil if nothing was to be created
+func notCreated(what string, quantity int) error {
+	if quantity == 0 {
+		return nil
+	}
+	return fmt.Errorf("error creating %v: the fake world creates nothing at random (add it by hand)", what)
+}
+
+// The fake writes no files, the exports return an error
+
+// PlantsToJSON does not export the plants
+func (w *World) PlantsToJSON(fileName string) error {
+	return notExported(fileName)
+}
+
+// BeingsToJSON does not export the beings
+func (w *World) BeingsToJSON(fileName string) error {
+	return notExported(fileName)
+}
+
+// PlantsToCSV does not export the plants
+func (w *World) PlantsToCSV(fileName string) error {
+	return notExported(fileName)
+}
+
+// BeingsToCSV does not export the beings
+func (w *World) BeingsToCSV(fileName string) error {
+	return notExported(fileName)
+}
+
+// SpeciesToCSV does not export the species
+func (w *World) SpeciesToCSV(fileName string) error {
+	return notExported(fileName)
+}
+
+// Export does not export the world
+func (w *World) Export() error {
+	return notExported("the world")
+}
+
+// notExported returns the error of the exports
+func notExported(what string) error {
+	return fmt.Errorf("error exporting %v: the fake world writes no files", what)
+}
@@ -0,0 +1,746 @@
+// Package worldtest provides World, a small in-memory GoWorld.World whose surfaces, beings and food are set by hand
+// (nothing is generated), so pathfinding, brains and renderers can be developed and tested without a Perlin world.
+// The fake only moves the beings towards what their brains decide, it does not feed, breed or age them
+package worldtest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/pathing"
+	"github.com/rubinda/GoWorld/terrain"
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// Legend maps the characters of the maps drawn for Parse to the names of the surfaces (see terrain.Surfaces), a
+	// ford ('=') is water land beings can cross
+	Legend = map[rune]string{
+		'~': "Water",
+		'=': "Water",
+		'.': "Grassland",
+		'T': "Forest",
+		':': "Gravel",
+		'^': "Mountain",
+		'A': "Moutain Peak",
+	}
+)
+
+// The fake has to keep up with the interface
+var _ GoWorld.World = (*World)(nil)
+
+// World is a fake world on a grid of surfaces (Grassland unless set otherwise)
+type World struct {
+	Width, Height int
+	Epoch         uint64
+	DayLength     uint64                    // The number of epochs in a day (3600 if 0)
+	YearLength    uint64                    // The number of days in a year (4 if 0)
+	Beings        map[string]*GoWorld.Being // The beings living in the world (ID: Being)
+	Food          map[string]*GoWorld.Food  // The food on the map (ID: Food)
+	// Brain decides for the beings without a brain of their own (they wander if it is nil as well)
+	Brain GoWorld.Brain
+	// Pathfinder finds the paths of the beings and their costs (the A* of package pathing unless replaced)
+	Pathfinder GoWorld.Pathfinder
+
+	surfaces [][]int     // The position of the surface of every spot among terrain.Surfaces
+	heights  [][]float64 // The height of every spot (0 lowest, 1 highest)
+	fords    map[GoWorld.Location]bool
+	beingAt  map[GoWorld.Location]uuid.UUID
+	image    *image.RGBA // The colors of the surfaces
+	latest   map[uuid.UUID]latestUpdate
+	traced   uuid.UUID     // The being whose plans are kept (see TraceBeing)
+	plan     *GoWorld.Plan // The latest plan of the traced being
+	names    map[uuid.UUID]string
+	timings  map[string][]time.Duration
+}
+
+// latestUpdate is what a being decided and did in its latest update
+type latestUpdate struct {
+	action  GoWorld.Action
+	decided bool // A brain decided on the action (beings without one wander)
+	done    GoWorld.Outcome
+	path    []GoWorld.Location // The moves towards the spot of the action (the spot of the being first)
+}
+
+// New creates a world of the size covered with grassland, its heights follow the surfaces (see SetSurface)
+func New(width, height int) *World {
+	w := &World{
+		Width:    width,
+		Height:   height,
+		surfaces: make([][]int, width),
+		heights:  make([][]float64, width),
+		image:    image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+	grassland, _ := surfaceNamed("Grassland")
+	for x := 0; x < width; x++ {
+		w.surfaces[x] = make([]int, height)
+		w.heights[x] = make([]float64, height)
+		for y := 0; y < height; y++ {
+			w.setSurface(GoWorld.Location{X: x, Y: y}, grassland)
+		}
+	}
+	w.Pathfinder = pathing.NewPathfinder(w)
+	_ = w.New()
+	return w
+}
+
+// Parse creates a world from a map drawn with the characters of the Legend, a row for every Y (the top one is 0) and a
+// character for every X, e.g. Parse("..~..", ".T=T.", "..~..") is a river with a ford between two trees
+// Returns an error if there are no rows, the rows are not equally long or a character is not in the Legend
+func Parse(rows ...string) (*World, error) {
+	if len(rows) == 0 || rows[0] == "" {
+		return nil, fmt.Errorf("error parsing world: the map is empty")
+	}
+	width := len([]rune(rows[0]))
+	w := New(width, len(rows))
+	for y, row := range rows {
+		spots := []rune(row)
+		if len(spots) != width {
+			return nil, fmt.Errorf("error parsing world: row %d is %d spots wide instead of %d", y, len(spots),
+				width)
+		}
+		for x, spot := range spots {
+			name, known := Legend[spot]
+			if !known {
+				return nil, fmt.Errorf("error parsing world: unknown surface %q at (%d, %d)", spot, x, y)
+			}
+			location := GoWorld.Location{X: x, Y: y}
+			if err := w.SetSurface(location, name); err != nil {
+				return nil, fmt.Errorf("error parsing world: %v", err)
+			}
+			if spot == '=' {
+				w.fords[location] = true
+			}
+		}
+	}
+	return w, nil
+}
+
+// New removes all beings and food and turns the clock back, the surfaces stay as they are
+func (w *World) New() error {
+	w.Epoch = 0
+	w.Beings = make(map[string]*GoWorld.Being)
+	w.Food = make(map[string]*GoWorld.Food)
+	w.beingAt = make(map[GoWorld.Location]uuid.UUID)
+	if w.fords == nil {
+		w.fords = make(map[GoWorld.Location]bool)
+	}
+	w.latest = make(map[uuid.UUID]latestUpdate)
+	w.plan = nil
+	return nil
+}
+
+// surfaceNamed returns the position of the surface with the common name among terrain.Surfaces
+// Returns false if there is no such surface
+func surfaceNamed(name string) (int, bool) {
+	for i, surface := range terrain.Surfaces {
+		if surface.CommonName == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// SetSurface changes the surface at the location to the one with the common name (e.g. Forest), its height to the
+// middle of the elevation zone of the surface (see SetHeight). Beings that can not stand on the surface are removed
+// Returns an error if the location is out of bounds or there is no such surface
+func (w *World) SetSurface(location GoWorld.Location, name string) error {
+	if w.IsOutOfBounds(location) {
+		return fmt.Errorf("error setting surface: location %v is out of bounds", location)
+	}
+	surface, known := surfaceNamed(name)
+	if !known {
+		return fmt.Errorf("error setting surface: unknown surface %v", name)
+	}
+	w.setSurface(location, surface)
+	return nil
+}
+
+// SetSurfaceAt changes the surface at the location to the one with the ID (see terrain.Surfaces and SetSurface)
+// Returns an error if the location is out of bounds or there is no surface with the ID
+func (w *World) SetSurfaceAt(location GoWorld.Location, surfaceID uuid.UUID) error {
+	if w.IsOutOfBounds(location) {
+		return fmt.Errorf("error setting surface: location %v is out of bounds", location)
+	}
+	for i, surface := range terrain.Surfaces {
+		if surface.ID == surfaceID {
+			w.setSurface(location, i)
+			return nil
+		}
+	}
+	return fmt.Errorf("error setting surface: no surface with id %v", surfaceID)
+}
+
+// setSurface changes the surface at the location, fords dry up on other surfaces than water
+func (w *World) setSurface(location GoWorld.Location, surface int) {
+	w.surfaces[location.X][location.Y] = surface
+	w.heights[location.X][location.Y] = (float64(surface) + 0.5) / float64(len(terrain.Surfaces))
+	w.image.SetRGBA(location.X, location.Y, terrain.Surfaces[surface].Color)
+	if terrain.Surfaces[surface].CommonName != "Water" {
+		delete(w.fords, location)
+	}
+	if id := w.beingAt[location]; id != uuid.Nil && !w.suits(location, w.Beings[id.String()].Type) {
+		_ = w.RemoveBeing(id)
+	}
+}
+
+// SetHeight changes the height of the terrain at the location (0 lowest, 1 highest), e.g. for renderers
+// Returns an error if the location is out of bounds or the height is outside 0 - 1
+func (w *World) SetHeight(location GoWorld.Location, height float64) error {
+	if w.IsOutOfBounds(location) {
+		return fmt.Errorf("error setting height: location %v is out of bounds", location)
+	}
+	if height < 0 || height > 1 {
+		return fmt.Errorf("error setting height: %v is outside 0 - 1", height)
+	}
+	w.heights[location.X][location.Y] = height
+	return nil
+}
+
+// SetFord makes land beings able to cross the water at the location (or takes the ford away)
+// Returns an error if the location is out of bounds or not water
+func (w *World) SetFord(location GoWorld.Location, ford bool) error {
+	if w.IsOutOfBounds(location) {
+		return fmt.Errorf("error setting ford: location %v is out of bounds", location)
+	}
+	if name, _ := w.GetSurfaceNameAt(location); name != "Water" {
+		return fmt.Errorf("error setting ford: the surface at %v is %v, not water", location, name)
+	}
+	if ford {
+		w.fords[location] = true
+	} else {
+		delete(w.fords, location)
+	}
+	return nil
+}
+
+// suits checks if a being of the type could stand on the surface at the location (the same rules as in the terrain:
+// flyers go anywhere, water beings onto water and grassland, amphibians onto water and habitable surfaces, the others
+// onto habitable surfaces and fords)
+func (w *World) suits(location GoWorld.Location, beingType string) bool {
+	surface := terrain.Surfaces[w.surfaces[location.X][location.Y]]
+	switch beingType {
+	case "Flying", "Insect":
+		return true
+	case "Water":
+		return surface.CommonName == "Water" || surface.CommonName == "Grassland"
+	case "Amphibian":
+		return surface.CommonName == "Water" || surface.Habitable
+	}
+	return surface.Habitable || w.fords[location]
+}
+
+// canStand checks if a being of the type can be placed at the location (it is in bounds, free and suits it)
+func (w *World) canStand(location GoWorld.Location, beingType string) bool {
+	return !w.IsOutOfBounds(location) && w.beingAt[location] == uuid.Nil && w.suits(location, beingType)
+}
+
+// GetTerrainImage returns the colors of the surfaces (a pixel for every spot, kept up to date)
+func (w *World) GetTerrainImage() *image.RGBA {
+	return w.image
+}
+
+// GetBeings returns the living beings (ID: Being)
+func (w *World) GetBeings() map[string]*GoWorld.Being {
+	return w.Beings
+}
+
+// GetFood returns the food on the map (ID: Food)
+func (w *World) GetFood() map[string]*GoWorld.Food {
+	return w.Food
+}
+
+// GetSurfaceColorAtSpot returns the color of the surface at the location
+func (w *World) GetSurfaceColorAtSpot(spot GoWorld.Location) color.RGBA {
+	return w.image.RGBAAt(spot.X, spot.Y)
+}
+
+// GetSurfaceNameAt returns the common name of the surface at the location
+// Returns an error if the location is out of bounds
+func (w *World) GetSurfaceNameAt(location GoWorld.Location) (string, error) {
+	if w.IsOutOfBounds(location) {
+		return "", fmt.Errorf("error providing surface: location %v is out of bounds", location)
+	}
+	return terrain.Surfaces[w.surfaces[location.X][location.Y]].CommonName, nil
+}
+
+// GetHeightAt returns the height of the terrain at the location (0 lowest, 1 highest)
+// Returns an error if the location is out of bounds
+func (w *World) GetHeightAt(location GoWorld.Location) (float64, error) {
+	if w.IsOutOfBounds(location) {
+		return 0, fmt.Errorf("error providing height: location %v is out of bounds", location)
+	}
+	return w.heights[location.X][location.Y], nil
+}
+
+// GetBeingAt returns the ID of the being at the location (uuid.Nil if there is none)
+// Returns an error if the location is out of bounds
+func (w *World) GetBeingAt(location GoWorld.Location) (uuid.UUID, error) {
+	if w.IsOutOfBounds(location) {
+		return uuid.Nil, fmt.Errorf("error providing being: location %v is out of bounds", location)
+	}
+	return w.beingAt[location], nil
+}
+
+// GetSize returns the width and the height of the world
+func (w *World) GetSize() (int, int) {
+	return w.Width, w.Height
+}
+
+// IsHabitable returns if beings can move across the surface at the location (and plants grow on it)
+// Returns an error if the location is out of bounds
+func (w *World) IsHabitable(location GoWorld.Location) (bool, error) {
+	if w.IsOutOfBounds(location) {
+		return false, fmt.Errorf("error checking habitable spot: location %v is out of bounds", location)
+	}
+	return terrain.Surfaces[w.surfaces[location.X][location.Y]].Habitable, nil
+}
+
+// IsOutOfBounds returns true if the location is outside the world
+func (w *World) IsOutOfBounds(location GoWorld.Location) bool {
+	return location.X < 0 || location.X >= w.Width || location.Y < 0 || location.Y >= w.Height
+}
+
+// IsFord returns true if land beings can cross the water at the location (see SetFord)
+func (w *World) IsFord(location GoWorld.Location) bool {
+	return w.fords[location]
+}
+
+// GetFoodWithID returns the food with the ID (nil if there is none)
+func (w *World) GetFoodWithID(id uuid.UUID) *GoWorld.Food {
+	return w.Food[id.String()]
+}
+
+// GetBeingWithID returns the being with the ID (nil if there is none)
+func (w *World) GetBeingWithID(id uuid.UUID) *GoWorld.Being {
+	return w.Beings[id.String()]
+}
+
+// Distance returns the euclidean distance between the locations
+func (w *World) Distance(from, to GoWorld.Location) float64 {
+	return math.Hypot(float64(from.X-to.X), float64(from.Y-to.Y))
+}
+
+// AddBeing places the being onto the map at its position (its habitat is the surface there if it has none yet), a
+// being without an ID gets a new one
+// Returns an error if the being is of an unknown type, already lives in the world or can not stand at its position
+func (w *World) AddBeing(b *GoWorld.Being) error {
+	if b == nil {
+		return fmt.Errorf("error adding being: no being given")
+	}
+	if !terrain.KnownType(b.Type) {
+		return fmt.Errorf("error adding being: unknown being type %v", b.Type)
+	}
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	if _, exists := w.Beings[b.ID.String()]; exists {
+		return fmt.Errorf("error adding being: being %v already lives in the world", b.ID)
+	}
+	if !w.canStand(b.Position, b.Type) {
+		return fmt.Errorf("error adding being: a being of type %v can not be placed at %v", b.Type, b.Position)
+	}
+	if b.Habitat == uuid.Nil {
+		b.Habitat = terrain.Surfaces[w.surfaces[b.Position.X][b.Position.Y]].ID
+	}
+	w.beingAt[b.Position] = b.ID
+	w.Beings[b.ID.String()] = b
+	return nil
+}
+
+// RemoveBeing takes the being off the map
+// Returns an error if there is no being with the ID
+func (w *World) RemoveBeing(id uuid.UUID) error {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return fmt.Errorf("error removing being: no being with id %v", id)
+	}
+	delete(w.Beings, id.String())
+	delete(w.beingAt, b.Position)
+	delete(w.latest, id)
+	return nil
+}
+
+// AddFood places the food onto the map at its position, food without an ID gets a new one. The fake does not check
+// the surface, food can be put anywhere (also more of it onto the same spot)
+// Returns an error if the food is already on the map or its position is out of bounds
+func (w *World) AddFood(f *GoWorld.Food) error {
+	if f == nil {
+		return fmt.Errorf("error adding food: no food given")
+	}
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	if _, exists := w.Food[f.ID.String()]; exists {
+		return fmt.Errorf("error adding food: food %v is already on the map", f.ID)
+	}
+	if w.IsOutOfBounds(f.Position) {
+		return fmt.Errorf("error adding food: position %v is out of bounds", f.Position)
+	}
+	if f.Habitat == uuid.Nil {
+		f.Habitat = terrain.Surfaces[w.surfaces[f.Position.X][f.Position.Y]].ID
+	}
+	w.Food[f.ID.String()] = f
+	return nil
+}
+
+// RemoveFood takes the food off the map
+// Returns an error if there is no food with the ID
+func (w *World) RemoveFood(id uuid.UUID) error {
+	if _, exists := w.Food[id.String()]; !exists {
+		return fmt.Errorf("error removing food: no food with id %v", id)
+	}
+	delete(w.Food, id.String())
+	return nil
+}
+
+// KillBeing takes the being off the map, the fake leaves no body behind (the cause is not kept)
+// Returns an error if there is no being with the ID
+func (w *World) KillBeing(id uuid.UUID, cause string) error {
+	if w.Beings[id.String()] == nil {
+		return fmt.Errorf("error killing being: no being with id %v", id)
+	}
+	return w.RemoveBeing(id)
+}
+
+// FeedBeing lowers the hunger of the being by the amount (not below zero)
+// Returns an error if there is no being with the ID or the amount is negative
+func (w *World) FeedBeing(id uuid.UUID, amount float64) error {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return fmt.Errorf("error feeding being: no being with id %v", id)
+	}
+	if amount < 0 {
+		return fmt.Errorf("error feeding being: negative amount %v", amount)
+	}
+	b.Hunger = math.Max(b.Hunger-amount, 0)
+	return nil
+}
+
+// HealBeing lowers the injury of the being by the amount (not below zero)
+// Returns an error if there is no being with the ID or the amount is negative
+func (w *World) HealBeing(id uuid.UUID, amount float64) error {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return fmt.Errorf("error healing being: no being with id %v", id)
+	}
+	if amount < 0 {
+		return fmt.Errorf("error healing being: negative amount %v", amount)
+	}
+	b.Injury = math.Max(b.Injury-amount, 0)
+	return nil
+}
+
+// SetAttribute sets the named numeric attribute of the being (named as the Being field, e.g. Speed). The fake does
+// not know the ranges of the attributes, it only refuses negative values
+// Returns an error if there is no being with the ID, no such attribute or the value is negative
+func (w *World) SetAttribute(id uuid.UUID, name string, value float64) error {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return fmt.Errorf("error setting attribute: no being with id %v", id)
+	}
+	attribute := reflect.ValueOf(b).Elem().FieldByName(name)
+	if !attribute.IsValid() || attribute.Kind() != reflect.Float64 {
+		return fmt.Errorf("error setting attribute: unknown attribute %v", name)
+	}
+	if value < 0 {
+		return fmt.Errorf("error setting attribute: negative value %v of %v", value, name)
+	}
+	attribute.SetFloat(value)
+	return nil
+}
+
+// TeleportBeing moves the being straight to the location (it has to be a spot the being could stand on)
+// Returns an error if there is no being with the ID or it can not stand at the location
+func (w *World) TeleportBeing(id uuid.UUID, to GoWorld.Location) error {
+	b := w.Beings[id.String()]
+	if b == nil {
+		return fmt.Errorf("error teleporting being: no being with id %v", id)
+	}
+	if to == b.Position {
+		return nil
+	}
+	if !w.canStand(to, b.Type) {
+		return fmt.Errorf("error teleporting being: a being of type %v can not be placed at %v", b.Type, to)
+	}
+	w.moveBeing(b, to)
+	return nil
+}
+
+// moveBeing moves the being to the location (it has to be free)
+func (w *World) moveBeing(b *GoWorld.Being, to GoWorld.Location) {
+	delete(w.beingAt, b.Position)
+	w.beingAt[to] = b.ID
+	b.Heading = GoWorld.Location{X: to.X - b.Position.X, Y: to.Y - b.Position.Y}
+	b.Position = to
+}
+
+// Snapshot returns a deep copy of the beings, food and the colored terrain
+func (w *World) Snapshot() *GoWorld.Snapshot {
+	s := &GoWorld.Snapshot{
+		Epoch:  w.Epoch,
+		Season: w.Season(),
+		Night:  w.IsNight(),
+		Width:  w.Width,
+		Height: w.Height,
+		Beings: make(map[string]GoWorld.Being, len(w.Beings)),
+		Food:   make(map[string]GoWorld.Food, len(w.Food)),
+	}
+	for id, b := range w.Beings {
+		s.Beings[id] = copyBeing(*b)
+	}
+	for id, f := range w.Food {
+		s.Food[id] = copyFood(*f)
+	}
+	s.Terrain = image.NewRGBA(w.image.Bounds())
+	copy(s.Terrain.Pix, w.image.Pix)
+	return s
+}
+
+// Restore replaces the beings, food and time with the ones from the snapshot (the surfaces stay as they are)
+// Returns an error if the snapshot is of a world with another size or a being can not stand at its position
+func (w *World) Restore(s *GoWorld.Snapshot) error {
+	if s.Width != w.Width || s.Height != w.Height {
+		return fmt.Errorf("error restoring snapshot: the snapshot is %dx%d, the world %dx%d", s.Width, s.Height,
+			w.Width, w.Height)
+	}
+	_ = w.New()
+	w.Epoch = s.Epoch
+	for _, f := range s.Food {
+		food := copyFood(f)
+		w.Food[food.ID.String()] = &food
+	}
+	for _, b := range s.Beings {
+		being := copyBeing(b)
+		if err := w.AddBeing(&being); err != nil {
+			return fmt.Errorf("error restoring snapshot: %v", err)
+		}
+	}
+	return nil
+}
+
+// copyBeing returns a copy of the being that shares no slices with it
+func copyBeing(being GoWorld.Being) GoWorld.Being {
+	being.Memory.Water = append([]GoWorld.Location(nil), being.Memory.Water...)
+	being.Memory.Food = append([]GoWorld.Location(nil), being.Memory.Food...)
+	being.Tags = append([]string(nil), being.Tags...)
+	return being
+}
+
+// copyFood returns a copy of the food that shares no slices with it
+func copyFood(food GoWorld.Food) GoWorld.Food {
+	food.Tags = append([]string(nil), food.Tags...)
+	return food
+}
+
+// Hash returns a digest of the time, the beings, the food and the surfaces
+func (w *World) Hash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d;%v;%v;", w.Epoch, w.surfaces, w.heights)
+	w.ForEachBeing(nil, func(b *GoWorld.Being) {
+		being := *b
+		being.Brain = nil
+		fmt.Fprintf(h, "%+v;", being)
+	})
+	w.ForEachFood(nil, func(f *GoWorld.Food) {
+		fmt.Fprintf(h, "%+v;", *f)
+	})
+	fords := make([]string, 0, len(w.fords))
+	for location := range w.fords {
+		fords = append(fords, fmt.Sprintf("%09d,%09d", location.X, location.Y))
+	}
+	sort.Strings(fords)
+	fmt.Fprintf(h, "%v", fords)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// ForEachBeing calls fn for every being the filter accepts (all of them if the filter is nil), in the order of their
+// IDs. Beings removed while iterating are skipped, the ones added are not visited
+func (w *World) ForEachBeing(filter func(*GoWorld.Being) bool, fn func(*GoWorld.Being)) {
+	ids := make([]string, 0, len(w.Beings))
+	for id := range w.Beings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if b, alive := w.Beings[id]; alive && (filter == nil || filter(b)) {
+			fn(b)
+		}
+	}
+}
+
+// ForEachFood calls fn for every food the filter accepts (all of it if the filter is nil), in the order of their IDs.
+// Food removed while iterating is skipped, new food is not visited
+func (w *World) ForEachFood(filter func(*GoWorld.Food) bool, fn func(*GoWorld.Food)) {
+	ids := make([]string, 0, len(w.Food))
+	for id := range w.Food {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if f, exists := w.Food[id]; exists && (filter == nil || filter(f)) {
+			fn(f)
+		}
+	}
+}
+
+// Query returns the IDs of the beings and then the food matching the expression (see terrain.Query), each in the
+// order of their IDs
+// Returns an error if the expression can not be parsed
+func (w *World) Query(expression string) ([]uuid.UUID, error) {
+	q, err := terrain.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	w.ForEachBeing(q.MatchBeing, func(b *GoWorld.Being) {
+		ids = append(ids, b.ID)
+	})
+	w.ForEachFood(q.MatchFood, func(f *GoWorld.Food) {
+		ids = append(ids, f.ID)
+	})
+	return ids, nil
+}
+
+// FindNearestWater returns the water spot closest to the location (the first one of equally close spots, going
+// through the columns from the left)
+// Returns false if there is no water in the world
+func (w *World) FindNearestWater(from GoWorld.Location) (GoWorld.Location, bool) {
+	closest := GoWorld.Location{}
+	closestDist := 0.
+	found := false
+	for x := 0; x < w.Width; x++ {
+		for y := 0; y < w.Height; y++ {
+			spot := GoWorld.Location{X: x, Y: y}
+			if name, _ := w.GetSurfaceNameAt(spot); name != "Water" {
+				continue
+			}
+			if dist := w.Distance(from, spot); !found || dist < closestDist {
+				closest, closestDist, found = spot, dist, true
+			}
+		}
+	}
+	return closest, found
+}
+
+// FindNearestFood returns the food closest to the location that a being of the diet (its type) can eat (see
+// terrain.CanEat), any food if the diet is empty (the one with the lower ID of equally close food)
+// Returns nil if there is no such food
+func (w *World) FindNearestFood(from GoWorld.Location, diet string) *GoWorld.Food {
+	var closest *GoWorld.Food
+	closestDist := 0.
+	w.ForEachFood(func(f *GoWorld.Food) bool {
+		return diet == "" || terrain.CanEat(diet, f)
+	}, func(f *GoWorld.Food) {
+		if dist := w.Distance(from, f.Position); closest == nil || dist < closestDist {
+			closest, closestDist = f, dist
+		}
+	})
+	return closest
+}
+
+// FindNearestBeing returns the being closest to the location that the filter accepts (any being if the filter is nil,
+// the one with the lower ID of equally close beings)
+// Returns nil if no being is accepted
+func (w *World) FindNearestBeing(from GoWorld.Location, filter func(*GoWorld.Being) bool) *GoWorld.Being {
+	var closest *GoWorld.Being
+	closestDist := 0.
+	w.ForEachBeing(filter, func(b *GoWorld.Being) {
+		if dist := w.Distance(from, b.Position); closest == nil || dist < closestDist {
+			closest, closestDist = b, dist
+		}
+	})
+	return closest
+}
+
+// NameEntity gives the being or food a name (an empty name removes it), the name stays known once the being or food
+// is gone (see EntityName)
+// Returns an error if there is no being or food with the ID
+func (w *World) NameEntity(id uuid.UUID, name string) error {
+	name = strings.TrimSpace(name)
+	if b, ok := w.Beings[id.String()]; ok {
+		b.Name = name
+	} else if f, ok := w.Food[id.String()]; ok {
+		f.Name = name
+	} else {
+		return fmt.Errorf("error naming entity: no being or food with id %v", id)
+	}
+	if w.names == nil {
+		w.names = make(map[uuid.UUID]string)
+	}
+	if name == "" {
+		delete(w.names, id)
+	} else {
+		w.names[id] = name
+	}
+	return nil
+}
+
+// TagEntity adds the tag to the being or food (nothing changes if it already has it), tags are compared without case
+// Returns an error if the tag is empty or holds spaces or if there is no being or food with the ID
+func (w *World) TagEntity(id uuid.UUID, tag string) error {
+	if tag == "" || strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("error tagging entity: the tag %q is empty or holds spaces", tag)
+	}
+	tags, err := w.entityTags(id)
+	if err != nil {
+		return fmt.Errorf("error tagging entity: %v", err)
+	}
+	for _, t := range *tags {
+		if strings.EqualFold(t, tag) {
+			return nil
+		}
+	}
+	// A new slice, the snapshots share the old one
+	*tags = append((*tags)[:len(*tags):len(*tags)], tag)
+	return nil
+}
+
+// UntagEntity removes the tag from the being or food (nothing changes if it does not have it)
+// Returns an error if there is no being or food with the ID
+func (w *World) UntagEntity(id uuid.UUID, tag string) error {
+	tags, err := w.entityTags(id)
+	if err != nil {
+		return fmt.Errorf("error untagging entity: %v", err)
+	}
+	var kept []string
+	for _, t := range *tags {
+		if !strings.EqualFold(t, tag) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) != len(*tags) {
+		*tags = kept
+	}
+	return nil
+}
+
+// EntityName returns the name of the being or food, also of the ones that are gone (empty if it has none)
+func (w *World) EntityName(id uuid.UUID) string {
+	if b, ok := w.Beings[id.String()]; ok {
+		return b.Name
+	}
+	if f, ok := w.Food[id.String()]; ok {
+		return f.Name
+	}
+	return w.names[id]
+}
+
+// entityTags returns the tags of the being or food with the ID
+func (w *World) entityTags(id uuid.UUID) (*[]string, error) {
+	if b, ok := w.Beings[id.String()]; ok {
+		return &b.Tags, nil
+	}
+	if f, ok := w.Food[id.String()]; ok {
+		return &f.Tags, nil
+	}
+	return nil, fmt.Errorf("no being or food with id %v", id)
+}
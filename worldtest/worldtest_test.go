@@ -0,0 +1,147 @@
+package worldtest
+
+import (
+	"github.com/google/uuid"
+	"github.com/rubinda/GoWorld"
+	"github.com/rubinda/GoWorld/pathing"
+	"github.com/rubinda/GoWorld/terrain"
+	"reflect"
+	"testing"
+)
+
+// mirrored returns the fake world parsed from the rows and a seeded terrain world of the same size with the same
+// surfaces and fords, so both can be asked the same questions
+func mirrored(t *testing.T, rows ...string) (*World, *terrain.RandomWorld) {
+	t.Helper()
+	fake, err := Parse(rows...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terrainWorld, err := terrain.NewRandomWorld(fake.Width, fake.Height, terrain.WithSeed(1), terrain.WithTerrainImage(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y, row := range rows {
+		for x, spot := range []rune(row) {
+			location := GoWorld.Location{X: x, Y: y}
+			surface, _ := surfaceNamed(Legend[spot])
+			if err := terrainWorld.SetSurfaceAt(location, terrain.Surfaces[surface].ID); err != nil {
+				t.Fatal(err)
+			}
+			terrainWorld.TerrainSpots[x][y].Ford = spot == '='
+		}
+	}
+	return fake, terrainWorld
+}
+
+// TestPathfinding checks that the pathfinder finds the same paths with the same costs in the fake and the terrain
+func TestPathfinding(t *testing.T) {
+	river := []string{
+		"..~..",
+		".T=T.",
+		"..~..",
+	}
+	tests := []struct {
+		name      string
+		rows      []string
+		from, to  GoWorld.Location
+		beingType string
+		found     bool
+		via       GoWorld.Location // A spot the path has to go through
+	}{
+		{"ford", river, GoWorld.Location{X: 0, Y: 1}, GoWorld.Location{X: 4, Y: 1}, "Carnivore", true,
+			GoWorld.Location{X: 2, Y: 1}},
+		{"no ford", []string{"..~..", "..~..", "..~.."}, GoWorld.Location{X: 0, Y: 1}, GoWorld.Location{X: 4, Y: 1},
+			"Carnivore", false, GoWorld.Location{}},
+		{"flying over water", []string{"..~..", "..~..", "..~.."}, GoWorld.Location{X: 0, Y: 1},
+			GoWorld.Location{X: 4, Y: 1}, "Flying", true, GoWorld.Location{X: 2, Y: 1}},
+		{"around the peaks", []string{".....", "AAAA.", "....."}, GoWorld.Location{X: 2, Y: 0},
+			GoWorld.Location{X: 2, Y: 2}, "Flying", true, GoWorld.Location{X: 4, Y: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake, terrainWorld := mirrored(t, test.rows...)
+			fakeCost, fakeErr := fake.PathCost(test.from, test.to, test.beingType)
+			terrainCost, terrainErr := terrainWorld.PathCost(test.from, test.to, test.beingType)
+			if (fakeErr == nil) != test.found || (terrainErr == nil) != test.found {
+				t.Fatalf("found a path: fake %v, terrain %v, want %v", fakeErr == nil, terrainErr == nil, test.found)
+			}
+			if fakeCost != terrainCost {
+				t.Errorf("path cost: fake %v, terrain %v", fakeCost, terrainCost)
+			}
+			if !test.found {
+				return
+			}
+			crossesWater := test.beingType == "Flying"
+			fakePath := pathing.NewPathfinder(fake).GetPath(test.from, test.to, crossesWater)
+			terrainPath := pathing.NewPathfinder(terrainWorld).GetPath(test.from, test.to, crossesWater)
+			if !reflect.DeepEqual(fakePath, terrainPath) {
+				t.Fatalf("path: fake %v, terrain %v", fakePath, terrainPath)
+			}
+			for _, spot := range fakePath {
+				if spot == test.via {
+					return
+				}
+			}
+			t.Errorf("path %v does not go through %v", fakePath, test.via)
+		})
+	}
+}
+
+// TestBuiltinBrain checks that the beings of the fake decide like the ones of the terrain when the built-in brain
+// decides for them and that they move towards what they decided on
+func TestBuiltinBrain(t *testing.T) {
+	rows := []string{
+		".......",
+		"~......",
+		".......",
+	}
+	tests := []struct {
+		name     string
+		being    GoWorld.Being
+		action   string
+		location GoWorld.Location
+	}{
+		{"thirsty drinks", GoWorld.Being{Type: "Carnivore", Thirst: 200, Energy: 200},
+			"drink", GoWorld.Location{X: 0, Y: 0}},
+		{"exhausted rests", GoWorld.Being{Type: "Carnivore", Thirst: 200},
+			"rest", GoWorld.Location{X: 4, Y: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake, terrainWorld := mirrored(t, rows...)
+			fake.Brain = terrain.BuiltinBrain{World: terrainWorld}
+			being := test.being
+			being.ID = uuid.New()
+			being.Position = GoWorld.Location{X: 4, Y: 1}
+			being.VisionRange = 6
+			being.LifeExpectancy = 30
+			being.Personality = GoWorld.Personality{Thirst: 1, Hunger: 1, Mating: 1}
+			fakeBeing, terrainBeing := being, being
+			if err := fake.AddBeing(&fakeBeing); err != nil {
+				t.Fatal(err)
+			}
+			if err := terrainWorld.AddBeing(&terrainBeing); err != nil {
+				t.Fatal(err)
+			}
+
+			want := terrain.BuiltinBrain{World: terrainWorld}.Decide(&terrainBeing, terrainWorld.Perceive(&terrainBeing))
+			if want.Name != test.action || want.Location != test.location {
+				t.Fatalf("terrain decided %v at %v, want %v at %v", want.Name, want.Location, test.action,
+					test.location)
+			}
+			fake.UpdateBeing(&fakeBeing)
+			got, decided := fake.Decision(fakeBeing.ID)
+			if !decided || got != want {
+				t.Fatalf("fake decided %v at %v, terrain %v at %v", got.Name, got.Location, want.Name, want.Location)
+			}
+			// The being takes one step of its path towards the spot (or stays to rest)
+			moved := fake.Distance(being.Position, fakeBeing.Position)
+			if test.action == "rest" && moved != 0 || test.action != "rest" && (moved == 0 || moved >= 2 ||
+				fake.Distance(fakeBeing.Position, want.Location) >= fake.Distance(being.Position, want.Location)) {
+				t.Errorf("being moved from %v to %v for %v at %v", being.Position, fakeBeing.Position, want.Name,
+					want.Location)
+			}
+		})
+	}
+}